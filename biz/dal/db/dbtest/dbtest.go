@@ -0,0 +1,33 @@
+// Package dbtest 为测试创建临时的 SQLite 数据库（纯 Go 驱动，无需 MySQL 与 cgo）
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"memogo/biz/dal/db"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open 在测试的临时目录中创建数据库并建好全部数据表，测试结束后自动删除；
+// 事务以 BEGIN IMMEDIATE 开始并等待写锁，并发写入的测试不会因 SQLITE_BUSY 失败
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "memogo.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	conn, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return conn
+}
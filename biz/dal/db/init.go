@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"memogo/biz/dal/model"
+	"memogo/pkg/collation"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -41,13 +42,81 @@ func Init() {
 	}
 
 	// 自动迁移
-	if err := DB.AutoMigrate(&model.User{}, &model.Todo{}); err != nil {
+	if err := Migrate(DB); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+	if err := backfillTitleSortKeys(DB); err != nil {
+		log.Fatalf("Failed to backfill title sort keys: %v", err)
+	}
 
 	log.Println("Database initialized successfully")
 }
 
+// Migrate 自动迁移全部数据表（测试中也用它为临时数据库建表）
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&model.User{},
+		&model.Todo{},
+		&model.ChatAccount{},
+		&model.Project{},
+		&model.IssueConnector{},
+		&model.IssueLink{},
+		&model.IngestRecord{},
+		&model.IncomingWebhook{},
+		&model.UserSetting{},
+		&model.AuditLog{},
+		&model.FeatureFlag{},
+		&model.Note{},
+		&model.NoteLink{},
+		&model.Goal{},
+		&model.GoalLink{},
+		&model.TodoStatusChange{},
+		&model.TodoDueChange{},
+		&model.Sprint{},
+		&model.SprintItem{},
+		&model.Habit{},
+		&model.HabitCheckIn{},
+		&model.TodoTransfer{},
+		&model.Delegation{},
+		&model.TodoTag{},
+		&model.AuthEvent{},
+		&model.ExportWatermark{},
+		&model.Plan{},
+		&model.PlanAssignment{},
+		&model.BulkUpdate{},
+	)
+}
+
+// backfillTitleSortKeys 为新增排序键列之前写入的待办补算标题排序键（含已删除的），分批执行
+func backfillTitleSortKeys(db *gorm.DB) error {
+	var filled int
+	var lastID uint
+	for {
+		// 按 ID 推进：只含可忽略字符的标题排序键仍为空，不能靠重新查询判断是否完成
+		var todos []model.Todo
+		if err := db.Unscoped().Select("id", "title").
+			Where("id > ? AND title_sort_key = '' AND title <> ''", lastID).
+			Order("id ASC").Limit(500).Find(&todos).Error; err != nil {
+			return err
+		}
+		if len(todos) == 0 {
+			break
+		}
+		lastID = todos[len(todos)-1].ID
+		for _, t := range todos {
+			if err := db.Unscoped().Model(&model.Todo{}).Where("id = ?", t.ID).
+				UpdateColumn("title_sort_key", collation.Key(t.Title)).Error; err != nil {
+				return err
+			}
+		}
+		filled += len(todos)
+	}
+	if filled > 0 {
+		log.Printf("Backfilled title sort keys for %d todos", filled)
+	}
+	return nil
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
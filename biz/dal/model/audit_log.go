@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// AuditLog 审计日志：记录账号合并等敏感操作，只增不改
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ActorID    uint   `gorm:"index;not null" json:"actor_id"` // 操作人
+	Action     string `gorm:"size:50;index;not null" json:"action"`
+	TargetType string `gorm:"size:30" json:"target_type"`
+	TargetID   uint   `gorm:"index" json:"target_id"`
+	Detail     string `gorm:"type:text" json:"detail"` // JSON
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
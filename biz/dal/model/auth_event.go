@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// AuthEvent 认证事件：注册、登录（含失败）与刷新令牌，只增不改，供安全审计与数据分析
+type AuthEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID    uint   `gorm:"index;not null;default:0" json:"user_id"` // 用户不存在（如用户名错误）时为 0
+	Username  string `gorm:"size:50;not null;default:''" json:"username"`
+	Event     string `gorm:"size:20;index;not null" json:"event"`
+	IP        string `gorm:"size:64;not null;default:''" json:"ip"`
+	UserAgent string `gorm:"size:255;not null;default:''" json:"user_agent"`
+}
+
+// 认证事件类型
+const (
+	AuthEventRegister      = "register"
+	AuthEventLogin         = "login"
+	AuthEventLoginFailed   = "login_failed"
+	AuthEventRefresh       = "refresh"
+	AuthEventRefreshFailed = "refresh_failed"
+)
+
+// TableName 指定表名
+func (AuthEvent) TableName() string {
+	return "auth_events"
+}
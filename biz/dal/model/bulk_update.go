@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// BulkUpdate 按筛选表达式批量操作待办：预览时保存命中的待办集合指纹与确认令牌，凭令牌执行一次
+type BulkUpdate struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID uint   `gorm:"index;not null" json:"user_id"`
+	Token  string `gorm:"size:64;uniqueIndex;not null" json:"-"`
+
+	Query  string     `gorm:"size:1000;not null" json:"query"` // 规范化后的筛选表达式
+	Action BulkAction `gorm:"serializer:json;type:text" json:"action"`
+
+	Matched     int    `gorm:"not null" json:"matched"`
+	Fingerprint string `gorm:"size:64;not null" json:"-"` // 命中待办 ID 集合的摘要，执行时集合变化则拒绝
+
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	ExecutedAt *time.Time `json:"executed_at"`
+	Affected   int64      `gorm:"not null;default:0" json:"affected"`
+}
+
+// BulkAction 批量操作及其参数
+type BulkAction struct {
+	Type      string `json:"type"`
+	Status    string `json:"status,omitempty"`     // set_status：todo | done
+	Tag       string `json:"tag,omitempty"`        // add_tag
+	ProjectID uint   `json:"project_id,omitempty"` // move：0 表示移出项目
+	DueTime   int64  `json:"due_time,omitempty"`   // set_due：Unix 秒，0 表示清空
+}
+
+// 批量操作类型
+const (
+	BulkSetStatus = "set_status"
+	BulkAddTag    = "add_tag"
+	BulkMove      = "move"
+	BulkDelete    = "delete"
+	BulkSetDue    = "set_due"
+)
+
+// TableName 指定表名
+func (BulkUpdate) TableName() string {
+	return "bulk_updates"
+}
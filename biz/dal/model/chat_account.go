@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChatAccount 聊天平台账号与 memogo 用户的绑定关系
+type ChatAccount struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Provider   string `gorm:"uniqueIndex:idx_chat_account;not null;size:20" json:"provider"` // 例如 "slack"
+	TeamID     string `gorm:"uniqueIndex:idx_chat_account;not null;size:64" json:"team_id"`
+	ChatUserID string `gorm:"uniqueIndex:idx_chat_account;not null;size:64" json:"chat_user_id"`
+
+	// UserID 为 0 表示尚未绑定
+	UserID uint `gorm:"index;not null;default:0" json:"user_id"`
+
+	// 一次性绑定码：用户在聊天中执行 `/todo link` 获取，再通过已登录的 API 提交完成绑定
+	LinkCode          string     `gorm:"index;size:16" json:"-"`
+	LinkCodeExpiresAt *time.Time `json:"-"`
+}
+
+// TableName 指定表名
+func (ChatAccount) TableName() string {
+	return "chat_accounts"
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Delegation 待办的“等待他人”状态：委托给 memogo 之外的人，超过预计日期后自动生成跟进待办
+type Delegation struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID       uint   `gorm:"index;not null" json:"user_id"`
+	TodoID       uint   `gorm:"uniqueIndex;not null" json:"todo_id"`
+	Delegate     string `gorm:"size:100;not null" json:"delegate"`           // 被委托人
+	Contact      string `gorm:"size:200;not null;default:''" json:"contact"` // 联系方式（邮箱、电话等）
+	ExpectedDate string `gorm:"size:10;not null;index" json:"expected_date"` // 2006-01-02，用户时区
+
+	// 跟进记录：FollowUpTodoID 为最近一次生成的跟进待办
+	FollowUps      int        `gorm:"not null;default:0" json:"follow_ups"`
+	LastFollowUpAt *time.Time `json:"last_follow_up_at"`
+	FollowUpTodoID *uint      `json:"follow_up_todo_id"`
+}
+
+// TableName 指定表名
+func (Delegation) TableName() string {
+	return "delegations"
+}
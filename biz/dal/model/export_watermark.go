@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ExportWatermark 分析数据导出的进度：每个数据集记录已导出的最后一行，下次只导出其后的变更
+type ExportWatermark struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Dataset  string    `gorm:"size:30;uniqueIndex;not null" json:"dataset"`
+	LastTime time.Time `json:"last_time"` // 最后一行的变更时间（todos 为 updated_at，事件表为 created_at）
+	LastID   uint      `gorm:"not null;default:0" json:"last_id"`
+	Rows     int64     `gorm:"not null;default:0" json:"rows"` // 累计导出行数
+}
+
+// TableName 指定表名
+func (ExportWatermark) TableName() string {
+	return "export_watermarks"
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// FeatureFlag 功能开关：Enabled 为总开关，开启后按用户、角色、工作区定向放量，
+// 其余用户按 Percentage 百分比灰度（100 即对所有人开启的布尔开关）
+type FeatureFlag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Key         string `gorm:"column:flag_key;size:100;uniqueIndex;not null" json:"key"`
+	Description string `gorm:"size:500" json:"description"`
+	Enabled     bool   `gorm:"not null;default:false" json:"enabled"`
+	Percentage  int    `gorm:"not null;default:0" json:"percentage"` // 0-100
+
+	// 定向名单（JSON 存储），命中任意一项即开启，不受百分比限制
+	UserIDs      []uint   `gorm:"serializer:json;type:text" json:"user_ids"`
+	Roles        []string `gorm:"serializer:json;type:text" json:"roles"`
+	WorkspaceIDs []uint   `gorm:"serializer:json;type:text" json:"workspace_ids"`
+}
+
+// TableName 指定表名
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Goal 目标：关联待办与项目，按关联待办的完成情况计算进度
+type Goal struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID      uint   `gorm:"index;not null" json:"user_id"`
+	Title       string `gorm:"not null;size:200" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	StartDate   string `gorm:"size:10;not null" json:"start_date"`  // 2006-01-02，计算期望进度的起点
+	TargetDate  string `gorm:"size:10;not null" json:"target_date"` // 2006-01-02，当天结束前完成
+	// WeightByEstimate 为 true 时按待办的估算工作量加权计算进度
+	WeightByEstimate bool `gorm:"not null;default:false" json:"weight_by_estimate"`
+}
+
+// TableName 指定表名
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// GoalLink 目标关联的待办或项目（关联项目时包含其全部下级节点中的待办）
+type GoalLink struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint   `gorm:"index;not null" json:"user_id"`
+	GoalID     uint   `gorm:"not null;uniqueIndex:idx_goal_links_target,priority:1" json:"goal_id"`
+	TargetType string `gorm:"size:10;not null;uniqueIndex:idx_goal_links_target,priority:2" json:"target_type"` // todo | project
+	TargetID   uint   `gorm:"not null;uniqueIndex:idx_goal_links_target,priority:3" json:"target_id"`
+}
+
+// 目标关联类型
+const (
+	GoalLinkTodo    = "todo"
+	GoalLinkProject = "project"
+)
+
+// TableName 指定表名
+func (GoalLink) TableName() string {
+	return "goal_links"
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Habit 习惯：按计划打卡，统计连续天数与完成率（与待办不同，不会“完成”）
+type Habit struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID    uint   `gorm:"index;not null" json:"user_id"`
+	Name      string `gorm:"not null;size:100" json:"name"`
+	Frequency string `gorm:"size:10;not null" json:"frequency"` // daily | weekly
+	// TimesPerWeek 每周目标次数（frequency 为 weekly 时使用，1-7）
+	TimesPerWeek int    `gorm:"not null;default:0" json:"times_per_week"`
+	StartDate    string `gorm:"size:10;not null" json:"start_date"` // 2006-01-02，用户时区下开始计算完成率的日期
+}
+
+// 习惯频率
+const (
+	HabitDaily  = "daily"
+	HabitWeekly = "weekly"
+)
+
+// TableName 指定表名
+func (Habit) TableName() string {
+	return "habits"
+}
+
+// HabitCheckIn 习惯打卡，每个习惯每天（用户时区下的日期）最多一条
+type HabitCheckIn struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID  uint   `gorm:"index;not null" json:"user_id"`
+	HabitID uint   `gorm:"not null;uniqueIndex:idx_habit_check_ins_date,priority:1" json:"habit_id"`
+	Date    string `gorm:"size:10;not null;uniqueIndex:idx_habit_check_ins_date,priority:2" json:"date"` // 2006-01-02
+}
+
+// TableName 指定表名
+func (HabitCheckIn) TableName() string {
+	return "habit_check_ins"
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IncomingWebhook 入站 webhook：外部系统向专属 URL 推送任意 JSON，按模板映射为待办
+type IncomingWebhook struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID    uint   `gorm:"index;not null" json:"user_id"`
+	ProjectID *uint  `json:"project_id"`
+	Name      string `gorm:"size:100" json:"name"`
+	// Token 出现在 URL 中（/v1/hooks/:token），作为接收端的身份凭证
+	Token string `gorm:"uniqueIndex;size:64;not null" json:"token"`
+	// Secret 非空时要求请求携带 X-Memogo-Signature: sha256=<hex(HMAC-SHA256(body))>
+	Secret string `gorm:"size:128" json:"-"`
+
+	// 字段模板：整体为路径（如 $.alert.name）时直接取值，也可写成 "[{{$.level}}] {{$.msg}}"
+	TitleTemplate          string `gorm:"size:500;not null" json:"title_template"`
+	ContentTemplate        string `gorm:"type:text" json:"content_template"`
+	DueTimeTemplate        string `gorm:"size:200" json:"due_time_template"`
+	IdempotencyKeyTemplate string `gorm:"size:200" json:"idempotency_key_template"`
+
+	DeliveryCount   int64      `gorm:"not null;default:0" json:"delivery_count"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at"`
+}
+
+// TableName 指定表名
+func (IncomingWebhook) TableName() string {
+	return "incoming_webhooks"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// IngestRecord 消息队列导入待办的幂等记录
+type IngestRecord struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID         uint   `gorm:"uniqueIndex:idx_ingest_key;not null" json:"user_id"`
+	IdempotencyKey string `gorm:"uniqueIndex:idx_ingest_key;not null;size:128" json:"idempotency_key"`
+	Source         string `gorm:"size:20" json:"source"` // "nats" | "redis" | "webhook"
+	TodoID         uint   `gorm:"index;not null;default:0" json:"todo_id"`
+}
+
+// TableName 指定表名
+func (IngestRecord) TableName() string {
+	return "ingest_records"
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 冲突处理策略：两端在上次同步后都发生了变化时采用哪一边
+const (
+	ConflictPolicyNewest = "newest" // 以更新时间较新的一方为准（默认）
+	ConflictPolicyRemote = "remote" // 以 issue 平台为准
+	ConflictPolicyLocal  = "local"  // 以 memogo 为准
+)
+
+// IssueConnector 项目与 GitHub/GitLab 仓库的同步连接
+type IssueConnector struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID    uint  `gorm:"index;not null" json:"user_id"`
+	ProjectID *uint `gorm:"index" json:"project_id"` // 导入的待办归属的项目
+
+	Provider string `gorm:"not null;size:20" json:"provider"` // "github" | "gitlab"
+	BaseURL  string `gorm:"size:255" json:"base_url"`         // 为空使用官方 API 地址
+	Repo     string `gorm:"not null;size:255" json:"repo"`    // owner/name 或 GitLab 项目路径
+	Assignee string `gorm:"size:100" json:"assignee"`         // 只导入指派给该用户的 issue
+
+	Token         string `gorm:"size:255" json:"-"`
+	WebhookSecret string `gorm:"size:64" json:"-"`
+
+	ConflictPolicy string     `gorm:"not null;size:10;default:newest" json:"conflict_policy"`
+	LastSyncedAt   *time.Time `json:"last_synced_at"`
+	LastError      string     `gorm:"size:500" json:"last_error"`
+}
+
+// TableName 指定表名
+func (IssueConnector) TableName() string {
+	return "issue_connectors"
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// IssueLink 待办与远程 issue 的对应关系及上次同步时的状态
+type IssueLink struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ConnectorID uint   `gorm:"uniqueIndex:idx_issue_link;not null" json:"connector_id"`
+	IssueNumber int64  `gorm:"uniqueIndex:idx_issue_link;not null" json:"issue_number"`
+	IssueURL    string `gorm:"size:500" json:"issue_url"`
+	TodoID      uint   `gorm:"index;not null" json:"todo_id"`
+
+	RemoteClosed    bool      `gorm:"not null;default:false" json:"remote_closed"`
+	RemoteUpdatedAt time.Time `json:"remote_updated_at"`
+	// SyncedAt 两端状态最后一次一致的时间，用于判断哪一端在之后发生了变化
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// TableName 指定表名
+func (IssueLink) TableName() string {
+	return "issue_links"
+}
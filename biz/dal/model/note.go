@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+)
+
+// Note 笔记：自由格式的 Markdown 备忘；JournalDate 非空时为当天的日记（每个用户每天一篇）
+type Note struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint    `gorm:"not null;uniqueIndex:idx_notes_user_journal,priority:1" json:"user_id"`
+	JournalDate *string `gorm:"size:10;uniqueIndex:idx_notes_user_journal,priority:2" json:"journal_date"` // 2006-01-02
+	Title       string  `gorm:"not null;size:200;index" json:"title"`
+	Content     string  `gorm:"type:text" json:"content"`
+}
+
+// TableName 指定表名
+func (Note) TableName() string {
+	return "notes"
+}
+
+// NoteLink 笔记中的 [[wiki 链接]]：从笔记 NoteID 指向待办、笔记或日记；
+// 目标笔记尚不存在时 TargetID 为 0，创建同名笔记后自动解析
+type NoteLink struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint   `gorm:"index;not null" json:"user_id"`
+	NoteID     uint   `gorm:"index;not null" json:"note_id"`
+	TargetType string `gorm:"size:10;not null;index:idx_note_links_target,priority:1" json:"target_type"` // todo | note
+	TargetID   uint   `gorm:"not null;default:0;index:idx_note_links_target,priority:2" json:"target_id"`
+	Target     string `gorm:"size:200;not null" json:"target"` // 链接原文（规范化后）
+}
+
+// 链接目标类型
+const (
+	NoteLinkTargetTodo    = "todo"
+	NoteLinkTargetNote    = "note"
+	NoteLinkTargetJournal = "journal" // 按日期指向日记，TargetID 为日记笔记的 ID
+)
+
+// TableName 指定表名
+func (NoteLink) TableName() string {
+	return "note_links"
+}
@@ -0,0 +1,89 @@
+package model
+
+import "time"
+
+// Plan 订阅套餐：Limits 为各项资源的数量上限（未列出的不限），Features 为套餐包含的功能
+type Plan struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Code        string `gorm:"column:plan_code;size:50;uniqueIndex;not null" json:"code"`
+	Name        string `gorm:"size:100;not null" json:"name"`
+	Description string `gorm:"size:500" json:"description"`
+	// IsDefault 没有分配套餐的用户使用默认套餐（最多一个）；没有默认套餐时不受限制
+	IsDefault bool `gorm:"not null;default:false" json:"is_default"`
+
+	Limits   map[string]int `gorm:"serializer:json;type:text" json:"limits"`
+	Features []string       `gorm:"serializer:json;type:text" json:"features"`
+}
+
+// TableName 指定表名
+func (Plan) TableName() string {
+	return "plans"
+}
+
+// 套餐限额项
+const (
+	LimitTodos            = "todos"             // 待办数（不含已删除的）
+	LimitProjects         = "projects"          // 项目树节点数
+	LimitIncomingWebhooks = "incoming_webhooks" // 入站 webhook 数
+	LimitAttachments      = "attachments"       // 附件数（尚无附件功能，调用方自行统计用量）
+	LimitMembers          = "members"           // 工作区成员数（尚无工作区，调用方自行统计用量）
+)
+
+// 套餐功能
+const (
+	FeatureIncomingWebhooks = "incoming_webhooks" // 入站 webhook
+	FeatureIssueSync        = "issue_sync"        // GitHub / GitLab issue 同步
+	FeaturePlannerPDF       = "planner_pdf"       // PDF 计划本
+	FeatureCalDAV           = "caldav"            // CalDAV 同步（尚未提供）
+)
+
+// PlanAssignment 套餐分配：每个用户或工作区最多一条
+type PlanAssignment struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubjectType string `gorm:"size:20;not null;uniqueIndex:idx_plan_assignments_subject,priority:1" json:"subject_type"`
+	SubjectID   uint   `gorm:"not null;uniqueIndex:idx_plan_assignments_subject,priority:2" json:"subject_id"`
+	PlanCode    string `gorm:"size:50;not null;index" json:"plan"`
+	Status      string `gorm:"size:20;not null" json:"status"`
+	// ExpiresAt 手动分配的到期时间，为空表示不过期
+	ExpiresAt *time.Time `json:"expires_at"`
+
+	// 计费提供方的订阅信息，手动分配时为空
+	BillingProvider  string     `gorm:"size:30;not null;default:''" json:"billing_provider"`
+	SubscriptionID   string     `gorm:"size:100;not null;default:''" json:"subscription_id"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end"`
+}
+
+// TableName 指定表名
+func (PlanAssignment) TableName() string {
+	return "plan_assignments"
+}
+
+// 套餐分配的主体类型
+const (
+	PlanSubjectUser      = "user"
+	PlanSubjectWorkspace = "workspace"
+)
+
+// 套餐分配状态（与 pkg/billing 的订阅状态一致）
+const (
+	PlanStatusActive   = "active"
+	PlanStatusTrialing = "trialing"
+	PlanStatusPastDue  = "past_due" // 扣款失败，宽限期内仍可使用
+	PlanStatusCanceled = "canceled"
+)
+
+// Effective 分配在 now 时刻是否生效
+func (a *PlanAssignment) Effective(now time.Time) bool {
+	switch a.Status {
+	case PlanStatusActive, PlanStatusTrialing, PlanStatusPastDue:
+	default:
+		return false
+	}
+	return a.ExpiresAt == nil || now.Before(*a.ExpiresAt)
+}
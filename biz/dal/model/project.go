@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Project 项目树节点：领域（area）→ 项目（project）→ 分区（section），待办可挂在任意节点上
+type Project struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID   uint   `gorm:"index;not null" json:"user_id"`
+	ParentID *uint  `gorm:"index" json:"parent_id"` // nil 表示顶层节点
+	Kind     string `gorm:"size:20;not null;default:'project'" json:"kind"`
+	Name     string `gorm:"not null;size:100" json:"name"`
+
+	// 待办编号前缀（如 OPS），空串表示未设置；设置后新待办按项目顺序编号为 OPS-1、OPS-2……
+	Key     string `gorm:"column:project_key;size:10;not null;default:''" json:"key"`
+	TodoSeq int    `gorm:"not null;default:0" json:"-"` // 已分配的最大编号
+}
+
+var (
+	projectKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+	todoKeyRe    = regexp.MustCompile(`^([A-Z][A-Z0-9]{1,9})-([1-9][0-9]{0,8})$`)
+)
+
+// NormalizeProjectKey 规范化项目前缀（去空白、转大写），格式不合法时 ok 为 false
+func NormalizeProjectKey(key string) (string, bool) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	return key, projectKeyRe.MatchString(key)
+}
+
+// FormatTodoKey 拼出待办编号，如 OPS-17
+func FormatTodoKey(projectKey string, n int) string {
+	return fmt.Sprintf("%s-%d", projectKey, n)
+}
+
+// ParseTodoKey 解析待办编号（不区分大小写），返回规范化后的编号与序号
+func ParseTodoKey(s string) (key string, n int, ok bool) {
+	key = strings.ToUpper(strings.TrimSpace(s))
+	m := todoKeyRe.FindStringSubmatch(key)
+	if m == nil {
+		return "", 0, false
+	}
+	n, _ = strconv.Atoi(m[2])
+	return key, n, true
+}
+
+// 项目树节点类型
+const (
+	ProjectKindArea    = "area"    // 领域：顶层或挂在其他领域下
+	ProjectKindProject = "project" // 项目：顶层或挂在领域下
+	ProjectKindSection = "section" // 分区：挂在项目下
+)
+
+// TableName 指定表名
+func (Project) TableName() string {
+	return "projects"
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sprint 迭代：属于某个项目，有起止日期，关闭后不能再修改
+type Sprint struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	ProjectID uint       `gorm:"index;not null" json:"project_id"`
+	Name      string     `gorm:"not null;size:100" json:"name"`
+	StartDate string     `gorm:"size:10;not null" json:"start_date"` // 2006-01-02
+	EndDate   string     `gorm:"size:10;not null" json:"end_date"`   // 2006-01-02，含当天
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+// TableName 指定表名
+func (Sprint) TableName() string {
+	return "sprints"
+}
+
+// SprintItem 待办加入迭代的记录；移出或结转时记录 RemovedAt 而不删除，燃尽图据此回放范围变化
+type SprintItem struct {
+	ID uint `gorm:"primarykey" json:"id"`
+
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	SprintID  uint       `gorm:"index;not null" json:"sprint_id"`
+	TodoID    uint       `gorm:"index;not null" json:"todo_id"`
+	AddedAt   time.Time  `gorm:"not null" json:"added_at"`
+	RemovedAt *time.Time `json:"removed_at"`
+	// CarriedOver 关闭迭代时仍未完成而被结转；CarriedTo 为结转到的迭代（0 表示只移出、不加入其他迭代）
+	CarriedOver bool `gorm:"not null;default:false" json:"carried_over"`
+	CarriedTo   uint `gorm:"not null;default:0" json:"carried_to"`
+}
+
+// TableName 指定表名
+func (SprintItem) TableName() string {
+	return "sprint_items"
+}
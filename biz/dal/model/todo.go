@@ -10,19 +10,73 @@ import (
 type Todo struct {
     ID        uint           `gorm:"primarykey" json:"id"`
     CreatedAt time.Time      `json:"created_at"`
-    UpdatedAt time.Time      `json:"updated_at"`
+    UpdatedAt time.Time      `gorm:"index" json:"updated_at"`
     DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
-    UserID  uint   `gorm:"index;not null" json:"user_id"`
+    UserID    uint  `gorm:"index;index:idx_todos_user_title_sort,priority:1;index:idx_todos_user_key,priority:1;not null" json:"user_id"`
+    ProjectID *uint `gorm:"index" json:"project_id"`
+
+    // 项目内编号（如 OPS-17），由仓库层在待办进入设置了前缀的项目时分配；KeyProjectID 为分配编号的项目
+    Key          *string `gorm:"column:todo_key;size:24;index:idx_todos_user_key,priority:2" json:"key"`
+    KeyProjectID *uint   `gorm:"index" json:"-"`
+
     Title   string `gorm:"not null;size:200" json:"title"`
     Content string `gorm:"type:text;not null" json:"content"`
 
+    // 标题排序键（pkg/collation，中文按拼音），由仓库层在写入标题时维护
+    TitleSortKey string `gorm:"size:512;not null;default:'';index:idx_todos_user_title_sort,priority:2" json:"-"`
+
     Status int32 `gorm:"not null;default:0" json:"status"` // 0: TODO, 1: DONE
 
     StartTime *time.Time `json:"start_time"`
     EndTime   *time.Time `json:"end_time"`
     DueTime   *time.Time `json:"due_time"`
+
+    // 改期次数（截止时间从一个时间改为另一个时间，见 TodoDueChange），由仓库层维护
+    RescheduleCount int `gorm:"not null;default:0" json:"reschedule_count"`
+
+    // 估算工作量（单位由用户自定，如小时或故事点），0 表示未估算；用于目标进度加权
+    Estimate int `gorm:"not null;default:0" json:"estimate"`
+
+    // 重复规则：完成后按规则生成下一条；DueShift 把截止日期调整到工作日（含调休）
+    Recurrence       string     `gorm:"size:20;not null;default:''" json:"recurrence"`
+    DueShift         string     `gorm:"size:20;not null;default:''" json:"due_shift"`
+    RecurrenceAnchor *time.Time `json:"-"` // 调整前的计划日期，按它推算下一次，避免顺延累积
+
+    // 农历重复规则（Recurrence 为 lunar_yearly 时使用）：每年农历 LunarMonth 月 LunarDay 日
+    LunarMonth int  `gorm:"not null;default:0" json:"lunar_month"`
+    LunarDay   int  `gorm:"not null;default:0" json:"lunar_day"`
+    LunarLeap  bool `gorm:"not null;default:false" json:"lunar_leap"`
+
+    // GTD 清单：inbox 收集箱（未处理）、next 下一步行动、someday 将来 / 也许；空串表示未进入 GTD 流程
+    GtdList string `gorm:"size:10;not null;default:'';index" json:"gtd_list"`
 }
 
+// 重复规则
+const (
+    RecurrenceNone        = ""
+    RecurrenceDaily       = "daily"
+    RecurrenceWorkday     = "workday" // 下一个工作日（跳过节假日，含调休上班日）
+    RecurrenceWeekly      = "weekly"
+    RecurrenceMonthly     = "monthly"
+    RecurrenceYearly      = "yearly"
+    RecurrenceLunarYearly = "lunar_yearly" // 每年农历某月某日（闰月无对应时落在普通月）
+)
+
+// 截止日期调整方式
+const (
+    DueShiftNone        = ""
+    DueShiftNextWorkday = "next_workday" // 落在非工作日时顺延到下一个工作日
+    DueShiftPrevWorkday = "prev_workday" // 落在非工作日时提前到上一个工作日
+)
+
+// GTD 清单
+const (
+    GtdNone    = ""
+    GtdInbox   = "inbox"
+    GtdNext    = "next"
+    GtdSomeday = "someday"
+)
+
 func (Todo) TableName() string { return "todos" }
 
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TodoDueChange 待办截止时间变更历史：设置、改期与清空都会记录，用于统计拖延
+type TodoDueChange struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID uint       `gorm:"index;not null" json:"user_id"`
+	TodoID uint       `gorm:"index;not null" json:"todo_id"`
+	OldDue *time.Time `json:"old_due"` // 为空表示首次设置
+	NewDue *time.Time `json:"new_due"` // 为空表示清空
+}
+
+// IsReschedule 是否为改期（原本有截止时间、改为另一个时间）；首次设置与清空不算
+func (c *TodoDueChange) IsReschedule() bool {
+	return c.OldDue != nil && c.NewDue != nil && !c.OldDue.Equal(*c.NewDue)
+}
+
+// TableName 指定表名
+func (TodoDueChange) TableName() string {
+	return "todo_due_changes"
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// TodoStatusChange 待办状态变更历史，用于燃尽图等按时间回放状态的统计
+type TodoStatusChange struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID     uint  `gorm:"index;not null" json:"user_id"`
+	TodoID     uint  `gorm:"index;not null" json:"todo_id"`
+	FromStatus int32 `gorm:"not null" json:"from_status"`
+	ToStatus   int32 `gorm:"not null" json:"to_status"`
+}
+
+// TableName 指定表名
+func (TodoStatusChange) TableName() string {
+	return "todo_status_changes"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TodoTag 待办标签；以 @ 开头的为 GTD 情境标签（如 @home、@office）
+type TodoTag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID uint   `gorm:"not null;index:idx_todo_tags_user_name,priority:1" json:"user_id"`
+	TodoID uint   `gorm:"not null;uniqueIndex:idx_todo_tags_todo_name,priority:1" json:"todo_id"`
+	Name   string `gorm:"size:50;not null;uniqueIndex:idx_todo_tags_todo_name,priority:2;index:idx_todo_tags_user_name,priority:2" json:"name"`
+}
+
+// ContextTagPrefix 情境标签前缀
+const ContextTagPrefix = "@"
+
+// MaxTodoTags 每条待办最多的标签数
+const MaxTodoTags = 20
+
+// TableName 指定表名
+func (TodoTag) TableName() string {
+	return "todo_tags"
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// TodoTransfer 待办所有权转移请求：由原所有者（或管理员代为）发起，接收方接受后才执行
+type TodoTransfer struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	FromUserID  uint `gorm:"index;not null" json:"from_user_id"`
+	ToUserID    uint `gorm:"index;not null" json:"to_user_id"`
+	InitiatorID uint `gorm:"not null" json:"initiator_id"` // 原所有者本人或管理员
+
+	Scope     string `gorm:"size:10;not null" json:"scope"`                       // todos | project | open
+	TodoIDs   []uint `gorm:"serializer:json;type:text" json:"todo_ids,omitempty"` // scope 为 todos 时
+	ProjectID uint   `gorm:"not null;default:0" json:"project_id,omitempty"`      // scope 为 project 时（含全部下级节点）
+	Note      string `gorm:"size:500;not null;default:''" json:"note"`
+
+	Status      string     `gorm:"size:10;not null;index" json:"status"` // pending | accepted | declined | cancelled
+	RespondedAt *time.Time `json:"responded_at"`
+	// 接受时实际转移的数量
+	MovedTodos    int64 `gorm:"not null;default:0" json:"moved_todos"`
+	MovedProjects int64 `gorm:"not null;default:0" json:"moved_projects"`
+}
+
+// 转移范围
+const (
+	TransferScopeTodos   = "todos"   // 指定的待办
+	TransferScopeProject = "project" // 项目（含下级节点）及其中的全部待办
+	TransferScopeOpen    = "open"    // 全部未完成的待办
+)
+
+// 转移状态
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+)
+
+// TableName 指定表名
+func (TodoTransfer) TableName() string {
+	return "todo_transfers"
+}
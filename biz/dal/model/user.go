@@ -15,8 +15,18 @@ type User struct {
 
 	Username     string `gorm:"uniqueIndex;not null;size:50" json:"username"`
 	PasswordHash string `gorm:"not null;size:255" json:"-"`
+
+	Role string `gorm:"size:20;not null;default:'user'" json:"role"` // user | admin
+	// TokensRevokedAt 此前签发的令牌全部失效（账号合并、强制下线）
+	TokensRevokedAt *time.Time `json:"-"`
 }
 
+// 用户角色
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // TableName 指定表名
 func (User) TableName() string {
 	return "users"
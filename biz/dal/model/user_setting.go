@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// UserSetting 用户偏好设置（无记录时使用零值默认）
+type UserSetting struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint `gorm:"uniqueIndex;not null" json:"user_id"`
+
+	// 节假日（含周末，调休上班日除外）不发送提醒 / 摘要；摘要推送尚未提供，SkipHolidayDigests 只保存偏好
+	SkipHolidayReminders bool `gorm:"not null;default:false" json:"skip_holiday_reminders"`
+	SkipHolidayDigests   bool `gorm:"not null;default:false" json:"skip_holiday_digests"`
+
+	// IANA 时区名（如 America/New_York），空串表示北京时间；用于按用户当地日期记录习惯打卡
+	Timezone string `gorm:"size:64;not null;default:''" json:"timezone"`
+}
+
+// TableName 指定表名
+func (UserSetting) TableName() string {
+	return "user_settings"
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// mergeReassignModels 合并账号时只需把 user_id 改为目标账号、不存在冲突的表
+var mergeReassignModels = []interface{ TableName() string }{
+	&model.ChatAccount{},
+	&model.IssueConnector{},
+	&model.IncomingWebhook{},
+	&model.Note{}, // 同一天的日记冲突先由 mergeJournals 处理
+	&model.NoteLink{},
+	&model.Goal{},
+	&model.GoalLink{},
+	&model.TodoStatusChange{},
+	&model.TodoDueChange{},
+	&model.Sprint{},
+	&model.SprintItem{},
+	&model.Habit{},
+	&model.HabitCheckIn{},
+	&model.Delegation{},
+	&model.TodoTag{},
+	&model.AuthEvent{},
+}
+
+// MergeStats 账号合并的统计：每张表迁移的行数与解决的冲突数
+type MergeStats struct {
+	Moved     map[string]int64 `json:"moved"`
+	Conflicts map[string]int64 `json:"conflicts"`
+	// DroppedPlan 两个账号都有套餐分配时被舍弃的一条（已删除），其订阅由上层取消
+	DroppedPlan *model.PlanAssignment `json:"dropped_plan,omitempty"`
+}
+
+// AccountMergeRepository 账号合并数据访问层
+type AccountMergeRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountMergeRepository 创建账号合并仓库实例
+func NewAccountMergeRepository(db *gorm.DB) *AccountMergeRepository {
+	return &AccountMergeRepository{db: db}
+}
+
+// Merge 在同一事务中把 source 的数据迁移到 target，吊销 source 的令牌并软删除 source
+// 冲突处理：同名项目合并到目标账号的项目；同一天的日记合并正文；重复的导入幂等键、用户设置以目标账号为准；
+// 套餐分配见 mergePlanAssignments
+func (r *AccountMergeRepository) Merge(sourceID, targetID uint) (*MergeStats, error) {
+	stats := &MergeStats{Moved: map[string]int64{}, Conflicts: map[string]int64{}}
+	var source, target model.User
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if err := mergeProjects(tx, sourceID, targetID, stats); err != nil {
+			return err
+		}
+
+		// 待办（含已软删除的，保留完整历史）
+		res := tx.Unscoped().Model(&model.Todo{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+		if res.Error != nil {
+			return res.Error
+		}
+		stats.Moved["todos"] = res.RowsAffected
+
+		if err := mergeJournals(tx, sourceID, targetID, stats); err != nil {
+			return err
+		}
+
+		for _, m := range mergeReassignModels {
+			res := tx.Unscoped().Model(m).Where("user_id = ?", sourceID).Update("user_id", targetID)
+			if res.Error != nil {
+				return res.Error
+			}
+			stats.Moved[m.TableName()] = res.RowsAffected
+		}
+
+		// 待办转移请求：改为指向目标账号，变成转给自己的待处理请求直接取消
+		for _, col := range []string{"from_user_id", "to_user_id"} {
+			if err := tx.Model(&model.TodoTransfer{}).Where(col+" = ?", sourceID).Update(col, targetID).Error; err != nil {
+				return err
+			}
+		}
+		res = tx.Model(&model.TodoTransfer{}).
+			Where("from_user_id = ? AND to_user_id = ? AND status = ?", targetID, targetID, model.TransferPending).
+			Updates(map[string]interface{}{"status": model.TransferCancelled, "responded_at": time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		stats.Conflicts["todo_transfers"] = res.RowsAffected
+
+		// 导入幂等键：目标账号已有同名键时丢弃源账号的记录
+		res = tx.Where("user_id = ? AND idempotency_key IN (?)", sourceID,
+			tx.Model(&model.IngestRecord{}).Select("idempotency_key").Where("user_id = ?", targetID),
+		).Delete(&model.IngestRecord{})
+		if res.Error != nil {
+			return res.Error
+		}
+		stats.Conflicts["ingest_records"] = res.RowsAffected
+		res = tx.Model(&model.IngestRecord{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+		if res.Error != nil {
+			return res.Error
+		}
+		stats.Moved["ingest_records"] = res.RowsAffected
+
+		// 用户设置：目标账号已有设置时保留目标账号的
+		var targetSettings int64
+		if err := tx.Model(&model.UserSetting{}).Where("user_id = ?", targetID).Count(&targetSettings).Error; err != nil {
+			return err
+		}
+		if targetSettings > 0 {
+			res = tx.Where("user_id = ?", sourceID).Delete(&model.UserSetting{})
+			stats.Conflicts["user_settings"] = res.RowsAffected
+		} else {
+			res = tx.Model(&model.UserSetting{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+			stats.Moved["user_settings"] = res.RowsAffected
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if err := mergePlanAssignments(tx, sourceID, targetID, stats); err != nil {
+			return err
+		}
+
+		// 吊销源账号已签发的令牌并软删除
+		if err := tx.Model(&source).Update("tokens_revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 清除两个账号的用户与待办缓存
+	userRepo := NewUserRepository(r.db)
+	userRepo.invalidateUserCache(&source)
+	userRepo.invalidateUserCache(&target)
+	todoRepo := NewTodoRepository(r.db)
+	todoRepo.invalidateUserCache(sourceID)
+	todoRepo.invalidateUserCache(targetID)
+	return stats, nil
+}
+
+// mergeProjects 迁移项目树；目标账号已有同类型同名的顶层节点时，把待办、引用与子节点改挂到目标节点并删除源节点，
+// 下级节点随父节点整体迁移；按被合并节点分配的待办编号清除，迁入后前缀与目标账号重复的项目清空前缀
+func mergeProjects(tx *gorm.DB, sourceID, targetID uint, stats *MergeStats) error {
+	var sourceProjects, targetProjects []model.Project
+	if err := tx.Where("user_id = ?", sourceID).Find(&sourceProjects).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? AND parent_id IS NULL", targetID).Find(&targetProjects).Error; err != nil {
+		return err
+	}
+	byName := make(map[string]uint, len(targetProjects))
+	for _, p := range targetProjects {
+		byName[p.Kind+"/"+p.Name] = p.ID
+	}
+
+	var moved []uint
+	for _, p := range sourceProjects {
+		targetProjectID, conflict := byName[p.Kind+"/"+p.Name]
+		if p.ParentID != nil || !conflict {
+			if err := tx.Model(&p).Update("user_id", targetID).Error; err != nil {
+				return err
+			}
+			moved = append(moved, p.ID)
+			stats.Moved["projects"]++
+			continue
+		}
+		if err := clearTodoKeys(tx.Where("key_project_id = ?", p.ID)); err != nil {
+			return err
+		}
+		for _, m := range []interface{}{&model.Todo{}, &model.IssueConnector{}, &model.IncomingWebhook{}, &model.Sprint{}, &model.TodoTransfer{}} {
+			if err := tx.Unscoped().Model(m).Where("project_id = ?", p.ID).Update("project_id", targetProjectID).Error; err != nil {
+				return err
+			}
+		}
+		if err := relinkGoalProject(tx, p.ID, targetProjectID, stats); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Project{}).Where("parent_id = ?", p.ID).Update("parent_id", targetProjectID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&p).Error; err != nil {
+			return err
+		}
+		stats.Conflicts["projects"]++
+	}
+	return releaseTakenKeys(tx, targetID, moved)
+}
+
+// relinkGoalProject 把关联被合并项目的目标改为关联目标项目；目标已关联目标项目的，删除重复的关联（避免违反 idx_goal_links_target）
+func relinkGoalProject(tx *gorm.DB, fromProjectID, toProjectID uint, stats *MergeStats) error {
+	var linked []uint
+	if err := tx.Model(&model.GoalLink{}).Where("target_type = ? AND target_id = ?", model.GoalLinkProject, toProjectID).
+		Pluck("goal_id", &linked).Error; err != nil {
+		return err
+	}
+	links := func() *gorm.DB {
+		return tx.Where("target_type = ? AND target_id = ?", model.GoalLinkProject, fromProjectID)
+	}
+	if len(linked) > 0 {
+		res := links().Where("goal_id IN ?", linked).Delete(&model.GoalLink{})
+		if res.Error != nil {
+			return res.Error
+		}
+		stats.Conflicts["goal_links"] += res.RowsAffected
+	}
+	return links().Model(&model.GoalLink{}).Update("target_id", toProjectID).Error
+}
+
+// mergePlanAssignments 迁移源账号的套餐分配；两个账号都有分配时保留仍然有效的一条（都有效或都无效时保留目标账号的），
+// 另一条删除并记入 stats.DroppedPlan
+func mergePlanAssignments(tx *gorm.DB, sourceID, targetID uint, stats *MergeStats) error {
+	var source, target model.PlanAssignment
+	err := tx.Where("subject_type = ? AND subject_id = ?", model.PlanSubjectUser, sourceID).First(&source).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = tx.Where("subject_type = ? AND subject_id = ?", model.PlanSubjectUser, targetID).First(&target).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		stats.Moved["plan_assignments"] = 1
+		return tx.Model(&source).Update("subject_id", targetID).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	stats.Conflicts["plan_assignments"] = 1
+	now := time.Now()
+	if !source.Effective(now) || target.Effective(now) {
+		stats.DroppedPlan = &source
+		return tx.Delete(&source).Error
+	}
+	// 目标账号的分配已失效，改用源账号的（先删除目标的，避免违反主体唯一索引）
+	stats.DroppedPlan = &target
+	if err := tx.Delete(&target).Error; err != nil {
+		return err
+	}
+	return tx.Model(&source).Update("subject_id", targetID).Error
+}
+
+// mergeJournals 两个账号在同一天都有日记时，把源日记的正文追加到目标日记，链接改挂到目标日记后删除源日记
+func mergeJournals(tx *gorm.DB, sourceID, targetID uint, stats *MergeStats) error {
+	var sourceJournals []model.Note
+	err := tx.Where("user_id = ? AND journal_date IN (?)", sourceID,
+		tx.Model(&model.Note{}).Select("journal_date").Where("user_id = ? AND journal_date IS NOT NULL", targetID),
+	).Find(&sourceJournals).Error
+	if err != nil {
+		return err
+	}
+	for _, src := range sourceJournals {
+		var dst model.Note
+		if err := tx.Where("user_id = ? AND journal_date = ?", targetID, *src.JournalDate).First(&dst).Error; err != nil {
+			return err
+		}
+		if src.Content != "" {
+			if err := tx.Model(&dst).Update("content", dst.Content+"\n\n---\n\n"+src.Content).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.NoteLink{}).Where("note_id = ?", src.ID).Update("note_id", dst.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.NoteLink{}).
+			Where("target_type IN ? AND target_id = ?", []string{model.NoteLinkTargetNote, model.NoteLinkTargetJournal}, src.ID).
+			Update("target_id", dst.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&src).Error; err != nil {
+			return err
+		}
+		stats.Conflicts["notes"]++
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"testing"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// projectMergeFixture 两个账号各有一个同名顶层项目，合并时源项目并入目标项目
+type projectMergeFixture struct {
+	db                     *gorm.DB
+	source, target         model.User
+	sourceProj, targetProj model.Project
+}
+
+func newProjectMergeFixture(t *testing.T) *projectMergeFixture {
+	t.Helper()
+	f := &projectMergeFixture{db: dbtest.Open(t)}
+	f.source = model.User{Username: "source", PasswordHash: "x"}
+	f.target = model.User{Username: "target", PasswordHash: "x"}
+	for _, u := range []*model.User{&f.source, &f.target} {
+		if err := f.db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.sourceProj = model.Project{UserID: f.source.ID, Name: "Work"}
+	f.targetProj = model.Project{UserID: f.target.ID, Name: "Work"}
+	for _, p := range []*model.Project{&f.sourceProj, &f.targetProj} {
+		if err := f.db.Create(p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func (f *projectMergeFixture) merge(t *testing.T) *MergeStats {
+	t.Helper()
+	stats, err := NewAccountMergeRepository(f.db).Merge(f.source.ID, f.target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Conflicts["projects"] != 1 {
+		t.Fatalf("project conflicts = %d, want the two Work projects merged", stats.Conflicts["projects"])
+	}
+	return stats
+}
+
+func TestMergeProjectsMovesSprints(t *testing.T) {
+	f := newProjectMergeFixture(t)
+	sprint := model.Sprint{UserID: f.source.ID, ProjectID: f.sourceProj.ID, Name: "S1", StartDate: "2026-10-01", EndDate: "2026-10-14"}
+	if err := f.db.Create(&sprint).Error; err != nil {
+		t.Fatal(err)
+	}
+	f.merge(t)
+
+	sprints, err := NewSprintRepository(f.db).ListByProject(f.target.ID, f.targetProj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sprints) != 1 || sprints[0].ID != sprint.ID {
+		t.Fatalf("target project sprints = %+v, want the merged sprint", sprints)
+	}
+}
+
+func TestMergeProjectsRelinksGoals(t *testing.T) {
+	f := newProjectMergeFixture(t)
+	goals := make([]model.Goal, 2)
+	for i := range goals {
+		goals[i] = model.Goal{UserID: f.source.ID, Title: "goal", StartDate: "2026-10-01", TargetDate: "2026-12-31"}
+		if err := f.db.Create(&goals[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// goals[0] 同时关联两个同名项目，合并后只保留一条；goals[1] 只关联源项目，改为关联目标项目
+	links := []model.GoalLink{
+		{UserID: f.source.ID, GoalID: goals[0].ID, TargetType: model.GoalLinkProject, TargetID: f.sourceProj.ID},
+		{UserID: f.source.ID, GoalID: goals[0].ID, TargetType: model.GoalLinkProject, TargetID: f.targetProj.ID},
+		{UserID: f.source.ID, GoalID: goals[1].ID, TargetType: model.GoalLinkProject, TargetID: f.sourceProj.ID},
+	}
+	if err := f.db.Create(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	stats := f.merge(t)
+	if stats.Conflicts["goal_links"] != 1 {
+		t.Errorf("goal link conflicts = %d, want 1", stats.Conflicts["goal_links"])
+	}
+
+	for _, g := range goals {
+		var got []model.GoalLink
+		if err := f.db.Where("goal_id = ?", g.ID).Find(&got).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].TargetType != model.GoalLinkProject || got[0].TargetID != f.targetProj.ID {
+			t.Errorf("goal %d links = %+v, want one link to project %d", g.ID, got, f.targetProj.ID)
+		}
+	}
+}
+
+func TestMergeProjectsMovesPendingTransfers(t *testing.T) {
+	f := newProjectMergeFixture(t)
+	transfer := model.TodoTransfer{FromUserID: f.source.ID, ToUserID: 99, InitiatorID: f.source.ID,
+		Scope: model.TransferScopeProject, ProjectID: f.sourceProj.ID, Status: model.TransferPending}
+	if err := f.db.Create(&transfer).Error; err != nil {
+		t.Fatal(err)
+	}
+	f.merge(t)
+
+	var got model.TodoTransfer
+	if err := f.db.First(&got, transfer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.FromUserID != f.target.ID || got.ProjectID != f.targetProj.ID || got.Status != model.TransferPending {
+		t.Errorf("transfer after merge = %+v, want a pending transfer of project %d from user %d", got, f.targetProj.ID, f.target.ID)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// AnalyticsExportRepository 分析数据导出：按水位线增量读取各数据集，并保存导出进度
+type AnalyticsExportRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsExportRepository 创建分析导出仓库实例
+func NewAnalyticsExportRepository(db *gorm.DB) *AnalyticsExportRepository {
+	return &AnalyticsExportRepository{db: db}
+}
+
+// GetWatermark 获取数据集的水位线，从未导出过时返回零值（Dataset 已填好）
+func (r *AnalyticsExportRepository) GetWatermark(dataset string) (*model.ExportWatermark, error) {
+	wm := model.ExportWatermark{Dataset: dataset}
+	if err := r.db.Where("dataset = ?", dataset).Limit(1).Find(&wm).Error; err != nil {
+		return nil, err
+	}
+	return &wm, nil
+}
+
+// SaveWatermark 保存水位线（不存在时创建）
+func (r *AnalyticsExportRepository) SaveWatermark(wm *model.ExportWatermark) error {
+	return r.db.Save(wm).Error
+}
+
+// TodosChangedAfter 按 (updated_at, id) 顺序读取水位线之后、until 之前变更过的待办（含已删除的）
+func (r *AnalyticsExportRepository) TodosChangedAfter(after time.Time, afterID uint, until time.Time, limit int) ([]model.Todo, error) {
+	var todos []model.Todo
+	err := r.db.Unscoped().
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", after, after, afterID).
+		Where("updated_at < ?", until).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&todos).Error
+	return todos, err
+}
+
+// StatusChangesAfter 读取 id 大于 afterID、until 之前产生的状态变更
+func (r *AnalyticsExportRepository) StatusChangesAfter(afterID uint, until time.Time, limit int) ([]model.TodoStatusChange, error) {
+	var list []model.TodoStatusChange
+	err := r.db.Where("id > ? AND created_at < ?", afterID, until).
+		Order("id ASC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// AuthEventsAfter 读取 id 大于 afterID、until 之前产生的认证事件
+func (r *AnalyticsExportRepository) AuthEventsAfter(afterID uint, until time.Time, limit int) ([]model.AuthEvent, error) {
+	var list []model.AuthEvent
+	err := r.db.Where("id > ? AND created_at < ?", afterID, until).
+		Order("id ASC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
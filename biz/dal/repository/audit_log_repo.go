@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository 审计日志数据访问层
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓库实例
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Record 写入一条审计日志，detail 序列化为 JSON
+func (r *AuditLogRepository) Record(actorID uint, action, targetType string, targetID uint, detail interface{}) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	return r.db.Create(&model.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     string(data),
+	}).Error
+}
+
+// AuditLogFilter 审计日志查询条件（零值表示不限）
+type AuditLogFilter struct {
+	Action   string
+	ActorID  uint
+	TargetID uint
+	Cursor   uint // 返回 id < cursor 的记录
+	Limit    int
+}
+
+// List 按 id 倒序查询审计日志
+func (r *AuditLogRepository) List(f AuditLogFilter) ([]model.AuditLog, error) {
+	q := r.db.Model(&model.AuditLog{})
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.ActorID != 0 {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.TargetID != 0 {
+		q = q.Where("target_id = ?", f.TargetID)
+	}
+	if f.Cursor != 0 {
+		q = q.Where("id < ?", f.Cursor)
+	}
+	var logs []model.AuditLog
+	if err := q.Order("id DESC").Limit(f.Limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuthEventRepository 认证事件数据访问层
+type AuthEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthEventRepository 创建认证事件仓库实例
+func NewAuthEventRepository(db *gorm.DB) *AuthEventRepository {
+	return &AuthEventRepository{db: db}
+}
+
+// Record 写入一条认证事件
+func (r *AuthEventRepository) Record(event *model.AuthEvent) error {
+	return r.db.Create(event).Error
+}
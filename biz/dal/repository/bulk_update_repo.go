@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/pkg/todoquery"
+
+	"gorm.io/gorm"
+)
+
+// ErrBulkUpdateNotFound 确认令牌不存在、已过期或已使用
+var ErrBulkUpdateNotFound = errors.New("bulk update token not found, expired or already used")
+
+// TodoCond 待办筛选条件（由服务层从筛选表达式解析而来，各条件之间为“且”）
+type TodoCond struct {
+	Field  string // todoquery.Field*
+	Negate bool
+
+	Text   string // 关键词、标签、GTD 清单、is 的取值
+	Status int32
+	// project：节点及其全部下级的 ID，为空表示未归属项目
+	ProjectIDs []uint
+	// due / created：左闭右开的时间区间（任一端可为 nil）；due 两端都为 nil 时按 HasDue 判断有无截止时间
+	From, To *time.Time
+	HasDue   bool
+}
+
+// BulkUpdateRepository 批量操作数据访问层
+type BulkUpdateRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkUpdateRepository 创建批量操作仓库实例
+func NewBulkUpdateRepository(db *gorm.DB) *BulkUpdateRepository {
+	return &BulkUpdateRepository{db: db}
+}
+
+// MatchIDs 返回满足全部条件的待办数量，不超过 limit 时同时返回 ID（升序，限定用户）
+func (r *BulkUpdateRepository) MatchIDs(userID uint, conds []TodoCond, limit int) ([]uint, int64, error) {
+	q := r.db.Model(&model.Todo{}).Where("user_id = ?", userID)
+	for _, c := range conds {
+		q = r.withCond(q, userID, c)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 || total > int64(limit) {
+		return nil, total, nil
+	}
+	var ids []uint
+	if err := q.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, 0, err
+	}
+	return ids, int64(len(ids)), nil
+}
+
+// withCond 追加一个筛选条件；取反时包一层 NOT
+func (r *BulkUpdateRepository) withCond(q *gorm.DB, userID uint, c TodoCond) *gorm.DB {
+	var query string
+	var args []interface{}
+	switch c.Field {
+	case todoquery.FieldKeyword:
+		like := "%" + c.Text + "%"
+		query, args = "title LIKE ? OR content LIKE ?", []interface{}{like, like}
+		if key, _, ok := model.ParseTodoKey(c.Text); ok {
+			query, args = query+" OR todo_key = ?", append(args, key)
+		}
+	case todoquery.FieldStatus:
+		query, args = "status = ?", []interface{}{c.Status}
+	case todoquery.FieldTag:
+		tagged := r.db.Model(&model.TodoTag{}).Select("todo_id").Where("user_id = ? AND name = ?", userID, c.Text)
+		query, args = "id IN (?)", []interface{}{tagged}
+	case todoquery.FieldProject:
+		if len(c.ProjectIDs) == 0 {
+			query = "project_id IS NULL"
+		} else {
+			// COALESCE 让取反时也包含未归属项目的待办
+			query, args = "COALESCE(project_id, 0) IN ?", []interface{}{c.ProjectIDs}
+		}
+	case todoquery.FieldGtd:
+		query, args = "gtd_list = ?", []interface{}{c.Text}
+	case todoquery.FieldIs:
+		query = "recurrence <> ''"
+	case todoquery.FieldDue, todoquery.FieldCreated:
+		column := "due_time"
+		if c.Field == todoquery.FieldCreated {
+			column = "created_at"
+		}
+		switch {
+		case c.From != nil && c.To != nil:
+			query, args = column+" >= ? AND "+column+" < ?", []interface{}{*c.From, *c.To}
+		case c.From != nil:
+			query, args = column+" >= ?", []interface{}{*c.From}
+		case c.To != nil:
+			query, args = column+" < ?", []interface{}{*c.To}
+		case c.HasDue:
+			query = column + " IS NOT NULL"
+		default:
+			query = column + " IS NULL"
+		}
+	default:
+		return q
+	}
+	if c.Negate {
+		return q.Where("NOT ("+query+")", args...)
+	}
+	return q.Where("("+query+")", args...)
+}
+
+// Create 保存预览
+func (r *BulkUpdateRepository) Create(op *model.BulkUpdate) error {
+	return r.db.Create(op).Error
+}
+
+// GetPending 按令牌获取尚未执行且未过期的预览（限定用户）
+func (r *BulkUpdateRepository) GetPending(userID uint, token string, now time.Time) (*model.BulkUpdate, error) {
+	var op model.BulkUpdate
+	err := r.db.Where("user_id = ? AND token = ? AND executed_at IS NULL AND expires_at > ?", userID, token, now).
+		First(&op).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBulkUpdateNotFound
+		}
+		return nil, err
+	}
+	return &op, nil
+}
+
+// List 列出用户最近的批量操作（按 id 倒序）
+func (r *BulkUpdateRepository) List(userID uint, limit int) ([]model.BulkUpdate, error) {
+	var ops []model.BulkUpdate
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&ops).Error; err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Execute 在同一事务中占用令牌（只能使用一次）并对 ids 执行操作，返回实际改动的待办数量；
+// 操作参数由上层校验，move 的目标项目须属于该用户；set_due 时 shiftDue 按各待办的调整方式把截止时间移到工作日
+func (r *BulkUpdateRepository) Execute(op *model.BulkUpdate, ids []uint, now time.Time, shiftDue func(due time.Time, shift string) time.Time) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.BulkUpdate{}).
+			Where("id = ? AND executed_at IS NULL AND expires_at > ?", op.ID, now).
+			Update("executed_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrBulkUpdateNotFound
+		}
+		var err error
+		if len(ids) > 0 {
+			if affected, err = applyBulkAction(tx, op.UserID, op.Action, ids, shiftDue); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.BulkUpdate{}).Where("id = ?", op.ID).Update("affected", affected).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	op.ExecutedAt, op.Affected = &now, affected
+
+	// 清除该用户的待办缓存
+	NewTodoRepository(r.db).invalidateUserCache(op.UserID)
+	return affected, nil
+}
+
+func applyBulkAction(tx *gorm.DB, userID uint, action model.BulkAction, ids []uint, shiftDue func(time.Time, string) time.Time) (int64, error) {
+	scope := func() *gorm.DB {
+		return tx.Model(&model.Todo{}).Where("user_id = ? AND id IN ?", userID, ids)
+	}
+	switch action.Type {
+	case model.BulkSetStatus:
+		status := int32(0)
+		if action.Status == "done" {
+			status = 1
+		}
+		return bulkSetStatus(tx, scope, userID, status)
+	case model.BulkAddTag:
+		return bulkAddTag(tx, userID, ids, action.Tag)
+	case model.BulkMove:
+		var projectID *uint
+		if action.ProjectID != 0 {
+			projectID = &action.ProjectID
+		}
+		return bulkMove(tx, scope, projectID)
+	case model.BulkDelete:
+		res := softDeleteTodos(scope())
+		return res.RowsAffected, res.Error
+	case model.BulkSetDue:
+		var due *time.Time
+		if action.DueTime != 0 {
+			t := time.Unix(action.DueTime, 0)
+			due = &t
+		}
+		return bulkSetDue(tx, scope, userID, due, shiftDue)
+	}
+	return 0, nil
+}
+
+// bulkSetStatus 只改状态不同的待办，并记录状态变更历史
+func bulkSetStatus(tx *gorm.DB, scope func() *gorm.DB, userID uint, status int32) (int64, error) {
+	var todos []model.Todo
+	if err := scope().Select("id", "status").Where("status <> ?", status).Find(&todos).Error; err != nil {
+		return 0, err
+	}
+	if len(todos) == 0 {
+		return 0, nil
+	}
+	changed := make([]uint, len(todos))
+	changes := make([]model.TodoStatusChange, len(todos))
+	for i, t := range todos {
+		changed[i] = t.ID
+		changes[i] = model.TodoStatusChange{UserID: userID, TodoID: t.ID, FromStatus: t.Status, ToStatus: status}
+	}
+	res := tx.Model(&model.Todo{}).Where("id IN ?", changed).Update("status", status)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, tx.CreateInBatches(changes, 500).Error
+}
+
+// bulkAddTag 给尚无该标签、且标签数未满的待办加上标签
+func bulkAddTag(tx *gorm.DB, userID uint, ids []uint, tag string) (int64, error) {
+	var existing []model.TodoTag
+	if err := tx.Select("todo_id", "name").Where("user_id = ? AND todo_id IN ?", userID, ids).
+		Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	counts := make(map[uint]int, len(ids))
+	tagged := make(map[uint]bool)
+	for _, t := range existing {
+		counts[t.TodoID]++
+		if t.Name == tag {
+			tagged[t.TodoID] = true
+		}
+	}
+	var tags []model.TodoTag
+	for _, id := range ids {
+		if !tagged[id] && counts[id] < model.MaxTodoTags {
+			tags = append(tags, model.TodoTag{UserID: userID, TodoID: id, Name: tag})
+		}
+	}
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	return int64(len(tags)), tx.CreateInBatches(tags, 500).Error
+}
+
+// bulkMove 修改所属项目，并按新项目分配编号
+func bulkMove(tx *gorm.DB, scope func() *gorm.DB, projectID *uint) (int64, error) {
+	res := scope().Update("project_id", projectID)
+	if res.Error != nil || projectID == nil {
+		return res.RowsAffected, res.Error
+	}
+	var todos []model.Todo
+	if err := scope().Select("id", "user_id", "project_id", "key_project_id").Order("id ASC").
+		Find(&todos).Error; err != nil {
+		return 0, err
+	}
+	for i := range todos {
+		if err := assignTodoKey(tx, &todos[i]); err != nil {
+			return 0, err
+		}
+	}
+	return res.RowsAffected, nil
+}
+
+// bulkSetDue 修改截止时间：按各待办的调整方式移到工作日，重复待办以设置的日期为新的计划日期；
+// 记录变更历史并累计改期次数（与单条修改一致）
+func bulkSetDue(tx *gorm.DB, scope func() *gorm.DB, userID uint, due *time.Time, shiftDue func(time.Time, string) time.Time) (int64, error) {
+	var todos []model.Todo
+	if err := scope().Select("id", "due_time", "due_shift").Find(&todos).Error; err != nil {
+		return 0, err
+	}
+	var affected int64
+	var changes []model.TodoDueChange
+	for _, t := range todos {
+		newDue := due
+		if due != nil {
+			shifted := shiftDue(*due, t.DueShift)
+			newDue = &shifted
+		}
+		change := model.TodoDueChange{UserID: userID, TodoID: t.ID, OldDue: t.DueTime, NewDue: newDue}
+		if (change.OldDue == nil) == (change.NewDue == nil) && !change.IsReschedule() {
+			continue // 截止时间没有变化
+		}
+		updates := map[string]interface{}{"due_time": newDue, "recurrence_anchor": due}
+		if change.IsReschedule() {
+			updates["reschedule_count"] = gorm.Expr("reschedule_count + 1")
+		}
+		if err := tx.Model(&model.Todo{}).Where("id = ?", t.ID).Updates(updates).Error; err != nil {
+			return 0, err
+		}
+		affected++
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	return affected, tx.CreateInBatches(changes, 500).Error
+}
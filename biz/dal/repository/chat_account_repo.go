@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrChatAccountNotFound 聊天账号未找到
+	ErrChatAccountNotFound = errors.New("chat account not found")
+)
+
+// ChatAccountRepository 聊天账号绑定数据访问层
+type ChatAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewChatAccountRepository 创建聊天账号仓库实例
+func NewChatAccountRepository(db *gorm.DB) *ChatAccountRepository {
+	return &ChatAccountRepository{db: db}
+}
+
+// Get 按平台 + 团队 + 聊天用户查找
+func (r *ChatAccountRepository) Get(provider, teamID, chatUserID string) (*model.ChatAccount, error) {
+	var account model.ChatAccount
+	err := r.db.Where("provider = ? AND team_id = ? AND chat_user_id = ?", provider, teamID, chatUserID).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChatAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SaveLinkCode 为聊天账号写入一次性绑定码（不存在则创建未绑定记录）；
+// 解绑后的记录是软删除的，仍占着唯一索引，这里恢复成未绑定状态再复用
+func (r *ChatAccountRepository) SaveLinkCode(provider, teamID, chatUserID, code string, expiresAt time.Time) error {
+	var account model.ChatAccount
+	err := r.db.Unscoped().Where("provider = ? AND team_id = ? AND chat_user_id = ?", provider, teamID, chatUserID).
+		First(&account).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err != nil {
+		account = model.ChatAccount{
+			Provider:   provider,
+			TeamID:     teamID,
+			ChatUserID: chatUserID,
+		}
+	} else if account.DeletedAt.Valid {
+		account.DeletedAt = gorm.DeletedAt{}
+		account.UserID = 0
+	}
+	account.LinkCode = code
+	account.LinkCodeExpiresAt = &expiresAt
+	return r.db.Unscoped().Save(&account).Error
+}
+
+// LinkByCode 用绑定码把聊天账号绑定到 userID，绑定码一次性使用
+func (r *ChatAccountRepository) LinkByCode(provider, code string, userID uint, now time.Time) (*model.ChatAccount, error) {
+	var account model.ChatAccount
+	err := r.db.Where("provider = ? AND link_code = ? AND link_code_expires_at > ?", provider, code, now).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChatAccountNotFound
+		}
+		return nil, err
+	}
+
+	account.UserID = userID
+	account.LinkCode = ""
+	account.LinkCodeExpiresAt = nil
+	if err := r.db.Save(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListByUser 列出某用户绑定的所有聊天账号
+func (r *ChatAccountRepository) ListByUser(userID uint) ([]model.ChatAccount, error) {
+	var accounts []model.ChatAccount
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Unlink 解除绑定（限定用户）
+func (r *ChatAccountRepository) Unlink(userID, id uint) (int64, error) {
+	tx := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.ChatAccount{})
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return tx.RowsAffected, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDelegationNotFound 待办不在等待状态
+	ErrDelegationNotFound = errors.New("todo is not waiting for anyone")
+)
+
+// DelegationRepository 委托（等待他人）数据访问层
+type DelegationRepository struct {
+	db *gorm.DB
+}
+
+// NewDelegationRepository 创建委托仓库实例
+func NewDelegationRepository(db *gorm.DB) *DelegationRepository {
+	return &DelegationRepository{db: db}
+}
+
+// Save 设置待办的委托信息（已存在时更新被委托人、联系方式与预计日期，跟进记录保留）
+func (r *DelegationRepository) Save(d *model.Delegation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "todo_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"delegate", "contact", "expected_date", "updated_at"}),
+	}).Create(d).Error
+}
+
+// GetByTodo 获取待办的委托信息（限定用户）
+func (r *DelegationRepository) GetByTodo(userID, todoID uint) (*model.Delegation, error) {
+	var d model.Delegation
+	if err := r.db.Where("user_id = ? AND todo_id = ?", userID, todoID).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDelegationNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Delete 取消待办的等待状态（限定用户）
+func (r *DelegationRepository) Delete(userID, todoID uint) (int64, error) {
+	res := r.db.Where("user_id = ? AND todo_id = ?", userID, todoID).Delete(&model.Delegation{})
+	return res.RowsAffected, res.Error
+}
+
+// List 列出用户的全部委托（按预计日期升序）
+func (r *DelegationRepository) List(userID uint) ([]model.Delegation, error) {
+	var list []model.Delegation
+	if err := r.db.Where("user_id = ?", userID).Order("expected_date ASC, id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListExpectedBefore 列出所有用户中预计日期早于 date 的委托（供跟进任务扫描）
+func (r *DelegationRepository) ListExpectedBefore(date string) ([]model.Delegation, error) {
+	var list []model.Delegation
+	if err := r.db.Where("expected_date < ?", date).Order("user_id ASC, id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RecordFollowUp 记录一次跟进
+func (r *DelegationRepository) RecordFollowUp(id, followUpTodoID uint, at time.Time) error {
+	return r.db.Model(&model.Delegation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"follow_ups":        gorm.Expr("follow_ups + 1"),
+		"last_follow_up_at": at,
+		"follow_up_todo_id": followUpTodoID,
+	}).Error
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+	redisClient "memogo/biz/dal/redis"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrFeatureFlagNotFound 功能开关不存在
+	ErrFeatureFlagNotFound = errors.New("feature flag not found")
+	// ErrFeatureFlagExists 功能开关 key 已存在
+	ErrFeatureFlagExists = errors.New("feature flag already exists")
+)
+
+// featureFlagsCacheKey 全部开关缓存在同一个键里：开关数量少、读多写少，
+// 每次评估只需一次 Redis 读取
+const featureFlagsCacheKey = "feature_flags:all"
+
+// FeatureFlagRepository 功能开关数据访问层
+type FeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewFeatureFlagRepository 创建功能开关仓库实例
+func NewFeatureFlagRepository(db *gorm.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// 缓存失效：开关有任何写入都删除整份缓存
+func (r *FeatureFlagRepository) invalidateCache() {
+	if redisClient.RDB == nil {
+		return
+	}
+	redisClient.RDB.Del(context.Background(), featureFlagsCacheKey)
+}
+
+// Create 创建开关
+func (r *FeatureFlagRepository) Create(flag *model.FeatureFlag) error {
+	var count int64
+	if err := r.db.Model(&model.FeatureFlag{}).Where("flag_key = ?", flag.Key).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrFeatureFlagExists
+	}
+	if err := r.db.Create(flag).Error; err != nil {
+		return err
+	}
+	r.invalidateCache()
+	return nil
+}
+
+// ListAll 获取全部开关（按 key 排序，优先读缓存）
+func (r *FeatureFlagRepository) ListAll() ([]model.FeatureFlag, error) {
+	if redisClient.RDB != nil {
+		cachedData, err := redisClient.RDB.Get(context.Background(), featureFlagsCacheKey).Result()
+		if err == nil {
+			var flags []model.FeatureFlag
+			if json.Unmarshal([]byte(cachedData), &flags) == nil {
+				return flags, nil
+			}
+		}
+	}
+
+	var flags []model.FeatureFlag
+	if err := r.db.Order("flag_key ASC").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+
+	// 写入缓存（5分钟过期，写入时主动失效）
+	if redisClient.RDB != nil {
+		if data, err := json.Marshal(flags); err == nil {
+			redisClient.RDB.Set(context.Background(), featureFlagsCacheKey, data, 5*time.Minute)
+		}
+	}
+	return flags, nil
+}
+
+// GetByKey 按 key 获取开关（经由 ListAll 的缓存）
+func (r *FeatureFlagRepository) GetByKey(key string) (*model.FeatureFlag, error) {
+	flags, err := r.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range flags {
+		if flags[i].Key == key {
+			return &flags[i], nil
+		}
+	}
+	return nil, ErrFeatureFlagNotFound
+}
+
+// Update 从数据库读取最新的开关，交给 apply 修改后整行保存；apply 返回错误时不写入
+func (r *FeatureFlagRepository) Update(key string, apply func(flag *model.FeatureFlag) error) (*model.FeatureFlag, error) {
+	var flag model.FeatureFlag
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("flag_key = ?", key).First(&flag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFeatureFlagNotFound
+			}
+			return err
+		}
+		if err := apply(&flag); err != nil {
+			return err
+		}
+		return tx.Save(&flag).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateCache()
+	return &flag, nil
+}
+
+// Delete 按 key 删除开关（硬删除，key 可重新创建）
+func (r *FeatureFlagRepository) Delete(key string) (*model.FeatureFlag, error) {
+	var flag model.FeatureFlag
+	if err := r.db.Where("flag_key = ?", key).First(&flag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, err
+	}
+	if err := r.db.Delete(&flag).Error; err != nil {
+		return nil, err
+	}
+	r.invalidateCache()
+	return &flag, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGoalNotFound 目标不存在
+	ErrGoalNotFound = errors.New("goal not found")
+)
+
+// GoalRepository 目标数据访问层
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+// NewGoalRepository 创建目标仓库实例
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+// Create 新建目标
+func (r *GoalRepository) Create(goal *model.Goal) error {
+	return r.db.Create(goal).Error
+}
+
+// GetByID 按 ID 获取目标（限定用户）
+func (r *GoalRepository) GetByID(userID, id uint) (*model.Goal, error) {
+	var goal model.Goal
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&goal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoalNotFound
+		}
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// List 列出用户的全部目标（按目标日期升序）
+func (r *GoalRepository) List(userID uint) ([]model.Goal, error) {
+	var goals []model.Goal
+	if err := r.db.Where("user_id = ?", userID).Order("target_date ASC, id ASC").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// UpdateFields 部分更新目标（限定用户）
+func (r *GoalRepository) UpdateFields(userID, id uint, updates map[string]interface{}) (int64, error) {
+	res := r.db.Model(&model.Goal{}).Where("id = ? AND user_id = ?", id, userID).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+// Delete 删除目标（软删除）及其关联
+func (r *GoalRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Goal{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Where("goal_id = ?", id).Delete(&model.GoalLink{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// AddLink 关联待办或项目（已关联时忽略）
+func (r *GoalRepository) AddLink(link *model.GoalLink) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error
+}
+
+// RemoveLink 取消关联
+func (r *GoalRepository) RemoveLink(goalID uint, targetType string, targetID uint) (int64, error) {
+	res := r.db.Where("goal_id = ? AND target_type = ? AND target_id = ?", goalID, targetType, targetID).
+		Delete(&model.GoalLink{})
+	return res.RowsAffected, res.Error
+}
+
+// ListLinks 列出目标的全部关联
+func (r *GoalRepository) ListLinks(goalID uint) ([]model.GoalLink, error) {
+	var links []model.GoalLink
+	if err := r.db.Where("goal_id = ?", goalID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrHabitNotFound 习惯不存在
+	ErrHabitNotFound = errors.New("habit not found")
+)
+
+// HabitRepository 习惯数据访问层
+type HabitRepository struct {
+	db *gorm.DB
+}
+
+// NewHabitRepository 创建习惯仓库实例
+func NewHabitRepository(db *gorm.DB) *HabitRepository {
+	return &HabitRepository{db: db}
+}
+
+// Create 新建习惯
+func (r *HabitRepository) Create(habit *model.Habit) error {
+	return r.db.Create(habit).Error
+}
+
+// GetByID 按 ID 获取习惯（限定用户）
+func (r *HabitRepository) GetByID(userID, id uint) (*model.Habit, error) {
+	var habit model.Habit
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&habit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHabitNotFound
+		}
+		return nil, err
+	}
+	return &habit, nil
+}
+
+// List 列出用户的全部习惯（按创建顺序）
+func (r *HabitRepository) List(userID uint) ([]model.Habit, error) {
+	var habits []model.Habit
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&habits).Error; err != nil {
+		return nil, err
+	}
+	return habits, nil
+}
+
+// UpdateFields 部分更新习惯（限定用户）
+func (r *HabitRepository) UpdateFields(userID, id uint, updates map[string]interface{}) (int64, error) {
+	res := r.db.Model(&model.Habit{}).Where("id = ? AND user_id = ?", id, userID).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+// Delete 删除习惯（软删除）及其打卡记录
+func (r *HabitRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Habit{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Where("habit_id = ?", id).Delete(&model.HabitCheckIn{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// CheckIn 打卡（当天已打卡时忽略）
+func (r *HabitRepository) CheckIn(checkIn *model.HabitCheckIn) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(checkIn).Error
+}
+
+// UndoCheckIn 取消某天的打卡
+func (r *HabitRepository) UndoCheckIn(habitID uint, date string) (int64, error) {
+	res := r.db.Where("habit_id = ? AND date = ?", habitID, date).Delete(&model.HabitCheckIn{})
+	return res.RowsAffected, res.Error
+}
+
+// ListCheckInDates 列出习惯的全部打卡日期（升序）
+func (r *HabitRepository) ListCheckInDates(habitID uint) ([]string, error) {
+	var dates []string
+	if err := r.db.Model(&model.HabitCheckIn{}).Where("habit_id = ?", habitID).
+		Order("date ASC").Pluck("date", &dates).Error; err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
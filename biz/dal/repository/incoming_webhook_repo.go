@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIncomingWebhookNotFound 入站 webhook 不存在
+	ErrIncomingWebhookNotFound = errors.New("incoming webhook not found")
+)
+
+// IncomingWebhookRepository 入站 webhook 数据访问层
+type IncomingWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewIncomingWebhookRepository 创建入站 webhook 仓库实例
+func NewIncomingWebhookRepository(db *gorm.DB) *IncomingWebhookRepository {
+	return &IncomingWebhookRepository{db: db}
+}
+
+// Create 新建入站 webhook
+func (r *IncomingWebhookRepository) Create(hook *model.IncomingWebhook) error {
+	return r.db.Create(hook).Error
+}
+
+// GetByID 按 ID 获取（限定用户）
+func (r *IncomingWebhookRepository) GetByID(userID, id uint) (*model.IncomingWebhook, error) {
+	return r.first(r.db.Where("id = ? AND user_id = ?", id, userID))
+}
+
+// GetByToken 按 URL 令牌获取，不限定用户（接收端由令牌与签名认证）
+func (r *IncomingWebhookRepository) GetByToken(token string) (*model.IncomingWebhook, error) {
+	return r.first(r.db.Where("token = ?", token))
+}
+
+func (r *IncomingWebhookRepository) first(q *gorm.DB) (*model.IncomingWebhook, error) {
+	var hook model.IncomingWebhook
+	if err := q.First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIncomingWebhookNotFound
+		}
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// ListByUser 列出用户的入站 webhook
+func (r *IncomingWebhookRepository) ListByUser(userID uint) ([]model.IncomingWebhook, error) {
+	var hooks []model.IncomingWebhook
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// MarkDelivered 累加投递次数并记录最近投递时间
+func (r *IncomingWebhookRepository) MarkDelivered(id uint, at time.Time) error {
+	return r.db.Model(&model.IncomingWebhook{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"delivery_count":    gorm.Expr("delivery_count + 1"),
+		"last_delivered_at": at,
+	}).Error
+}
+
+// Delete 删除入站 webhook（限定用户，已创建的待办保留）
+func (r *IncomingWebhookRepository) Delete(userID, id uint) (int64, error) {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.IncomingWebhook{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
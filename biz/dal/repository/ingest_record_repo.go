@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IngestRecordRepository 导入幂等记录数据访问层
+type IngestRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewIngestRecordRepository 创建导入记录仓库实例
+func NewIngestRecordRepository(db *gorm.DB) *IngestRecordRepository {
+	return &IngestRecordRepository{db: db}
+}
+
+// CreateTodoOnce 在同一事务中写入幂等记录并创建待办
+// 幂等键已存在时不创建，返回之前创建的待办 ID 与 duplicate=true
+func (r *IngestRecordRepository) CreateTodoOnce(record *model.IngestRecord, todo *model.Todo) (todoID uint, duplicate bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 先占位幂等键：唯一索引冲突时不插入，RowsAffected 为 0
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			var existing model.IngestRecord
+			if err := tx.Where("user_id = ? AND idempotency_key = ?", record.UserID, record.IdempotencyKey).
+				First(&existing).Error; err != nil {
+				return err
+			}
+			todoID = existing.TodoID
+			duplicate = true
+			return nil
+		}
+
+		setTitleSortKey(todo)
+		if err := tx.Create(todo).Error; err != nil {
+			return err
+		}
+		if err := assignTodoKey(tx, todo); err != nil {
+			return err
+		}
+		todoID = todo.ID
+		return tx.Model(record).Update("todo_id", todo.ID).Error
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return todoID, duplicate, nil
+}
+
+// FindTodoID 按幂等键查找之前导入的待办 ID
+func (r *IngestRecordRepository) FindTodoID(userID uint, idempotencyKey string) (todoID uint, found bool, err error) {
+	var record model.IngestRecord
+	err = r.db.Select("todo_id").Where("user_id = ? AND idempotency_key = ?", userID, idempotencyKey).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return record.TodoID, true, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIssueConnectorNotFound 同步连接不存在
+	ErrIssueConnectorNotFound = errors.New("issue connector not found")
+)
+
+// IssueConnectorRepository issue 同步连接数据访问层
+type IssueConnectorRepository struct {
+	db *gorm.DB
+}
+
+// NewIssueConnectorRepository 创建同步连接仓库实例
+func NewIssueConnectorRepository(db *gorm.DB) *IssueConnectorRepository {
+	return &IssueConnectorRepository{db: db}
+}
+
+// Create 新建同步连接
+func (r *IssueConnectorRepository) Create(conn *model.IssueConnector) error {
+	return r.db.Create(conn).Error
+}
+
+// GetByID 按 ID 获取（限定用户）
+func (r *IssueConnectorRepository) GetByID(userID, id uint) (*model.IssueConnector, error) {
+	return r.first(r.db.Where("id = ? AND user_id = ?", id, userID))
+}
+
+// GetByIDForWebhook 按 ID 获取，不限定用户（webhook 请求由签名认证）
+func (r *IssueConnectorRepository) GetByIDForWebhook(id uint) (*model.IssueConnector, error) {
+	return r.first(r.db.Where("id = ?", id))
+}
+
+func (r *IssueConnectorRepository) first(q *gorm.DB) (*model.IssueConnector, error) {
+	var conn model.IssueConnector
+	if err := q.First(&conn).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIssueConnectorNotFound
+		}
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// ListByUser 列出用户的同步连接
+func (r *IssueConnectorRepository) ListByUser(userID uint) ([]model.IssueConnector, error) {
+	var conns []model.IssueConnector
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&conns).Error; err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
+
+// ListAll 列出全部同步连接（后台定时同步使用）
+func (r *IssueConnectorRepository) ListAll() ([]model.IssueConnector, error) {
+	var conns []model.IssueConnector
+	if err := r.db.Order("id ASC").Find(&conns).Error; err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
+
+// MarkSynced 记录一次同步的结果
+func (r *IssueConnectorRepository) MarkSynced(id uint, syncedAt *time.Time, lastError string) error {
+	updates := map[string]interface{}{"last_error": lastError}
+	if syncedAt != nil {
+		updates["last_synced_at"] = *syncedAt
+	}
+	return r.db.Model(&model.IssueConnector{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 删除同步连接及其 issue 对应关系（限定用户，已导入的待办保留）
+func (r *IssueConnectorRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.IssueConnector{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Where("connector_id = ?", id).Delete(&model.IssueLink{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrIssueLinkNotFound issue 对应关系不存在
+	ErrIssueLinkNotFound = errors.New("issue link not found")
+)
+
+// IssueLinkRepository 待办与 issue 对应关系数据访问层
+type IssueLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewIssueLinkRepository 创建对应关系仓库实例
+func NewIssueLinkRepository(db *gorm.DB) *IssueLinkRepository {
+	return &IssueLinkRepository{db: db}
+}
+
+// GetByIssue 按连接 + issue 编号查找
+func (r *IssueLinkRepository) GetByIssue(connectorID uint, number int64) (*model.IssueLink, error) {
+	var link model.IssueLink
+	if err := r.db.Where("connector_id = ? AND issue_number = ?", connectorID, number).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIssueLinkNotFound
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListByConnector 列出连接下的全部对应关系
+func (r *IssueLinkRepository) ListByConnector(connectorID uint) ([]model.IssueLink, error) {
+	var links []model.IssueLink
+	if err := r.db.Where("connector_id = ?", connectorID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Save 新建或更新对应关系
+func (r *IssueLinkRepository) Save(link *model.IssueLink) error {
+	return r.db.Save(link).Error
+}
+
+// CreateWithTodo 在同一事务中建立对应关系并创建待办
+// issue 已被导入（并发同步先写入了对应关系）时不创建，返回 created=false
+func (r *IssueLinkRepository) CreateWithTodo(link *model.IssueLink, todo *model.Todo) (created bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 先占位对应关系：唯一索引冲突时不插入，RowsAffected 为 0
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(link)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		setTitleSortKey(todo)
+		if err := tx.Create(todo).Error; err != nil {
+			return err
+		}
+		if err := assignTodoKey(tx, todo); err != nil {
+			return err
+		}
+		link.TodoID = todo.ID
+		created = true
+		return tx.Model(link).Update("todo_id", todo.ID).Error
+	})
+	if err != nil || !created {
+		return false, err
+	}
+	NewTodoRepository(r.db).invalidateUserCache(todo.UserID)
+	return true, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoteNotFound 笔记不存在
+	ErrNoteNotFound = errors.New("note not found")
+)
+
+// NoteFilter 笔记查询条件（零值表示不限）
+type NoteFilter struct {
+	Query   string // 标题或内容包含
+	Journal *bool  // true 只查日记，false 只查普通笔记
+	From    string // 日记日期下限（含），2006-01-02
+	To      string // 日记日期上限（含）
+	Cursor  uint   // 返回 id < cursor 的记录
+	Limit   int
+}
+
+// NoteRepository 笔记与 wiki 链接数据访问层
+type NoteRepository struct {
+	db *gorm.DB
+}
+
+// NewNoteRepository 创建笔记仓库实例
+func NewNoteRepository(db *gorm.DB) *NoteRepository {
+	return &NoteRepository{db: db}
+}
+
+// Create 新建笔记
+func (r *NoteRepository) Create(note *model.Note) error {
+	return r.db.Create(note).Error
+}
+
+// GetByID 按 ID 获取笔记（限定用户）
+func (r *NoteRepository) GetByID(userID, id uint) (*model.Note, error) {
+	var note model.Note
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoteNotFound
+		}
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetByJournalDate 获取某天的日记
+func (r *NoteRepository) GetByJournalDate(userID uint, date string) (*model.Note, error) {
+	var note model.Note
+	if err := r.db.Where("user_id = ? AND journal_date = ?", userID, date).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoteNotFound
+		}
+		return nil, err
+	}
+	return &note, nil
+}
+
+// FindByTitle 按标题查找普通笔记，重名时取最早创建的一篇
+func (r *NoteRepository) FindByTitle(userID uint, title string) (*model.Note, error) {
+	var note model.Note
+	err := r.db.Where("user_id = ? AND title = ? AND journal_date IS NULL", userID, title).
+		Order("id ASC").First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoteNotFound
+		}
+		return nil, err
+	}
+	return &note, nil
+}
+
+// ListByIDs 批量获取笔记（限定用户）
+func (r *NoteRepository) ListByIDs(userID uint, ids []uint) ([]model.Note, error) {
+	var notes []model.Note
+	if len(ids) == 0 {
+		return notes, nil
+	}
+	if err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// UpdateFields 部分更新笔记（限定用户）
+func (r *NoteRepository) UpdateFields(userID, id uint, updates map[string]interface{}) (int64, error) {
+	res := r.db.Model(&model.Note{}).Where("id = ? AND user_id = ?", id, userID).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+// Delete 删除笔记及其发出的链接；指向它的链接改为未解析，重建同名笔记后可重新解析
+func (r *NoteRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Note{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		if err := tx.Where("note_id = ?", id).Delete(&model.NoteLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.NoteLink{}).
+			Where("user_id = ? AND target_type IN ? AND target_id = ?", userID,
+				[]string{model.NoteLinkTargetNote, model.NoteLinkTargetJournal}, id).
+			Update("target_id", 0).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// List 按 id 倒序查询笔记
+func (r *NoteRepository) List(userID uint, f NoteFilter) ([]model.Note, error) {
+	q := r.db.Model(&model.Note{}).Where("user_id = ?", userID)
+	if f.Query != "" {
+		q = q.Where("title LIKE ? OR content LIKE ?", "%"+f.Query+"%", "%"+f.Query+"%")
+	}
+	if f.Journal != nil {
+		if *f.Journal {
+			q = q.Where("journal_date IS NOT NULL")
+		} else {
+			q = q.Where("journal_date IS NULL")
+		}
+	}
+	if f.From != "" {
+		q = q.Where("journal_date >= ?", f.From)
+	}
+	if f.To != "" {
+		q = q.Where("journal_date <= ?", f.To)
+	}
+	if f.Cursor != 0 {
+		q = q.Where("id < ?", f.Cursor)
+	}
+	var notes []model.Note
+	if err := q.Order("id DESC").Limit(f.Limit).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// ReplaceLinks 用 links 替换笔记发出的全部链接
+func (r *NoteRepository) ReplaceLinks(noteID uint, links []model.NoteLink) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("note_id = ?", noteID).Delete(&model.NoteLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// ResolvePending 把指向 target 的未解析链接指向新建（或改名）的笔记
+func (r *NoteRepository) ResolvePending(userID uint, targetType, target string, noteID uint) error {
+	return r.db.Model(&model.NoteLink{}).
+		Where("user_id = ? AND target_type = ? AND target = ? AND target_id = 0", userID, targetType, target).
+		Update("target_id", noteID).Error
+}
+
+// ListLinks 列出笔记发出的链接（按出现顺序）
+func (r *NoteRepository) ListLinks(noteID uint) ([]model.NoteLink, error) {
+	var links []model.NoteLink
+	if err := r.db.Where("note_id = ?", noteID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ListBacklinks 列出链接到目标（待办或笔记）的笔记，最近更新的在前
+func (r *NoteRepository) ListBacklinks(userID uint, targetTypes []string, targetID uint) ([]model.Note, error) {
+	var notes []model.Note
+	err := r.db.Where("user_id = ? AND id IN (?)", userID,
+		r.db.Model(&model.NoteLink{}).Select("note_id").
+			Where("user_id = ? AND target_type IN ? AND target_id = ?", userID, targetTypes, targetID),
+	).Order("updated_at DESC, id DESC").Find(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPlanNotFound 套餐不存在
+	ErrPlanNotFound = errors.New("plan not found")
+	// ErrPlanExists 套餐代码已存在
+	ErrPlanExists = errors.New("plan already exists")
+	// ErrPlanAssignmentNotFound 套餐分配不存在
+	ErrPlanAssignmentNotFound = errors.New("plan assignment not found")
+)
+
+// usageModels 可由仓库统计用量的限额项及对应的表（按 user_id 统计）
+var usageModels = map[string]interface{}{
+	model.LimitTodos:            &model.Todo{},
+	model.LimitProjects:         &model.Project{},
+	model.LimitIncomingWebhooks: &model.IncomingWebhook{},
+}
+
+// PlanRepository 套餐与套餐分配数据访问层
+type PlanRepository struct {
+	db *gorm.DB
+}
+
+// NewPlanRepository 创建套餐仓库实例
+func NewPlanRepository(db *gorm.DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+// Create 创建套餐；设为默认时取消其它套餐的默认标记
+func (r *PlanRepository) Create(plan *model.Plan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Plan{}).Where("plan_code = ?", plan.Code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPlanExists
+		}
+		if plan.IsDefault {
+			if err := clearDefaultPlan(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Create(plan).Error
+	})
+}
+
+// GetByCode 按代码获取套餐
+func (r *PlanRepository) GetByCode(code string) (*model.Plan, error) {
+	var plan model.Plan
+	if err := r.db.Where("plan_code = ?", code).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetDefault 获取默认套餐，没有时返回 nil
+func (r *PlanRepository) GetDefault() (*model.Plan, error) {
+	var plans []model.Plan
+	if err := r.db.Where("is_default = ?", true).Limit(1).Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, nil
+	}
+	return &plans[0], nil
+}
+
+// List 列出全部套餐（按代码排序）
+func (r *PlanRepository) List() ([]model.Plan, error) {
+	var plans []model.Plan
+	err := r.db.Order("plan_code ASC").Find(&plans).Error
+	return plans, err
+}
+
+// Update 从数据库读取最新的套餐，交给 apply 修改后整行保存；apply 返回错误时不写入
+func (r *PlanRepository) Update(code string, apply func(plan *model.Plan) error) (*model.Plan, error) {
+	var plan model.Plan
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_code = ?", code).First(&plan).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlanNotFound
+			}
+			return err
+		}
+		wasDefault := plan.IsDefault
+		if err := apply(&plan); err != nil {
+			return err
+		}
+		if plan.IsDefault && !wasDefault {
+			if err := clearDefaultPlan(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&plan).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Delete 删除套餐（硬删除）
+func (r *PlanRepository) Delete(code string) (*model.Plan, error) {
+	plan, err := r.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Delete(plan).Error; err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// CountAssignments 使用该套餐的分配数
+func (r *PlanRepository) CountAssignments(code string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PlanAssignment{}).Where("plan_code = ?", code).Count(&count).Error
+	return count, err
+}
+
+// GetAssignment 获取主体的套餐分配
+func (r *PlanRepository) GetAssignment(subjectType string, subjectID uint) (*model.PlanAssignment, error) {
+	var a model.PlanAssignment
+	if err := r.db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).First(&a).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanAssignmentNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SaveAssignment 创建或覆盖主体的套餐分配
+func (r *PlanRepository) SaveAssignment(a *model.PlanAssignment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.PlanAssignment
+		err := tx.Where("subject_type = ? AND subject_id = ?", a.SubjectType, a.SubjectID).First(&existing).Error
+		switch {
+		case err == nil:
+			a.ID, a.CreatedAt = existing.ID, existing.CreatedAt
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return tx.Save(a).Error
+	})
+}
+
+// DeleteAssignment 删除主体的套餐分配
+func (r *PlanRepository) DeleteAssignment(subjectType string, subjectID uint) (int64, error) {
+	tx := r.db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).Delete(&model.PlanAssignment{})
+	return tx.RowsAffected, tx.Error
+}
+
+// ListAssignments 列出套餐分配，plan 非空时只列该套餐的
+func (r *PlanRepository) ListAssignments(planCode string) ([]model.PlanAssignment, error) {
+	q := r.db.Order("subject_type ASC, subject_id ASC")
+	if planCode != "" {
+		q = q.Where("plan_code = ?", planCode)
+	}
+	var list []model.PlanAssignment
+	err := q.Find(&list).Error
+	return list, err
+}
+
+// CountUsage 统计用户在某个限额项上的用量；ok 为 false 表示该项无法由仓库统计
+func (r *PlanRepository) CountUsage(userID uint, limit string) (count int64, ok bool, err error) {
+	m, ok := usageModels[limit]
+	if !ok {
+		return 0, false, nil
+	}
+	err = r.db.Model(m).Where("user_id = ?", userID).Count(&count).Error
+	return count, true, err
+}
+
+func clearDefaultPlan(tx *gorm.DB) error {
+	return tx.Model(&model.Plan{}).Where("is_default = ?", true).Update("is_default", false).Error
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrProjectNotFound 项目不存在
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectHasChildren 节点下还有子节点，不能直接删除
+	ErrProjectHasChildren = errors.New("project has child nodes")
+	// ErrProjectKeyTaken 前缀已被用户的其他项目使用（含已删除的项目，避免编号重复）
+	ErrProjectKeyTaken = errors.New("project key already in use")
+)
+
+// ProjectRepository 项目数据访问层
+type ProjectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository 创建项目仓库实例
+func NewProjectRepository(db *gorm.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Create 新建项目，设置了前缀时校验前缀未被占用
+func (r *ProjectRepository) Create(project *model.Project) error {
+	if project.Key == "" {
+		return r.db.Create(project).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkKeyAvailable(tx, project.UserID, project.Key, 0); err != nil {
+			return err
+		}
+		return tx.Create(project).Error
+	})
+}
+
+// GetByID 按 ID 获取项目（限定用户）
+func (r *ProjectRepository) GetByID(userID, id uint) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// List 列出用户的全部项目
+func (r *ProjectRepository) List(userID uint) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// SetParent 修改节点的父节点（parentID 为 nil 表示移到顶层，限定用户）
+func (r *ProjectRepository) SetParent(userID, id uint, parentID *uint) (int64, error) {
+	res := r.db.Model(&model.Project{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("parent_id", parentID)
+	return res.RowsAffected, res.Error
+}
+
+// SetKey 设置项目的待办编号前缀（限定用户）：已按本项目编号的待办改用新前缀、序号不变，
+// scopeIDs 中尚未编号的待办按创建顺序补发编号；scopeIDs 为以本项目为最近带前缀上级的节点（含自身）
+func (r *ProjectRepository) SetKey(userID, id uint, key string, scopeIDs []uint) (*model.Project, error) {
+	var project model.Project
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkKeyAvailable(tx, userID, key, id); err != nil {
+			return err
+		}
+		// 先更新前缀：项目行锁与创建待办时的自增互斥，补发编号期间不会有并发分配
+		res := tx.Model(&model.Project{}).Where("id = ? AND user_id = ?", id, userID).Update("project_key", key)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrProjectNotFound
+		}
+		if err := tx.First(&project, id).Error; err != nil {
+			return err
+		}
+
+		var keyed []model.Todo
+		if err := tx.Unscoped().Select("id", "todo_key").
+			Where("key_project_id = ?", id).Find(&keyed).Error; err != nil {
+			return err
+		}
+		for _, t := range keyed {
+			if t.Key == nil {
+				continue
+			}
+			i := strings.LastIndexByte(*t.Key, '-')
+			n, err := strconv.Atoi((*t.Key)[i+1:])
+			if err != nil {
+				continue
+			}
+			if err := tx.Unscoped().Model(&model.Todo{}).Where("id = ?", t.ID).
+				UpdateColumn("todo_key", model.FormatTodoKey(key, n)).Error; err != nil {
+				return err
+			}
+		}
+
+		var unkeyed []uint
+		if len(scopeIDs) > 0 {
+			if err := tx.Model(&model.Todo{}).
+				Where("user_id = ? AND project_id IN ? AND key_project_id IS NULL", userID, scopeIDs).
+				Order("created_at ASC, id ASC").Pluck("id", &unkeyed).Error; err != nil {
+				return err
+			}
+		}
+		for _, todoID := range unkeyed {
+			project.TodoSeq++
+			if err := tx.Model(&model.Todo{}).Where("id = ?", todoID).UpdateColumns(map[string]interface{}{
+				"todo_key":       model.FormatTodoKey(key, project.TodoSeq),
+				"key_project_id": id,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if len(unkeyed) == 0 {
+			return nil
+		}
+		return tx.Model(&project).UpdateColumn("todo_seq", project.TodoSeq).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// checkKeyAvailable 校验前缀未被用户除 exceptID 外的项目占用（含已删除的项目）
+// 先锁住用户行直到事务结束：同一用户并发设置前缀时依次检查，不会都看到前缀空闲
+// （未设置前缀的项目都是空串，无法用 (user_id, project_key) 唯一索引约束）
+func checkKeyAvailable(tx *gorm.DB, userID uint, key string, exceptID uint) error {
+	var user model.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id = ?", userID).Limit(1).Find(&user).Error; err != nil {
+		return err
+	}
+	var taken int64
+	if err := tx.Unscoped().Model(&model.Project{}).
+		Where("user_id = ? AND project_key = ? AND id <> ?", userID, key, exceptID).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrProjectKeyTaken
+	}
+	return nil
+}
+
+// releaseTakenKeys 项目转入 userID 名下后，前缀与其已有项目重复的清空前缀，按这些项目分配的待办编号一并清除
+func releaseTakenKeys(tx *gorm.DB, userID uint, projectIDs []uint) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	existing := tx.Unscoped().Model(&model.Project{}).Select("project_key").
+		Where("user_id = ? AND project_key <> '' AND id NOT IN ?", userID, projectIDs)
+	var taken []uint
+	if err := tx.Model(&model.Project{}).
+		Where("id IN ? AND project_key IN (?)", projectIDs, existing).
+		Pluck("id", &taken).Error; err != nil {
+		return err
+	}
+	if len(taken) == 0 {
+		return nil
+	}
+	if err := tx.Model(&model.Project{}).Where("id IN ?", taken).
+		UpdateColumns(map[string]interface{}{"project_key": "", "todo_seq": 0}).Error; err != nil {
+		return err
+	}
+	return clearTodoKeys(tx.Where("key_project_id IN ?", taken))
+}
+
+// clearTodoKeys 清除 q 筛选出的待办的编号（含已删除的待办）
+func clearTodoKeys(q *gorm.DB) error {
+	return q.Unscoped().Model(&model.Todo{}).
+		UpdateColumns(map[string]interface{}{"todo_key": nil, "key_project_id": nil}).Error
+}
+
+// Delete 删除项目（软删除，限定用户），项目下的待办解除归属；有子节点时拒绝删除
+func (r *ProjectRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&model.Project{}).Where("user_id = ? AND parent_id = ?", userID, id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrProjectHasChildren
+		}
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Project{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		// 项目的迭代随项目一起删除
+		sprints := tx.Model(&model.Sprint{}).Select("id").Where("user_id = ? AND project_id = ?", userID, id)
+		if err := tx.Where("sprint_id IN (?)", sprints).Delete(&model.SprintItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND project_id = ?", userID, id).Delete(&model.Sprint{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Todo{}).
+			Where("user_id = ? AND project_id = ?", userID, id).
+			Update("project_id", nil).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+)
+
+func TestTodoKeysConcurrentCreate(t *testing.T) {
+	db := dbtest.Open(t)
+	user := model.User{Username: "alice", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	project := &model.Project{UserID: user.ID, Name: "Ops"}
+	if err := NewProjectRepository(db).Create(project); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewProjectRepository(db).SetKey(user.ID, project.ID, "OPS", []uint{project.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20
+	todoRepo := NewTodoRepository(db)
+	keys := make([]string, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			todo := &model.Todo{UserID: user.ID, ProjectID: &project.ID, Title: "t", Content: "t"}
+			if errs[i] = todoRepo.Create(todo); errs[i] == nil && todo.Key != nil {
+				keys[i] = *todo.Key
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 编号互不相同且连续：OPS-1 … OPS-n 各出现一次
+	seen := make(map[string]bool, n)
+	for i, key := range keys {
+		if errs[i] != nil {
+			t.Fatalf("create #%d: %v", i, errs[i])
+		}
+		if seen[key] {
+			t.Fatalf("key %q assigned twice", key)
+		}
+		seen[key] = true
+	}
+	for i := 1; i <= n; i++ {
+		if key := model.FormatTodoKey("OPS", i); !seen[key] {
+			t.Errorf("key %s missing from %v", key, keys)
+		}
+	}
+}
+
+func TestProjectKeyConcurrentSet(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewProjectRepository(db)
+	user := model.User{Username: "alice", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	const n = 5
+	projects := make([]*model.Project, n)
+	for i := range projects {
+		projects[i] = &model.Project{UserID: user.ID, Name: fmt.Sprintf("P%d", i)}
+		if err := repo.Create(projects[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func(i int, p *model.Project) {
+			defer wg.Done()
+			_, errs[i] = repo.SetKey(user.ID, p.ID, "OPS", []uint{p.ID})
+		}(i, p)
+	}
+	wg.Wait()
+
+	won := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, ErrProjectKeyTaken):
+			t.Fatalf("SetKey #%d: %v", i, err)
+		}
+	}
+	var keyed int64
+	if err := db.Model(&model.Project{}).Where("user_id = ? AND project_key = ?", user.ID, "OPS").Count(&keyed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if won != 1 || keyed != 1 {
+		t.Errorf("concurrent SetKey: %d succeeded, %d projects keyed OPS; want exactly 1", won, keyed)
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSprintNotFound 迭代不存在
+	ErrSprintNotFound = errors.New("sprint not found")
+)
+
+// SprintRepository 迭代数据访问层
+type SprintRepository struct {
+	db *gorm.DB
+}
+
+// NewSprintRepository 创建迭代仓库实例
+func NewSprintRepository(db *gorm.DB) *SprintRepository {
+	return &SprintRepository{db: db}
+}
+
+// Create 新建迭代
+func (r *SprintRepository) Create(sprint *model.Sprint) error {
+	return r.db.Create(sprint).Error
+}
+
+// GetByID 按 ID 获取迭代（限定用户）
+func (r *SprintRepository) GetByID(userID, id uint) (*model.Sprint, error) {
+	var sprint model.Sprint
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&sprint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSprintNotFound
+		}
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// ListByProject 列出项目的全部迭代（按开始日期升序）
+func (r *SprintRepository) ListByProject(userID, projectID uint) ([]model.Sprint, error) {
+	var sprints []model.Sprint
+	if err := r.db.Where("user_id = ? AND project_id = ?", userID, projectID).
+		Order("start_date ASC, id ASC").Find(&sprints).Error; err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+// UpdateFields 部分更新迭代（限定用户）
+func (r *SprintRepository) UpdateFields(userID, id uint, updates map[string]interface{}) (int64, error) {
+	res := r.db.Model(&model.Sprint{}).Where("id = ? AND user_id = ?", id, userID).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+// Delete 删除迭代（软删除）及其待办记录，待办本身不受影响
+func (r *SprintRepository) Delete(userID, id uint) (int64, error) {
+	var affected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Sprint{})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Where("sprint_id = ?", id).Delete(&model.SprintItem{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// ActiveItem 获取待办当前所在未关闭迭代的记录（不在任何迭代中时返回 nil）
+func (r *SprintRepository) ActiveItem(userID, todoID uint) (*model.SprintItem, error) {
+	var item model.SprintItem
+	err := r.db.Where("user_id = ? AND todo_id = ? AND removed_at IS NULL", userID, todoID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// AddItem 把待办加入迭代；若它在另一个迭代中则先从那里移出
+func (r *SprintRepository) AddItem(userID, sprintID, todoID uint, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.SprintItem{}).
+			Where("user_id = ? AND todo_id = ? AND sprint_id <> ? AND removed_at IS NULL", userID, todoID, sprintID).
+			Update("removed_at", at).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.SprintItem{}).
+			Where("sprint_id = ? AND todo_id = ? AND removed_at IS NULL", sprintID, todoID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&model.SprintItem{UserID: userID, SprintID: sprintID, TodoID: todoID, AddedAt: at}).Error
+	})
+}
+
+// RemoveItem 把待办移出迭代
+func (r *SprintRepository) RemoveItem(sprintID, todoID uint, at time.Time) (int64, error) {
+	res := r.db.Model(&model.SprintItem{}).
+		Where("sprint_id = ? AND todo_id = ? AND removed_at IS NULL", sprintID, todoID).
+		Update("removed_at", at)
+	return res.RowsAffected, res.Error
+}
+
+// ListItems 列出迭代的全部记录（含已移出与已结转的）
+func (r *SprintRepository) ListItems(sprintID uint) ([]model.SprintItem, error) {
+	var items []model.SprintItem
+	if err := r.db.Where("sprint_id = ?", sprintID).Order("added_at ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Close 关闭迭代：carry 中的待办标记为结转，nextID 不为 0 时加入该迭代；已关闭时返回 false
+func (r *SprintRepository) Close(userID, id uint, carry []uint, nextID uint, at time.Time) (closed bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Sprint{}).
+			Where("id = ? AND user_id = ? AND closed_at IS NULL", id, userID).
+			Update("closed_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		closed = true
+		if len(carry) == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.SprintItem{}).
+			Where("sprint_id = ? AND todo_id IN ? AND removed_at IS NULL", id, carry).
+			Updates(map[string]interface{}{"removed_at": at, "carried_over": true, "carried_to": nextID}).Error; err != nil {
+			return err
+		}
+		if nextID == 0 {
+			return nil
+		}
+		items := make([]model.SprintItem, 0, len(carry))
+		for _, todoID := range carry {
+			items = append(items, model.SprintItem{UserID: userID, SprintID: nextID, TodoID: todoID, AddedAt: at})
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return closed, nil
+}
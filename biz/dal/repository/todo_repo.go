@@ -3,15 +3,33 @@ package repository
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
+    "hash/fnv"
+    "strconv"
     "time"
 
     "memogo/biz/dal/model"
     redisClient "memogo/biz/dal/redis"
+    "memogo/pkg/collation"
 
     "gorm.io/gorm"
 )
 
+var (
+    // ErrTodoNotFound 待办不存在
+    ErrTodoNotFound = errors.New("todo not found")
+    // ErrInvalidCursor 游标指向的待办不存在
+    ErrInvalidCursor = errors.New("cursor does not match any todo")
+)
+
+// 待办列表排序方式
+const (
+    TodoSortCreated   = ""       // 按创建时间升序（默认）
+    TodoSortTitle     = "title"  // 按标题升序，中文按拼音
+    TodoSortTitleDesc = "-title" // 按标题降序
+)
+
 // TodoRepository 待办事项数据访问层
 type TodoRepository struct {
     db *gorm.DB
@@ -22,12 +40,138 @@ func NewTodoRepository(db *gorm.DB) *TodoRepository {
 }
 
 // 缓存键生成函数
-func (r *TodoRepository) listCacheKey(userID uint, statusFilter string, page, pageSize int) string {
-    return fmt.Sprintf("todos:list:user:%d:status:%s:page:%d:size:%d", userID, statusFilter, page, pageSize)
+func (r *TodoRepository) listCacheKey(userID uint, statusFilter string, projectIDs []uint, sort string, page, pageSize int) string {
+    return fmt.Sprintf("todos:list:user:%d:status:%s:scope:%s:sort:%s:page:%d:size:%d", userID, statusFilter, projectScopeKey(projectIDs), sort, page, pageSize)
+}
+
+func (r *TodoRepository) searchCacheKey(userID uint, keyword string, projectIDs []uint, sort string, page, pageSize int) string {
+    return fmt.Sprintf("todos:search:user:%d:kw:%s:scope:%s:sort:%s:page:%d:size:%d", userID, keyword, projectScopeKey(projectIDs), sort, page, pageSize)
+}
+
+// projectScopeKey 项目范围在缓存键中的表示：不限为 all，否则为 ID 列表的哈希（子树可能很大）
+func projectScopeKey(projectIDs []uint) string {
+    if projectIDs == nil {
+        return "all"
+    }
+    h := fnv.New64a()
+    for _, id := range projectIDs {
+        fmt.Fprintf(h, "%d,", id)
+    }
+    return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// todoOrder 排序方式对应的 ORDER BY，均以 ID 兜底保证顺序稳定
+func todoOrder(sort string) string {
+    switch sort {
+    case TodoSortTitle:
+        return "title_sort_key ASC, id ASC"
+    case TodoSortTitleDesc:
+        return "title_sort_key DESC, id DESC"
+    default:
+        return "created_at ASC, id ASC"
+    }
+}
+
+// withCursor 键集分页：cursor 为上一页最后一条的 ID，按标题排序时取该待办的排序键定位（含已删除的）
+func (r *TodoRepository) withCursor(q *gorm.DB, userID uint, sort string, cursor uint) (*gorm.DB, error) {
+    if cursor == 0 {
+        return q, nil
+    }
+    switch sort {
+    case TodoSortTitle, TodoSortTitleDesc:
+        var last model.Todo
+        if err := r.db.Unscoped().Select("id", "title_sort_key").
+            Where("id = ? AND user_id = ?", cursor, userID).First(&last).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil, ErrInvalidCursor
+            }
+            return nil, err
+        }
+        if sort == TodoSortTitle {
+            return q.Where("title_sort_key > ? OR (title_sort_key = ? AND id > ?)", last.TitleSortKey, last.TitleSortKey, cursor), nil
+        }
+        return q.Where("title_sort_key < ? OR (title_sort_key = ? AND id < ?)", last.TitleSortKey, last.TitleSortKey, cursor), nil
+    default:
+        // 按创建时间升序（旧→新），需要找比 cursor 更大的 ID
+        return q.Where("id > ?", cursor), nil
+    }
+}
+
+// setTitleSortKey 按标题计算排序键（所有写入 todos 的路径都要调用）
+func setTitleSortKey(todo *model.Todo) {
+    todo.TitleSortKey = collation.Key(todo.Title)
+}
+
+// assignTodoKey 按待办所在节点最近的、设置了前缀的上级项目分配编号（在事务内调用，待办已写入）
+// 已在该项目下编号的待办保持原编号；所在节点没有带前缀的上级时不分配，也保留已有编号
+func assignTodoKey(tx *gorm.DB, todo *model.Todo) error {
+    if todo.ProjectID == nil {
+        return nil
+    }
+    keyedID, err := nearestKeyedProjectID(tx, todo.UserID, *todo.ProjectID)
+    if err != nil || keyedID == 0 {
+        return err
+    }
+    if todo.KeyProjectID != nil && *todo.KeyProjectID == keyedID {
+        return nil
+    }
+    // 先自增再读回：自增持有项目行锁直到事务结束，并发创建依次拿到不同的编号
+    if err := tx.Model(&model.Project{}).Where("id = ?", keyedID).
+        UpdateColumn("todo_seq", gorm.Expr("todo_seq + 1")).Error; err != nil {
+        return err
+    }
+    var project model.Project
+    if err := tx.Select("id", "project_key", "todo_seq").Where("id = ?", keyedID).First(&project).Error; err != nil {
+        return err
+    }
+    key := model.FormatTodoKey(project.Key, project.TodoSeq)
+    if err := tx.Model(&model.Todo{}).Where("id = ?", todo.ID).
+        UpdateColumns(map[string]interface{}{"todo_key": key, "key_project_id": keyedID}).Error; err != nil {
+        return err
+    }
+    todo.Key, todo.KeyProjectID = &key, &keyedID
+    return nil
+}
+
+// nearestKeyedProjectID 从 projectID 开始向上查找第一个设置了前缀的节点，找不到时返回 0
+func nearestKeyedProjectID(tx *gorm.DB, userID, projectID uint) (uint, error) {
+    seen := make(map[uint]bool)
+    for id := projectID; id != 0 && !seen[id]; {
+        seen[id] = true
+        var project model.Project
+        if err := tx.Select("id", "parent_id", "project_key").
+            Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&project).Error; err != nil {
+            return 0, err
+        }
+        if project.ID == 0 {
+            return 0, nil
+        }
+        if project.Key != "" {
+            return project.ID, nil
+        }
+        if project.ParentID == nil {
+            return 0, nil
+        }
+        id = *project.ParentID
+    }
+    return 0, nil
+}
+
+// withKeyword 关键词筛选：匹配标题或正文；关键词形如 OPS-17 时同时按待办编号精确匹配
+func withKeyword(q *gorm.DB, keyword string) *gorm.DB {
+    like := "%" + keyword + "%"
+    if key, _, ok := model.ParseTodoKey(keyword); ok {
+        return q.Where("title LIKE ? OR content LIKE ? OR todo_key = ?", like, like, key)
+    }
+    return q.Where("title LIKE ? OR content LIKE ?", like, like)
 }
 
-func (r *TodoRepository) searchCacheKey(userID uint, keyword string, page, pageSize int) string {
-    return fmt.Sprintf("todos:search:user:%d:kw:%s:page:%d:size:%d", userID, keyword, page, pageSize)
+// withProjectScope 限定待办属于给定项目集合（nil 表示不限）
+func withProjectScope(q *gorm.DB, projectIDs []uint) *gorm.DB {
+    if projectIDs == nil {
+        return q
+    }
+    return q.Where("project_id IN ?", projectIDs)
 }
 
 func (r *TodoRepository) userCachePattern(userID uint) string {
@@ -50,9 +194,21 @@ func (r *TodoRepository) invalidateUserCache(userID uint) {
     }
 }
 
-// Create 新建待办
+// InvalidateCache 清除某用户的待办缓存（供绕过本仓库直接写 todos 表的场景使用）
+func (r *TodoRepository) InvalidateCache(userID uint) {
+    r.invalidateUserCache(userID)
+}
+
+// Create 新建待办，归属带前缀的项目时在同一事务中分配编号
 func (r *TodoRepository) Create(todo *model.Todo) error {
-    if err := r.db.Create(todo).Error; err != nil {
+    setTitleSortKey(todo)
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(todo).Error; err != nil {
+            return err
+        }
+        return assignTodoKey(tx, todo)
+    })
+    if err != nil {
         return err
     }
     // 清除该用户的缓存
@@ -60,11 +216,98 @@ func (r *TodoRepository) Create(todo *model.Todo) error {
     return nil
 }
 
-// UpdateStatusByID 按 ID 更新状态（限定用户）
-func (r *TodoRepository) UpdateStatusByID(userID, id uint, status int32) (int64, error) {
+// GetByID 按 ID 获取单条待办（限定用户）
+func (r *TodoRepository) GetByID(userID, id uint) (*model.Todo, error) {
+    var todo model.Todo
+    if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&todo).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, ErrTodoNotFound
+        }
+        return nil, err
+    }
+    return &todo, nil
+}
+
+// GetByKey 按项目内编号（如 OPS-17）获取单条待办（限定用户）
+func (r *TodoRepository) GetByKey(userID uint, key string) (*model.Todo, error) {
+    var todo model.Todo
+    if err := r.db.Where("user_id = ? AND todo_key = ?", userID, key).Order("id DESC").First(&todo).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, ErrTodoNotFound
+        }
+        return nil, err
+    }
+    return &todo, nil
+}
+
+// SetProject 设置待办所属项目（projectID 为 nil 表示移出项目，限定用户），移入另一个带前缀的项目时重新编号
+func (r *TodoRepository) SetProject(userID, id uint, projectID *uint) (int64, error) {
+    return r.UpdateFields(userID, id, map[string]interface{}{"project_id": projectID})
+}
+
+// ProjectTodoCount 按项目、状态分组的待办数量
+type ProjectTodoCount struct {
+    ProjectID *uint
+    Status    int32
+    Count     int64
+}
+
+// CountByProject 统计用户各项目下各状态的待办数量（project_id 为 nil 表示未归属项目）
+func (r *TodoRepository) CountByProject(userID uint) ([]ProjectTodoCount, error) {
+    var counts []ProjectTodoCount
+    err := r.db.Model(&model.Todo{}).
+        Select("project_id, status, COUNT(*) AS count").
+        Where("user_id = ?", userID).
+        Group("project_id, status").
+        Scan(&counts).Error
+    return counts, err
+}
+
+// ListLinked 列出 ID 在 ids 中或属于 projectIDs 中任一项目的待办（限定用户，去重）
+func (r *TodoRepository) ListLinked(userID uint, ids, projectIDs []uint) ([]model.Todo, error) {
+    var todos []model.Todo
+    if len(ids) == 0 && len(projectIDs) == 0 {
+        return todos, nil
+    }
+    q := r.db.Where("user_id = ?", userID)
+    switch {
+    case len(ids) == 0:
+        q = q.Where("project_id IN ?", projectIDs)
+    case len(projectIDs) == 0:
+        q = q.Where("id IN ?", ids)
+    default:
+        q = q.Where("id IN ? OR project_id IN ?", ids, projectIDs)
+    }
+    if err := q.Order("id ASC").Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// ListIDsByStatus 列出用户某状态下全部待办的 ID
+func (r *TodoRepository) ListIDsByStatus(userID uint, status int32) ([]uint, error) {
+    var ids []uint
+    if err := r.db.Model(&model.Todo{}).Where("user_id = ? AND status = ?", userID, status).
+        Order("id ASC").Pluck("id", &ids).Error; err != nil {
+        return nil, err
+    }
+    return ids, nil
+}
+
+// UpdateFields 按 ID 更新指定字段（限定用户），更新标题时同步排序键
+func (r *TodoRepository) UpdateFields(userID, id uint, updates map[string]interface{}) (int64, error) {
+    if title, ok := updates["title"].(string); ok {
+        updates["title_sort_key"] = collation.Key(title)
+    }
+    if due, ok := updates["due_time"]; ok {
+        return r.updateWithDueChange(userID, id, updates, due)
+    }
+    if _, ok := updates["project_id"]; ok {
+        return r.updateWithProject(userID, id, updates)
+    }
     tx := r.db.Model(&model.Todo{}).
         Where("id = ? AND user_id = ?", id, userID).
-        Update("status", status)
+        Updates(updates)
     if tx.Error != nil {
         return 0, tx.Error
     }
@@ -73,22 +316,279 @@ func (r *TodoRepository) UpdateStatusByID(userID, id uint, status int32) (int64,
     return tx.RowsAffected, nil
 }
 
-// UpdateAllStatus 按 from_status → to_status 批量更新（限定用户）
+// updateWithProject 更新包含所属项目的字段，并在同一事务中按新项目分配编号
+func (r *TodoRepository) updateWithProject(userID, id uint, updates map[string]interface{}) (int64, error) {
+    var affected int64
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        res := tx.Model(&model.Todo{}).
+            Where("id = ? AND user_id = ?", id, userID).
+            Updates(updates)
+        if res.Error != nil || res.RowsAffected == 0 {
+            return res.Error
+        }
+        affected = res.RowsAffected
+        var todo model.Todo
+        if err := tx.Select("id", "user_id", "project_id", "key_project_id").First(&todo, id).Error; err != nil {
+            return err
+        }
+        return assignTodoKey(tx, &todo)
+    })
+    if err != nil {
+        return 0, err
+    }
+    // 清除该用户的缓存
+    r.invalidateUserCache(userID)
+    return affected, nil
+}
+
+// updateWithDueChange 更新包含截止时间的字段，截止时间实际变化时记录变更历史并累计改期次数
+func (r *TodoRepository) updateWithDueChange(userID, id uint, updates map[string]interface{}, due interface{}) (int64, error) {
+    var newDue *time.Time
+    switch v := due.(type) {
+    case time.Time:
+        newDue = &v
+    case *time.Time:
+        newDue = v
+    }
+    var affected int64
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        var todo model.Todo
+        if err := tx.Select("id", "due_time").Where("id = ? AND user_id = ?", id, userID).First(&todo).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil
+            }
+            return err
+        }
+        change := model.TodoDueChange{UserID: userID, TodoID: id, OldDue: todo.DueTime, NewDue: newDue}
+        changed := (change.OldDue == nil) != (change.NewDue == nil) || change.IsReschedule()
+        if change.IsReschedule() {
+            updates["reschedule_count"] = gorm.Expr("reschedule_count + 1")
+        }
+        res := tx.Model(&model.Todo{}).
+            Where("id = ? AND user_id = ?", id, userID).
+            Updates(updates)
+        if res.Error != nil {
+            return res.Error
+        }
+        affected = res.RowsAffected
+        if !changed {
+            return nil
+        }
+        return tx.Create(&change).Error
+    })
+    if err != nil {
+        return 0, err
+    }
+    // 清除该用户的缓存
+    r.invalidateUserCache(userID)
+    return affected, nil
+}
+
+// ListDueChanges 列出待办的截止时间变更，按时间升序（限定用户）
+func (r *TodoRepository) ListDueChanges(userID, todoID uint) ([]model.TodoDueChange, error) {
+    var changes []model.TodoDueChange
+    if err := r.db.Where("user_id = ? AND todo_id = ?", userID, todoID).
+        Order("created_at ASC, id ASC").Find(&changes).Error; err != nil {
+        return nil, err
+    }
+    return changes, nil
+}
+
+// ListDueChangesSince 列出 since 之后的截止时间变更（只含未删除的待办，限定用户）
+func (r *TodoRepository) ListDueChangesSince(userID uint, since time.Time) ([]model.TodoDueChange, error) {
+    var changes []model.TodoDueChange
+    if err := r.db.Joins("JOIN todos ON todos.id = todo_due_changes.todo_id AND todos.deleted_at IS NULL").
+        Where("todo_due_changes.user_id = ? AND todo_due_changes.created_at >= ?", userID, since).
+        Order("todo_due_changes.created_at ASC, todo_due_changes.id ASC").
+        Find(&changes).Error; err != nil {
+        return nil, err
+    }
+    return changes, nil
+}
+
+// ListMostRescheduled 改期次数最多的未完成待办（限定用户）
+func (r *TodoRepository) ListMostRescheduled(userID uint, limit int) ([]model.Todo, error) {
+    var todos []model.Todo
+    if err := r.db.Where("user_id = ? AND status = ? AND reschedule_count > 0", userID, 0).
+        Order("reschedule_count DESC, id ASC").Limit(limit).Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// ListByIDs 按 ID 列出待办（不存在或已删除的忽略，限定用户）
+func (r *TodoRepository) ListByIDs(userID uint, ids []uint) ([]model.Todo, error) {
+    var todos []model.Todo
+    if len(ids) == 0 {
+        return todos, nil
+    }
+    if err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// UpdateStatusByID 按 ID 更新状态（限定用户），状态实际变化时记录变更历史
+func (r *TodoRepository) UpdateStatusByID(userID, id uint, status int32) (int64, error) {
+    var affected int64
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        var todo model.Todo
+        if err := tx.Select("id", "status").Where("id = ? AND user_id = ?", id, userID).First(&todo).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil
+            }
+            return err
+        }
+        res := tx.Model(&model.Todo{}).
+            Where("id = ? AND user_id = ?", id, userID).
+            Update("status", status)
+        if res.Error != nil {
+            return res.Error
+        }
+        affected = res.RowsAffected
+        if todo.Status == status {
+            return nil
+        }
+        return tx.Create(&model.TodoStatusChange{UserID: userID, TodoID: id, FromStatus: todo.Status, ToStatus: status}).Error
+    })
+    if err != nil {
+        return 0, err
+    }
+    // 清除该用户的缓存
+    r.invalidateUserCache(userID)
+    return affected, nil
+}
+
+// UpdateAllStatus 按 from_status → to_status 批量更新（限定用户），并记录变更历史
 func (r *TodoRepository) UpdateAllStatus(userID uint, fromStatus, toStatus int32) (int64, error) {
-    tx := r.db.Model(&model.Todo{}).
-        Where("user_id = ? AND status = ?", userID, fromStatus).
-        Update("status", toStatus)
-    if tx.Error != nil {
-        return 0, tx.Error
+    var affected int64
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        var ids []uint
+        if err := tx.Model(&model.Todo{}).
+            Where("user_id = ? AND status = ?", userID, fromStatus).
+            Pluck("id", &ids).Error; err != nil {
+            return err
+        }
+        if len(ids) == 0 {
+            return nil
+        }
+        res := tx.Model(&model.Todo{}).
+            Where("id IN ? AND status = ?", ids, fromStatus).
+            Update("status", toStatus)
+        if res.Error != nil {
+            return res.Error
+        }
+        affected = res.RowsAffected
+        if fromStatus == toStatus {
+            return nil
+        }
+        changes := make([]model.TodoStatusChange, 0, len(ids))
+        for _, id := range ids {
+            changes = append(changes, model.TodoStatusChange{UserID: userID, TodoID: id, FromStatus: fromStatus, ToStatus: toStatus})
+        }
+        return tx.CreateInBatches(changes, 500).Error
+    })
+    if err != nil {
+        return 0, err
     }
     // 清除该用户的缓存
     r.invalidateUserCache(userID)
-    return tx.RowsAffected, nil
+    return affected, nil
+}
+
+// ListStatusChanges 列出指定待办在 until 之前的状态变更，按时间升序（限定用户）
+func (r *TodoRepository) ListStatusChanges(userID uint, todoIDs []uint, until time.Time) ([]model.TodoStatusChange, error) {
+    var changes []model.TodoStatusChange
+    if len(todoIDs) == 0 {
+        return changes, nil
+    }
+    if err := r.db.Where("user_id = ? AND todo_id IN ? AND created_at < ?", userID, todoIDs, until).
+        Order("created_at ASC, id ASC").Find(&changes).Error; err != nil {
+        return nil, err
+    }
+    return changes, nil
+}
+
+// ListByDueRange 列出截止时间在 [from, to) 内的待办，按截止时间排序（限定用户）
+func (r *TodoRepository) ListByDueRange(userID uint, from, to time.Time, includeDone bool) ([]model.Todo, error) {
+    q := r.db.Where("user_id = ? AND due_time >= ? AND due_time < ?", userID, from, to)
+    if !includeDone {
+        q = q.Where("status = ?", 0)
+    }
+    var todos []model.Todo
+    if err := q.Order("due_time ASC, id ASC").Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// ListRecurring 列出指定状态下设置了重复规则的待办（限定用户）
+func (r *TodoRepository) ListRecurring(userID uint, status int32) ([]model.Todo, error) {
+    var todos []model.Todo
+    if err := r.db.Where("user_id = ? AND status = ? AND recurrence <> ''", userID, status).
+        Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// ListOpenByGtd 列出某个 GTD 清单中未完成的待办，按创建时间升序（限定用户）
+func (r *TodoRepository) ListOpenByGtd(userID uint, list string) ([]model.Todo, error) {
+    var todos []model.Todo
+    if err := r.db.Where("user_id = ? AND gtd_list = ? AND status = ?", userID, list, 0).
+        Order("created_at ASC, id ASC").Find(&todos).Error; err != nil {
+        return nil, err
+    }
+    return todos, nil
+}
+
+// CreateNextOccurrence 为重复待办生成下一条（复制标签），并清除上一条的重复规则（规则随系列转移到新待办）
+// 上一条的规则已被并发请求转移时不创建，返回 created=false
+func (r *TodoRepository) CreateNextOccurrence(prev, next *model.Todo) (created bool, err error) {
+    err = r.db.Transaction(func(tx *gorm.DB) error {
+        res := tx.Model(&model.Todo{}).
+            Where("id = ? AND user_id = ? AND recurrence = ?", prev.ID, prev.UserID, prev.Recurrence).
+            Updates(map[string]interface{}{"recurrence": "", "recurrence_anchor": nil})
+        if res.Error != nil {
+            return res.Error
+        }
+        if res.RowsAffected == 0 {
+            return nil
+        }
+        setTitleSortKey(next)
+        if err := tx.Create(next).Error; err != nil {
+            return err
+        }
+        if err := assignTodoKey(tx, next); err != nil {
+            return err
+        }
+        // 标签（含情境）随系列带到新待办
+        var tags []model.TodoTag
+        if err := tx.Where("user_id = ? AND todo_id = ?", prev.UserID, prev.ID).Find(&tags).Error; err != nil {
+            return err
+        }
+        if len(tags) > 0 {
+            for i := range tags {
+                tags[i].ID, tags[i].TodoID = 0, next.ID
+            }
+            if err := tx.Create(&tags).Error; err != nil {
+                return err
+            }
+        }
+        created = true
+        return nil
+    })
+    if err != nil {
+        return false, err
+    }
+    // 清除该用户的缓存
+    r.invalidateUserCache(prev.UserID)
+    return created, nil
 }
 
 // DeleteOne 删除单条（软删除，限定用户）
 func (r *TodoRepository) DeleteOne(userID, id uint) (int64, error) {
-    tx := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Todo{})
+    tx := softDeleteTodos(r.db.Model(&model.Todo{}).Where("id = ? AND user_id = ?", id, userID))
     if tx.Error != nil {
         return 0, tx.Error
     }
@@ -111,7 +611,7 @@ func (r *TodoRepository) DeleteByScope(userID uint, scope string) (int64, error)
         // 未知 scope 交给上层校验，这里默认不执行
         return 0, nil
     }
-    tx := q.Delete(&model.Todo{})
+    tx := softDeleteTodos(q)
     if tx.Error != nil {
         return 0, tx.Error
     }
@@ -120,8 +620,14 @@ func (r *TodoRepository) DeleteByScope(userID uint, scope string) (int64, error)
     return tx.RowsAffected, nil
 }
 
-// ListTodos 分页查询（按状态筛选，可选）
-func (r *TodoRepository) ListTodos(userID uint, statusFilter string, page, pageSize int) ([]model.Todo, int64, error) {
+// softDeleteTodos 软删除并同时更新 updated_at，按 updated_at 增量同步的下游（如分析导出）才能看到删除
+func softDeleteTodos(q *gorm.DB) *gorm.DB {
+    now := time.Now()
+    return q.Updates(map[string]interface{}{"deleted_at": now, "updated_at": now})
+}
+
+// ListTodos 分页查询（按状态、项目范围筛选，可选；sort 见 TodoSort* 常量）
+func (r *TodoRepository) ListTodos(userID uint, statusFilter string, projectIDs []uint, sort string, page, pageSize int) ([]model.Todo, int64, error) {
     var (
         todos []model.Todo
         total int64
@@ -129,7 +635,7 @@ func (r *TodoRepository) ListTodos(userID uint, statusFilter string, page, pageS
 
     // 尝试从缓存获取
     if redisClient.RDB != nil {
-        cacheKey := r.listCacheKey(userID, statusFilter, page, pageSize)
+        cacheKey := r.listCacheKey(userID, statusFilter, projectIDs, sort, page, pageSize)
         ctx := context.Background()
 
         cachedData, err := redisClient.RDB.Get(ctx, cacheKey).Result()
@@ -147,7 +653,7 @@ func (r *TodoRepository) ListTodos(userID uint, statusFilter string, page, pageS
     }
 
     // 缓存未命中，查询数据库
-    q := r.db.Model(&model.Todo{}).Where("user_id = ?", userID)
+    q := withProjectScope(r.db.Model(&model.Todo{}).Where("user_id = ?", userID), projectIDs)
     switch statusFilter {
     case "done":
         q = q.Where("status = ?", 1)
@@ -160,14 +666,14 @@ func (r *TodoRepository) ListTodos(userID uint, statusFilter string, page, pageS
     if page < 1 { page = 1 }
     if pageSize <= 0 { pageSize = 10 }
     offset := (page - 1) * pageSize
-    // 按创建时间升序：最早的备忘录显示在前面
-    if err := q.Order("created_at ASC, id ASC").Offset(offset).Limit(pageSize).Find(&todos).Error; err != nil {
+    // 默认按创建时间升序：最早的备忘录显示在前面
+    if err := q.Order(todoOrder(sort)).Offset(offset).Limit(pageSize).Find(&todos).Error; err != nil {
         return nil, 0, err
     }
 
     // 将结果写入缓存（5分钟过期）
     if redisClient.RDB != nil {
-        cacheKey := r.listCacheKey(userID, statusFilter, page, pageSize)
+        cacheKey := r.listCacheKey(userID, statusFilter, projectIDs, sort, page, pageSize)
         ctx := context.Background()
 
         type CachedResult struct {
@@ -184,7 +690,7 @@ func (r *TodoRepository) ListTodos(userID uint, statusFilter string, page, pageS
 }
 
 // SearchTodos 分页关键词查询（title/content 模糊匹配）
-func (r *TodoRepository) SearchTodos(userID uint, keyword string, page, pageSize int) ([]model.Todo, int64, error) {
+func (r *TodoRepository) SearchTodos(userID uint, keyword string, projectIDs []uint, sort string, page, pageSize int) ([]model.Todo, int64, error) {
     var (
         todos []model.Todo
         total int64
@@ -192,7 +698,7 @@ func (r *TodoRepository) SearchTodos(userID uint, keyword string, page, pageSize
 
     // 尝试从缓存获取
     if redisClient.RDB != nil {
-        cacheKey := r.searchCacheKey(userID, keyword, page, pageSize)
+        cacheKey := r.searchCacheKey(userID, keyword, projectIDs, sort, page, pageSize)
         ctx := context.Background()
 
         cachedData, err := redisClient.RDB.Get(ctx, cacheKey).Result()
@@ -210,23 +716,22 @@ func (r *TodoRepository) SearchTodos(userID uint, keyword string, page, pageSize
     }
 
     // 缓存未命中，查询数据库
-    q := r.db.Model(&model.Todo{}).
-        Where("user_id = ?", userID).
-        Where("title LIKE ? OR content LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+    q := withProjectScope(withKeyword(r.db.Model(&model.Todo{}).
+        Where("user_id = ?", userID), keyword), projectIDs)
     if err := q.Count(&total).Error; err != nil {
         return nil, 0, err
     }
     if page < 1 { page = 1 }
     if pageSize <= 0 { pageSize = 10 }
     offset := (page - 1) * pageSize
-    // 按创建时间升序：最早的备忘录显示在前面
-    if err := q.Order("created_at ASC, id ASC").Offset(offset).Limit(pageSize).Find(&todos).Error; err != nil {
+    // 默认按创建时间升序：最早的备忘录显示在前面
+    if err := q.Order(todoOrder(sort)).Offset(offset).Limit(pageSize).Find(&todos).Error; err != nil {
         return nil, 0, err
     }
 
     // 将结果写入缓存（5分钟过期）
     if redisClient.RDB != nil {
-        cacheKey := r.searchCacheKey(userID, keyword, page, pageSize)
+        cacheKey := r.searchCacheKey(userID, keyword, projectIDs, sort, page, pageSize)
         ctx := context.Background()
 
         type CachedResult struct {
@@ -243,13 +748,13 @@ func (r *TodoRepository) SearchTodos(userID uint, keyword string, page, pageSize
 }
 
 // ListTodosCursor 游标分页查询（用于高效遍历全部数据）
-// cursor: 上一页最后一条的 ID，首次查询传 0
+// cursor: 上一页最后一条的 ID，首次查询传 0；sort 见 TodoSort* 常量，翻页时须保持不变
 // 返回: todos列表, 下一页的cursor(0表示无下一页), hasMore(是否有更多数据), error
-func (r *TodoRepository) ListTodosCursor(userID uint, statusFilter string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+func (r *TodoRepository) ListTodosCursor(userID uint, statusFilter string, projectIDs []uint, sort string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
     var todos []model.Todo
 
     // 构建基础查询
-    q := r.db.Model(&model.Todo{}).Where("user_id = ?", userID)
+    q := withProjectScope(r.db.Model(&model.Todo{}).Where("user_id = ?", userID), projectIDs)
 
     // 状态过滤
     switch statusFilter {
@@ -259,13 +764,14 @@ func (r *TodoRepository) ListTodosCursor(userID uint, statusFilter string, curso
         q = q.Where("status = ?", 0)
     }
 
-    // 游标过滤：因为是升序（旧→新），需要找比 cursor 更大的 ID
-    if cursor > 0 {
-        q = q.Where("id > ?", cursor)
+    // 游标过滤
+    q, err := r.withCursor(q, userID, sort, cursor)
+    if err != nil {
+        return nil, 0, false, err
     }
 
     // 查询 limit+1 条，用于判断是否还有下一页
-    if err := q.Order("created_at ASC, id ASC").Limit(limit + 1).Find(&todos).Error; err != nil {
+    if err := q.Order(todoOrder(sort)).Limit(limit + 1).Find(&todos).Error; err != nil {
         return nil, 0, false, err
     }
 
@@ -286,21 +792,21 @@ func (r *TodoRepository) ListTodosCursor(userID uint, statusFilter string, curso
 }
 
 // SearchTodosCursor 关键词游标分页查询
-func (r *TodoRepository) SearchTodosCursor(userID uint, keyword string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+func (r *TodoRepository) SearchTodosCursor(userID uint, keyword string, projectIDs []uint, sort string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
     var todos []model.Todo
 
     // 构建查询
-    q := r.db.Model(&model.Todo{}).
-        Where("user_id = ?", userID).
-        Where("title LIKE ? OR content LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+    q := withProjectScope(withKeyword(r.db.Model(&model.Todo{}).
+        Where("user_id = ?", userID), keyword), projectIDs)
 
     // 游标过滤
-    if cursor > 0 {
-        q = q.Where("id > ?", cursor)
+    q, err := r.withCursor(q, userID, sort, cursor)
+    if err != nil {
+        return nil, 0, false, err
     }
 
     // 查询 limit+1 条
-    if err := q.Order("created_at ASC, id ASC").Limit(limit + 1).Find(&todos).Error; err != nil {
+    if err := q.Order(todoOrder(sort)).Limit(limit + 1).Find(&todos).Error; err != nil {
         return nil, 0, false, err
     }
 
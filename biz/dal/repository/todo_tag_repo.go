@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+// TagCount 标签及使用它的待办数量
+type TagCount struct {
+	Name  string `json:"name"`
+	Open  int64  `json:"open"`
+	Total int64  `json:"total"`
+}
+
+// TodoTagRepository 待办标签数据访问层
+type TodoTagRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoTagRepository 创建标签仓库实例
+func NewTodoTagRepository(db *gorm.DB) *TodoTagRepository {
+	return &TodoTagRepository{db: db}
+}
+
+// Replace 用 names 替换待办的全部标签
+func (r *TodoTagRepository) Replace(userID, todoID uint, names []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND todo_id = ?", userID, todoID).Delete(&model.TodoTag{}).Error; err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		tags := make([]model.TodoTag, 0, len(names))
+		for _, name := range names {
+			tags = append(tags, model.TodoTag{UserID: userID, TodoID: todoID, Name: name})
+		}
+		return tx.Create(&tags).Error
+	})
+}
+
+// ListByTodos 列出指定待办的标签，按名称排序（限定用户）
+func (r *TodoTagRepository) ListByTodos(userID uint, todoIDs []uint) ([]model.TodoTag, error) {
+	var tags []model.TodoTag
+	if len(todoIDs) == 0 {
+		return tags, nil
+	}
+	if err := r.db.Where("user_id = ? AND todo_id IN ?", userID, todoIDs).
+		Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Counts 统计用户各标签下（未删除的）待办数量，按名称排序
+func (r *TodoTagRepository) Counts(userID uint) ([]TagCount, error) {
+	var counts []TagCount
+	err := r.db.Model(&model.TodoTag{}).
+		Select("todo_tags.name AS name, SUM(CASE WHEN todos.status = 0 THEN 1 ELSE 0 END) AS open, COUNT(*) AS total").
+		Joins("JOIN todos ON todos.id = todo_tags.todo_id AND todos.deleted_at IS NULL").
+		Where("todo_tags.user_id = ?", userID).
+		Group("todo_tags.name").
+		Order("todo_tags.name ASC").
+		Scan(&counts).Error
+	return counts, err
+}
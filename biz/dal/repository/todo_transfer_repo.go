@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTodoTransferNotFound 转移请求不存在
+	ErrTodoTransferNotFound = errors.New("todo transfer not found")
+	// ErrTodoTransferNotPending 转移请求已处理
+	ErrTodoTransferNotPending = errors.New("todo transfer is no longer pending")
+)
+
+// TodoTransferRepository 待办所有权转移数据访问层
+type TodoTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoTransferRepository 创建转移仓库实例
+func NewTodoTransferRepository(db *gorm.DB) *TodoTransferRepository {
+	return &TodoTransferRepository{db: db}
+}
+
+// Create 新建转移请求
+func (r *TodoTransferRepository) Create(t *model.TodoTransfer) error {
+	return r.db.Create(t).Error
+}
+
+// GetByID 按 ID 获取转移请求（参与方校验由上层负责）
+func (r *TodoTransferRepository) GetByID(id uint) (*model.TodoTransfer, error) {
+	var t model.TodoTransfer
+	if err := r.db.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoTransferNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TodoTransferFilter 转移请求查询条件
+type TodoTransferFilter struct {
+	Incoming bool   // 作为接收方
+	Outgoing bool   // 作为原所有者
+	Status   string // 为空表示不限
+}
+
+// List 列出与用户相关的转移请求（按 id 倒序）
+func (r *TodoTransferRepository) List(userID uint, f TodoTransferFilter) ([]model.TodoTransfer, error) {
+	q := r.db.Model(&model.TodoTransfer{})
+	switch {
+	case f.Incoming && !f.Outgoing:
+		q = q.Where("to_user_id = ?", userID)
+	case f.Outgoing && !f.Incoming:
+		q = q.Where("from_user_id = ?", userID)
+	default:
+		q = q.Where("to_user_id = ? OR from_user_id = ?", userID, userID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	var transfers []model.TodoTransfer
+	if err := q.Order("id DESC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// Respond 把待处理的请求改为 declined / cancelled；已被处理时返回 ErrTodoTransferNotPending
+func (r *TodoTransferRepository) Respond(id uint, status string, at time.Time) error {
+	res := r.db.Model(&model.TodoTransfer{}).
+		Where("id = ? AND status = ?", id, model.TransferPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTodoTransferNotPending
+	}
+	return nil
+}
+
+// TransferItems 接受时要转移的数据：待办与项目（项目须为原所有者的完整子树）
+type TransferItems struct {
+	TodoIDs    []uint
+	ProjectIDs []uint
+	RootID     uint // 子树根节点，转移后成为顶层节点
+}
+
+// Accept 在同一事务中把请求标记为 accepted 并把数据转移给接收方：
+// 待办（仅限仍属于原所有者的）及其状态历史、委托与标签、项目子树及其迭代；单独转移的待办移出原项目、迭代与目标
+func (r *TodoTransferRepository) Accept(t *model.TodoTransfer, items TransferItems, at time.Time) error {
+	from, to := t.FromUserID, t.ToUserID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.TodoTransfer{}).
+			Where("id = ? AND status = ?", t.ID, model.TransferPending).
+			Updates(map[string]interface{}{"status": model.TransferAccepted, "responded_at": at})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTodoTransferNotPending
+		}
+
+		var todoIDs []uint
+		if len(items.TodoIDs) > 0 {
+			if err := tx.Model(&model.Todo{}).Where("user_id = ? AND id IN ?", from, items.TodoIDs).
+				Pluck("id", &todoIDs).Error; err != nil {
+				return err
+			}
+		}
+		if len(todoIDs) > 0 {
+			updates := map[string]interface{}{"user_id": to}
+			if len(items.ProjectIDs) == 0 {
+				updates["project_id"] = nil
+			}
+			if err := tx.Model(&model.Todo{}).Where("id IN ?", todoIDs).Updates(updates).Error; err != nil {
+				return err
+			}
+			// 编号只在随项目一起转移时保留，其余由原所有者的项目分配的编号清除
+			keys := tx.Where("id IN ?", todoIDs)
+			if len(items.ProjectIDs) > 0 {
+				keys = keys.Where("key_project_id NOT IN ?", items.ProjectIDs)
+			}
+			if err := clearTodoKeys(keys); err != nil {
+				return err
+			}
+			for _, m := range []interface{}{&model.TodoStatusChange{}, &model.TodoDueChange{}, &model.Delegation{}, &model.TodoTag{}} {
+				if err := tx.Model(m).Where("todo_id IN ?", todoIDs).Update("user_id", to).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("user_id = ? AND target_type = ? AND target_id IN ?", from, model.GoalLinkTodo, todoIDs).
+				Delete(&model.GoalLink{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(items.ProjectIDs) > 0 {
+			res := tx.Model(&model.Project{}).Where("user_id = ? AND id IN ?", from, items.ProjectIDs).Update("user_id", to)
+			if res.Error != nil {
+				return res.Error
+			}
+			t.MovedProjects = res.RowsAffected
+			if err := tx.Model(&model.Project{}).Where("id = ?", items.RootID).Update("parent_id", nil).Error; err != nil {
+				return err
+			}
+			if err := releaseTakenKeys(tx, to, items.ProjectIDs); err != nil {
+				return err
+			}
+			// 原所有者的接入配置不能再把待办导入到已转出的项目
+			for _, m := range []interface{}{&model.IssueConnector{}, &model.IncomingWebhook{}} {
+				if err := tx.Model(m).Where("user_id = ? AND project_id IN ?", from, items.ProjectIDs).
+					Update("project_id", nil).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("user_id = ? AND target_type = ? AND target_id IN ?", from, model.GoalLinkProject, items.ProjectIDs).
+				Delete(&model.GoalLink{}).Error; err != nil {
+				return err
+			}
+			sprints := tx.Model(&model.Sprint{}).Select("id").Where("user_id = ? AND project_id IN ?", from, items.ProjectIDs)
+			if err := tx.Model(&model.SprintItem{}).Where("sprint_id IN (?)", sprints).Update("user_id", to).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Sprint{}).Where("user_id = ? AND project_id IN ?", from, items.ProjectIDs).
+				Update("user_id", to).Error; err != nil {
+				return err
+			}
+		} else if len(todoIDs) > 0 {
+			if err := tx.Model(&model.SprintItem{}).
+				Where("user_id = ? AND todo_id IN ? AND removed_at IS NULL", from, todoIDs).
+				Update("removed_at", at).Error; err != nil {
+				return err
+			}
+		}
+
+		t.MovedTodos = int64(len(todoIDs))
+		return tx.Model(&model.TodoTransfer{}).Where("id = ?", t.ID).Updates(map[string]interface{}{
+			"moved_todos":    t.MovedTodos,
+			"moved_projects": t.MovedProjects,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	t.Status, t.RespondedAt = model.TransferAccepted, &at
+
+	// 清除双方的待办缓存
+	todoRepo := NewTodoRepository(r.db)
+	todoRepo.invalidateUserCache(from)
+	todoRepo.invalidateUserCache(to)
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+
+	"memogo/biz/dal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSettingRepository 用户设置数据访问层
+type UserSettingRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSettingRepository 创建用户设置仓库实例
+func NewUserSettingRepository(db *gorm.DB) *UserSettingRepository {
+	return &UserSettingRepository{db: db}
+}
+
+// Get 获取用户设置，不存在时返回默认值（不落库）
+func (r *UserSettingRepository) Get(userID uint) (*model.UserSetting, error) {
+	var setting model.UserSetting
+	if err := r.db.Where("user_id = ?", userID).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.UserSetting{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// Save 写入用户设置（按 user_id 插入或更新 updates 中的列）
+func (r *UserSettingRepository) Save(setting *model.UserSetting, columns ...string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(setting).Error
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/billing"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newAccountMergeService() *service.AccountMergeService {
+	return service.NewAccountMergeService(
+		repository.NewUserRepository(db.DB),
+		repository.NewAccountMergeRepository(db.DB),
+		repository.NewAuditLogRepository(db.DB),
+		billing.Default(),
+	)
+}
+
+// MergeAccount 把另一个账号（提供其用户名和密码）合并到当前登录账号
+// @router /v1/account/merge [POST]
+func MergeAccount(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	result, err := newAccountMergeService().MergeSelf(userID, req.Username, req.Password)
+	writeAccountMergeResult(c, result, err)
+}
+
+// AdminMergeAccounts 管理员把 source 账号合并到 target 账号
+// @router /v1/admin/users/merge [POST]
+func AdminMergeAccounts(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		SourceUserID int64 `json:"source_user_id"`
+		TargetUserID int64 `json:"target_user_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	result, err := newAccountMergeService().MergeByAdmin(adminID, uint(req.SourceUserID), uint(req.TargetUserID))
+	writeAccountMergeResult(c, result, err)
+}
+
+func writeAccountMergeResult(c *app.RequestContext, result *service.AccountMergeResult, err error) {
+	if err != nil && result == nil {
+		switch {
+		case errors.Is(err, service.ErrMergeSameAccount),
+			errors.Is(err, service.ErrUsernameRequired),
+			errors.Is(err, service.ErrPasswordRequired):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, service.ErrInvalidCredentials):
+			c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Merge failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	msg := "ok"
+	if err != nil {
+		// 合并已完成，但取消舍弃的订阅或写入审计日志失败
+		msg = "merged, follow-up failed: " + err.Error()
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": msg, "data": result})
+}
+
+// ListAuditLogs 查询审计日志（管理员，游标分页）
+// @router /v1/admin/audit-logs [GET]
+func ListAuditLogs(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Action   string `query:"action"`
+		ActorID  int64  `query:"actor_id"`
+		TargetID int64  `query:"target_id"`
+		Cursor   int64  `query:"cursor"`
+		Limit    int    `query:"limit"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	svc := service.NewAuditService(repository.NewAuditLogRepository(db.DB))
+	logs, err := svc.List(repository.AuditLogFilter{
+		Action:   req.Action,
+		ActorID:  uint(req.ActorID),
+		TargetID: uint(req.TargetID),
+		Cursor:   uint(req.Cursor),
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	var next uint
+	if len(logs) > 0 {
+		next = logs[len(logs)-1].ID
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{"logs": logs, "next_cursor": next}})
+}
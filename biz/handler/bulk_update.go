@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+	"memogo/pkg/todoquery"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newBulkUpdateService() *service.BulkUpdateService {
+	return service.NewBulkUpdateService(
+		repository.NewBulkUpdateRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+	)
+}
+
+// PreviewBulkUpdate 试运行批量操作：返回筛选表达式命中的待办数量、样例与确认令牌，不修改数据
+// @router /v1/todos/bulk/preview [POST]
+func PreviewBulkUpdate(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Query  string           `json:"query"`
+		Action model.BulkAction `json:"action"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	preview, err := newBulkUpdateService().Preview(userID, req.Query, req.Action, time.Now())
+	if err != nil {
+		writeBulkUpdateError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": preview})
+}
+
+// ExecuteBulkUpdate 凭预览返回的确认令牌执行批量操作（令牌只能使用一次）
+// @router /v1/todos/bulk/execute [POST]
+func ExecuteBulkUpdate(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	op, err := newBulkUpdateService().Execute(userID, req.Token, time.Now())
+	if err != nil {
+		writeBulkUpdateError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": op})
+}
+
+// ListBulkUpdates 最近的批量操作（预览与执行结果）
+// @router /v1/todos/bulk [GET]
+func ListBulkUpdates(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	ops, err := newBulkUpdateService().History(userID)
+	if err != nil {
+		writeBulkUpdateError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": ops})
+}
+
+func writeBulkUpdateError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, todoquery.ErrInvalidQuery),
+		errors.Is(err, service.ErrInvalidBulkAction),
+		errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrBulkTooManyMatches),
+		errors.Is(err, service.ErrBulkPreviewStale):
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+	case errors.Is(err, repository.ErrBulkUpdateNotFound),
+		errors.Is(err, repository.ErrProjectNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Bulk update failed: " + err.Error(), "data": nil})
+	}
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/lunar"
+	"memogo/pkg/middleware"
+	"memogo/pkg/workday"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newCalendarService() *service.CalendarService {
+	return service.NewCalendarService(repository.NewUserSettingRepository(db.DB))
+}
+
+// ListWorkdays 查询日期范围内的工作日 / 节假日 / 调休（from、to 为 YYYY-MM-DD，默认本月）
+// @router /v1/calendar/workdays [GET]
+func ListWorkdays(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		From string `query:"from"`
+		To   string `query:"to"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now().In(workday.Location)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, workday.Location)
+	to := from.AddDate(0, 1, -1)
+	var err error
+	if req.From != "" {
+		if from, err = workday.ParseDate(req.From); err != nil {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": "invalid from: " + err.Error(), "data": nil})
+			return
+		}
+	}
+	if req.To != "" {
+		if to, err = workday.ParseDate(req.To); err != nil {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": "invalid to: " + err.Error(), "data": nil})
+			return
+		}
+	}
+
+	days, err := newCalendarService().Days(from, to)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{
+		"days":  days,
+		"years": workday.Default().Years(),
+	}})
+}
+
+// NextWorkday 查询某日之后的第 n 个工作日（date 默认今天，n 默认 1）
+// @router /v1/calendar/next-workday [GET]
+func NextWorkday(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Date string `query:"date"`
+		N    int    `query:"n"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	date := time.Now()
+	if req.Date != "" {
+		var err error
+		if date, err = workday.ParseDate(req.Date); err != nil {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": "invalid date: " + err.Error(), "data": nil})
+			return
+		}
+	}
+	if req.N < 1 || req.N > 366 {
+		req.N = 1
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": newCalendarService().NextWorkday(date, req.N)})
+}
+
+// GetCalendarSettings 获取节假日跳过设置
+// @router /v1/calendar/settings [GET]
+func GetCalendarSettings(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	setting, err := newCalendarService().GetSettings(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Get settings failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": setting})
+}
+
+// UpdateCalendarSettings 更新节假日跳过设置（提醒 / 摘要）与时区
+// @router /v1/calendar/settings [PATCH]
+func UpdateCalendarSettings(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		SkipHolidayReminders *bool   `json:"skip_holiday_reminders"`
+		SkipHolidayDigests   *bool   `json:"skip_holiday_digests"`
+		Timezone             *string `json:"timezone"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	setting, err := newCalendarService().UpdateSettings(userID, service.CalendarSettingsUpdate{
+		SkipHolidayReminders: req.SkipHolidayReminders,
+		SkipHolidayDigests:   req.SkipHolidayDigests,
+		Timezone:             req.Timezone,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Update settings failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": setting})
+}
+
+// SetTodoRecurrence 设置待办的重复规则与截止日期调整方式（均传空串表示取消）
+// 农历规则：recurrence 为 lunar_yearly，lunar 为农历月日（如 "八月十五"）
+// @router /v1/todos/:id/recurrence [PUT]
+func SetTodoRecurrence(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID         int64  `path:"id"`
+		Recurrence string `json:"recurrence"`
+		DueShift   string `json:"due_shift"`
+		Lunar      string `json:"lunar"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todoSvc := service.NewTodoService(repository.NewTodoRepository(db.DB))
+	todo, err := todoSvc.SetRecurrence(userID, uint(req.ID), service.RecurrenceInput{
+		Rule:     req.Recurrence,
+		DueShift: req.DueShift,
+		Lunar:    req.Lunar,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRecurrence),
+			errors.Is(err, service.ErrInvalidDueShift),
+			errors.Is(err, service.ErrRecurrenceNeedsDueTime),
+			errors.Is(err, service.ErrLunarDateRequired),
+			errors.Is(err, lunar.ErrInvalidDate):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrTodoNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Set recurrence failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": toRecurrenceView(todo)})
+}
+
+// GetTodoRecurrence 查看待办的重复规则与下一次截止时间
+// @router /v1/todos/:id/recurrence [GET]
+func GetTodoRecurrence(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todoSvc := service.NewTodoService(repository.NewTodoRepository(db.DB))
+	todo, err := todoSvc.GetTodo(userID, uint(req.ID))
+	if err != nil {
+		if errors.Is(err, repository.ErrTodoNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Get failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": toRecurrenceView(todo)})
+}
+
+// toRecurrenceView 待办 + 重复规则 + 截止日的日历与农历信息 + 下一次截止时间
+func toRecurrenceView(t *model.Todo) utils.H {
+	cal := workday.Default()
+	view := utils.H{
+		"todo":       toAPITodo(t),
+		"recurrence": t.Recurrence,
+		"due_shift":  t.DueShift,
+	}
+	if rule := service.LunarRuleString(t); rule != "" {
+		view["lunar_rule"] = rule
+	}
+	if t.DueTime != nil {
+		view["due_day"] = cal.Day(*t.DueTime)
+	}
+	if _, next, ok := service.NextOccurrence(cal, t, time.Now()); ok {
+		view["next_due_time"] = next.Unix()
+	}
+	return view
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+	"memogo/pkg/slack"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const chatProviderSlack = "slack"
+
+func newChatService() *service.ChatService {
+	return service.NewChatService(
+		repository.NewChatAccountRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// SlackCommand 处理 Slack 斜杠命令（/todo add|list|done|link）
+// 请求签名由 SlackSignatureMiddleware 校验；Slack 要求直接返回消息体而不是统一响应格式
+func SlackCommand(ctx context.Context, c *app.RequestContext) {
+	teamID := string(c.PostForm("team_id"))
+	chatUserID := string(c.PostForm("user_id"))
+	if teamID == "" || chatUserID == "" {
+		c.String(consts.StatusBadRequest, "team_id and user_id are required")
+		return
+	}
+
+	reply, err := newChatService().HandleCommand(chatProviderSlack, teamID, chatUserID, string(c.PostForm("text")))
+	if err != nil {
+		c.JSON(consts.StatusOK, &slack.Message{
+			ResponseType: slack.ResponseEphemeral,
+			Text:         "Command failed: " + err.Error(),
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, renderSlackReply(reply))
+}
+
+// SlackInteraction 处理 Slack 交互回调（消息按钮点击）
+func SlackInteraction(ctx context.Context, c *app.RequestContext) {
+	payload, err := slack.ParseInteractionPayload(string(c.PostForm("payload")))
+	if err != nil {
+		c.String(consts.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	chatSvc := newChatService()
+	userID, err := chatSvc.ResolveUser(chatProviderSlack, payload.Team.ID, payload.User.ID)
+	if err != nil {
+		text := "Interaction failed: " + err.Error()
+		if errors.Is(err, service.ErrChatNotLinked) {
+			text = "Your chat account is not linked to memogo yet. Run `/todo link` to get a link code."
+		}
+		c.JSON(consts.StatusOK, &slack.Message{ResponseType: slack.ResponseEphemeral, Text: text})
+		return
+	}
+
+	for _, action := range payload.Actions {
+		if action.ActionID != slack.ActionCompleteTodo {
+			continue
+		}
+		id, err := strconv.ParseUint(action.Value, 10, 64)
+		if err != nil {
+			c.String(consts.StatusBadRequest, "invalid todo id")
+			return
+		}
+		reply, err := chatSvc.CompleteTodo(userID, uint(id))
+		if err != nil {
+			c.JSON(consts.StatusOK, &slack.Message{
+				ResponseType: slack.ResponseEphemeral,
+				Text:         "Interaction failed: " + err.Error(),
+			})
+			return
+		}
+		msg := renderSlackReply(reply)
+		msg.ReplaceOriginal = true
+		c.JSON(consts.StatusOK, msg)
+		return
+	}
+
+	// 未识别的交互：返回 200 让 Slack 停止重试
+	c.Status(consts.StatusOK)
+}
+
+// LinkChatAccount 已登录用户提交 `/todo link` 得到的绑定码
+// @router /v1/integrations/chat/link [POST]
+func LinkChatAccount(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	account, err := newChatService().LinkAccount(chatProviderSlack, userID, req.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLinkCode) {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Link failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": account})
+}
+
+// ListChatAccounts 列出当前用户绑定的聊天账号
+// @router /v1/integrations/chat/accounts [GET]
+func ListChatAccounts(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	accounts, err := newChatService().ListAccounts(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": accounts})
+}
+
+// UnlinkChatAccount 解除聊天账号绑定
+// @router /v1/integrations/chat/accounts/:id [DELETE]
+func UnlinkChatAccount(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	affected, err := newChatService().UnlinkAccount(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Unlink failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// renderSlackReply 把 ChatReply 渲染为 Slack Block Kit 消息
+func renderSlackReply(reply *service.ChatReply) *slack.Message {
+	msg := &slack.Message{ResponseType: slack.ResponseEphemeral, Text: reply.Text}
+	if len(reply.Todos) == 0 {
+		return msg
+	}
+
+	msg.Blocks = append(msg.Blocks, slack.SectionBlock(reply.Text))
+	for _, t := range reply.Todos {
+		line := fmt.Sprintf("*#%d* %s", t.ID, t.Title)
+		if t.DueTime != nil {
+			line += fmt.Sprintf("  _due %s_", t.DueTime.Format("2006-01-02"))
+		}
+		block := slack.SectionBlock(line)
+		if t.Status == 0 {
+			block.Accessory = slack.CompleteButton(t.ID)
+		} else {
+			block.Text.Text = "~" + line + "~"
+		}
+		msg.Blocks = append(msg.Blocks, block)
+	}
+	return msg
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"context"
+
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// GetConcurrencyStats 各并发预算（read / write / auth）的当前上限、并发数与累计接受 / 拒绝数（管理员）；
+// 未启用并发限制时 data 为 null
+// @router /v1/admin/concurrency [GET]
+func GetConcurrencyStats(ctx context.Context, c *app.RequestContext) {
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": middleware.ConcurrencyStats()})
+}
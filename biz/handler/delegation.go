@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newDelegationService() *service.DelegationService {
+	return service.NewDelegationService(
+		repository.NewDelegationRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewUserSettingRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// GetTodoWaiting 获取待办的等待信息
+// @router /v1/todos/:id/waiting [GET]
+func GetTodoWaiting(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	view, err := newDelegationService().Get(userID, uint(req.ID))
+	writeDelegationResult(c, view, err)
+}
+
+// SetTodoWaiting 把待办设为等待他人（被委托人、联系方式、预计日期）
+// @router /v1/todos/:id/waiting [PUT]
+func SetTodoWaiting(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID           int64  `path:"id"`
+		Delegate     string `json:"delegate"`
+		Contact      string `json:"contact"`
+		ExpectedDate string `json:"expected_date"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	view, err := newDelegationService().Set(userID, uint(req.ID), service.DelegationInput{
+		Delegate:     req.Delegate,
+		Contact:      req.Contact,
+		ExpectedDate: req.ExpectedDate,
+	})
+	writeDelegationResult(c, view, err)
+}
+
+// ClearTodoWaiting 取消等待状态
+// @router /v1/todos/:id/waiting [DELETE]
+func ClearTodoWaiting(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	affected, err := newDelegationService().Clear(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// ListWaiting 列出等待他人的未完成待办（按预计日期升序，overdue=true 只返回已逾期的）
+// @router /v1/waiting [GET]
+func ListWaiting(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Overdue bool `query:"overdue"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	list, err := newDelegationService().List(userID, req.Overdue)
+	writeDelegationResult(c, list, err)
+}
+
+func writeDelegationResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDelegateRequired),
+			errors.Is(err, service.ErrInvalidExpectedDate),
+			errors.Is(err, service.ErrDelegationTodoDone):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrDelegationNotFound),
+			errors.Is(err, repository.ErrTodoNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Waiting request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
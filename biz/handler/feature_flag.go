@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newFeatureFlagService() *service.FeatureFlagService {
+	return service.NewFeatureFlagService(repository.NewFeatureFlagRepository(db.DB), repository.NewAuditLogRepository(db.DB))
+}
+
+// featureFlagBody 创建/更新开关的请求体，省略的字段保持不变
+type featureFlagBody struct {
+	Description  *string   `json:"description"`
+	Enabled      *bool     `json:"enabled"`
+	Percentage   *int      `json:"percentage"`
+	UserIDs      *[]uint   `json:"user_ids"`
+	Roles        *[]string `json:"roles"`
+	WorkspaceIDs *[]uint   `json:"workspace_ids"`
+}
+
+func (b featureFlagBody) input() service.FeatureFlagInput {
+	return service.FeatureFlagInput{
+		Description:  b.Description,
+		Enabled:      b.Enabled,
+		Percentage:   b.Percentage,
+		UserIDs:      b.UserIDs,
+		Roles:        b.Roles,
+		WorkspaceIDs: b.WorkspaceIDs,
+	}
+}
+
+// ListMyFeatureFlags 返回全部开关对当前用户的评估结果
+// @router /v1/feature-flags [GET]
+func ListMyFeatureFlags(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	user, err := repository.NewUserRepository(db.DB).GetByID(userID)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	flags, err := newFeatureFlagService().EvaluateAll(service.FlagContextForUser(user))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Evaluate failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": flags})
+}
+
+// CreateFeatureFlag 创建功能开关（管理员）
+// @router /v1/admin/feature-flags [POST]
+func CreateFeatureFlag(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Key string `json:"key"`
+		featureFlagBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	flag, err := newFeatureFlagService().CreateFlag(adminID, req.Key, req.input())
+	if err != nil && flag == nil {
+		writeFeatureFlagError(c, err)
+		return
+	}
+	writeFeatureFlagResult(c, flag, err)
+}
+
+// ListFeatureFlags 列出全部功能开关（管理员）
+// @router /v1/admin/feature-flags [GET]
+func ListFeatureFlags(ctx context.Context, c *app.RequestContext) {
+	flags, err := newFeatureFlagService().ListFlags()
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": flags})
+}
+
+// UpdateFeatureFlag 部分更新功能开关（管理员）
+// @router /v1/admin/feature-flags/:key [PATCH]
+func UpdateFeatureFlag(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Key string `path:"key"`
+		featureFlagBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	flag, err := newFeatureFlagService().UpdateFlag(adminID, req.Key, req.input())
+	if err != nil && flag == nil {
+		writeFeatureFlagError(c, err)
+		return
+	}
+	writeFeatureFlagResult(c, flag, err)
+}
+
+// DeleteFeatureFlag 删除功能开关（管理员）
+// @router /v1/admin/feature-flags/:key [DELETE]
+func DeleteFeatureFlag(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Key string `path:"key"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	err = newFeatureFlagService().DeleteFlag(adminID, req.Key)
+	if err != nil && errors.Is(err, repository.ErrFeatureFlagNotFound) {
+		writeFeatureFlagError(c, err)
+		return
+	}
+	msg := "ok"
+	if err != nil {
+		msg = "deleted, audit log failed: " + err.Error()
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": msg, "data": nil})
+}
+
+// EvaluateFeatureFlag 查看开关对指定用户的评估结果及命中原因（管理员排查用）
+// @router /v1/admin/feature-flags/:key/evaluate [GET]
+func EvaluateFeatureFlag(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Key         string `path:"key"`
+		UserID      int64  `query:"user_id"`
+		WorkspaceID int64  `query:"workspace_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	user, err := repository.NewUserRepository(db.DB).GetByID(uint(req.UserID))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Evaluate failed: " + err.Error(), "data": nil})
+		return
+	}
+
+	fc := service.FlagContextForUser(user)
+	fc.WorkspaceID = uint(req.WorkspaceID)
+	eval, err := newFeatureFlagService().Evaluate(req.Key, fc)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Evaluate failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": eval})
+}
+
+func writeFeatureFlagError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFlagKey),
+		errors.Is(err, service.ErrInvalidRolloutPercentage),
+		errors.Is(err, service.ErrInvalidFlagRole),
+		errors.Is(err, repository.ErrFeatureFlagExists):
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+	case errors.Is(err, repository.ErrFeatureFlagNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Save failed: " + err.Error(), "data": nil})
+	}
+}
+
+func writeFeatureFlagResult(c *app.RequestContext, flag interface{}, err error) {
+	msg := "ok"
+	if err != nil {
+		// 开关已保存，但审计日志写入失败
+		msg = "saved, audit log failed: " + err.Error()
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": msg, "data": flag})
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newGoalService() *service.GoalService {
+	return service.NewGoalService(
+		repository.NewGoalRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+	)
+}
+
+// goalBody 创建/更新目标的请求体，省略的字段保持不变
+type goalBody struct {
+	Title            *string `json:"title"`
+	Description      *string `json:"description"`
+	StartDate        *string `json:"start_date"`
+	TargetDate       *string `json:"target_date"`
+	WeightByEstimate *bool   `json:"weight_by_estimate"`
+}
+
+func (b goalBody) input() service.GoalInput {
+	return service.GoalInput{
+		Title:            b.Title,
+		Description:      b.Description,
+		StartDate:        b.StartDate,
+		TargetDate:       b.TargetDate,
+		WeightByEstimate: b.WeightByEstimate,
+	}
+}
+
+// CreateGoal 创建目标
+// @router /v1/goals [POST]
+func CreateGoal(ctx context.Context, c *app.RequestContext) {
+	var req goalBody
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	goal, err := newGoalService().Create(userID, req.input())
+	writeGoalResult(c, goal, err)
+}
+
+// ListGoals 列出目标及进度（at_risk=true 只返回有风险的目标）
+// @router /v1/goals [GET]
+func ListGoals(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		AtRisk bool `query:"at_risk"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	svc := newGoalService()
+	var goals []service.GoalView
+	if req.AtRisk {
+		goals, err = svc.ListAtRisk(userID)
+	} else {
+		goals, err = svc.List(userID)
+	}
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": goals})
+}
+
+// GetGoal 获取目标详情（含计入进度的待办）
+// @router /v1/goals/:id [GET]
+func GetGoal(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	goal, err := newGoalService().Get(userID, uint(req.ID))
+	writeGoalResult(c, goal, err)
+}
+
+// UpdateGoal 部分更新目标
+// @router /v1/goals/:id [PATCH]
+func UpdateGoal(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+		goalBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	goal, err := newGoalService().Update(userID, uint(req.ID), req.input())
+	writeGoalResult(c, goal, err)
+}
+
+// DeleteGoal 删除目标（关联的待办与项目不受影响）
+// @router /v1/goals/:id [DELETE]
+func DeleteGoal(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	affected, err := newGoalService().Delete(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// LinkGoalTodo 把待办关联到目标
+// @router /v1/goals/:id/todos/:target_id [PUT]
+func LinkGoalTodo(ctx context.Context, c *app.RequestContext) {
+	changeGoalLink(c, model.GoalLinkTodo, true)
+}
+
+// UnlinkGoalTodo 取消待办与目标的关联
+// @router /v1/goals/:id/todos/:target_id [DELETE]
+func UnlinkGoalTodo(ctx context.Context, c *app.RequestContext) {
+	changeGoalLink(c, model.GoalLinkTodo, false)
+}
+
+// LinkGoalProject 把项目（含全部下级节点）关联到目标
+// @router /v1/goals/:id/projects/:target_id [PUT]
+func LinkGoalProject(ctx context.Context, c *app.RequestContext) {
+	changeGoalLink(c, model.GoalLinkProject, true)
+}
+
+// UnlinkGoalProject 取消项目与目标的关联
+// @router /v1/goals/:id/projects/:target_id [DELETE]
+func UnlinkGoalProject(ctx context.Context, c *app.RequestContext) {
+	changeGoalLink(c, model.GoalLinkProject, false)
+}
+
+func changeGoalLink(c *app.RequestContext, targetType string, link bool) {
+	var req struct {
+		ID       int64 `path:"id"`
+		TargetID int64 `path:"target_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	svc := newGoalService()
+	var goal *service.GoalDetail
+	if link {
+		goal, err = svc.Link(userID, uint(req.ID), targetType, uint(req.TargetID))
+	} else {
+		goal, err = svc.Unlink(userID, uint(req.ID), targetType, uint(req.TargetID))
+	}
+	writeGoalResult(c, goal, err)
+}
+
+func writeGoalResult(c *app.RequestContext, goal *service.GoalDetail, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoalTitleRequired),
+			errors.Is(err, service.ErrInvalidGoalDate),
+			errors.Is(err, service.ErrGoalDateRange),
+			errors.Is(err, service.ErrInvalidGoalLink):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrGoalNotFound),
+			errors.Is(err, repository.ErrTodoNotFound),
+			errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Goal request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": goal})
+}
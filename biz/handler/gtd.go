@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newGtdService() *service.GtdService {
+	return service.NewGtdService(
+		repository.NewTodoRepository(db.DB),
+		repository.NewTodoTagRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewNoteRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// CaptureInbox 快速记录到收集箱
+// @router /v1/gtd/inbox [POST]
+func CaptureInbox(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todo, err := newGtdService().Capture(userID, req.Title, req.Content)
+	writeGtdResult(c, todo, err)
+}
+
+// ListInbox 收集箱中未处理的条目
+// @router /v1/gtd/inbox [GET]
+func ListInbox(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todos, err := newGtdService().Inbox(userID)
+	writeGtdResult(c, todos, err)
+}
+
+// ProcessInboxItem 处理一条待办：转为下一步行动、将来 / 也许、项目、参考资料或删除
+// @router /v1/gtd/inbox/:id/process [POST]
+func ProcessInboxItem(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID          int64    `path:"id"`
+		To          string   `json:"to"`
+		ProjectID   uint     `json:"project_id"`
+		ProjectName string   `json:"project_name"`
+		ParentID    uint     `json:"parent_id"`
+		Contexts    []string `json:"contexts"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	result, err := newGtdService().Clarify(userID, uint(req.ID), service.ClarifyInput{
+		To:          req.To,
+		ProjectID:   req.ProjectID,
+		ProjectName: req.ProjectName,
+		ParentID:    req.ParentID,
+		Contexts:    req.Contexts,
+	})
+	writeGtdResult(c, result, err)
+}
+
+// ListNextActions 按情境分组的下一步行动（context=@home 只返回该情境）
+// @router /v1/gtd/next-actions [GET]
+func ListNextActions(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Context string `query:"context"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	groups, err := newGtdService().NextActions(userID, req.Context)
+	writeGtdResult(c, groups, err)
+}
+
+// ListSomeday 将来 / 也许清单
+// @router /v1/gtd/someday [GET]
+func ListSomeday(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todos, err := newGtdService().Someday(userID)
+	writeGtdResult(c, todos, err)
+}
+
+// SetTodoTags 替换待办的标签（含 @ 开头的情境标签）
+// @router /v1/todos/:id/tags [PUT]
+func SetTodoTags(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID   int64    `path:"id"`
+		Tags []string `json:"tags"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todo, err := newGtdService().SetTags(userID, uint(req.ID), req.Tags)
+	writeGtdResult(c, todo, err)
+}
+
+// ListTags 用户的标签及待办数量
+// @router /v1/tags [GET]
+func ListTags(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	tags, err := newGtdService().Tags(userID)
+	writeGtdResult(c, tags, err)
+}
+
+func writeGtdResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTitleRequired),
+			errors.Is(err, service.ErrInvalidTag),
+			errors.Is(err, service.ErrTooManyTags),
+			errors.Is(err, service.ErrInvalidClarifyTarget),
+			errors.Is(err, service.ErrClarifyProjectRequired),
+			errors.Is(err, service.ErrClarifyTodoDone),
+			errors.Is(err, service.ErrProjectNameRequired),
+			errors.Is(err, service.ErrInvalidProjectParent),
+			errors.Is(err, service.ErrNoteContentTooLong):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, service.ErrPlanLimitReached):
+			c.JSON(consts.StatusForbidden, utils.H{"status": 403, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrTodoNotFound),
+			errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "GTD request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newHabitService() *service.HabitService {
+	return service.NewHabitService(repository.NewHabitRepository(db.DB), repository.NewUserSettingRepository(db.DB))
+}
+
+// habitBody 创建/更新习惯的请求体，省略的字段保持不变
+type habitBody struct {
+	Name         *string `json:"name"`
+	Frequency    *string `json:"frequency"`
+	TimesPerWeek *int    `json:"times_per_week"`
+	StartDate    *string `json:"start_date"`
+}
+
+func (b habitBody) input() service.HabitInput {
+	return service.HabitInput{Name: b.Name, Frequency: b.Frequency, TimesPerWeek: b.TimesPerWeek, StartDate: b.StartDate}
+}
+
+// CreateHabit 创建习惯
+// @router /v1/habits [POST]
+func CreateHabit(ctx context.Context, c *app.RequestContext) {
+	var req habitBody
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	habit, err := newHabitService().Create(userID, req.input())
+	writeHabitResult(c, habit, err)
+}
+
+// ListHabits 列出习惯及连续次数、完成率
+// @router /v1/habits [GET]
+func ListHabits(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	habits, err := newHabitService().List(userID)
+	writeHabitResult(c, habits, err)
+}
+
+// GetHabit 获取习惯及统计
+// @router /v1/habits/:id [GET]
+func GetHabit(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	habit, err := newHabitService().Get(userID, uint(req.ID))
+	writeHabitResult(c, habit, err)
+}
+
+// UpdateHabit 部分更新习惯
+// @router /v1/habits/:id [PATCH]
+func UpdateHabit(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+		habitBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	habit, err := newHabitService().Update(userID, uint(req.ID), req.input())
+	writeHabitResult(c, habit, err)
+}
+
+// DeleteHabit 删除习惯及其打卡记录
+// @router /v1/habits/:id [DELETE]
+func DeleteHabit(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	affected, err := newHabitService().Delete(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// CheckInHabit 打卡（date 为 YYYY-MM-DD 或 today，按用户时区）
+// @router /v1/habits/:id/check-ins/:date [PUT]
+func CheckInHabit(ctx context.Context, c *app.RequestContext) {
+	changeHabitCheckIn(c, true)
+}
+
+// UndoHabitCheckIn 取消某天的打卡
+// @router /v1/habits/:id/check-ins/:date [DELETE]
+func UndoHabitCheckIn(ctx context.Context, c *app.RequestContext) {
+	changeHabitCheckIn(c, false)
+}
+
+func changeHabitCheckIn(c *app.RequestContext, checkIn bool) {
+	var req struct {
+		ID   int64  `path:"id"`
+		Date string `path:"date"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	svc := newHabitService()
+	var habit *service.HabitView
+	if checkIn {
+		habit, err = svc.CheckIn(userID, uint(req.ID), req.Date)
+	} else {
+		habit, err = svc.UndoCheckIn(userID, uint(req.ID), req.Date)
+	}
+	writeHabitResult(c, habit, err)
+}
+
+// GetHabitHistory 逐日打卡记录（from/to 为用户时区的日期，默认最近一年），用于热力图
+// @router /v1/habits/:id/history [GET]
+func GetHabitHistory(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID   int64  `path:"id"`
+		From string `query:"from"`
+		To   string `query:"to"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	history, err := newHabitService().History(userID, uint(req.ID), req.From, req.To)
+	writeHabitResult(c, history, err)
+}
+
+func writeHabitResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrHabitNameRequired),
+			errors.Is(err, service.ErrInvalidHabitFrequency),
+			errors.Is(err, service.ErrInvalidTimesPerWeek),
+			errors.Is(err, service.ErrInvalidHabitDate),
+			errors.Is(err, service.ErrHabitDateOutOfRange),
+			errors.Is(err, service.ErrInvalidDateRange):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrHabitNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Habit request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
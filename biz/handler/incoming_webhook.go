@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/jsonpath"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newIncomingWebhookService() *service.IncomingWebhookService {
+	return service.NewIncomingWebhookService(
+		repository.NewIncomingWebhookRepository(db.DB),
+		repository.NewIngestRecordRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// CreateIncomingWebhook 创建入站 webhook，返回接收 URL 与签名密钥
+// @router /v1/webhooks/incoming [POST]
+func CreateIncomingWebhook(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Name                   string `json:"name"`
+		ProjectID              int64  `json:"project_id"`
+		TitleTemplate          string `json:"title_template"`
+		ContentTemplate        string `json:"content_template"`
+		DueTimeTemplate        string `json:"due_time_template"`
+		IdempotencyKeyTemplate string `json:"idempotency_key_template"`
+		RequireSignature       bool   `json:"require_signature"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	hook, err := newIncomingWebhookService().CreateWebhook(userID, service.CreateIncomingWebhookInput{
+		Name:                   req.Name,
+		ProjectID:              uint(req.ProjectID),
+		TitleTemplate:          req.TitleTemplate,
+		ContentTemplate:        req.ContentTemplate,
+		DueTimeTemplate:        req.DueTimeTemplate,
+		IdempotencyKeyTemplate: req.IdempotencyKeyTemplate,
+		RequireSignature:       req.RequireSignature,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTitleTemplateRequired), errors.Is(err, jsonpath.ErrInvalidPath):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Create webhook failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+
+	// 签名密钥只在创建时返回一次
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{
+		"webhook":          hook,
+		"url_path":         "/v1/hooks/" + hook.Token,
+		"signature_header": service.WebhookSignatureHeader,
+		"secret":           hook.Secret,
+	}})
+}
+
+// ListIncomingWebhooks 列出入站 webhook
+// @router /v1/webhooks/incoming [GET]
+func ListIncomingWebhooks(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	hooks, err := newIncomingWebhookService().ListWebhooks(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": hooks})
+}
+
+// DeleteIncomingWebhook 删除入站 webhook（已创建的待办保留）
+// @router /v1/webhooks/incoming/:id [DELETE]
+func DeleteIncomingWebhook(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	affected, err := newIncomingWebhookService().DeleteWebhook(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// PreviewIncomingWebhook 用样例 JSON 试算模板映射结果，不创建待办
+// @router /v1/webhooks/incoming/:id/preview [POST]
+func PreviewIncomingWebhook(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindPath(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	draft, err := newIncomingWebhookService().Preview(userID, uint(req.ID), c.Request.Body())
+	if err != nil {
+		writeIncomingWebhookError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": draft})
+}
+
+// ReceiveIncomingWebhook 接收外部推送并创建待办（URL 令牌 + 可选签名认证，无需 JWT）
+// @router /v1/hooks/:token [POST]
+func ReceiveIncomingWebhook(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Token string `path:"token"`
+	}
+	if err := c.BindPath(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	signature := string(c.Request.Header.Peek(service.WebhookSignatureHeader))
+	result, err := newIncomingWebhookService().Deliver(req.Token, signature, c.Request.Body())
+	if err != nil {
+		writeIncomingWebhookError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": result})
+}
+
+func writeIncomingWebhookError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookPayload), errors.Is(err, jsonpath.ErrInvalidPath):
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+	case errors.Is(err, service.ErrWebhookSignatureMismatch):
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": err.Error(), "data": nil})
+	case errors.Is(err, service.ErrFeatureNotInPlan), errors.Is(err, service.ErrPlanLimitReached):
+		c.JSON(consts.StatusForbidden, utils.H{"status": 403, "msg": err.Error(), "data": nil})
+	case errors.Is(err, repository.ErrIncomingWebhookNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Webhook failed: " + err.Error(), "data": nil})
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/issuetracker"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newIssueSyncService() *service.IssueSyncService {
+	return service.NewIssueSyncService(
+		repository.NewIssueConnectorRepository(db.DB),
+		repository.NewIssueLinkRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// CreateIssueConnector 把项目连接到 GitHub/GitLab 仓库
+// @router /v1/integrations/issues/connectors [POST]
+func CreateIssueConnector(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Provider       string `json:"provider"`
+		BaseURL        string `json:"base_url"`
+		Repo           string `json:"repo"`
+		Token          string `json:"token"`
+		Assignee       string `json:"assignee"`
+		ProjectID      int64  `json:"project_id"`
+		WebhookSecret  string `json:"webhook_secret"`
+		ConflictPolicy string `json:"conflict_policy"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	conn, err := newIssueSyncService().CreateConnector(userID, service.CreateIssueConnectorInput{
+		Provider:       req.Provider,
+		BaseURL:        req.BaseURL,
+		Repo:           req.Repo,
+		Token:          req.Token,
+		Assignee:       req.Assignee,
+		ProjectID:      uint(req.ProjectID),
+		WebhookSecret:  req.WebhookSecret,
+		ConflictPolicy: req.ConflictPolicy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, issuetracker.ErrUnsupportedProvider),
+			errors.Is(err, issuetracker.ErrBaseURLNotAllowed),
+			errors.Is(err, service.ErrRepoRequired),
+			errors.Is(err, service.ErrInvalidConflictPolicy):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Create connector failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+
+	// webhook 密钥只在创建时返回一次
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{
+		"connector":      conn,
+		"webhook_path":   fmt.Sprintf("/v1/integrations/issues/webhook/%d", conn.ID),
+		"webhook_secret": conn.WebhookSecret,
+	}})
+}
+
+// ListIssueConnectors 列出同步连接
+// @router /v1/integrations/issues/connectors [GET]
+func ListIssueConnectors(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	conns, err := newIssueSyncService().ListConnectors(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": conns})
+}
+
+// DeleteIssueConnector 删除同步连接（已导入的待办保留）
+// @router /v1/integrations/issues/connectors/:id [DELETE]
+func DeleteIssueConnector(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	affected, err := newIssueSyncService().DeleteConnector(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// SyncIssueConnector 立即执行一次双向同步
+// @router /v1/integrations/issues/connectors/:id/sync [POST]
+func SyncIssueConnector(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	result, err := newIssueSyncService().SyncConnector(ctx, userID, uint(req.ID))
+	if err != nil {
+		if errors.Is(err, repository.ErrIssueConnectorNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+			return
+		}
+		if errors.Is(err, service.ErrFeatureNotInPlan) {
+			c.JSON(consts.StatusForbidden, utils.H{"status": 403, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusBadGateway, utils.H{"status": 502, "msg": "Sync failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": result})
+}
+
+// IssueWebhook 接收 GitHub/GitLab issue 事件（签名认证，无需 JWT）
+// @router /v1/integrations/issues/webhook/:id [POST]
+func IssueWebhook(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindPath(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	header := func(key string) string { return string(c.Request.Header.Peek(key)) }
+	err := newIssueSyncService().HandleWebhook(ctx, uint(req.ID), header, c.Request.Body())
+	if err != nil {
+		switch {
+		case errors.Is(err, issuetracker.ErrIgnoredEvent):
+			c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ignored", "data": nil})
+		case errors.Is(err, repository.ErrIssueConnectorNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		case errors.Is(err, issuetracker.ErrInvalidWebhookSignature):
+			c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": err.Error(), "data": nil})
+		case errors.Is(err, service.ErrFeatureNotInPlan):
+			c.JSON(consts.StatusForbidden, utils.H{"status": 403, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Webhook failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": nil})
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
 	"memogo/biz/dal/repository"
 	"memogo/biz/model/memogo/api"
 	"memogo/biz/service"
@@ -50,6 +51,9 @@ func Register(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
+	if claims, err := jwt.ParseToken(accessToken); err == nil {
+		middleware.RecordAuthEvent(c, claims.UserID, claims.Username, model.AuthEventRegister)
+	}
 
 	resp := &api.AuthResp{
 		Status: 200,
@@ -113,6 +117,7 @@ func RefreshToken(ctx context.Context, c *app.RequestContext) {
 			status = consts.StatusUnauthorized
 			msg = "Invalid refresh token"
 		}
+		middleware.RecordAuthEvent(c, 0, "", model.AuthEventRefreshFailed)
 
 		c.JSON(status, &api.AuthResp{
 			Status: int32(status),
@@ -129,6 +134,7 @@ func RefreshToken(ctx context.Context, c *app.RequestContext) {
 		})
 		return
 	}
+	middleware.RecordAuthEvent(c, accessClaims.UserID, accessClaims.Username, model.AuthEventRefresh)
 	refreshClaims, err := jwt.ParseToken(newRefreshToken)
 	if err != nil {
 		c.JSON(consts.StatusInternalServerError, &api.AuthResp{
@@ -4,11 +4,13 @@ package api
 
 import (
 	"context"
+	"errors"
 	"memogo/biz/dal/db"
 	"memogo/biz/dal/repository"
 	"memogo/biz/model/memogo/api"
 	"memogo/biz/service"
 	"memogo/pkg/middleware"
+	"strconv"
 	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -30,6 +32,12 @@ func ListTodos(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusUnauthorized, &api.ListTodosResp{Status: 401, Msg: "Unauthorized: " + err.Error()})
 		return
 	}
+	projectIDs, err := projectScope(c, userID)
+	if err != nil {
+		status := projectScopeStatus(err)
+		c.JSON(status, &api.ListTodosResp{Status: int32(status), Msg: err.Error()})
+		return
+	}
 
 	statusStr := strings.ToLower(req.GetStatus())
 	page := int(req.GetPage())
@@ -38,7 +46,11 @@ func ListTodos(ctx context.Context, c *app.RequestContext) {
 	todoRepo := repository.NewTodoRepository(db.DB)
 	todoSvc := service.NewTodoService(todoRepo)
 
-	items, total, err := todoSvc.ListTodos(userID, statusStr, page, pageSize)
+	items, total, err := todoSvc.ListTodos(userID, statusStr, projectIDs, c.Query("sort"), page, pageSize)
+	if errors.Is(err, service.ErrInvalidSort) {
+		c.JSON(consts.StatusBadRequest, &api.ListTodosResp{Status: 400, Msg: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(consts.StatusInternalServerError, &api.ListTodosResp{Status: 500, Msg: "List failed: " + err.Error()})
 		return
@@ -90,6 +102,12 @@ func SearchTodos(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusUnauthorized, &api.SearchTodosResp{Status: 401, Msg: "Unauthorized: " + err.Error()})
 		return
 	}
+	projectIDs, err := projectScope(c, userID)
+	if err != nil {
+		status := projectScopeStatus(err)
+		c.JSON(status, &api.SearchTodosResp{Status: int32(status), Msg: err.Error()})
+		return
+	}
 
 	q := req.GetQ()
 	page := int(req.GetPage())
@@ -97,7 +115,11 @@ func SearchTodos(ctx context.Context, c *app.RequestContext) {
 
 	todoRepo := repository.NewTodoRepository(db.DB)
 	todoSvc := service.NewTodoService(todoRepo)
-	items, total, err := todoSvc.SearchTodos(userID, q, page, pageSize)
+	items, total, err := todoSvc.SearchTodos(userID, q, projectIDs, c.Query("sort"), page, pageSize)
+	if errors.Is(err, service.ErrInvalidSort) {
+		c.JSON(consts.StatusBadRequest, &api.SearchTodosResp{Status: 400, Msg: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(consts.StatusInternalServerError, &api.SearchTodosResp{Status: 500, Msg: "Search failed: " + err.Error()})
 		return
@@ -151,6 +173,12 @@ func ListTodosCursor(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusUnauthorized, &api.ListTodosCursorResp{Status: 401, Msg: "Unauthorized: " + err.Error()})
 		return
 	}
+	projectIDs, err := projectScope(c, userID)
+	if err != nil {
+		status := projectScopeStatus(err)
+		c.JSON(status, &api.ListTodosCursorResp{Status: int32(status), Msg: err.Error()})
+		return
+	}
 
 	statusStr := strings.ToLower(req.GetStatus())
 	cursor := uint(req.GetCursor())
@@ -159,7 +187,11 @@ func ListTodosCursor(ctx context.Context, c *app.RequestContext) {
 	todoRepo := repository.NewTodoRepository(db.DB)
 	todoSvc := service.NewTodoService(todoRepo)
 
-	items, nextCursor, hasMore, err := todoSvc.ListTodosCursor(userID, statusStr, cursor, limit)
+	items, nextCursor, hasMore, err := todoSvc.ListTodosCursor(userID, statusStr, projectIDs, c.Query("sort"), cursor, limit)
+	if errors.Is(err, service.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(consts.StatusBadRequest, &api.ListTodosCursorResp{Status: 400, Msg: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(consts.StatusInternalServerError, &api.ListTodosCursorResp{Status: 500, Msg: "Query failed: " + err.Error()})
 		return
@@ -215,6 +247,12 @@ func SearchTodosCursor(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusUnauthorized, &api.SearchTodosCursorResp{Status: 401, Msg: "Unauthorized: " + err.Error()})
 		return
 	}
+	projectIDs, err := projectScope(c, userID)
+	if err != nil {
+		status := projectScopeStatus(err)
+		c.JSON(status, &api.SearchTodosCursorResp{Status: int32(status), Msg: err.Error()})
+		return
+	}
 
 	keyword := req.GetQ()
 	cursor := uint(req.GetCursor())
@@ -223,7 +261,11 @@ func SearchTodosCursor(ctx context.Context, c *app.RequestContext) {
 	todoRepo := repository.NewTodoRepository(db.DB)
 	todoSvc := service.NewTodoService(todoRepo)
 
-	items, nextCursor, hasMore, err := todoSvc.SearchTodosCursor(userID, keyword, cursor, limit)
+	items, nextCursor, hasMore, err := todoSvc.SearchTodosCursor(userID, keyword, projectIDs, c.Query("sort"), cursor, limit)
+	if errors.Is(err, service.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(consts.StatusBadRequest, &api.SearchTodosCursorResp{Status: 400, Msg: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(consts.StatusInternalServerError, &api.SearchTodosCursorResp{Status: 500, Msg: "Search failed: " + err.Error()})
 		return
@@ -261,3 +303,31 @@ func SearchTodosCursor(ctx context.Context, c *app.RequestContext) {
 		},
 	})
 }
+
+// projectScope 解析可选的 project_id 查询参数（IDL 未定义，直接读取查询串），
+// 返回该节点及其全部下级的 ID，例如传入领域即筛选该领域下所有项目与分区的待办；未传时返回 nil 表示不限
+func projectScope(c *app.RequestContext, userID uint) ([]uint, error) {
+	raw := c.Query("project_id")
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errInvalidProjectID
+	}
+	projectSvc := service.NewProjectService(repository.NewProjectRepository(db.DB), repository.NewTodoRepository(db.DB))
+	return projectSvc.SubtreeIDs(userID, uint(id))
+}
+
+var errInvalidProjectID = errors.New("project_id must be a positive integer")
+
+func projectScopeStatus(err error) int {
+	switch {
+	case errors.Is(err, errInvalidProjectID):
+		return consts.StatusBadRequest
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return consts.StatusNotFound
+	default:
+		return consts.StatusInternalServerError
+	}
+}
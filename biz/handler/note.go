@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newNoteService() *service.NoteService {
+	return service.NewNoteService(repository.NewNoteRepository(db.DB), repository.NewTodoRepository(db.DB))
+}
+
+// CreateNote 创建 Markdown 笔记，正文中的 [[wiki 链接]] 会被解析
+// @router /v1/notes [POST]
+func CreateNote(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	detail, err := newNoteService().Create(userID, req.Title, req.Content)
+	writeNoteResult(c, detail, err)
+}
+
+// ListNotes 搜索笔记（q 匹配标题或内容；journal=true 只看日记，可配合 from/to 日期范围）
+// @router /v1/notes [GET]
+func ListNotes(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Q       string `query:"q"`
+		Journal *bool  `query:"journal"`
+		From    string `query:"from"`
+		To      string `query:"to"`
+		Cursor  int64  `query:"cursor"`
+		Limit   int    `query:"limit"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	f := repository.NoteFilter{Query: req.Q, Journal: req.Journal, Cursor: uint(req.Cursor), Limit: req.Limit}
+	if req.From != "" {
+		f.From, err = service.ParseJournalDate(req.From)
+	}
+	if err == nil && req.To != "" {
+		f.To, err = service.ParseJournalDate(req.To)
+	}
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		return
+	}
+
+	notes, err := newNoteService().List(userID, f)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	var next uint
+	if len(notes) > 0 {
+		next = notes[len(notes)-1].ID
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{"notes": notes, "next_cursor": next}})
+}
+
+// GetNote 获取笔记详情（含发出的链接与反向链接）
+// @router /v1/notes/:id [GET]
+func GetNote(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	detail, err := newNoteService().Get(userID, uint(req.ID))
+	writeNoteResult(c, detail, err)
+}
+
+// UpdateNote 部分更新笔记（title / content）
+// @router /v1/notes/:id [PATCH]
+func UpdateNote(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID      int64   `path:"id"`
+		Title   *string `json:"title"`
+		Content *string `json:"content"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	detail, err := newNoteService().Update(userID, uint(req.ID), service.NoteUpdate{Title: req.Title, Content: req.Content})
+	writeNoteResult(c, detail, err)
+}
+
+// DeleteNote 删除笔记（指向它的链接变为未解析）
+// @router /v1/notes/:id [DELETE]
+func DeleteNote(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	affected, err := newNoteService().Delete(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// GetJournal 获取某天的日记（date 为 YYYY-MM-DD 或 today）
+// @router /v1/notes/journal/:date [GET]
+func GetJournal(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Date string `path:"date"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	detail, err := newNoteService().Journal(userID, req.Date)
+	writeNoteResult(c, detail, err)
+}
+
+// SaveJournal 写入某天的日记，不存在时创建
+// @router /v1/notes/journal/:date [PUT]
+func SaveJournal(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Date    string `path:"date"`
+		Content string `json:"content"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	detail, err := newNoteService().SaveJournal(userID, req.Date, req.Content)
+	writeNoteResult(c, detail, err)
+}
+
+func writeNoteResult(c *app.RequestContext, detail *service.NoteDetail, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoteTitleRequired),
+			errors.Is(err, service.ErrNoteTitleTooLong),
+			errors.Is(err, service.ErrNoteContentTooLong),
+			errors.Is(err, service.ErrInvalidJournalDate),
+			errors.Is(err, service.ErrJournalTitleFixed):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrNoteNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Note request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": detail})
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/billing"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newPlanService() *service.PlanService {
+	return service.NewPlanService(
+		repository.NewPlanRepository(db.DB),
+		repository.NewUserRepository(db.DB),
+		repository.NewAuditLogRepository(db.DB),
+		billing.Default(),
+	)
+}
+
+func newEntitlementService() *service.EntitlementService {
+	return service.NewEntitlementService(repository.NewPlanRepository(db.DB))
+}
+
+// planBody 创建/更新套餐的请求体，省略的字段保持不变
+type planBody struct {
+	Name        *string         `json:"name"`
+	Description *string         `json:"description"`
+	IsDefault   *bool           `json:"is_default"`
+	Limits      *map[string]int `json:"limits"`
+	Features    *[]string       `json:"features"`
+}
+
+func (b planBody) input() service.PlanInput {
+	return service.PlanInput{
+		Name:        b.Name,
+		Description: b.Description,
+		IsDefault:   b.IsDefault,
+		Limits:      b.Limits,
+		Features:    b.Features,
+	}
+}
+
+// GetMyEntitlements 当前用户适用的套餐、包含的功能与各限额项的用量
+// @router /v1/entitlements [GET]
+func GetMyEntitlements(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	summary, err := newEntitlementService().Summary(service.SubjectForUser(userID))
+	writePlanResult(c, summary, err)
+}
+
+// CreatePlan 创建套餐（管理员）
+// @router /v1/admin/plans [POST]
+func CreatePlan(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Code string `json:"code"`
+		planBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	plan, err := newPlanService().CreatePlan(adminID, req.Code, req.input())
+	if err != nil && plan == nil {
+		writePlanError(c, err)
+		return
+	}
+	writePlanSaved(c, plan, err)
+}
+
+// ListPlans 列出全部套餐（管理员）
+// @router /v1/admin/plans [GET]
+func ListPlans(ctx context.Context, c *app.RequestContext) {
+	plans, err := newPlanService().ListPlans()
+	writePlanResult(c, plans, err)
+}
+
+// UpdatePlan 部分更新套餐（管理员）
+// @router /v1/admin/plans/:code [PATCH]
+func UpdatePlan(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Code string `path:"code"`
+		planBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	plan, err := newPlanService().UpdatePlan(adminID, req.Code, req.input())
+	if err != nil && plan == nil {
+		writePlanError(c, err)
+		return
+	}
+	writePlanSaved(c, plan, err)
+}
+
+// DeletePlan 删除没有分配的套餐（管理员）
+// @router /v1/admin/plans/:code [DELETE]
+func DeletePlan(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Code string `path:"code"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	err = newPlanService().DeletePlan(adminID, req.Code)
+	writePlanResult(c, nil, err)
+}
+
+// AssignPlan 为用户或工作区分配套餐，billing=true 时经由计费提供方订阅（管理员）
+// @router /v1/admin/plan-assignments [PUT]
+func AssignPlan(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		SubjectType string `json:"subject_type"`
+		SubjectID   uint   `json:"subject_id"`
+		Plan        string `json:"plan"`
+		ExpiresAt   int64  `json:"expires_at"` // unix 秒，0 表示不过期
+		Billing     bool   `json:"billing"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	in := service.PlanAssignInput{
+		SubjectType: req.SubjectType,
+		SubjectID:   req.SubjectID,
+		PlanCode:    req.Plan,
+		Billing:     req.Billing,
+	}
+	if req.ExpiresAt > 0 {
+		t := time.Unix(req.ExpiresAt, 0)
+		in.ExpiresAt = &t
+	}
+	a, err := newPlanService().Assign(ctx, adminID, in)
+	if err != nil && a == nil {
+		writePlanError(c, err)
+		return
+	}
+	writePlanSaved(c, a, err)
+}
+
+// ListPlanAssignments 列出套餐分配，plan 非空时只列该套餐的（管理员）
+// @router /v1/admin/plan-assignments [GET]
+func ListPlanAssignments(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Plan string `query:"plan"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := newPlanService().ListAssignments(req.Plan)
+	writePlanResult(c, list, err)
+}
+
+// UnassignPlan 取消分配及其订阅，主体回到默认套餐（管理员）
+// @router /v1/admin/plan-assignments/:subject_type/:subject_id [DELETE]
+func UnassignPlan(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		SubjectType string `path:"subject_type"`
+		SubjectID   int64  `path:"subject_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	err = newPlanService().Unassign(ctx, adminID, req.SubjectType, uint(req.SubjectID))
+	writePlanResult(c, nil, err)
+}
+
+// SyncPlanAssignment 从计费提供方同步订阅状态（管理员）
+// @router /v1/admin/plan-assignments/:subject_type/:subject_id/sync [POST]
+func SyncPlanAssignment(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		SubjectType string `path:"subject_type"`
+		SubjectID   int64  `path:"subject_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+
+	a, err := newPlanService().SyncAssignment(ctx, req.SubjectType, uint(req.SubjectID))
+	writePlanResult(c, a, err)
+}
+
+func writePlanResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		writePlanError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
+
+func writePlanSaved(c *app.RequestContext, data interface{}, err error) {
+	msg := "ok"
+	if err != nil {
+		// 已保存，但审计日志写入失败
+		msg = "saved, audit log failed: " + err.Error()
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": msg, "data": data})
+}
+
+func writePlanError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPlanCode),
+		errors.Is(err, service.ErrPlanNameRequired),
+		errors.Is(err, service.ErrInvalidPlanLimit),
+		errors.Is(err, service.ErrInvalidPlanFeature),
+		errors.Is(err, service.ErrPlanInUse),
+		errors.Is(err, service.ErrInvalidPlanSubject),
+		errors.Is(err, service.ErrBillingNotConfigured),
+		errors.Is(err, service.ErrNoSubscription),
+		errors.Is(err, repository.ErrPlanExists):
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+	case errors.Is(err, repository.ErrPlanNotFound),
+		errors.Is(err, repository.ErrPlanAssignmentNotFound),
+		errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Plan request failed: " + err.Error(), "data": nil})
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+	"memogo/pkg/planner"
+	"memogo/pkg/workday"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ExportPlannerPDF 导出日期范围内的待办为可打印 PDF 计划本
+// from、to 为 YYYY-MM-DD（默认今天起 7 天），group_by 为 day（默认）或 project
+// @router /v1/planner/pdf [GET]
+func ExportPlannerPDF(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		From        string `query:"from"`
+		To          string `query:"to"`
+		GroupBy     string `query:"group_by"`
+		IncludeDone *bool  `query:"include_done"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	opts := service.PlannerOptions{GroupBy: req.GroupBy, IncludeDone: true, From: time.Now()}
+	if req.IncludeDone != nil {
+		opts.IncludeDone = *req.IncludeDone
+	}
+	if req.From != "" {
+		if opts.From, err = workday.ParseDate(req.From); err != nil {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": "invalid from: " + err.Error(), "data": nil})
+			return
+		}
+	}
+	opts.To = opts.From.AddDate(0, 0, 6)
+	if req.To != "" {
+		if opts.To, err = workday.ParseDate(req.To); err != nil {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": "invalid to: " + err.Error(), "data": nil})
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	svc := service.NewPlannerService(repository.NewTodoRepository(db.DB), repository.NewProjectRepository(db.DB))
+	if err := svc.ExportPDF(&buf, userID, opts); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidGroupBy), errors.Is(err, service.ErrInvalidDateRange):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, planner.ErrFontUnavailable):
+			c.JSON(consts.StatusServiceUnavailable, utils.H{"status": 503, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Export failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("planner-%s.pdf", opts.From.In(workday.Location).Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(consts.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newProjectService() *service.ProjectService {
+	return service.NewProjectService(
+		repository.NewProjectRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+	)
+}
+
+// CreateProject 创建项目树节点（kind: area|project|section，默认 project；key 为可选的待办编号前缀，如 OPS）
+// @router /v1/projects [POST]
+func CreateProject(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Name     string `json:"name"`
+		Kind     string `json:"kind"`
+		Key      string `json:"key"`
+		ParentID int64  `json:"parent_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	project, err := newProjectService().Create(userID, req.Name, req.Kind, req.Key, uint(req.ParentID))
+	if err != nil {
+		if writeProjectTreeError(c, err) {
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Create project failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": project})
+}
+
+// ListProjects 列出项目
+// @router /v1/projects [GET]
+func ListProjects(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	projects, err := newProjectService().List(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": projects})
+}
+
+// GetProjectTree 返回项目树（领域 → 项目 → 分区）及每个节点的待办数量
+// @router /v1/projects/tree [GET]
+func GetProjectTree(ctx context.Context, c *app.RequestContext) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	tree, err := newProjectService().Tree(userID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": tree})
+}
+
+// MoveProject 修改节点的父节点（parent_id 为 0 表示移到顶层）
+// @router /v1/projects/:id/parent [PATCH]
+func MoveProject(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID       int64 `path:"id"`
+		ParentID int64 `json:"parent_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	project, err := newProjectService().Move(userID, uint(req.ID), uint(req.ParentID))
+	if err != nil {
+		if writeProjectTreeError(c, err) {
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Move failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": project})
+}
+
+// SetProjectKey 设置或修改节点的待办编号前缀（如 OPS，之后新建的待办编号为 OPS-1、OPS-2……）
+// @router /v1/projects/:id/key [PATCH]
+func SetProjectKey(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID  int64  `path:"id"`
+		Key string `json:"key"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	project, err := newProjectService().SetKey(userID, uint(req.ID), req.Key)
+	if err != nil {
+		if writeProjectTreeError(c, err) {
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Set key failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": project})
+}
+
+// DeleteProject 删除项目（项目下的待办保留，仅解除归属；有子节点时需先移走或删除子节点）
+// @router /v1/projects/:id [DELETE]
+func DeleteProject(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	affected, err := newProjectService().Delete(userID, uint(req.ID))
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectHasChildren) {
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// MoveTodoToProject 设置待办所属项目（project_id 为 0 表示移出项目）
+// @router /v1/todos/:id/project [PATCH]
+func MoveTodoToProject(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID        int64 `path:"id"`
+		ProjectID int64 `json:"project_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+	affected, err := newProjectService().MoveTodo(userID, uint(req.ID), uint(req.ProjectID))
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Move failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// writeProjectTreeError 输出项目树校验错误，非此类错误返回 false 由调用方处理
+func writeProjectTreeError(c *app.RequestContext, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrProjectNameRequired),
+		errors.Is(err, service.ErrInvalidProjectKind),
+		errors.Is(err, service.ErrInvalidProjectParent),
+		errors.Is(err, service.ErrProjectCycle),
+		errors.Is(err, service.ErrInvalidProjectKey),
+		errors.Is(err, repository.ErrProjectKeyTaken):
+		c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+	case errors.Is(err, repository.ErrProjectNotFound):
+		c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+	default:
+		return false
+	}
+	return true
+}
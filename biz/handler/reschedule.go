@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newRescheduleService() *service.RescheduleService {
+	return service.NewRescheduleService(
+		repository.NewTodoRepository(db.DB),
+		repository.NewTodoTagRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+	)
+}
+
+// GetTodoReschedules 待办的截止时间变更历史与改期次数
+// @router /v1/todos/:id/reschedules [GET]
+func GetTodoReschedules(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	history, err := newRescheduleService().History(userID, uint(req.ID))
+	writeRescheduleResult(c, history, err)
+}
+
+// GetRescheduleStats 拖延统计：最近 days 天按项目或标签（group_by=project|tag）的平均推迟天数，以及改期最多的待办
+// @router /v1/stats/reschedules [GET]
+func GetRescheduleStats(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		GroupBy string `query:"group_by"`
+		Days    int    `query:"days"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	stats, err := newRescheduleService().Stats(userID, req.GroupBy, req.Days, time.Now())
+	writeRescheduleResult(c, stats, err)
+}
+
+func writeRescheduleResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRescheduleGroup):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrTodoNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Reschedule query failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newSprintService() *service.SprintService {
+	return service.NewSprintService(
+		repository.NewSprintRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+	)
+}
+
+// sprintBody 创建/更新迭代的请求体，省略的字段保持不变
+type sprintBody struct {
+	Name      *string `json:"name"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+func (b sprintBody) input() service.SprintInput {
+	return service.SprintInput{Name: b.Name, StartDate: b.StartDate, EndDate: b.EndDate}
+}
+
+// CreateSprint 在项目下创建迭代
+// @router /v1/projects/:id/sprints [POST]
+func CreateSprint(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+		sprintBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	sprint, err := newSprintService().Create(userID, uint(req.ID), req.input())
+	writeSprintResult(c, sprint, err)
+}
+
+// ListSprints 列出项目的迭代
+// @router /v1/projects/:id/sprints [GET]
+func ListSprints(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	sprints, err := newSprintService().List(userID, uint(req.ID))
+	writeSprintResult(c, sprints, err)
+}
+
+// GetSprint 获取迭代详情（含当前待办与已结转的待办）
+// @router /v1/sprints/:id [GET]
+func GetSprint(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	sprint, err := newSprintService().Get(userID, uint(req.ID))
+	writeSprintResult(c, sprint, err)
+}
+
+// UpdateSprint 部分更新迭代（已关闭的迭代不能修改）
+// @router /v1/sprints/:id [PATCH]
+func UpdateSprint(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+		sprintBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	sprint, err := newSprintService().Update(userID, uint(req.ID), req.input())
+	writeSprintResult(c, sprint, err)
+}
+
+// DeleteSprint 删除迭代（其中的待办不受影响）
+// @router /v1/sprints/:id [DELETE]
+func DeleteSprint(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	affected, err := newSprintService().Delete(userID, uint(req.ID))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Delete failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": affected})
+}
+
+// AddSprintTodo 把待办加入迭代
+// @router /v1/sprints/:id/todos/:todo_id [PUT]
+func AddSprintTodo(ctx context.Context, c *app.RequestContext) {
+	changeSprintTodo(c, true)
+}
+
+// RemoveSprintTodo 把待办移出迭代
+// @router /v1/sprints/:id/todos/:todo_id [DELETE]
+func RemoveSprintTodo(ctx context.Context, c *app.RequestContext) {
+	changeSprintTodo(c, false)
+}
+
+func changeSprintTodo(c *app.RequestContext, add bool) {
+	var req struct {
+		ID     int64 `path:"id"`
+		TodoID int64 `path:"todo_id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	svc := newSprintService()
+	var sprint *service.SprintDetail
+	if add {
+		sprint, err = svc.AddTodo(userID, uint(req.ID), uint(req.TodoID))
+	} else {
+		sprint, err = svc.RemoveTodo(userID, uint(req.ID), uint(req.TodoID))
+	}
+	writeSprintResult(c, sprint, err)
+}
+
+// CloseSprint 关闭迭代，未完成的待办结转到 carry_over_to 指定的迭代（省略则只移出）
+// @router /v1/sprints/:id/close [POST]
+func CloseSprint(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID          int64 `path:"id"`
+		CarryOverTo int64 `json:"carry_over_to"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	result, err := newSprintService().Close(userID, uint(req.ID), uint(req.CarryOverTo))
+	writeSprintResult(c, result, err)
+}
+
+// GetSprintBurndown 迭代的每日燃尽/燃起数据
+// @router /v1/sprints/:id/burndown [GET]
+func GetSprintBurndown(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	burndown, err := newSprintService().Burndown(userID, uint(req.ID))
+	writeSprintResult(c, burndown, err)
+}
+
+func writeSprintResult(c *app.RequestContext, data interface{}, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSprintNameRequired),
+			errors.Is(err, service.ErrInvalidSprintDate),
+			errors.Is(err, service.ErrSprintDateRange),
+			errors.Is(err, service.ErrSprintOverlap),
+			errors.Is(err, service.ErrSprintProjectKind),
+			errors.Is(err, service.ErrSprintClosed),
+			errors.Is(err, service.ErrTodoNotInSprintProject),
+			errors.Is(err, service.ErrInvalidCarryOver):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrSprintNotFound),
+			errors.Is(err, repository.ErrTodoNotFound),
+			errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Sprint request failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": data})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/model/memogo/api"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// UpdateTodo 部分更新待办（title / content / due_time / estimate，due_time 传 0 表示清空）
+// @router /v1/todos/:id [PATCH]
+func UpdateTodo(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID       int64   `path:"id"`
+		Title    *string `json:"title"`
+		Content  *string `json:"content"`
+		DueTime  *int64  `json:"due_time"`
+		Estimate *int    `json:"estimate"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	in := service.TodoUpdate{Title: req.Title, Content: req.Content, Estimate: req.Estimate}
+	if req.DueTime != nil {
+		if *req.DueTime == 0 {
+			in.ClearDueTime = true
+		} else {
+			t := time.Unix(*req.DueTime, 0)
+			in.DueTime = &t
+		}
+	}
+
+	todoSvc := service.NewTodoService(repository.NewTodoRepository(db.DB))
+	todo, err := todoSvc.UpdateTodo(userID, uint(req.ID), in)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTitleRequired), errors.Is(err, service.ErrContentRequired),
+			errors.Is(err, service.ErrInvalidEstimate):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrTodoNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Update failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": toAPITodo(todo)})
+}
+
+// GetTodo 获取单条待办（:id 可以是待办 ID 或项目内编号如 OPS-17），附带所属项目与链接到它的笔记（反向链接）
+// @router /v1/todos/:id [GET]
+func GetTodo(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ID string `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	todo, err := service.NewTodoService(repository.NewTodoRepository(db.DB)).GetTodoByRef(userID, req.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTodoNotFound) {
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Query failed: " + err.Error(), "data": nil})
+		return
+	}
+	backlinks, err := newNoteService().TodoBacklinks(userID, todo.ID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Query failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": utils.H{
+		"todo":             toAPITodo(todo),
+		"key":              todo.Key,
+		"project_id":       todo.ProjectID,
+		"estimate":         todo.Estimate,
+		"backlinks":        backlinks,
+		"reschedule_count": todo.RescheduleCount,
+	}})
+}
+
+// toAPITodo 转为 API 模型（与生成的处理器保持相同的字段语义）
+func toAPITodo(t *model.Todo) *api.Todo {
+	at := &api.Todo{
+		ID:        int64(t.ID),
+		Title:     t.Title,
+		Content:   t.Content,
+		Status:    api.TodoStatus(t.Status),
+		CreatedAt: t.CreatedAt.Unix(),
+	}
+	if t.StartTime != nil {
+		at.StartTime = t.StartTime.Unix()
+	}
+	if t.EndTime != nil {
+		at.EndTime = t.EndTime.Unix()
+	}
+	if t.DueTime != nil {
+		at.DueTime = t.DueTime.Unix()
+	}
+	return at
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/middleware"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func newTodoTransferService() *service.TodoTransferService {
+	return service.NewTodoTransferService(
+		repository.NewTodoTransferRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewUserRepository(db.DB),
+		repository.NewAuditLogRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+}
+
+// transferBody 转移范围：scope 为 todos（todo_ids）、project（project_id，含下级节点）或 open（全部未完成）
+type transferBody struct {
+	Scope     string  `json:"scope"`
+	TodoIDs   []int64 `json:"todo_ids"`
+	ProjectID int64   `json:"project_id"`
+	Note      string  `json:"note"`
+}
+
+func (b transferBody) input(toUserID uint) service.TodoTransferInput {
+	in := service.TodoTransferInput{ToUserID: toUserID, Scope: b.Scope, ProjectID: uint(b.ProjectID), Note: b.Note}
+	for _, id := range b.TodoIDs {
+		in.TodoIDs = append(in.TodoIDs, uint(id))
+	}
+	return in
+}
+
+// RequestTodoTransfer 把自己的待办转移给另一个用户（to_username），对方接受后生效
+// @router /v1/transfers [POST]
+func RequestTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		ToUsername string `json:"to_username"`
+		transferBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	svc := newTodoTransferService()
+	toUserID, err := svc.RecipientID(req.ToUsername)
+	if err != nil {
+		writeTodoTransferResult(c, nil, err)
+		return
+	}
+	transfer, err := svc.Request(userID, userID, req.input(toUserID))
+	writeTodoTransferResult(c, transfer, err)
+}
+
+// AdminRequestTodoTransfer 管理员代为发起转移（如成员离职），仍需接收方接受
+// @router /v1/admin/transfers [POST]
+func AdminRequestTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		FromUserID int64 `json:"from_user_id"`
+		ToUserID   int64 `json:"to_user_id"`
+		transferBody
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	transfer, err := newTodoTransferService().Request(adminID, uint(req.FromUserID), req.input(uint(req.ToUserID)))
+	writeTodoTransferResult(c, transfer, err)
+}
+
+// ListTodoTransfers 列出与自己相关的转移请求（direction=incoming|outgoing，status 可选）
+// @router /v1/transfers [GET]
+func ListTodoTransfers(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Direction string `query:"direction"`
+		Status    string `query:"status"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	transfers, err := newTodoTransferService().List(userID, repository.TodoTransferFilter{
+		Incoming: req.Direction == "incoming",
+		Outgoing: req.Direction == "outgoing",
+		Status:   req.Status,
+	})
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "List failed: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": "ok", "data": transfers})
+}
+
+// GetTodoTransfer 获取转移请求
+// @router /v1/transfers/:id [GET]
+func GetTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	respondTodoTransfer(c, (*service.TodoTransferService).Get)
+}
+
+// AcceptTodoTransfer 接收方接受转移
+// @router /v1/transfers/:id/accept [POST]
+func AcceptTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	respondTodoTransfer(c, (*service.TodoTransferService).Accept)
+}
+
+// DeclineTodoTransfer 接收方拒绝转移
+// @router /v1/transfers/:id/decline [POST]
+func DeclineTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	respondTodoTransfer(c, (*service.TodoTransferService).Decline)
+}
+
+// CancelTodoTransfer 原所有者或发起人撤回转移
+// @router /v1/transfers/:id/cancel [POST]
+func CancelTodoTransfer(ctx context.Context, c *app.RequestContext) {
+	respondTodoTransfer(c, (*service.TodoTransferService).Cancel)
+}
+
+func respondTodoTransfer(c *app.RequestContext, fn func(*service.TodoTransferService, uint, uint) (*model.TodoTransfer, error)) {
+	var req struct {
+		ID int64 `path:"id"`
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.String(consts.StatusBadRequest, err.Error())
+		return
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+		return
+	}
+
+	transfer, err := fn(newTodoTransferService(), userID, uint(req.ID))
+	writeTodoTransferResult(c, transfer, err)
+}
+
+func writeTodoTransferResult(c *app.RequestContext, transfer *model.TodoTransfer, err error) {
+	if err != nil && transfer == nil {
+		switch {
+		case errors.Is(err, service.ErrTransferToSelf),
+			errors.Is(err, service.ErrInvalidTransferScope),
+			errors.Is(err, service.ErrTransferEmpty),
+			errors.Is(err, service.ErrTransferSection),
+			errors.Is(err, repository.ErrTodoTransferNotPending):
+			c.JSON(consts.StatusBadRequest, utils.H{"status": 400, "msg": err.Error(), "data": nil})
+		case errors.Is(err, service.ErrTransferForbidden),
+			errors.Is(err, service.ErrFeatureNotInPlan),
+			errors.Is(err, service.ErrPlanLimitReached):
+			c.JSON(consts.StatusForbidden, utils.H{"status": 403, "msg": err.Error(), "data": nil})
+		case errors.Is(err, repository.ErrTodoTransferNotFound),
+			errors.Is(err, repository.ErrUserNotFound),
+			errors.Is(err, repository.ErrTodoNotFound),
+			errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(consts.StatusNotFound, utils.H{"status": 404, "msg": err.Error(), "data": nil})
+		default:
+			c.JSON(consts.StatusInternalServerError, utils.H{"status": 500, "msg": "Transfer failed: " + err.Error(), "data": nil})
+		}
+		return
+	}
+	msg := "ok"
+	if err != nil {
+		// 操作已完成，但审计日志写入失败
+		msg = "done, audit log failed: " + err.Error()
+	}
+	c.JSON(consts.StatusOK, utils.H{"status": 200, "msg": msg, "data": transfer})
+}
@@ -3,6 +3,7 @@
 package api
 
 import (
+	"memogo/biz/dal/model"
 	"memogo/pkg/middleware"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -49,8 +50,8 @@ func _todos0Mw() []app.HandlerFunc {
 }
 
 func _createtodoMw() []app.HandlerFunc {
-	// 需要 JWT 认证
-	return []app.HandlerFunc{middleware.JWTMiddleware.MiddlewareFunc()}
+	// 需要 JWT 认证，并受套餐的待办数上限约束
+	return []app.HandlerFunc{middleware.JWTMiddleware.MiddlewareFunc(), middleware.RequireLimit(model.LimitTodos)}
 }
 
 func _updateallstatusMw() []app.HandlerFunc {
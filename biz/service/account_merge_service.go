@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/repository"
+	"memogo/pkg/billing"
+	"memogo/pkg/hash"
+)
+
+var (
+	// ErrMergeSameAccount 不能把账号合并到自身
+	ErrMergeSameAccount = errors.New("source and target accounts must differ")
+)
+
+// 审计日志动作
+const (
+	AuditActionAccountMerge = "account.merge"
+)
+
+// AccountMergeResult 合并结果
+type AccountMergeResult struct {
+	SourceUserID uint                   `json:"source_user_id"`
+	TargetUserID uint                   `json:"target_user_id"`
+	Stats        *repository.MergeStats `json:"stats"`
+}
+
+// AccountMergeService 账号合并
+type AccountMergeService struct {
+	userRepo  *repository.UserRepository
+	mergeRepo *repository.AccountMergeRepository
+	auditRepo *repository.AuditLogRepository
+	provider  billing.Provider // 取消合并时舍弃的套餐订阅，可为空
+}
+
+// NewAccountMergeService 创建账号合并服务实例
+func NewAccountMergeService(
+	userRepo *repository.UserRepository,
+	mergeRepo *repository.AccountMergeRepository,
+	auditRepo *repository.AuditLogRepository,
+	provider billing.Provider,
+) *AccountMergeService {
+	return &AccountMergeService{userRepo: userRepo, mergeRepo: mergeRepo, auditRepo: auditRepo, provider: provider}
+}
+
+// MergeByAdmin 管理员把 source 账号合并到 target 账号（管理员身份由路由中间件校验）
+func (s *AccountMergeService) MergeByAdmin(adminID, sourceID, targetID uint) (*AccountMergeResult, error) {
+	return s.merge(adminID, sourceID, targetID, "admin")
+}
+
+// MergeSelf 已登录用户提供另一个账号的用户名和密码，把该账号合并到当前账号
+// 当前账号由 JWT 证明，源账号由密码证明，即双重认证
+func (s *AccountMergeService) MergeSelf(targetID uint, sourceUsername, sourcePassword string) (*AccountMergeResult, error) {
+	if sourceUsername == "" {
+		return nil, ErrUsernameRequired
+	}
+	if sourcePassword == "" {
+		return nil, ErrPasswordRequired
+	}
+	source, err := s.userRepo.GetByUsername(sourceUsername)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if err := hash.VerifyPassword(source.PasswordHash, sourcePassword); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return s.merge(targetID, source.ID, targetID, "self")
+}
+
+func (s *AccountMergeService) merge(actorID, sourceID, targetID uint, mode string) (*AccountMergeResult, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeSameAccount
+	}
+	source, err := s.userRepo.GetByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.mergeRepo.Merge(sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountMergeResult{SourceUserID: sourceID, TargetUserID: targetID, Stats: stats}
+	detail := map[string]interface{}{
+		"mode":            mode,
+		"source_user_id":  sourceID,
+		"source_username": source.Username,
+		"target_user_id":  targetID,
+		"moved":           stats.Moved,
+		"conflicts":       stats.Conflicts,
+	}
+	// 合并已提交，取消舍弃的订阅或审计写入失败只返回错误让调用方感知，不回滚
+	var cancelErr error
+	if stats.DroppedPlan != nil {
+		detail["dropped_plan"] = stats.DroppedPlan
+		cancelErr = NewPlanService(nil, nil, nil, s.provider).cancelSubscription(context.Background(), stats.DroppedPlan)
+	}
+	if err := errors.Join(cancelErr, s.auditRepo.Record(actorID, AuditActionAccountMerge, "user", targetID, detail)); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/blobstore"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ErrInvalidExportFormat 导出格式无效
+var ErrInvalidExportFormat = errors.New("export format must be parquet or csv.gz")
+
+// 导出文件格式
+const (
+	ExportFormatParquet = "parquet" // zstd 压缩
+	ExportFormatCSVGzip = "csv.gz"
+)
+
+// 导出的数据集
+const (
+	DatasetTodos         = "todos"
+	DatasetStatusChanges = "todo_status_changes"
+	DatasetAuthEvents    = "auth_events"
+)
+
+const defaultExportBatchSize = 5000
+
+// ExportOptions 导出参数
+type ExportOptions struct {
+	Format    string        // parquet 或 csv.gz，默认 parquet
+	Prefix    string        // 对象 key 前缀，如 analytics/memogo
+	Lag       time.Duration // 只导出早于 now - Lag 的变更，避免漏掉尚未提交的事务
+	BatchSize int           // 每个文件最多的行数，默认 5000
+}
+
+// ExportResult 一个数据集的本次导出结果
+type ExportResult struct {
+	Dataset   string                `json:"dataset"`
+	Rows      int                   `json:"rows"`
+	Files     []string              `json:"files"`
+	Watermark model.ExportWatermark `json:"watermark"`
+}
+
+// AnalyticsExportService 把待办、状态变更与认证事件增量导出为按日期分区的 Parquet / CSV.gz 文件，供数据仓库加载。
+// 文件路径为 <prefix>/<dataset>/dt=YYYY-MM-DD/<dataset>-<运行时间>-<序号>.<ext>，日期按 UTC 取行的变更时间；
+// 每写完一个文件就推进水位线，中途失败时下次从上一个成功的文件之后继续（至少一次，消费方按 id 与 updated_at 去重）
+type AnalyticsExportService struct {
+	repo  *repository.AnalyticsExportRepository
+	store blobstore.Store
+	opts  ExportOptions
+}
+
+// NewAnalyticsExportService 创建分析导出服务实例
+func NewAnalyticsExportService(repo *repository.AnalyticsExportRepository, store blobstore.Store, opts ExportOptions) (*AnalyticsExportService, error) {
+	switch opts.Format {
+	case "":
+		opts.Format = ExportFormatParquet
+	case ExportFormatParquet, ExportFormatCSVGzip:
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultExportBatchSize
+	}
+	if opts.Lag < 0 {
+		opts.Lag = 0
+	}
+	return &AnalyticsExportService{repo: repo, store: store, opts: opts}, nil
+}
+
+// Run 导出所有数据集自上次以来的变更；某个数据集失败不影响其它数据集，返回第一个错误
+func (s *AnalyticsExportService) Run(ctx context.Context, now time.Time) ([]ExportResult, error) {
+	run := exportRun{
+		stamp: now.UTC().Format("20060102T150405Z"),
+		until: now.Add(-s.opts.Lag),
+	}
+	var firstErr error
+	results := make([]ExportResult, 0, 3)
+	for _, export := range []func(context.Context, exportRun) (*ExportResult, error){
+		s.exportTodos, s.exportStatusChanges, s.exportAuthEvents,
+	} {
+		result, err := export(ctx, run)
+		if result != nil {
+			results = append(results, *result)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return results, firstErr
+}
+
+type exportRun struct {
+	stamp string    // 本次运行的时间戳，用于文件名
+	until time.Time // 只导出此时间之前的变更
+}
+
+func (s *AnalyticsExportService) exportTodos(ctx context.Context, run exportRun) (*ExportResult, error) {
+	wm, err := s.repo.GetWatermark(DatasetTodos)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExportResult{Dataset: DatasetTodos, Files: []string{}}
+	for part := 1; ; part++ {
+		todos, err := s.repo.TodosChangedAfter(wm.LastTime, wm.LastID, run.until, s.opts.BatchSize)
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(todos) == 0 {
+			return s.finish(result, wm), nil
+		}
+		rows := make([]todoExportRow, 0, len(todos))
+		for i := range todos {
+			rows = append(rows, newTodoExportRow(&todos[i]))
+		}
+		files, err := writePartitioned(ctx, s, DatasetTodos, run, part, rows, func(r todoExportRow) time.Time { return r.UpdatedAt })
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		last := todos[len(todos)-1]
+		wm.LastTime, wm.LastID = last.UpdatedAt, last.ID
+		if err := s.advance(result, wm, files, len(todos)); err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(todos) < s.opts.BatchSize {
+			return s.finish(result, wm), nil
+		}
+	}
+}
+
+func (s *AnalyticsExportService) exportStatusChanges(ctx context.Context, run exportRun) (*ExportResult, error) {
+	wm, err := s.repo.GetWatermark(DatasetStatusChanges)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExportResult{Dataset: DatasetStatusChanges, Files: []string{}}
+	for part := 1; ; part++ {
+		list, err := s.repo.StatusChangesAfter(wm.LastID, run.until, s.opts.BatchSize)
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(list) == 0 {
+			return s.finish(result, wm), nil
+		}
+		rows := make([]statusChangeExportRow, 0, len(list))
+		for i := range list {
+			rows = append(rows, newStatusChangeExportRow(&list[i]))
+		}
+		files, err := writePartitioned(ctx, s, DatasetStatusChanges, run, part, rows, func(r statusChangeExportRow) time.Time { return r.CreatedAt })
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		last := list[len(list)-1]
+		wm.LastTime, wm.LastID = last.CreatedAt, last.ID
+		if err := s.advance(result, wm, files, len(list)); err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(list) < s.opts.BatchSize {
+			return s.finish(result, wm), nil
+		}
+	}
+}
+
+func (s *AnalyticsExportService) exportAuthEvents(ctx context.Context, run exportRun) (*ExportResult, error) {
+	wm, err := s.repo.GetWatermark(DatasetAuthEvents)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExportResult{Dataset: DatasetAuthEvents, Files: []string{}}
+	for part := 1; ; part++ {
+		list, err := s.repo.AuthEventsAfter(wm.LastID, run.until, s.opts.BatchSize)
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(list) == 0 {
+			return s.finish(result, wm), nil
+		}
+		rows := make([]authEventExportRow, 0, len(list))
+		for i := range list {
+			rows = append(rows, newAuthEventExportRow(&list[i]))
+		}
+		files, err := writePartitioned(ctx, s, DatasetAuthEvents, run, part, rows, func(r authEventExportRow) time.Time { return r.CreatedAt })
+		if err != nil {
+			return s.finish(result, wm), err
+		}
+		last := list[len(list)-1]
+		wm.LastTime, wm.LastID = last.CreatedAt, last.ID
+		if err := s.advance(result, wm, files, len(list)); err != nil {
+			return s.finish(result, wm), err
+		}
+		if len(list) < s.opts.BatchSize {
+			return s.finish(result, wm), nil
+		}
+	}
+}
+
+// advance 文件写完后推进水位线
+func (s *AnalyticsExportService) advance(result *ExportResult, wm *model.ExportWatermark, files []string, rows int) error {
+	wm.Rows += int64(rows)
+	if err := s.repo.SaveWatermark(wm); err != nil {
+		return err
+	}
+	result.Rows += rows
+	result.Files = append(result.Files, files...)
+	return nil
+}
+
+func (s *AnalyticsExportService) finish(result *ExportResult, wm *model.ExportWatermark) *ExportResult {
+	result.Watermark = *wm
+	return result
+}
+
+// exportRow 导出行：Parquet 按结构体标签编码，CSV 按 csvHeader / csvRecord 编码
+type exportRow interface {
+	csvHeader() []string
+	csvRecord() []string
+}
+
+// writePartitioned 把一批行按日期分区写成文件，返回写入的 key
+func writePartitioned[T exportRow](ctx context.Context, s *AnalyticsExportService, dataset string, run exportRun, part int, rows []T, at func(T) time.Time) ([]string, error) {
+	byDate := map[string][]T{}
+	var dates []string
+	for _, r := range rows {
+		dt := at(r).UTC().Format(time.DateOnly)
+		if _, ok := byDate[dt]; !ok {
+			dates = append(dates, dt)
+		}
+		byDate[dt] = append(byDate[dt], r)
+	}
+	keys := make([]string, 0, len(dates))
+	for _, dt := range dates {
+		data, err := encodeRows(s.opts.Format, byDate[dt])
+		if err != nil {
+			return keys, err
+		}
+		key := fmt.Sprintf("%s/dt=%s/%s-%s-%04d.%s", dataset, dt, dataset, run.stamp, part, s.opts.Format)
+		if s.opts.Prefix != "" {
+			key = s.opts.Prefix + "/" + key
+		}
+		if err := s.store.Put(ctx, key, data); err != nil {
+			return keys, fmt.Errorf("write %s: %w", key, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func encodeRows[T exportRow](format string, rows []T) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case ExportFormatParquet:
+		w := parquet.NewGenericWriter[T](&buf, parquet.Compression(&parquet.Zstd))
+		if _, err := w.Write(rows); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case ExportFormatCSVGzip:
+		gz := gzip.NewWriter(&buf)
+		w := csv.NewWriter(gz)
+		var zero T
+		if err := w.Write(zero.csvHeader()); err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if err := w.Write(r.csvRecord()); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+	return buf.Bytes(), nil
+}
+
+// todoExportRow 待办的导出行：不含正文，已删除的待办带 deleted_at
+type todoExportRow struct {
+	ID         int64      `parquet:"id"`
+	UserID     int64      `parquet:"user_id"`
+	ProjectID  *int64     `parquet:"project_id,optional"`
+	Title      string     `parquet:"title"`
+	Status     int32      `parquet:"status"`
+	GtdList    string     `parquet:"gtd_list"`
+	Estimate   int64      `parquet:"estimate"`
+	Recurrence string     `parquet:"recurrence"`
+	StartTime  *time.Time `parquet:"start_time,optional,timestamp(millisecond)"`
+	EndTime    *time.Time `parquet:"end_time,optional,timestamp(millisecond)"`
+	DueTime    *time.Time `parquet:"due_time,optional,timestamp(millisecond)"`
+	Reschedule int64      `parquet:"reschedule_count"`
+	CreatedAt  time.Time  `parquet:"created_at,timestamp(millisecond)"`
+	UpdatedAt  time.Time  `parquet:"updated_at,timestamp(millisecond)"`
+	DeletedAt  *time.Time `parquet:"deleted_at,optional,timestamp(millisecond)"`
+}
+
+func newTodoExportRow(t *model.Todo) todoExportRow {
+	row := todoExportRow{
+		ID:         int64(t.ID),
+		UserID:     int64(t.UserID),
+		Title:      t.Title,
+		Status:     t.Status,
+		GtdList:    t.GtdList,
+		Estimate:   int64(t.Estimate),
+		Recurrence: t.Recurrence,
+		StartTime:  utcPtr(t.StartTime),
+		EndTime:    utcPtr(t.EndTime),
+		DueTime:    utcPtr(t.DueTime),
+		Reschedule: int64(t.RescheduleCount),
+		CreatedAt:  t.CreatedAt.UTC(),
+		UpdatedAt:  t.UpdatedAt.UTC(),
+	}
+	if t.ProjectID != nil {
+		id := int64(*t.ProjectID)
+		row.ProjectID = &id
+	}
+	if t.DeletedAt.Valid {
+		row.DeletedAt = utcPtr(&t.DeletedAt.Time)
+	}
+	return row
+}
+
+func (todoExportRow) csvHeader() []string {
+	return []string{"id", "user_id", "project_id", "title", "status", "gtd_list", "estimate", "recurrence",
+		"start_time", "end_time", "due_time", "reschedule_count", "created_at", "updated_at", "deleted_at"}
+}
+
+func (r todoExportRow) csvRecord() []string {
+	projectID := ""
+	if r.ProjectID != nil {
+		projectID = strconv.FormatInt(*r.ProjectID, 10)
+	}
+	return []string{
+		strconv.FormatInt(r.ID, 10), strconv.FormatInt(r.UserID, 10), projectID, r.Title,
+		strconv.Itoa(int(r.Status)), r.GtdList, strconv.FormatInt(r.Estimate, 10), r.Recurrence,
+		csvTime(r.StartTime), csvTime(r.EndTime), csvTime(r.DueTime), strconv.FormatInt(r.Reschedule, 10),
+		csvTime(&r.CreatedAt), csvTime(&r.UpdatedAt), csvTime(r.DeletedAt),
+	}
+}
+
+// statusChangeExportRow 状态变更的导出行
+type statusChangeExportRow struct {
+	ID         int64     `parquet:"id"`
+	UserID     int64     `parquet:"user_id"`
+	TodoID     int64     `parquet:"todo_id"`
+	FromStatus int32     `parquet:"from_status"`
+	ToStatus   int32     `parquet:"to_status"`
+	CreatedAt  time.Time `parquet:"created_at,timestamp(millisecond)"`
+}
+
+func newStatusChangeExportRow(c *model.TodoStatusChange) statusChangeExportRow {
+	return statusChangeExportRow{
+		ID:         int64(c.ID),
+		UserID:     int64(c.UserID),
+		TodoID:     int64(c.TodoID),
+		FromStatus: c.FromStatus,
+		ToStatus:   c.ToStatus,
+		CreatedAt:  c.CreatedAt.UTC(),
+	}
+}
+
+func (statusChangeExportRow) csvHeader() []string {
+	return []string{"id", "user_id", "todo_id", "from_status", "to_status", "created_at"}
+}
+
+func (r statusChangeExportRow) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10), strconv.FormatInt(r.UserID, 10), strconv.FormatInt(r.TodoID, 10),
+		strconv.Itoa(int(r.FromStatus)), strconv.Itoa(int(r.ToStatus)), csvTime(&r.CreatedAt),
+	}
+}
+
+// authEventExportRow 认证事件的导出行：不含用户名，失败登录无法关联用户时 user_id 为 0
+type authEventExportRow struct {
+	ID        int64     `parquet:"id"`
+	UserID    int64     `parquet:"user_id"`
+	Event     string    `parquet:"event"`
+	IP        string    `parquet:"ip"`
+	UserAgent string    `parquet:"user_agent"`
+	CreatedAt time.Time `parquet:"created_at,timestamp(millisecond)"`
+}
+
+func newAuthEventExportRow(e *model.AuthEvent) authEventExportRow {
+	return authEventExportRow{
+		ID:        int64(e.ID),
+		UserID:    int64(e.UserID),
+		Event:     e.Event,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+		CreatedAt: e.CreatedAt.UTC(),
+	}
+}
+
+func (authEventExportRow) csvHeader() []string {
+	return []string{"id", "user_id", "event", "ip", "user_agent", "created_at"}
+}
+
+func (r authEventExportRow) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10), strconv.FormatInt(r.UserID, 10), r.Event, r.IP, r.UserAgent, csvTime(&r.CreatedAt),
+	}
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// csvTime CSV 中的时间统一为 UTC RFC 3339（毫秒），空值为空串
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
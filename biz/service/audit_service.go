@@ -0,0 +1,26 @@
+package service
+
+import (
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+// AuditService 审计日志查询
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// List 按 id 倒序查询审计日志（游标分页，limit 默认 20、最大 100）
+func (s *AuditService) List(f repository.AuditLogFilter) ([]model.AuditLog, error) {
+	if f.Limit <= 0 {
+		f.Limit = 20
+	} else if f.Limit > 100 {
+		f.Limit = 100
+	}
+	return s.auditRepo.List(f)
+}
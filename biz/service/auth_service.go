@@ -125,6 +125,11 @@ func (s *AuthService) RefreshToken(refreshToken string) (newAccessToken, newRefr
 		return "", "", err
 	}
 
+	// 令牌签发早于吊销时间（如账号合并）时拒绝刷新
+	if user.TokensRevokedAt != nil && claims.OrigIat <= user.TokensRevokedAt.Unix() {
+		return "", "", jwtPkg.ErrInvalidToken
+	}
+
 	// 生成新的令牌对
 	newAccessToken, newRefreshToken, err = jwtPkg.GenerateTokenPair(user.ID, user.Username)
 	if err != nil {
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/todoquery"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrInvalidBulkAction 批量操作类型或参数无效
+	ErrInvalidBulkAction = errors.New("invalid bulk action")
+	// ErrBulkTooManyMatches 命中的待办超过单次批量操作的上限
+	ErrBulkTooManyMatches = errors.New("too many todos match the query")
+	// ErrBulkPreviewStale 预览之后命中的待办发生了变化，需要重新预览
+	ErrBulkPreviewStale = errors.New("matching todos changed since preview, preview again")
+)
+
+const (
+	maxBulkTodos   = 1000
+	bulkSampleSize = 10
+	bulkTokenTTL   = 10 * time.Minute
+)
+
+// BulkPreview 批量操作预览：命中数量与前几条样例；有命中时附带确认令牌，凭令牌在有效期内执行一次
+type BulkPreview struct {
+	Query     string           `json:"query"`
+	Action    model.BulkAction `json:"action"`
+	Matched   int64            `json:"matched"`
+	Sample    []model.Todo     `json:"sample"`
+	Token     string           `json:"token,omitempty"`
+	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+}
+
+// BulkUpdateService 按筛选表达式批量操作待办
+type BulkUpdateService struct {
+	bulkRepo    *repository.BulkUpdateRepository
+	todoRepo    *repository.TodoRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewBulkUpdateService 创建批量操作服务实例
+func NewBulkUpdateService(bulkRepo *repository.BulkUpdateRepository, todoRepo *repository.TodoRepository, projectRepo *repository.ProjectRepository) *BulkUpdateService {
+	return &BulkUpdateService{bulkRepo: bulkRepo, todoRepo: todoRepo, projectRepo: projectRepo}
+}
+
+// Preview 解析筛选表达式并试运行：返回命中数量、样例与确认令牌，不修改任何待办
+func (s *BulkUpdateService) Preview(userID uint, query string, action model.BulkAction, now time.Time) (*BulkPreview, error) {
+	terms, err := todoquery.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if action, err = s.checkAction(userID, action); err != nil {
+		return nil, err
+	}
+	ids, err := s.match(userID, terms, now)
+	if err != nil {
+		return nil, err
+	}
+	preview := &BulkPreview{Query: todoquery.Format(terms), Action: action, Matched: int64(len(ids)), Sample: []model.Todo{}}
+	if len(ids) == 0 {
+		return preview, nil
+	}
+	if preview.Sample, err = s.todoRepo.ListByIDs(userID, ids[:min(len(ids), bulkSampleSize)]); err != nil {
+		return nil, err
+	}
+	token, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	op := &model.BulkUpdate{
+		UserID:      userID,
+		Token:       token,
+		Query:       preview.Query,
+		Action:      action,
+		Matched:     len(ids),
+		Fingerprint: fingerprintIDs(ids),
+		ExpiresAt:   now.Add(bulkTokenTTL),
+	}
+	if err := s.bulkRepo.Create(op); err != nil {
+		return nil, err
+	}
+	preview.Token, preview.ExpiresAt = token, &op.ExpiresAt
+	return preview, nil
+}
+
+// Execute 凭预览的确认令牌执行批量操作；命中的待办与预览时不同则拒绝（令牌保留，可重新预览）
+func (s *BulkUpdateService) Execute(userID uint, token string, now time.Time) (*model.BulkUpdate, error) {
+	op, err := s.bulkRepo.GetPending(userID, token, now)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := todoquery.Parse(op.Query)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkAction(userID, op.Action); err != nil {
+		return nil, err
+	}
+	ids, err := s.match(userID, terms, now)
+	if err != nil && !errors.Is(err, ErrBulkTooManyMatches) {
+		return nil, err
+	}
+	if err != nil || fingerprintIDs(ids) != op.Fingerprint {
+		return nil, ErrBulkPreviewStale
+	}
+
+	// 批量完成时与单条完成一样为重复待办生成下一条
+	var recurring []model.Todo
+	if op.Action.Type == model.BulkSetStatus && op.Action.Status == "done" {
+		todos, err := s.todoRepo.ListByIDs(userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range todos {
+			if t.Recurrence != model.RecurrenceNone && t.Status != 1 {
+				recurring = append(recurring, t)
+			}
+		}
+	}
+	shiftDue := func(due time.Time, shift string) time.Time {
+		return ShiftDue(workday.Default(), due, shift)
+	}
+	if _, err := s.bulkRepo.Execute(op, ids, now, shiftDue); err != nil {
+		return nil, err
+	}
+	todoSvc := NewTodoService(s.todoRepo)
+	for i := range recurring {
+		if err := todoSvc.spawnNextOccurrence(&recurring[i]); err != nil {
+			return op, err
+		}
+	}
+	return op, nil
+}
+
+// History 列出最近的批量操作
+func (s *BulkUpdateService) History(userID uint) ([]model.BulkUpdate, error) {
+	return s.bulkRepo.List(userID, 50)
+}
+
+// checkAction 校验并规范化操作参数
+func (s *BulkUpdateService) checkAction(userID uint, action model.BulkAction) (model.BulkAction, error) {
+	out := model.BulkAction{Type: action.Type}
+	switch action.Type {
+	case model.BulkSetStatus:
+		if action.Status != "todo" && action.Status != "done" {
+			return out, fmt.Errorf("%w: status must be todo or done", ErrInvalidBulkAction)
+		}
+		out.Status = action.Status
+	case model.BulkAddTag:
+		tags, err := normalizeTags([]string{action.Tag})
+		if err != nil {
+			return out, err
+		}
+		out.Tag = tags[0]
+	case model.BulkMove:
+		if action.ProjectID != 0 {
+			if _, err := s.projectRepo.GetByID(userID, action.ProjectID); err != nil {
+				return out, err
+			}
+		}
+		out.ProjectID = action.ProjectID
+	case model.BulkDelete:
+	case model.BulkSetDue:
+		if action.DueTime < 0 {
+			return out, fmt.Errorf("%w: due_time must be a unix timestamp or 0 to clear", ErrInvalidBulkAction)
+		}
+		out.DueTime = action.DueTime
+	default:
+		return out, fmt.Errorf("%w: type must be set_status, add_tag, move, delete or set_due", ErrInvalidBulkAction)
+	}
+	return out, nil
+}
+
+// match 把筛选项解析为查询条件，返回命中的待办 ID（升序）
+func (s *BulkUpdateService) match(userID uint, terms []todoquery.Term, now time.Time) ([]uint, error) {
+	conds := make([]repository.TodoCond, 0, len(terms))
+	for _, t := range terms {
+		resolved, err := s.resolveTerm(userID, t, now)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, resolved...)
+	}
+	ids, total, err := s.bulkRepo.MatchIDs(userID, conds, maxBulkTodos)
+	if err != nil {
+		return nil, err
+	}
+	if total > maxBulkTodos {
+		return nil, fmt.Errorf("%w: %d matched, at most %d per operation", ErrBulkTooManyMatches, total, maxBulkTodos)
+	}
+	return ids, nil
+}
+
+// resolveTerm 解释筛选项的值：项目展开为含下级的 ID，日期按工作日历所在时区解释
+func (s *BulkUpdateService) resolveTerm(userID uint, t todoquery.Term, now time.Time) ([]repository.TodoCond, error) {
+	c := repository.TodoCond{Field: t.Field, Negate: t.Negate}
+	invalid := func(msg string) error {
+		return fmt.Errorf("%w: %s (%s)", todoquery.ErrInvalidQuery, msg, t)
+	}
+	if t.Negate && (t.Field == todoquery.FieldDue || t.Field == todoquery.FieldCreated) {
+		return nil, invalid("dates cannot be negated")
+	}
+	switch t.Field {
+	case todoquery.FieldKeyword:
+		c.Text = t.Value
+	case todoquery.FieldStatus:
+		switch t.Value {
+		case "todo":
+			c.Status = 0
+		case "done":
+			c.Status = 1
+		default:
+			return nil, invalid("status must be todo or done")
+		}
+	case todoquery.FieldTag:
+		tags, err := normalizeTags([]string{t.Value})
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		c.Text = tags[0]
+	case todoquery.FieldProject:
+		if t.Value != "none" {
+			ids, err := s.projectScope(userID, t.Value)
+			if err != nil {
+				return nil, err
+			}
+			c.ProjectIDs = ids
+		}
+	case todoquery.FieldGtd:
+		if t.Value != model.GtdInbox && t.Value != model.GtdNext && t.Value != model.GtdSomeday {
+			return nil, invalid("gtd must be inbox, next or someday")
+		}
+		c.Text = t.Value
+	case todoquery.FieldIs:
+		if t.Value != "recurring" {
+			return nil, invalid("is only supports recurring")
+		}
+	case todoquery.FieldDue, todoquery.FieldCreated:
+		return resolveDateTerm(c, t, now, invalid)
+	}
+	return []repository.TodoCond{c}, nil
+}
+
+// projectScope 按 ID 或编号前缀找到节点，返回它及其全部下级的 ID
+func (s *BulkUpdateService) projectScope(userID uint, value string) ([]uint, error) {
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	var root uint
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		root = uint(id)
+	} else if key, ok := model.NormalizeProjectKey(value); ok {
+		for _, p := range projects {
+			if p.Key == key {
+				root = p.ID
+			}
+		}
+	}
+	if _, ok := indexProjects(projects)[root]; !ok {
+		return nil, fmt.Errorf("%w: %s", repository.ErrProjectNotFound, value)
+	}
+	return expandProjectIDs(projects, []uint{root}), nil
+}
+
+// resolveDateTerm 日期条件转为左闭右开区间：due:2026-10-20 为当天，due<=2026-10-20 含当天；
+// 另支持 today / tomorrow / yesterday，due:none / due:any（有无截止时间）与 due:overdue（已逾期且未完成）
+func resolveDateTerm(c repository.TodoCond, t todoquery.Term, now time.Time, invalid func(string) error) ([]repository.TodoCond, error) {
+	if t.Field == todoquery.FieldDue && t.Op == todoquery.OpEq {
+		switch t.Value {
+		case "none":
+			return []repository.TodoCond{c}, nil
+		case "any":
+			c.HasDue = true
+			return []repository.TodoCond{c}, nil
+		case "overdue":
+			c.To = &now
+			return []repository.TodoCond{c, {Field: todoquery.FieldStatus, Status: 0}}, nil
+		}
+	}
+	var day time.Time
+	switch t.Value {
+	case "today":
+		day = dayStart(now)
+	case "tomorrow":
+		day = dayStart(now).AddDate(0, 0, 1)
+	case "yesterday":
+		day = dayStart(now).AddDate(0, 0, -1)
+	default:
+		var err error
+		if day, err = time.ParseInLocation(time.DateOnly, t.Value, workday.Location); err != nil {
+			return nil, invalid("date must be YYYY-MM-DD, today, tomorrow or yesterday")
+		}
+	}
+	next := day.AddDate(0, 0, 1)
+	switch t.Op {
+	case todoquery.OpEq:
+		c.From, c.To = &day, &next
+	case todoquery.OpLt:
+		c.To = &day
+	case todoquery.OpLte:
+		c.To = &next
+	case todoquery.OpGt:
+		c.From = &next
+	case todoquery.OpGte:
+		c.From = &day
+	}
+	return []repository.TodoCond{c}, nil
+}
+
+// fingerprintIDs 命中待办 ID 集合的摘要（ids 已升序）
+func fingerprintIDs(ids []uint) string {
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write(strconv.AppendUint(nil, uint64(id), 10))
+		h.Write([]byte{','})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+func newBulkFixture(t *testing.T) (*BulkUpdateService, *repository.TodoRepository) {
+	t.Helper()
+	db := dbtest.Open(t)
+	todoRepo := repository.NewTodoRepository(db)
+	svc := NewBulkUpdateService(repository.NewBulkUpdateRepository(db), todoRepo, repository.NewProjectRepository(db))
+	return svc, todoRepo
+}
+
+func createBulkTodo(t *testing.T, todoRepo *repository.TodoRepository, title, shift string) *model.Todo {
+	t.Helper()
+	todo := &model.Todo{UserID: 1, Title: title, Content: title, DueShift: shift}
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
+
+func TestBulkSetDueShiftsToWorkday(t *testing.T) {
+	svc, todoRepo := newBulkFixture(t)
+	shifted := createBulkTodo(t, todoRepo, "report shifted", model.DueShiftNextWorkday)
+	plain := createBulkTodo(t, todoRepo, "report plain", model.DueShiftNone)
+
+	// 周六：设置了顺延的待办移到下一个工作日，其余保持设置的时间
+	due := time.Date(2026, 10, 17, 9, 0, 0, 0, workday.Location)
+	want := ShiftDue(workday.Default(), due, model.DueShiftNextWorkday)
+	if want.Equal(due) {
+		t.Fatalf("%v should not be a workday", due)
+	}
+	now := time.Now()
+	preview, err := svc.Preview(1, "report", model.BulkAction{Type: model.BulkSetDue, DueTime: due.Unix()}, now)
+	if err != nil || preview.Matched != 2 {
+		t.Fatalf("preview = %+v, %v", preview, err)
+	}
+	op, err := svc.Execute(1, preview.Token, now)
+	if err != nil || op.Affected != 2 {
+		t.Fatalf("execute = %+v, %v", op, err)
+	}
+
+	for _, tt := range []struct {
+		todo *model.Todo
+		want time.Time
+	}{{shifted, want}, {plain, due}} {
+		got, err := todoRepo.GetByID(1, tt.todo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.DueTime == nil || !got.DueTime.Equal(tt.want) {
+			t.Errorf("%s due = %v, want %v", got.Title, got.DueTime, tt.want)
+		}
+		if got.RecurrenceAnchor == nil || !got.RecurrenceAnchor.Equal(due) {
+			t.Errorf("%s recurrence anchor = %v, want %v", got.Title, got.RecurrenceAnchor, due)
+		}
+	}
+}
+
+func TestBulkExecuteToken(t *testing.T) {
+	svc, todoRepo := newBulkFixture(t)
+	createBulkTodo(t, todoRepo, "report a", "")
+	createBulkTodo(t, todoRepo, "report b", "")
+	createBulkTodo(t, todoRepo, "unrelated", "")
+	now := time.Now()
+	done := model.BulkAction{Type: model.BulkSetStatus, Status: "done"}
+
+	preview, err := svc.Preview(1, "report", done, now)
+	if err != nil || preview.Matched != 2 || preview.Token == "" {
+		t.Fatalf("preview = %+v, %v", preview, err)
+	}
+
+	// 预览后又有待办命中：拒绝执行，令牌保留
+	extra := createBulkTodo(t, todoRepo, "report c", "")
+	if _, err := svc.Execute(1, preview.Token, now); !errors.Is(err, ErrBulkPreviewStale) {
+		t.Fatalf("execute after matches changed = %v, want ErrBulkPreviewStale", err)
+	}
+	if _, err := todoRepo.DeleteOne(1, extra.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 令牌只属于创建它的用户，过期后不可用
+	if _, err := svc.Execute(2, preview.Token, now); !errors.Is(err, repository.ErrBulkUpdateNotFound) {
+		t.Fatalf("execute by another user = %v, want ErrBulkUpdateNotFound", err)
+	}
+	if _, err := svc.Execute(1, preview.Token, now.Add(bulkTokenTTL+time.Second)); !errors.Is(err, repository.ErrBulkUpdateNotFound) {
+		t.Fatalf("execute after expiry = %v, want ErrBulkUpdateNotFound", err)
+	}
+
+	op, err := svc.Execute(1, preview.Token, now)
+	if err != nil || op.Affected != 2 || op.ExecutedAt == nil {
+		t.Fatalf("execute = %+v, %v", op, err)
+	}
+	// 令牌只能使用一次
+	if _, err := svc.Execute(1, preview.Token, now); !errors.Is(err, repository.ErrBulkUpdateNotFound) {
+		t.Fatalf("replayed execute = %v, want ErrBulkUpdateNotFound", err)
+	}
+	if _, total, _ := todoRepo.ListTodos(1, "done", nil, repository.TodoSortCreated, 1, 10); total != 2 {
+		t.Errorf("done todos = %d, want 2", total)
+	}
+
+	// 没有命中时不发放令牌
+	if empty, err := svc.Preview(1, "nothing-matches", done, now); err != nil || empty.Matched != 0 || empty.Token != "" {
+		t.Errorf("empty preview = %+v, %v", empty, err)
+	}
+}
+
+func TestFingerprintIDs(t *testing.T) {
+	if fingerprintIDs([]uint{1, 2}) != fingerprintIDs([]uint{1, 2}) {
+		t.Error("same ids should have the same fingerprint")
+	}
+	for _, other := range [][]uint{{12}, {1}, {1, 2, 3}, nil} {
+		if fingerprintIDs([]uint{1, 2}) == fingerprintIDs(other) {
+			t.Errorf("fingerprint of [1 2] equals fingerprint of %v", other)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据，精简镜像中也能解析用户时区
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrInvalidDateRange 日期范围非法
+	ErrInvalidDateRange = errors.New("invalid date range")
+	// ErrInvalidTimezone 时区名无效
+	ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Asia/Shanghai")
+)
+
+// maxCalendarRangeDays 单次查询日历的最大天数
+const maxCalendarRangeDays = 366
+
+// 通知类型（用于节假日跳过判断）
+const (
+	NotifyReminder = "reminder"
+)
+
+// CalendarSettingsUpdate 节假日相关设置的部分更新
+type CalendarSettingsUpdate struct {
+	SkipHolidayReminders *bool
+	SkipHolidayDigests   *bool
+	Timezone             *string
+}
+
+// CalendarService 工作日日历与节假日设置
+type CalendarService struct {
+	settingRepo *repository.UserSettingRepository
+}
+
+// NewCalendarService 创建日历服务实例
+func NewCalendarService(settingRepo *repository.UserSettingRepository) *CalendarService {
+	return &CalendarService{settingRepo: settingRepo}
+}
+
+// Days 列出 [from, to] 的日历信息
+func (s *CalendarService) Days(from, to time.Time) ([]workday.Day, error) {
+	if to.Before(from) || to.Sub(from) > maxCalendarRangeDays*24*time.Hour {
+		return nil, ErrInvalidDateRange
+	}
+	return workday.Default().Range(from, to), nil
+}
+
+// NextWorkday 返回 date 之后第 n 个工作日
+func (s *CalendarService) NextWorkday(date time.Time, n int) workday.Day {
+	cal := workday.Default()
+	return cal.Day(cal.NextWorkday(date, n))
+}
+
+// GetSettings 获取节假日设置
+func (s *CalendarService) GetSettings(userID uint) (*model.UserSetting, error) {
+	return s.settingRepo.Get(userID)
+}
+
+// UpdateSettings 更新节假日与时区设置
+func (s *CalendarService) UpdateSettings(userID uint, in CalendarSettingsUpdate) (*model.UserSetting, error) {
+	setting, err := s.settingRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	if in.SkipHolidayReminders != nil {
+		setting.SkipHolidayReminders = *in.SkipHolidayReminders
+		columns = append(columns, "skip_holiday_reminders")
+	}
+	if in.SkipHolidayDigests != nil {
+		setting.SkipHolidayDigests = *in.SkipHolidayDigests
+		columns = append(columns, "skip_holiday_digests")
+	}
+	if in.Timezone != nil {
+		if _, err := LoadTimezone(*in.Timezone); err != nil {
+			return nil, err
+		}
+		setting.Timezone = *in.Timezone
+		columns = append(columns, "timezone")
+	}
+	if len(columns) == 0 {
+		return setting, nil
+	}
+	if err := s.settingRepo.Save(setting, columns...); err != nil {
+		return nil, err
+	}
+	return s.settingRepo.Get(userID)
+}
+
+// ShouldNotify 判断在 at 时刻是否向用户发送某类通知（提醒等发送方调用）
+// 用户开启对应的跳过选项且当天不是工作日时返回 false
+func (s *CalendarService) ShouldNotify(userID uint, kind string, at time.Time) (bool, error) {
+	setting, err := s.settingRepo.Get(userID)
+	if err != nil {
+		return false, err
+	}
+	skip := false
+	switch kind {
+	case NotifyReminder:
+		skip = setting.SkipHolidayReminders
+	}
+	if skip && !workday.Default().IsWorkday(at) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// UserLocation 获取用户设置的时区（未设置时为北京时间）
+func (s *CalendarService) UserLocation(userID uint) (*time.Location, error) {
+	setting, err := s.settingRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTimezone(setting.Timezone)
+}
+
+// LoadTimezone 解析 IANA 时区名，空串为北京时间
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return workday.Location, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrChatNotLinked 聊天账号尚未绑定 memogo 用户
+	ErrChatNotLinked = errors.New("chat account is not linked")
+	// ErrInvalidLinkCode 绑定码无效或已过期
+	ErrInvalidLinkCode = errors.New("invalid or expired link code")
+)
+
+const (
+	// chatLinkCodeTTL 绑定码有效期
+	chatLinkCodeTTL = 10 * time.Minute
+	// chatListLimit `/todo list` 最多返回的条数
+	chatListLimit = 20
+)
+
+// ChatReply 斜杠命令的处理结果，由 handler 渲染为具体平台的消息格式
+type ChatReply struct {
+	Text  string
+	Todos []model.Todo // 非空时渲染为带“完成”按钮的列表
+}
+
+// ChatService 聊天斜杠命令服务
+type ChatService struct {
+	chatRepo     *repository.ChatAccountRepository
+	todoRepo     *repository.TodoRepository
+	entitlements *EntitlementService
+}
+
+// NewChatService 创建聊天服务实例
+func NewChatService(chatRepo *repository.ChatAccountRepository, todoRepo *repository.TodoRepository, planRepo *repository.PlanRepository) *ChatService {
+	return &ChatService{chatRepo: chatRepo, todoRepo: todoRepo, entitlements: NewEntitlementService(planRepo)}
+}
+
+// ResolveUser 把聊天账号映射为 memogo 用户 ID
+func (s *ChatService) ResolveUser(provider, teamID, chatUserID string) (uint, error) {
+	account, err := s.chatRepo.Get(provider, teamID, chatUserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrChatAccountNotFound) {
+			return 0, ErrChatNotLinked
+		}
+		return 0, err
+	}
+	if account.UserID == 0 {
+		return 0, ErrChatNotLinked
+	}
+	return account.UserID, nil
+}
+
+// HandleCommand 处理斜杠命令文本，例如 "add 买牛奶"、"list"、"done 42"
+func (s *ChatService) HandleCommand(provider, teamID, chatUserID, text string) (*ChatReply, error) {
+	sub, arg := splitCommand(text)
+
+	// link / help 不要求已绑定
+	switch sub {
+	case "", "help":
+		return &ChatReply{Text: chatHelpText}, nil
+	case "link":
+		return s.issueLinkCode(provider, teamID, chatUserID)
+	}
+
+	userID, err := s.ResolveUser(provider, teamID, chatUserID)
+	if err != nil {
+		if errors.Is(err, ErrChatNotLinked) {
+			return &ChatReply{Text: "Your chat account is not linked to memogo yet. Run `/todo link` to get a link code."}, nil
+		}
+		return nil, err
+	}
+
+	switch sub {
+	case "add":
+		return s.add(userID, arg)
+	case "list":
+		return s.list(userID, arg)
+	case "done":
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || id == 0 {
+			return &ChatReply{Text: "Usage: `/todo done <id>`"}, nil
+		}
+		return s.CompleteTodo(userID, uint(id))
+	default:
+		return &ChatReply{Text: fmt.Sprintf("Unknown command `%s`.\n%s", sub, chatHelpText)}, nil
+	}
+}
+
+// CompleteTodo 将待办标记为完成（斜杠命令与按钮回调共用）
+func (s *ChatService) CompleteTodo(userID, id uint) (*ChatReply, error) {
+	// 经由 TodoService 完成，重复待办会生成下一条
+	affected, err := NewTodoService(s.todoRepo).UpdateTodoStatus(userID, id, 1)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return &ChatReply{Text: fmt.Sprintf("Todo #%d not found.", id)}, nil
+	}
+	return &ChatReply{Text: fmt.Sprintf(":white_check_mark: Todo #%d marked as done.", id)}, nil
+}
+
+// LinkAccount 已登录用户提交绑定码，完成聊天账号绑定
+func (s *ChatService) LinkAccount(provider string, userID uint, code string) (*model.ChatAccount, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrInvalidLinkCode
+	}
+	account, err := s.chatRepo.LinkByCode(provider, code, userID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrChatAccountNotFound) {
+			return nil, ErrInvalidLinkCode
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListAccounts 列出用户已绑定的聊天账号
+func (s *ChatService) ListAccounts(userID uint) ([]model.ChatAccount, error) {
+	return s.chatRepo.ListByUser(userID)
+}
+
+// UnlinkAccount 解除聊天账号绑定
+func (s *ChatService) UnlinkAccount(userID, id uint) (int64, error) {
+	return s.chatRepo.Unlink(userID, id)
+}
+
+func (s *ChatService) add(userID uint, title string) (*ChatReply, error) {
+	if title == "" {
+		return &ChatReply{Text: "Usage: `/todo add <title>`"}, nil
+	}
+	todo := &model.Todo{
+		UserID:  userID,
+		Title:   title,
+		Content: title, // 聊天中只有一行文本，内容与标题相同
+		Status:  0,
+	}
+	if err := s.entitlements.CheckLimit(SubjectForUser(userID), model.LimitTodos, 1); err != nil {
+		if errors.Is(err, ErrPlanLimitReached) {
+			return &ChatReply{Text: "You have reached the todo limit of your plan. Complete or delete some todos, or upgrade your plan."}, nil
+		}
+		return nil, err
+	}
+	if err := s.todoRepo.Create(todo); err != nil {
+		return nil, err
+	}
+	return &ChatReply{Text: fmt.Sprintf("Created todo #%d: %s", todo.ID, todo.Title)}, nil
+}
+
+func (s *ChatService) list(userID uint, filter string) (*ChatReply, error) {
+	status := strings.ToLower(filter)
+	if status == "" {
+		status = "todo"
+	}
+	if status != "todo" && status != "done" && status != "all" {
+		return &ChatReply{Text: "Usage: `/todo list [todo|done|all]`"}, nil
+	}
+
+	todos, total, err := s.todoRepo.ListTodos(userID, status, nil, repository.TodoSortCreated, 1, chatListLimit)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return &ChatReply{Text: "No todos here. :tada:"}, nil
+	}
+	text := fmt.Sprintf("%d todo(s):", total)
+	if total > int64(len(todos)) {
+		text = fmt.Sprintf("Showing %d of %d todo(s):", len(todos), total)
+	}
+	return &ChatReply{Text: text, Todos: todos}, nil
+}
+
+func (s *ChatService) issueLinkCode(provider, teamID, chatUserID string) (*ChatReply, error) {
+	code, err := randomLinkCode()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.chatRepo.SaveLinkCode(provider, teamID, chatUserID, code, time.Now().Add(chatLinkCodeTTL)); err != nil {
+		return nil, err
+	}
+	return &ChatReply{Text: fmt.Sprintf(
+		"Your link code is `%s` (valid for %d minutes). Submit it via `POST /v1/integrations/chat/link` while logged in to memogo.",
+		code, int(chatLinkCodeTTL.Minutes()))}, nil
+}
+
+// splitCommand 拆分子命令与参数
+func splitCommand(text string) (sub, arg string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ""
+	}
+	parts := strings.SplitN(text, " ", 2)
+	sub = strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		arg = strings.TrimSpace(parts[1])
+	}
+	return sub, arg
+}
+
+// randomLinkCode 生成 8 位大写字母数字绑定码（去掉易混淆字符）
+func randomLinkCode() (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = alphabet[int(buf[i])%len(alphabet)]
+	}
+	return string(buf), nil
+}
+
+const chatHelpText = "Usage:\n" +
+	"• `/todo add <title>` create a todo\n" +
+	"• `/todo list [todo|done|all]` list todos\n" +
+	"• `/todo done <id>` mark a todo as done\n" +
+	"• `/todo link` link this chat account to memogo"
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		text     string
+		sub, arg string
+	}{
+		{"", "", ""},
+		{"   ", "", ""},
+		{"list", "list", ""},
+		{"LIST done", "list", "done"},
+		{"add 买牛奶", "add", "买牛奶"},
+		{"  add   buy milk and eggs  ", "add", "buy milk and eggs"},
+		{"Add Call Mom", "add", "Call Mom"},
+		{"done 42", "done", "42"},
+		{"done", "done", ""},
+	}
+	for _, tt := range tests {
+		sub, arg := splitCommand(tt.text)
+		if sub != tt.sub || arg != tt.arg {
+			t.Errorf("splitCommand(%q) = (%q, %q), want (%q, %q)", tt.text, sub, arg, tt.sub, tt.arg)
+		}
+	}
+}
+
+// newLinkedChat 创建聊天服务，并把 slack 账号 T1/U1 绑定到用户 1
+func newLinkedChat(t *testing.T) (*ChatService, *repository.TodoRepository) {
+	t.Helper()
+	conn := dbtest.Open(t)
+	chatRepo := repository.NewChatAccountRepository(conn)
+	todoRepo := repository.NewTodoRepository(conn)
+	if err := chatRepo.SaveLinkCode("slack", "T1", "U1", "LINKCODE", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chatRepo.LinkByCode("slack", "LINKCODE", 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return NewChatService(chatRepo, todoRepo, repository.NewPlanRepository(conn)), todoRepo
+}
+
+func TestChatHandleCommand(t *testing.T) {
+	chat, todoRepo := newLinkedChat(t)
+	run := func(text string) *ChatReply {
+		t.Helper()
+		reply, err := chat.HandleCommand("slack", "T1", "U1", text)
+		if err != nil {
+			t.Fatalf("HandleCommand(%q): %v", text, err)
+		}
+		return reply
+	}
+
+	for _, text := range []string{"", "help"} {
+		if reply := run(text); reply.Text != chatHelpText {
+			t.Errorf("HandleCommand(%q) = %q, want help text", text, reply.Text)
+		}
+	}
+	if reply := run("add"); !strings.HasPrefix(reply.Text, "Usage: `/todo add") {
+		t.Errorf("add without title = %q, want usage", reply.Text)
+	}
+
+	if reply := run("add  buy milk "); !strings.Contains(reply.Text, "buy milk") {
+		t.Fatalf("add = %q", reply.Text)
+	}
+	run("add call mom")
+	todos, _, err := todoRepo.ListTodos(1, "all", nil, repository.TodoSortCreated, 1, 10)
+	if err != nil || len(todos) != 2 {
+		t.Fatalf("ListTodos = %d todos, %v; want 2", len(todos), err)
+	}
+	var milk model.Todo
+	for _, todo := range todos {
+		if todo.Title == "buy milk" {
+			milk = todo
+		}
+	}
+	if milk.ID == 0 || milk.Content != "buy milk" || milk.Status != 0 {
+		t.Fatalf("created todo = %+v", milk)
+	}
+
+	if reply := run("list"); len(reply.Todos) != 2 {
+		t.Errorf("list = %d todos, want 2", len(reply.Todos))
+	}
+	if reply := run("list nope"); !strings.HasPrefix(reply.Text, "Usage: `/todo list") {
+		t.Errorf("list with bad filter = %q, want usage", reply.Text)
+	}
+
+	for _, text := range []string{"done", "done abc", "done 0"} {
+		if reply := run(text); !strings.HasPrefix(reply.Text, "Usage: `/todo done") {
+			t.Errorf("HandleCommand(%q) = %q, want usage", text, reply.Text)
+		}
+	}
+	if reply := run("done 9999"); !strings.Contains(reply.Text, "not found") {
+		t.Errorf("done on missing todo = %q", reply.Text)
+	}
+	if reply := run("done " + strconv.FormatUint(uint64(milk.ID), 10)); !strings.Contains(reply.Text, "marked as done") {
+		t.Fatalf("done = %q", reply.Text)
+	}
+	if reply := run("list done"); len(reply.Todos) != 1 || reply.Todos[0].ID != milk.ID {
+		t.Errorf("list done = %+v, want only #%d", reply.Todos, milk.ID)
+	}
+	if reply := run("list"); len(reply.Todos) != 1 || reply.Todos[0].ID == milk.ID {
+		t.Errorf("list todo = %+v, want the other todo", reply.Todos)
+	}
+
+	if reply := run("frobnicate"); !strings.HasPrefix(reply.Text, "Unknown command `frobnicate`") {
+		t.Errorf("unknown command = %q", reply.Text)
+	}
+}
+
+func TestChatRequiresLink(t *testing.T) {
+	chat, _ := newLinkedChat(t)
+	reply, err := chat.HandleCommand("slack", "T1", "U2", "add sneaky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply.Text, "not linked") {
+		t.Fatalf("unlinked add = %q, want link hint", reply.Text)
+	}
+}
+
+func TestChatRelinkAfterUnlink(t *testing.T) {
+	chat, _ := newLinkedChat(t)
+	accounts, err := chat.ListAccounts(1)
+	if err != nil || len(accounts) != 1 {
+		t.Fatalf("ListAccounts = %v, %v", accounts, err)
+	}
+	if n, err := chat.UnlinkAccount(1, accounts[0].ID); err != nil || n != 1 {
+		t.Fatalf("UnlinkAccount = %d, %v", n, err)
+	}
+	if _, err := chat.ResolveUser("slack", "T1", "U1"); !errors.Is(err, ErrChatNotLinked) {
+		t.Fatalf("ResolveUser after unlink = %v, want ErrChatNotLinked", err)
+	}
+
+	reply, err := chat.HandleCommand("slack", "T1", "U1", "link")
+	if err != nil {
+		t.Fatalf("link after unlink: %v", err)
+	}
+	_, rest, _ := strings.Cut(reply.Text, "`")
+	code, _, _ := strings.Cut(rest, "`")
+	if _, err := chat.LinkAccount("slack", 2, strings.ToLower(code)); err != nil {
+		t.Fatalf("LinkAccount(%q): %v", code, err)
+	}
+	if userID, err := chat.ResolveUser("slack", "T1", "U1"); err != nil || userID != 2 {
+		t.Fatalf("ResolveUser after relink = %d, %v; want 2", userID, err)
+	}
+}
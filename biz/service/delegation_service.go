@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrDelegateRequired 被委托人必填
+	ErrDelegateRequired = errors.New("delegate is required")
+	// ErrInvalidExpectedDate 预计日期无效
+	ErrInvalidExpectedDate = errors.New("expected_date must be YYYY-MM-DD")
+	// ErrDelegationTodoDone 已完成的待办不能设为等待
+	ErrDelegationTodoDone = errors.New("todo is already done")
+)
+
+// DelegationInput 设置等待状态的参数
+type DelegationInput struct {
+	Delegate     string
+	Contact      string
+	ExpectedDate string
+}
+
+// DelegationView 等待中的待办
+type DelegationView struct {
+	model.Delegation
+	Title       string `json:"title"`
+	ProjectID   *uint  `json:"project_id"`
+	Overdue     bool   `json:"overdue"`
+	DaysOverdue int    `json:"days_overdue"`
+}
+
+// FollowUpResult 一轮跟进扫描的结果
+type FollowUpResult struct {
+	Created int `json:"created"` // 生成的跟进待办数
+	Skipped int `json:"skipped"` // 因节假日设置或套餐待办数上限跳过的数量
+}
+
+// DelegationService “等待他人”跟踪
+type DelegationService struct {
+	delegationRepo *repository.DelegationRepository
+	todoRepo       *repository.TodoRepository
+	calendar       *CalendarService
+	entitlements   *EntitlementService
+}
+
+// NewDelegationService 创建委托服务实例
+func NewDelegationService(
+	delegationRepo *repository.DelegationRepository,
+	todoRepo *repository.TodoRepository,
+	settingRepo *repository.UserSettingRepository,
+	planRepo *repository.PlanRepository,
+) *DelegationService {
+	return &DelegationService{
+		delegationRepo: delegationRepo,
+		todoRepo:       todoRepo,
+		calendar:       NewCalendarService(settingRepo),
+		entitlements:   NewEntitlementService(planRepo),
+	}
+}
+
+// Set 把待办设为等待他人（已在等待时更新信息）
+func (s *DelegationService) Set(userID, todoID uint, in DelegationInput) (*DelegationView, error) {
+	delegate := strings.TrimSpace(in.Delegate)
+	if delegate == "" {
+		return nil, ErrDelegateRequired
+	}
+	if _, err := time.Parse(workday.DateLayout, in.ExpectedDate); err != nil {
+		return nil, ErrInvalidExpectedDate
+	}
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if isDone(todo) {
+		return nil, ErrDelegationTodoDone
+	}
+	d := &model.Delegation{
+		UserID:       userID,
+		TodoID:       todoID,
+		Delegate:     truncate(delegate, 100),
+		Contact:      truncate(strings.TrimSpace(in.Contact), 200),
+		ExpectedDate: in.ExpectedDate,
+	}
+	if err := s.delegationRepo.Save(d); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, todoID)
+}
+
+// Get 获取待办的等待信息
+func (s *DelegationService) Get(userID, todoID uint) (*DelegationView, error) {
+	d, err := s.delegationRepo.GetByTodo(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.today(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	v := delegationView(d, todo, today)
+	return &v, nil
+}
+
+// Clear 取消等待状态（已收到结果）
+func (s *DelegationService) Clear(userID, todoID uint) (int64, error) {
+	return s.delegationRepo.Delete(userID, todoID)
+}
+
+// List 列出等待中的未完成待办（按预计日期升序），overdueOnly 只返回已超过预计日期的
+func (s *DelegationService) List(userID uint, overdueOnly bool) ([]DelegationView, error) {
+	list, err := s.delegationRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.todos(userID, list)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.today(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	views := make([]DelegationView, 0, len(list))
+	for i := range list {
+		todo, ok := todos[list[i].TodoID]
+		if !ok || isDone(todo) {
+			continue
+		}
+		v := delegationView(&list[i], todo, today)
+		if !overdueOnly || v.Overdue {
+			views = append(views, v)
+		}
+	}
+	return views, nil
+}
+
+// FollowUp 为超过预计日期的等待项生成跟进待办（由定时任务调用）。
+// 同一等待项在上一条跟进待办完成之前、或距上次跟进不足 repeat 时不再生成；用户设置节假日不提醒时当天跳过
+func (s *DelegationService) FollowUp(ctx context.Context, now time.Time, repeat time.Duration) (*FollowUpResult, error) {
+	// 各时区中最晚的“今天”不超过北京时间的明天
+	list, err := s.delegationRepo.ListExpectedBefore(now.In(workday.Location).AddDate(0, 0, 1).Format(workday.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	result := &FollowUpResult{}
+	for i := range list {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		created, err := s.followUp(&list[i], now, repeat)
+		if err != nil {
+			log.Printf("Delegation follow-up for todo %d failed: %v", list[i].TodoID, err)
+			continue
+		}
+		switch created {
+		case followUpCreated:
+			result.Created++
+		case followUpSkipped:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+const (
+	followUpNone = iota
+	followUpCreated
+	followUpSkipped
+)
+
+func (s *DelegationService) followUp(d *model.Delegation, now time.Time, repeat time.Duration) (int, error) {
+	today, err := s.today(d.UserID, now)
+	if err != nil {
+		return followUpNone, err
+	}
+	if d.ExpectedDate >= today {
+		return followUpNone, nil
+	}
+	if d.LastFollowUpAt != nil && now.Sub(*d.LastFollowUpAt) < repeat {
+		return followUpNone, nil
+	}
+	todo, err := s.todoRepo.GetByID(d.UserID, d.TodoID)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		return followUpNone, nil
+	}
+	if err != nil || isDone(todo) {
+		return followUpNone, err
+	}
+	if d.FollowUpTodoID != nil {
+		prev, err := s.todoRepo.GetByID(d.UserID, *d.FollowUpTodoID)
+		if err == nil && !isDone(prev) {
+			return followUpNone, nil
+		}
+	}
+	ok, err := s.calendar.ShouldNotify(d.UserID, NotifyReminder, now)
+	if err != nil {
+		return followUpNone, err
+	}
+	if !ok {
+		return followUpSkipped, nil
+	}
+	// 已达套餐待办数上限：不记录跟进，腾出额度后的下一轮扫描再生成
+	if err := s.entitlements.CheckLimit(SubjectForUser(d.UserID), model.LimitTodos, 1); err != nil {
+		if errors.Is(err, ErrPlanLimitReached) {
+			return followUpSkipped, nil
+		}
+		return followUpNone, err
+	}
+
+	v := delegationView(d, todo, today)
+	content := fmt.Sprintf("等待 %s 完成「%s」，预计 %s，已逾期 %d 天。", d.Delegate, todo.Title, d.ExpectedDate, v.DaysOverdue)
+	if d.Contact != "" {
+		content += "联系方式：" + d.Contact
+	}
+	followUp := &model.Todo{
+		UserID:    d.UserID,
+		ProjectID: todo.ProjectID,
+		Title:     truncate("跟进 "+d.Delegate+"："+todo.Title, 200),
+		Content:   content,
+		DueTime:   &now,
+	}
+	if err := s.todoRepo.Create(followUp); err != nil {
+		return followUpNone, err
+	}
+	if err := s.delegationRepo.RecordFollowUp(d.ID, followUp.ID, now); err != nil {
+		return followUpNone, err
+	}
+	return followUpCreated, nil
+}
+
+// today 用户时区下 at 所在的日期
+func (s *DelegationService) today(userID uint, at time.Time) (string, error) {
+	loc, err := s.calendar.UserLocation(userID)
+	if err != nil {
+		return "", err
+	}
+	return at.In(loc).Format(workday.DateLayout), nil
+}
+
+func (s *DelegationService) todos(userID uint, list []model.Delegation) (map[uint]*model.Todo, error) {
+	ids := make([]uint, 0, len(list))
+	for _, d := range list {
+		ids = append(ids, d.TodoID)
+	}
+	todos, err := s.todoRepo.ListLinked(userID, ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Todo, len(todos))
+	for i := range todos {
+		byID[todos[i].ID] = &todos[i]
+	}
+	return byID, nil
+}
+
+func delegationView(d *model.Delegation, todo *model.Todo, today string) DelegationView {
+	v := DelegationView{Delegation: *d, Title: todo.Title, ProjectID: todo.ProjectID}
+	if d.ExpectedDate < today {
+		v.Overdue = true
+		expected, _ := time.Parse(workday.DateLayout, d.ExpectedDate)
+		now, _ := time.Parse(workday.DateLayout, today)
+		v.DaysOverdue = int(now.Sub(expected).Hours() / 24)
+	}
+	return v
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrFeatureNotInPlan 当前套餐不包含该功能
+	ErrFeatureNotInPlan = errors.New("feature not included in plan")
+	// ErrPlanLimitReached 已达到套餐上限
+	ErrPlanLimitReached = errors.New("plan limit reached")
+	// ErrUsageNotCounted 该限额项无法自动统计用量，需调用 CheckUsage
+	ErrUsageNotCounted = errors.New("usage of this limit is not tracked")
+)
+
+// 套餐的来源
+const (
+	PlanSourceUser      = "user"      // 分配给用户
+	PlanSourceWorkspace = "workspace" // 分配给用户所在工作区
+	PlanSourceDefault   = "default"   // 默认套餐
+	PlanSourceNone      = "none"      // 没有可用套餐，不受限制
+)
+
+// EntitlementSubject 权益判断的主体
+// 与 FlagContext 相同，目前没有工作区概念，WorkspaceID 由调用方按需填充，为 0 时不参与
+type EntitlementSubject struct {
+	UserID      uint
+	WorkspaceID uint
+}
+
+// SubjectForUser 由用户 ID 构造主体
+func SubjectForUser(userID uint) EntitlementSubject {
+	return EntitlementSubject{UserID: userID}
+}
+
+// ResolvedPlan 主体当前适用的套餐；Plan 为空表示不受限制
+type ResolvedPlan struct {
+	Plan       *model.Plan           `json:"plan"`
+	Source     string                `json:"source"`
+	Assignment *model.PlanAssignment `json:"assignment,omitempty"`
+}
+
+// LimitUsage 限额项的上限与用量
+type LimitUsage struct {
+	Key   string `json:"key"`
+	Limit int    `json:"limit"`
+	Used  *int64 `json:"used"` // 无法统计时为空
+}
+
+// EntitlementSummary 主体的权益概览
+type EntitlementSummary struct {
+	ResolvedPlan
+	Features []string     `json:"features"`
+	Limits   []LimitUsage `json:"limits"`
+}
+
+// EntitlementService 套餐权益判断：功能是否可用、资源数量是否超出上限，供 handler 与 service 调用
+type EntitlementService struct {
+	planRepo *repository.PlanRepository
+}
+
+// NewEntitlementService 创建权益服务实例
+func NewEntitlementService(planRepo *repository.PlanRepository) *EntitlementService {
+	return &EntitlementService{planRepo: planRepo}
+}
+
+// Resolve 按 用户分配 → 工作区分配 → 默认套餐 的顺序确定适用的套餐；
+// 分配已取消、过期或套餐已删除时跳过
+func (s *EntitlementService) Resolve(sub EntitlementSubject) (*ResolvedPlan, error) {
+	now := time.Now()
+	candidates := []struct {
+		subjectType string
+		subjectID   uint
+		source      string
+	}{
+		{model.PlanSubjectUser, sub.UserID, PlanSourceUser},
+		{model.PlanSubjectWorkspace, sub.WorkspaceID, PlanSourceWorkspace},
+	}
+	for _, c := range candidates {
+		if c.subjectID == 0 {
+			continue
+		}
+		a, err := s.planRepo.GetAssignment(c.subjectType, c.subjectID)
+		if errors.Is(err, repository.ErrPlanAssignmentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !a.Effective(now) {
+			continue
+		}
+		plan, err := s.planRepo.GetByCode(a.PlanCode)
+		if errors.Is(err, repository.ErrPlanNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &ResolvedPlan{Plan: plan, Source: c.source, Assignment: a}, nil
+	}
+	plan, err := s.planRepo.GetDefault()
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return &ResolvedPlan{Source: PlanSourceNone}, nil
+	}
+	return &ResolvedPlan{Plan: plan, Source: PlanSourceDefault}, nil
+}
+
+// HasFeature 套餐是否包含功能；不受限制时总是包含
+func (s *EntitlementService) HasFeature(sub EntitlementSubject, feature string) (bool, error) {
+	resolved, err := s.Resolve(sub)
+	if err != nil {
+		return false, err
+	}
+	return resolved.Plan == nil || slices.Contains(resolved.Plan.Features, feature), nil
+}
+
+// CheckFeature 套餐不包含功能时返回 ErrFeatureNotInPlan
+func (s *EntitlementService) CheckFeature(sub EntitlementSubject, feature string) error {
+	ok, err := s.HasFeature(sub, feature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrFeatureNotInPlan, feature)
+	}
+	return nil
+}
+
+// CheckLimit 统计用户当前用量，再新增 adding 个后超出上限时返回 ErrPlanLimitReached
+func (s *EntitlementService) CheckLimit(sub EntitlementSubject, key string, adding int) error {
+	limit, limited, err := s.limit(sub, key)
+	if err != nil || !limited {
+		return err
+	}
+	used, ok, err := s.planRepo.CountUsage(sub.UserID, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUsageNotCounted, key)
+	}
+	return checkUsage(key, limit, used, adding)
+}
+
+// CheckUsage 由调用方给出当前用量（用于仓库无法统计的限额项，如工作区成员数）
+func (s *EntitlementService) CheckUsage(sub EntitlementSubject, key string, used int64, adding int) error {
+	limit, limited, err := s.limit(sub, key)
+	if err != nil || !limited {
+		return err
+	}
+	return checkUsage(key, limit, used, adding)
+}
+
+// Summary 主体适用的套餐、功能与各限额项的用量
+func (s *EntitlementService) Summary(sub EntitlementSubject) (*EntitlementSummary, error) {
+	resolved, err := s.Resolve(sub)
+	if err != nil {
+		return nil, err
+	}
+	summary := &EntitlementSummary{ResolvedPlan: *resolved, Features: []string{}, Limits: []LimitUsage{}}
+	if resolved.Plan == nil {
+		return summary, nil
+	}
+	summary.Features = append(summary.Features, resolved.Plan.Features...)
+	keys := make([]string, 0, len(resolved.Plan.Limits))
+	for key := range resolved.Plan.Limits {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		usage := LimitUsage{Key: key, Limit: resolved.Plan.Limits[key]}
+		used, ok, err := s.planRepo.CountUsage(sub.UserID, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			usage.Used = &used
+		}
+		summary.Limits = append(summary.Limits, usage)
+	}
+	return summary, nil
+}
+
+// limit 限额项的上限；limited 为 false 表示不受限制（没有套餐或套餐未设置该项）
+func (s *EntitlementService) limit(sub EntitlementSubject, key string) (limit int, limited bool, err error) {
+	resolved, err := s.Resolve(sub)
+	if err != nil || resolved.Plan == nil {
+		return 0, false, err
+	}
+	limit, limited = resolved.Plan.Limits[key]
+	return limit, limited, nil
+}
+
+func checkUsage(key string, limit int, used int64, adding int) error {
+	if used+int64(adding) > int64(limit) {
+		return fmt.Errorf("%w: %s (%d)", ErrPlanLimitReached, key, limit)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+// setDefaultPlan 创建或替换默认套餐（代码 free）
+func setDefaultPlan(t *testing.T, planRepo *repository.PlanRepository, limits map[string]int, features ...string) {
+	t.Helper()
+	_, err := planRepo.Update("free", func(p *model.Plan) error {
+		p.Limits, p.Features = limits, features
+		return nil
+	})
+	if errors.Is(err, repository.ErrPlanNotFound) {
+		err = planRepo.Create(&model.Plan{Code: "free", Name: "Free", IsDefault: true, Limits: limits, Features: features})
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIncomingWebhookDeliverPlan(t *testing.T) {
+	db := dbtest.Open(t)
+	planRepo := repository.NewPlanRepository(db)
+	svc := NewIncomingWebhookService(
+		repository.NewIncomingWebhookRepository(db),
+		repository.NewIngestRecordRepository(db),
+		repository.NewTodoRepository(db),
+		repository.NewProjectRepository(db),
+		planRepo,
+	)
+	hook, err := svc.CreateWebhook(1, CreateIncomingWebhookInput{TitleTemplate: "$.title", IdempotencyKeyTemplate: "$.id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 1}, model.FeatureIncomingWebhooks)
+
+	first, err := svc.Deliver(hook.Token, "", []byte(`{"id":"a","title":"First"}`))
+	if err != nil || first.Duplicate {
+		t.Fatalf("first delivery = %+v, %v", first, err)
+	}
+	// 已达上限：新的推送被拒绝，重复投递仍返回之前的待办
+	if _, err := svc.Deliver(hook.Token, "", []byte(`{"id":"b","title":"Second"}`)); !errors.Is(err, ErrPlanLimitReached) {
+		t.Fatalf("delivery over the limit = %v, want ErrPlanLimitReached", err)
+	}
+	again, err := svc.Deliver(hook.Token, "", []byte(`{"id":"a","title":"First"}`))
+	if err != nil || !again.Duplicate || again.TodoID != first.TodoID {
+		t.Fatalf("redelivery at the limit = %+v, %v; want duplicate of #%d", again, err, first.TodoID)
+	}
+
+	// 创建后套餐降级，已有的 webhook 也不再可用
+	setDefaultPlan(t, planRepo, nil)
+	if _, err := svc.Deliver(hook.Token, "", []byte(`{"id":"c","title":"Third"}`)); !errors.Is(err, ErrFeatureNotInPlan) {
+		t.Fatalf("delivery after downgrade = %v, want ErrFeatureNotInPlan", err)
+	}
+}
+
+func TestChatAddPlanLimit(t *testing.T) {
+	chat, todoRepo := newLinkedChat(t)
+	setDefaultPlan(t, chat.entitlements.planRepo, map[string]int{model.LimitTodos: 1})
+
+	if reply, err := chat.HandleCommand("slack", "T1", "U1", "add first"); err != nil || !strings.HasPrefix(reply.Text, "Created todo") {
+		t.Fatalf("add under the limit = %+v, %v", reply, err)
+	}
+	reply, err := chat.HandleCommand("slack", "T1", "U1", "add second")
+	if err != nil || !strings.Contains(reply.Text, "todo limit") {
+		t.Fatalf("add over the limit = %+v, %v; want a limit message", reply, err)
+	}
+	if _, total, _ := todoRepo.ListTodos(1, "all", nil, repository.TodoSortCreated, 1, 10); total != 1 {
+		t.Errorf("todos after hitting the limit = %d, want 1", total)
+	}
+}
+
+func TestTodoTransferAcceptPlanLimit(t *testing.T) {
+	db := dbtest.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	todoRepo := repository.NewTodoRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	alice := &model.User{Username: "alice", PasswordHash: "x"}
+	bob := &model.User{Username: "bob", PasswordHash: "x"}
+	for _, u := range []*model.User{alice, bob} {
+		if err := userRepo.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, title := range []string{"one", "two"} {
+		if err := todoRepo.Create(&model.Todo{UserID: alice.ID, Title: title, Content: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := todoRepo.Create(&model.Todo{UserID: bob.ID, Title: "own", Content: "own"}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewTodoTransferService(
+		repository.NewTodoTransferRepository(db),
+		todoRepo,
+		repository.NewProjectRepository(db),
+		userRepo,
+		repository.NewAuditLogRepository(db),
+		planRepo,
+	)
+	transfer, err := svc.Request(alice.ID, alice.ID, TodoTransferInput{ToUserID: bob.ID, Scope: model.TransferScopeOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bob 已有 1 条，再转入 2 条超出上限 2
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 2})
+	if _, err := svc.Accept(bob.ID, transfer.ID); !errors.Is(err, ErrPlanLimitReached) {
+		t.Fatalf("accept over the limit = %v, want ErrPlanLimitReached", err)
+	}
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 3})
+	if _, err := svc.Accept(bob.ID, transfer.ID); err != nil {
+		t.Fatalf("accept within the limit: %v", err)
+	}
+}
+
+func TestEntitlementResolve(t *testing.T) {
+	planRepo := repository.NewPlanRepository(dbtest.Open(t))
+	svc := NewEntitlementService(planRepo)
+	for _, code := range []string{"basic", "team", "pro"} {
+		if err := planRepo.Create(&model.Plan{Code: code, Name: code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assign := func(subjectType string, subjectID uint, plan, status string, expiresAt *time.Time) {
+		t.Helper()
+		a := &model.PlanAssignment{SubjectType: subjectType, SubjectID: subjectID, PlanCode: plan, Status: status, ExpiresAt: expiresAt}
+		if err := planRepo.SaveAssignment(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolve := func(sub EntitlementSubject) (plan, source string) {
+		t.Helper()
+		resolved, err := svc.Resolve(sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolved.Plan != nil {
+			plan = resolved.Plan.Code
+		}
+		return plan, resolved.Source
+	}
+	sub := EntitlementSubject{UserID: 1, WorkspaceID: 7}
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	steps := []struct {
+		name         string
+		apply        func()
+		plan, source string
+	}{
+		{"no plans", func() {}, "", PlanSourceNone},
+		{"default plan", func() { setDefaultPlan(t, planRepo, nil) }, "free", PlanSourceDefault},
+		{"workspace over default", func() { assign(model.PlanSubjectWorkspace, 7, "team", model.PlanStatusActive, nil) }, "team", PlanSourceWorkspace},
+		{"user over workspace", func() { assign(model.PlanSubjectUser, 1, "pro", model.PlanStatusTrialing, nil) }, "pro", PlanSourceUser},
+		{"past due still applies", func() { assign(model.PlanSubjectUser, 1, "pro", model.PlanStatusPastDue, &future) }, "pro", PlanSourceUser},
+		{"expired user assignment skipped", func() { assign(model.PlanSubjectUser, 1, "pro", model.PlanStatusActive, &past) }, "team", PlanSourceWorkspace},
+		{"canceled workspace assignment skipped", func() { assign(model.PlanSubjectWorkspace, 7, "team", model.PlanStatusCanceled, nil) }, "free", PlanSourceDefault},
+		{"deleted plan skipped", func() {
+			assign(model.PlanSubjectUser, 1, "basic", model.PlanStatusActive, nil)
+			if _, err := planRepo.Delete("basic"); err != nil {
+				t.Fatal(err)
+			}
+		}, "free", PlanSourceDefault},
+	}
+	for _, step := range steps {
+		step.apply()
+		if plan, source := resolve(sub); plan != step.plan || source != step.source {
+			t.Fatalf("%s: Resolve = %q from %s, want %q from %s", step.name, plan, source, step.plan, step.source)
+		}
+	}
+	if plan, source := resolve(SubjectForUser(2)); plan != "free" || source != PlanSourceDefault {
+		t.Errorf("user without assignment = %q from %s, want the default plan", plan, source)
+	}
+}
+
+func TestEntitlementLimits(t *testing.T) {
+	db := dbtest.Open(t)
+	planRepo := repository.NewPlanRepository(db)
+	todoRepo := repository.NewTodoRepository(db)
+	svc := NewEntitlementService(planRepo)
+	sub := SubjectForUser(1)
+
+	// 没有套餐时不受限制，也不统计用量
+	if err := svc.CheckLimit(sub, model.LimitMembers, 1000); err != nil {
+		t.Fatalf("CheckLimit without plans = %v", err)
+	}
+
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 2, model.LimitMembers: 5}, model.FeaturePlannerPDF)
+	for i := 0; i < 2; i++ {
+		if err := todoRepo.Create(&model.Todo{UserID: 1, Title: "t", Content: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"todos at the limit", svc.CheckLimit(sub, model.LimitTodos, 1), ErrPlanLimitReached},
+		{"todos adding nothing", svc.CheckLimit(sub, model.LimitTodos, 0), nil},
+		{"other users' todos not counted", svc.CheckLimit(SubjectForUser(2), model.LimitTodos, 2), nil},
+		{"limit not in plan", svc.CheckLimit(sub, model.LimitProjects, 100), nil},
+		{"usage not countable", svc.CheckLimit(sub, model.LimitMembers, 1), ErrUsageNotCounted},
+		{"usage within the limit", svc.CheckUsage(sub, model.LimitMembers, 4, 1), nil},
+		{"usage over the limit", svc.CheckUsage(sub, model.LimitMembers, 4, 2), ErrPlanLimitReached},
+		{"usage of limit not in plan", svc.CheckUsage(sub, model.LimitAttachments, 1000, 1), nil},
+		{"feature in plan", svc.CheckFeature(sub, model.FeaturePlannerPDF), nil},
+		{"feature not in plan", svc.CheckFeature(sub, model.FeatureIssueSync), ErrFeatureNotInPlan},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) || (tt.want == nil && tt.err != nil) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+
+	// 删除待办后腾出额度
+	todos, _, err := todoRepo.ListTodos(1, "all", nil, repository.TodoSortCreated, 1, 10)
+	if err != nil || len(todos) == 0 {
+		t.Fatalf("ListTodos = %d, %v", len(todos), err)
+	}
+	if _, err := todoRepo.DeleteOne(1, todos[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CheckLimit(sub, model.LimitTodos, 1); err != nil {
+		t.Errorf("CheckLimit after deleting a todo = %v", err)
+	}
+}
+
+func TestGtdClarifyPlanLimit(t *testing.T) {
+	db := dbtest.Open(t)
+	planRepo := repository.NewPlanRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	svc := NewGtdService(
+		repository.NewTodoRepository(db),
+		repository.NewTodoTagRepository(db),
+		projectRepo,
+		repository.NewNoteRepository(db),
+		planRepo,
+	)
+	if err := projectRepo.Create(&model.Project{UserID: 1, Name: "Existing"}); err != nil {
+		t.Fatal(err)
+	}
+	todo, err := svc.Capture(1, "Plan the offsite", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitProjects: 1})
+	if _, err := svc.Clarify(1, todo.ID, ClarifyInput{To: ClarifyProject, ProjectName: "Offsite"}); !errors.Is(err, ErrPlanLimitReached) {
+		t.Fatalf("clarify into a new project over the limit = %v, want ErrPlanLimitReached", err)
+	}
+	if projects, _ := projectRepo.List(1); len(projects) != 1 {
+		t.Errorf("projects after hitting the limit = %d, want 1", len(projects))
+	}
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitProjects: 2})
+	result, err := svc.Clarify(1, todo.ID, ClarifyInput{To: ClarifyProject, ProjectName: "Offsite"})
+	if err != nil || result.Project == nil || result.Project.Name != "Offsite" {
+		t.Fatalf("clarify within the limit = %+v, %v", result, err)
+	}
+}
+
+func TestDelegationFollowUpPlanLimit(t *testing.T) {
+	db := dbtest.Open(t)
+	planRepo := repository.NewPlanRepository(db)
+	todoRepo := repository.NewTodoRepository(db)
+	svc := NewDelegationService(
+		repository.NewDelegationRepository(db),
+		todoRepo,
+		repository.NewUserSettingRepository(db),
+		planRepo,
+	)
+	todo := &model.Todo{UserID: 1, Title: "Vendor contract", Content: "sign"}
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Set(1, todo.ID, DelegationInput{Delegate: "Bob", ExpectedDate: "2026-01-05"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 8, 10, 0, 0, 0, time.UTC)
+
+	// 已达上限时跳过且不记录跟进，腾出额度后下一轮生成
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 1})
+	result, err := svc.FollowUp(context.Background(), now, time.Hour)
+	if err != nil || *result != (FollowUpResult{Skipped: 1}) {
+		t.Fatalf("follow-up at the limit = %+v, %v; want 1 skipped", result, err)
+	}
+	setDefaultPlan(t, planRepo, map[string]int{model.LimitTodos: 2})
+	result, err = svc.FollowUp(context.Background(), now, time.Hour)
+	if err != nil || *result != (FollowUpResult{Created: 1}) {
+		t.Fatalf("follow-up within the limit = %+v, %v; want 1 created", result, err)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrInvalidFlagKey 开关 key 格式无效
+	ErrInvalidFlagKey = errors.New("flag key must match [a-z0-9][a-z0-9._-]*, at most 100 characters")
+	// ErrInvalidRolloutPercentage 灰度百分比超出 0-100
+	ErrInvalidRolloutPercentage = errors.New("percentage must be between 0 and 100")
+	// ErrInvalidFlagRole 定向角色不存在
+	ErrInvalidFlagRole = errors.New("invalid role")
+)
+
+// 审计日志动作
+const (
+	AuditActionFeatureFlagCreate = "feature_flag.create"
+	AuditActionFeatureFlagUpdate = "feature_flag.update"
+	AuditActionFeatureFlagDelete = "feature_flag.delete"
+)
+
+// 评估结果的命中原因
+const (
+	FlagReasonNotFound  = "not_found"
+	FlagReasonDisabled  = "disabled"
+	FlagReasonUser      = "user"
+	FlagReasonRole      = "role"
+	FlagReasonWorkspace = "workspace"
+	FlagReasonRollout   = "rollout"
+	FlagReasonDefault   = "default"
+)
+
+var flagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+
+// FlagContext 开关评估的主体
+// 目前没有工作区概念，WorkspaceID 由调用方按需填充，为 0 时不参与工作区定向
+type FlagContext struct {
+	UserID      uint
+	Role        string
+	WorkspaceID uint
+}
+
+// FlagContextForUser 由用户构造评估主体
+func FlagContextForUser(user *model.User) FlagContext {
+	return FlagContext{UserID: user.ID, Role: user.Role}
+}
+
+// FlagEvaluation 单个开关的评估结果
+type FlagEvaluation struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+	Bucket  int    `json:"bucket"` // 用户在该开关下的灰度桶（0-99）
+}
+
+// FeatureFlagInput 创建或更新开关的参数，nil 字段保持不变（创建时取零值）
+type FeatureFlagInput struct {
+	Description  *string
+	Enabled      *bool
+	Percentage   *int
+	UserIDs      *[]uint
+	Roles        *[]string
+	WorkspaceIDs *[]uint
+}
+
+// FeatureFlagService 功能开关
+type FeatureFlagService struct {
+	flagRepo  *repository.FeatureFlagRepository
+	auditRepo *repository.AuditLogRepository
+}
+
+// NewFeatureFlagService 创建功能开关服务实例
+func NewFeatureFlagService(flagRepo *repository.FeatureFlagRepository, auditRepo *repository.AuditLogRepository) *FeatureFlagService {
+	return &FeatureFlagService{flagRepo: flagRepo, auditRepo: auditRepo}
+}
+
+// CreateFlag 创建开关（管理员）
+func (s *FeatureFlagService) CreateFlag(adminID uint, key string, in FeatureFlagInput) (*model.FeatureFlag, error) {
+	if !flagKeyPattern.MatchString(key) {
+		return nil, ErrInvalidFlagKey
+	}
+	flag := &model.FeatureFlag{Key: key}
+	if err := applyFlagInput(flag, in); err != nil {
+		return nil, err
+	}
+	if err := s.flagRepo.Create(flag); err != nil {
+		return nil, err
+	}
+	return flag, s.auditRepo.Record(adminID, AuditActionFeatureFlagCreate, "feature_flag", flag.ID, flag)
+}
+
+// UpdateFlag 部分更新开关（管理员）
+func (s *FeatureFlagService) UpdateFlag(adminID uint, key string, in FeatureFlagInput) (*model.FeatureFlag, error) {
+	var before model.FeatureFlag
+	flag, err := s.flagRepo.Update(key, func(flag *model.FeatureFlag) error {
+		before = *flag
+		return applyFlagInput(flag, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flag, s.auditRepo.Record(adminID, AuditActionFeatureFlagUpdate, "feature_flag", flag.ID,
+		map[string]interface{}{"before": before, "after": flag})
+}
+
+// DeleteFlag 删除开关（管理员）
+func (s *FeatureFlagService) DeleteFlag(adminID uint, key string) error {
+	flag, err := s.flagRepo.Delete(key)
+	if err != nil {
+		return err
+	}
+	return s.auditRepo.Record(adminID, AuditActionFeatureFlagDelete, "feature_flag", flag.ID, flag)
+}
+
+// ListFlags 列出全部开关
+func (s *FeatureFlagService) ListFlags() ([]model.FeatureFlag, error) {
+	return s.flagRepo.ListAll()
+}
+
+// Evaluate 评估单个开关；开关不存在时视为关闭
+func (s *FeatureFlagService) Evaluate(key string, fc FlagContext) (*FlagEvaluation, error) {
+	flag, err := s.flagRepo.GetByKey(key)
+	if err != nil {
+		if errors.Is(err, repository.ErrFeatureFlagNotFound) {
+			return &FlagEvaluation{Key: key, Reason: FlagReasonNotFound, Bucket: rolloutBucket(key, fc.UserID)}, nil
+		}
+		return nil, err
+	}
+	return EvaluateFlag(flag, fc), nil
+}
+
+// EvaluateAll 评估全部开关，返回 key → 是否开启（供前端按开关渲染）
+func (s *FeatureFlagService) EvaluateAll(fc FlagContext) (map[string]bool, error) {
+	flags, err := s.flagRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(flags))
+	for i := range flags {
+		result[flags[i].Key] = EvaluateFlag(&flags[i], fc).Enabled
+	}
+	return result, nil
+}
+
+// IsEnabled 供 handler 与 service 调用的便捷判断：出错或开关不存在都按关闭处理
+func (s *FeatureFlagService) IsEnabled(key string, fc FlagContext) bool {
+	eval, err := s.Evaluate(key, fc)
+	return err == nil && eval.Enabled
+}
+
+// EvaluateFlag 按规则评估：总开关 → 用户名单 → 角色 → 工作区 → 百分比灰度
+func EvaluateFlag(flag *model.FeatureFlag, fc FlagContext) *FlagEvaluation {
+	eval := &FlagEvaluation{Key: flag.Key, Bucket: rolloutBucket(flag.Key, fc.UserID)}
+	switch {
+	case !flag.Enabled:
+		eval.Reason = FlagReasonDisabled
+	case fc.UserID != 0 && slices.Contains(flag.UserIDs, fc.UserID):
+		eval.Enabled, eval.Reason = true, FlagReasonUser
+	case fc.Role != "" && slices.Contains(flag.Roles, fc.Role):
+		eval.Enabled, eval.Reason = true, FlagReasonRole
+	case fc.WorkspaceID != 0 && slices.Contains(flag.WorkspaceIDs, fc.WorkspaceID):
+		eval.Enabled, eval.Reason = true, FlagReasonWorkspace
+	case flag.Percentage >= 100 || (fc.UserID != 0 && eval.Bucket < flag.Percentage):
+		eval.Enabled, eval.Reason = true, FlagReasonRollout
+	default:
+		eval.Reason = FlagReasonDefault
+	}
+	return eval
+}
+
+// rolloutBucket 按 key 与用户 ID 哈希分桶：同一用户在同一开关下结果稳定，
+// 调高百分比只会新增用户而不会让已开启的用户掉出；不同开关的用户分布互不相关
+func rolloutBucket(key string, userID uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % 100)
+}
+
+func applyFlagInput(flag *model.FeatureFlag, in FeatureFlagInput) error {
+	if in.Percentage != nil {
+		if *in.Percentage < 0 || *in.Percentage > 100 {
+			return ErrInvalidRolloutPercentage
+		}
+		flag.Percentage = *in.Percentage
+	}
+	if in.Roles != nil {
+		for _, role := range *in.Roles {
+			if role != model.RoleUser && role != model.RoleAdmin {
+				return fmt.Errorf("%w: %s", ErrInvalidFlagRole, role)
+			}
+		}
+		flag.Roles = *in.Roles
+	}
+	if in.Description != nil {
+		flag.Description = truncate(*in.Description, 500)
+	}
+	if in.Enabled != nil {
+		flag.Enabled = *in.Enabled
+	}
+	if in.UserIDs != nil {
+		flag.UserIDs = *in.UserIDs
+	}
+	if in.WorkspaceIDs != nil {
+		flag.WorkspaceIDs = *in.WorkspaceIDs
+	}
+	return nil
+}
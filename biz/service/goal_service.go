@@ -0,0 +1,380 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrGoalTitleRequired 目标标题必填
+	ErrGoalTitleRequired = errors.New("goal title is required")
+	// ErrInvalidGoalDate 日期格式无效
+	ErrInvalidGoalDate = errors.New("start_date and target_date must be YYYY-MM-DD")
+	// ErrGoalDateRange 开始日期晚于目标日期
+	ErrGoalDateRange = errors.New("start_date must not be after target_date")
+	// ErrInvalidGoalLink 关联类型无效
+	ErrInvalidGoalLink = errors.New("link type must be todo or project")
+)
+
+// 风险判定结果
+const (
+	GoalNoWork   = "no_work"  // 尚未关联任何待办
+	GoalAchieved = "achieved" // 关联待办已全部完成
+	GoalOnTrack  = "on_track"
+	GoalOverdue  = "overdue" // 已过目标日期仍有剩余
+	GoalBehind   = "behind"  // 按目前的完成速度无法在目标日期前完成
+	GoalStalled  = "stalled" // 时间已过去一段但还没有任何完成
+)
+
+// goalStallThreshold 时间进度超过该比例仍无任何完成即视为停滞
+const goalStallThreshold = 0.25
+
+// maxProjectedDays 预计完成日期最多推算到 100 年后，更远时不给出日期（time.Duration 约 292 年即溢出）
+const maxProjectedDays = 100 * 365
+
+// GoalProgress 目标进度：未加权时每条待办权重为 1
+type GoalProgress struct {
+	TotalTodos  int     `json:"total_todos"`
+	DoneTodos   int     `json:"done_todos"`
+	TotalWeight float64 `json:"total_weight"`
+	DoneWeight  float64 `json:"done_weight"`
+	Percent     float64 `json:"percent"` // 0-100
+}
+
+// GoalRisk 风险评估：比较剩余工作与剩余时间
+type GoalRisk struct {
+	AtRisk          bool    `json:"at_risk"`
+	Reason          string  `json:"reason"`
+	ExpectedPercent float64 `json:"expected_percent"`         // 按时间线性推进应达到的进度
+	ProjectedDate   string  `json:"projected_date,omitempty"` // 按目前速度预计完成的日期
+}
+
+// GoalView 目标及其进度与风险
+type GoalView struct {
+	model.Goal
+	Progress GoalProgress `json:"progress"`
+	Risk     GoalRisk     `json:"risk"`
+}
+
+// GoalTodo 计入目标进度的待办
+type GoalTodo struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	Status    int32  `json:"status"`
+	Estimate  int    `json:"estimate"`
+	ProjectID *uint  `json:"project_id"`
+}
+
+// GoalDetail 目标详情：直接关联的待办、项目与计入进度的全部待办
+type GoalDetail struct {
+	GoalView
+	TodoIDs    []uint     `json:"todo_ids"`
+	ProjectIDs []uint     `json:"project_ids"`
+	Todos      []GoalTodo `json:"todos"`
+}
+
+// GoalInput 创建或更新目标的参数，nil 字段保持不变
+type GoalInput struct {
+	Title            *string
+	Description      *string
+	StartDate        *string
+	TargetDate       *string
+	WeightByEstimate *bool
+}
+
+// GoalService 目标
+type GoalService struct {
+	goalRepo    *repository.GoalRepository
+	todoRepo    *repository.TodoRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewGoalService 创建目标服务实例
+func NewGoalService(goalRepo *repository.GoalRepository, todoRepo *repository.TodoRepository, projectRepo *repository.ProjectRepository) *GoalService {
+	return &GoalService{goalRepo: goalRepo, todoRepo: todoRepo, projectRepo: projectRepo}
+}
+
+// Create 创建目标（start_date 默认今天，target_date 必填）
+func (s *GoalService) Create(userID uint, in GoalInput) (*GoalDetail, error) {
+	goal := &model.Goal{
+		UserID:    userID,
+		StartDate: time.Now().In(workday.Location).Format(workday.DateLayout),
+	}
+	if in.Title == nil {
+		return nil, ErrGoalTitleRequired
+	}
+	if in.TargetDate == nil {
+		return nil, ErrInvalidGoalDate
+	}
+	if err := applyGoalInput(goal, in); err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.Create(goal); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, goal.ID)
+}
+
+// Update 部分更新目标
+func (s *GoalService) Update(userID, id uint, in GoalInput) (*GoalDetail, error) {
+	goal, err := s.goalRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyGoalInput(goal, in); err != nil {
+		return nil, err
+	}
+	_, err = s.goalRepo.UpdateFields(userID, id, map[string]interface{}{
+		"title":              goal.Title,
+		"description":        goal.Description,
+		"start_date":         goal.StartDate,
+		"target_date":        goal.TargetDate,
+		"weight_by_estimate": goal.WeightByEstimate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// Delete 删除目标
+func (s *GoalService) Delete(userID, id uint) (int64, error) {
+	return s.goalRepo.Delete(userID, id)
+}
+
+// Get 获取目标详情
+func (s *GoalService) Get(userID, id uint) (*GoalDetail, error) {
+	goal, err := s.goalRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(goal, projects, time.Now())
+}
+
+// List 列出全部目标及进度（按目标日期升序）
+func (s *GoalService) List(userID uint) ([]GoalView, error) {
+	return s.list(userID, false)
+}
+
+// ListAtRisk 列出有风险的目标：已逾期、按目前速度无法按期完成或停滞
+func (s *GoalService) ListAtRisk(userID uint) ([]GoalView, error) {
+	return s.list(userID, true)
+}
+
+// Link 关联待办或项目（关联项目时包含其全部下级节点中的待办）
+func (s *GoalService) Link(userID, goalID uint, targetType string, targetID uint) (*GoalDetail, error) {
+	if _, err := s.goalRepo.GetByID(userID, goalID); err != nil {
+		return nil, err
+	}
+	switch targetType {
+	case model.GoalLinkTodo:
+		if _, err := s.todoRepo.GetByID(userID, targetID); err != nil {
+			return nil, err
+		}
+	case model.GoalLinkProject:
+		if _, err := s.projectRepo.GetByID(userID, targetID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidGoalLink
+	}
+	link := &model.GoalLink{UserID: userID, GoalID: goalID, TargetType: targetType, TargetID: targetID}
+	if err := s.goalRepo.AddLink(link); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, goalID)
+}
+
+// Unlink 取消关联
+func (s *GoalService) Unlink(userID, goalID uint, targetType string, targetID uint) (*GoalDetail, error) {
+	if targetType != model.GoalLinkTodo && targetType != model.GoalLinkProject {
+		return nil, ErrInvalidGoalLink
+	}
+	if _, err := s.goalRepo.GetByID(userID, goalID); err != nil {
+		return nil, err
+	}
+	if _, err := s.goalRepo.RemoveLink(goalID, targetType, targetID); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, goalID)
+}
+
+func (s *GoalService) list(userID uint, atRiskOnly bool) ([]GoalView, error) {
+	goals, err := s.goalRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	views := make([]GoalView, 0, len(goals))
+	for i := range goals {
+		d, err := s.evaluate(&goals[i], projects, now)
+		if err != nil {
+			return nil, err
+		}
+		if !atRiskOnly || d.Risk.AtRisk {
+			views = append(views, d.GoalView)
+		}
+	}
+	return views, nil
+}
+
+// evaluate 汇总关联待办（项目展开到全部下级）并计算进度与风险
+func (s *GoalService) evaluate(goal *model.Goal, projects []model.Project, now time.Time) (*GoalDetail, error) {
+	links, err := s.goalRepo.ListLinks(goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	d := &GoalDetail{GoalView: GoalView{Goal: *goal}, TodoIDs: []uint{}, ProjectIDs: []uint{}, Todos: []GoalTodo{}}
+	for _, l := range links {
+		if l.TargetType == model.GoalLinkTodo {
+			d.TodoIDs = append(d.TodoIDs, l.TargetID)
+		} else {
+			d.ProjectIDs = append(d.ProjectIDs, l.TargetID)
+		}
+	}
+	todos, err := s.todoRepo.ListLinked(goal.UserID, d.TodoIDs, expandProjectIDs(projects, d.ProjectIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range todos {
+		d.Todos = append(d.Todos, GoalTodo{ID: t.ID, Title: t.Title, Status: t.Status, Estimate: t.Estimate, ProjectID: t.ProjectID})
+	}
+	d.Progress = goalProgress(todos, goal.WeightByEstimate)
+	d.Risk = goalRisk(goal, d.Progress, now)
+	return d, nil
+}
+
+// goalProgress 计算进度；按估算加权时，未估算的待办取已估算待办的平均值（都未估算时为 1）
+func goalProgress(todos []model.Todo, weighted bool) GoalProgress {
+	p := GoalProgress{TotalTodos: len(todos)}
+	fallback := 1.0
+	if weighted {
+		var sum, n float64
+		for _, t := range todos {
+			if t.Estimate > 0 {
+				sum += float64(t.Estimate)
+				n++
+			}
+		}
+		if n > 0 {
+			fallback = sum / n
+		}
+	}
+	for _, t := range todos {
+		w := 1.0
+		if weighted {
+			w = fallback
+			if t.Estimate > 0 {
+				w = float64(t.Estimate)
+			}
+		}
+		p.TotalWeight += w
+		if t.Status == 1 {
+			p.DoneTodos++
+			p.DoneWeight += w
+		}
+	}
+	if p.TotalWeight > 0 {
+		p.Percent = roundPercent(p.DoneWeight / p.TotalWeight)
+	}
+	p.TotalWeight = math.Round(p.TotalWeight*100) / 100
+	p.DoneWeight = math.Round(p.DoneWeight*100) / 100
+	return p
+}
+
+// goalRisk 比较剩余工作与剩余时间：按开始以来的完成速度推算完成日期，晚于目标日期即有风险
+func goalRisk(goal *model.Goal, p GoalProgress, now time.Time) GoalRisk {
+	start, _ := time.ParseInLocation(workday.DateLayout, goal.StartDate, workday.Location)
+	target, _ := time.ParseInLocation(workday.DateLayout, goal.TargetDate, workday.Location)
+	end := target.AddDate(0, 0, 1) // 目标日期当天结束
+
+	elapsed := now.Sub(start)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	expected := 1.0
+	if duration := end.Sub(start); duration > 0 && elapsed < duration {
+		expected = float64(elapsed) / float64(duration)
+	}
+	r := GoalRisk{ExpectedPercent: roundPercent(expected)}
+
+	remaining := p.TotalWeight - p.DoneWeight
+	switch {
+	case p.TotalTodos == 0:
+		r.Reason = GoalNoWork
+	case remaining <= 0:
+		r.Reason = GoalAchieved
+	case !now.Before(end):
+		r.AtRisk, r.Reason = true, GoalOverdue
+	case p.DoneWeight == 0:
+		r.Reason = GoalOnTrack
+		if expected >= goalStallThreshold {
+			r.AtRisk, r.Reason = true, GoalStalled
+		}
+	default:
+		// 先按天数比较再换算成日期：速度很慢时所需天数换算成 time.Duration 会溢出
+		elapsedDays := math.Max(elapsed.Hours()/24, 1)
+		velocity := p.DoneWeight / elapsedDays
+		neededDays := remaining / velocity
+		if neededDays <= maxProjectedDays {
+			projected := now.Add(time.Duration(neededDays * 24 * float64(time.Hour)))
+			r.ProjectedDate = projected.In(workday.Location).Format(workday.DateLayout)
+		}
+		r.Reason = GoalOnTrack
+		if neededDays > end.Sub(now).Hours()/24 {
+			r.AtRisk, r.Reason = true, GoalBehind
+		}
+	}
+	return r
+}
+
+func applyGoalInput(goal *model.Goal, in GoalInput) error {
+	if in.Title != nil {
+		title := strings.TrimSpace(*in.Title)
+		if title == "" {
+			return ErrGoalTitleRequired
+		}
+		goal.Title = truncate(title, 200)
+	}
+	if in.Description != nil {
+		goal.Description = *in.Description
+	}
+	for _, d := range []struct {
+		in  *string
+		out *string
+	}{{in.StartDate, &goal.StartDate}, {in.TargetDate, &goal.TargetDate}} {
+		if d.in == nil {
+			continue
+		}
+		if _, err := time.Parse(workday.DateLayout, *d.in); err != nil {
+			return ErrInvalidGoalDate
+		}
+		*d.out = *d.in
+	}
+	if goal.StartDate > goal.TargetDate {
+		return ErrGoalDateRange
+	}
+	if in.WeightByEstimate != nil {
+		goal.WeightByEstimate = *in.WeightByEstimate
+	}
+	return nil
+}
+
+// roundPercent 比例转为保留一位小数的百分数
+func roundPercent(ratio float64) float64 {
+	return math.Round(ratio*1000) / 10
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/pkg/workday"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+func TestGoalRisk(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, workday.Location)
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format(workday.DateLayout) }
+
+	tests := []struct {
+		name          string
+		start, target int // 相对 now 的天数
+		total, done   float64
+		wantReason    string
+		wantAtRisk    bool
+		wantProjected string
+	}{
+		{"no work", -10, 10, 0, 0, GoalNoWork, false, ""},
+		{"achieved", -10, 10, 5, 5, GoalAchieved, false, ""},
+		{"overdue", -20, -1, 5, 2, GoalOverdue, true, ""},
+		{"not started yet", -1, 30, 5, 0, GoalOnTrack, false, ""},
+		{"stalled", -10, 10, 5, 0, GoalStalled, true, ""},
+		// 10 天完成 5，剩余 5 还需 10 天，目标还有 20 天
+		{"on track", -10, 19, 10, 5, GoalOnTrack, false, day(10)},
+		// 10 天完成 2，剩余 8 还需 40 天，目标只剩 10 天
+		{"behind", -10, 9, 10, 2, GoalBehind, true, day(40)},
+		// 按分钟估算加权：120 天只完成 1 分钟，剩余 20000 分钟需要约 6500 年，换算成 Duration 会溢出
+		{"far too slow to project", -120, 30, 20001, 1, GoalBehind, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := &model.Goal{StartDate: day(tt.start), TargetDate: day(tt.target)}
+			p := GoalProgress{TotalWeight: tt.total, DoneWeight: tt.done}
+			if tt.total > 0 {
+				p.TotalTodos = 1
+			}
+			r := goalRisk(goal, p, now)
+			if r.Reason != tt.wantReason || r.AtRisk != tt.wantAtRisk || r.ProjectedDate != tt.wantProjected {
+				t.Fatalf("goalRisk() = %+v, want reason=%s at_risk=%v projected=%q",
+					r, tt.wantReason, tt.wantAtRisk, tt.wantProjected)
+			}
+		})
+	}
+}
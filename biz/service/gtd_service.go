@@ -0,0 +1,367 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrInvalidTag 标签无效
+	ErrInvalidTag = errors.New("tags must be 1-50 characters without spaces or commas, contexts start with @")
+	// ErrTooManyTags 标签过多
+	ErrTooManyTags = errors.New("a todo can have at most 20 tags")
+	// ErrInvalidClarifyTarget 处理去向无效
+	ErrInvalidClarifyTarget = errors.New("to must be next, someday, project, reference or trash")
+	// ErrClarifyProjectRequired 转为项目时需要 project_id 或 project_name
+	ErrClarifyProjectRequired = errors.New("project_id or project_name is required")
+	// ErrClarifyTodoDone 已完成的待办无需处理
+	ErrClarifyTodoDone = errors.New("todo is already done")
+)
+
+// 处理（clarify）去向
+const (
+	ClarifyNext      = "next"      // 下一步行动
+	ClarifySomeday   = "someday"   // 将来 / 也许
+	ClarifyProject   = "project"   // 需要多步完成：放入已有项目或新建项目，自身成为该项目的下一步行动
+	ClarifyReference = "reference" // 无需行动的参考资料：转为笔记，待办删除
+	ClarifyTrash     = "trash"     // 无需保留：删除
+)
+
+const (
+	maxTagLen     = 50
+	noContextName = "" // 没有情境标签的下一步行动所在分组
+)
+
+// ClarifyInput 处理收集箱条目的参数
+type ClarifyInput struct {
+	To          string
+	ProjectID   uint     // 放入已有项目（next / someday 可选，project 二选一）
+	ProjectName string   // to 为 project 时新建项目
+	ParentID    uint     // 新建项目的上级领域
+	Contexts    []string // 情境标签，nil 表示保持不变
+}
+
+// TaggedTodo 带标签的待办
+type TaggedTodo struct {
+	model.Todo
+	Tags []string `json:"tags"`
+}
+
+// ClarifyResult 处理结果：待办被删除时 Todo 为空
+type ClarifyResult struct {
+	Todo    *TaggedTodo    `json:"todo"`
+	Project *model.Project `json:"project,omitempty"`
+	Note    *NoteDetail    `json:"note,omitempty"`
+}
+
+// NextActionGroup 某个情境下的下一步行动
+type NextActionGroup struct {
+	Context string       `json:"context"` // 空串表示未设置情境
+	Todos   []TaggedTodo `json:"todos"`
+}
+
+// GtdService GTD 工作流：收集箱、处理、下一步行动、将来 / 也许，以及待办标签
+type GtdService struct {
+	todoRepo     *repository.TodoRepository
+	tagRepo      *repository.TodoTagRepository
+	projectRepo  *repository.ProjectRepository
+	projects     *ProjectService
+	notes        *NoteService
+	entitlements *EntitlementService
+}
+
+// NewGtdService 创建 GTD 服务实例
+func NewGtdService(
+	todoRepo *repository.TodoRepository,
+	tagRepo *repository.TodoTagRepository,
+	projectRepo *repository.ProjectRepository,
+	noteRepo *repository.NoteRepository,
+	planRepo *repository.PlanRepository,
+) *GtdService {
+	return &GtdService{
+		todoRepo:     todoRepo,
+		tagRepo:      tagRepo,
+		projectRepo:  projectRepo,
+		projects:     NewProjectService(projectRepo, todoRepo),
+		notes:        NewNoteService(noteRepo, todoRepo),
+		entitlements: NewEntitlementService(planRepo),
+	}
+}
+
+// Capture 快速记录到收集箱，只需标题
+func (s *GtdService) Capture(userID uint, title, content string) (*TaggedTodo, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrTitleRequired
+	}
+	todo := &model.Todo{
+		UserID:  userID,
+		Title:   truncate(title, 200),
+		Content: content,
+		GtdList: model.GtdInbox,
+	}
+	if err := s.todoRepo.Create(todo); err != nil {
+		return nil, err
+	}
+	return &TaggedTodo{Todo: *todo, Tags: []string{}}, nil
+}
+
+// Inbox 收集箱中未处理的条目，最早记录的在前
+func (s *GtdService) Inbox(userID uint) ([]TaggedTodo, error) {
+	return s.listTagged(userID, model.GtdInbox)
+}
+
+// Someday 将来 / 也许清单
+func (s *GtdService) Someday(userID uint) ([]TaggedTodo, error) {
+	return s.listTagged(userID, model.GtdSomeday)
+}
+
+// Clarify 处理一条未完成的待办（通常来自收集箱，也可用于周回顾时重新安排将来 / 也许中的条目）
+func (s *GtdService) Clarify(userID, todoID uint, in ClarifyInput) (*ClarifyResult, error) {
+	if !slices.Contains([]string{ClarifyNext, ClarifySomeday, ClarifyProject, ClarifyReference, ClarifyTrash}, in.To) {
+		return nil, ErrInvalidClarifyTarget
+	}
+	var contexts []string
+	if in.Contexts != nil {
+		var err error
+		if contexts, err = normalizeTags(in.Contexts); err != nil {
+			return nil, err
+		}
+		for _, c := range contexts {
+			if !isContextTag(c) {
+				return nil, ErrInvalidTag
+			}
+		}
+	}
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if isDone(todo) {
+		return nil, ErrClarifyTodoDone
+	}
+
+	switch in.To {
+	case ClarifyTrash:
+		if _, err := s.todoRepo.DeleteOne(userID, todoID); err != nil {
+			return nil, err
+		}
+		return &ClarifyResult{}, nil
+	case ClarifyReference:
+		note, err := s.notes.Create(userID, todo.Title, todo.Content)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.todoRepo.DeleteOne(userID, todoID); err != nil {
+			return nil, err
+		}
+		return &ClarifyResult{Note: note}, nil
+	}
+
+	result := &ClarifyResult{}
+	updates := map[string]interface{}{"gtd_list": model.GtdNext}
+	if in.To == ClarifySomeday {
+		updates["gtd_list"] = model.GtdSomeday
+	}
+	projectID := in.ProjectID
+	if in.To == ClarifyProject && projectID == 0 {
+		name := strings.TrimSpace(in.ProjectName)
+		if name == "" {
+			return nil, ErrClarifyProjectRequired
+		}
+		if err := s.entitlements.CheckLimit(SubjectForUser(userID), model.LimitProjects, 1); err != nil {
+			return nil, err
+		}
+		project, err := s.projects.Create(userID, name, model.ProjectKindProject, "", in.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		result.Project = project
+		projectID = project.ID
+	} else if projectID != 0 {
+		project, err := s.projectRepo.GetByID(userID, projectID)
+		if err != nil {
+			return nil, err
+		}
+		result.Project = project
+	}
+	if projectID != 0 {
+		updates["project_id"] = projectID
+	}
+	if _, err := s.todoRepo.UpdateFields(userID, todoID, updates); err != nil {
+		return nil, err
+	}
+	if contexts != nil {
+		tags, err := s.tagNames(userID, todoID)
+		if err != nil {
+			return nil, err
+		}
+		tags = slices.DeleteFunc(tags, isContextTag)
+		if err := s.setTags(userID, todoID, append(tags, contexts...)); err != nil {
+			return nil, err
+		}
+	}
+	if result.Todo, err = s.tagged(userID, todoID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NextActions 下一步行动按情境分组（一条待办有多个情境时出现在每个分组中，没有情境的在最后一组）；
+// context 非空时只返回该情境
+func (s *GtdService) NextActions(userID uint, context string) ([]NextActionGroup, error) {
+	todos, err := s.listTagged(userID, model.GtdNext)
+	if err != nil {
+		return nil, err
+	}
+	context = strings.ToLower(strings.TrimSpace(context))
+	byContext := map[string][]TaggedTodo{}
+	for _, t := range todos {
+		matched := false
+		for _, tag := range t.Tags {
+			if isContextTag(tag) {
+				byContext[tag] = append(byContext[tag], t)
+				matched = true
+			}
+		}
+		if !matched {
+			byContext[noContextName] = append(byContext[noContextName], t)
+		}
+	}
+	names := make([]string, 0, len(byContext))
+	for name := range byContext {
+		if name != noContextName && (context == "" || name == context) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if _, ok := byContext[noContextName]; ok && context == "" {
+		names = append(names, noContextName)
+	}
+	groups := make([]NextActionGroup, 0, len(names))
+	for _, name := range names {
+		list := byContext[name]
+		// 有截止时间的按截止时间排在前面
+		slices.SortStableFunc(list, func(a, b TaggedTodo) int {
+			switch {
+			case a.DueTime == nil && b.DueTime == nil:
+				return 0
+			case a.DueTime == nil:
+				return 1
+			case b.DueTime == nil:
+				return -1
+			}
+			return a.DueTime.Compare(*b.DueTime)
+		})
+		groups = append(groups, NextActionGroup{Context: name, Todos: list})
+	}
+	return groups, nil
+}
+
+// SetTags 替换待办的全部标签（含情境标签）
+func (s *GtdService) SetTags(userID, todoID uint, names []string) (*TaggedTodo, error) {
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.todoRepo.GetByID(userID, todoID); err != nil {
+		return nil, err
+	}
+	if err := s.setTags(userID, todoID, tags); err != nil {
+		return nil, err
+	}
+	return s.tagged(userID, todoID)
+}
+
+// Tags 用户使用过的标签及待办数量
+func (s *GtdService) Tags(userID uint) ([]repository.TagCount, error) {
+	return s.tagRepo.Counts(userID)
+}
+
+func (s *GtdService) setTags(userID, todoID uint, tags []string) error {
+	if len(tags) > model.MaxTodoTags {
+		return ErrTooManyTags
+	}
+	return s.tagRepo.Replace(userID, todoID, tags)
+}
+
+func (s *GtdService) tagNames(userID, todoID uint) ([]string, error) {
+	tags, err := s.tagRepo.ListByTodos(userID, []uint{todoID})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+func (s *GtdService) tagged(userID, todoID uint) (*TaggedTodo, error) {
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.tagNames(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	return &TaggedTodo{Todo: *todo, Tags: names}, nil
+}
+
+func (s *GtdService) listTagged(userID uint, list string) ([]TaggedTodo, error) {
+	todos, err := s.todoRepo.ListOpenByGtd(userID, list)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(todos))
+	for _, t := range todos {
+		ids = append(ids, t.ID)
+	}
+	tags, err := s.tagRepo.ListByTodos(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byTodo := make(map[uint][]string, len(todos))
+	for _, t := range tags {
+		byTodo[t.TodoID] = append(byTodo[t.TodoID], t.Name)
+	}
+	result := make([]TaggedTodo, 0, len(todos))
+	for _, t := range todos {
+		names := byTodo[t.ID]
+		if names == nil {
+			names = []string{}
+		}
+		result = append(result, TaggedTodo{Todo: t, Tags: names})
+	}
+	return result, nil
+}
+
+// normalizeTags 去除首尾空白、转小写并去重；标签不能含空白或逗号，单独的 @ 无效
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == model.ContextTagPrefix || utf8.RuneCountInString(name) > maxTagLen ||
+			strings.ContainsFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+			return nil, ErrInvalidTag
+		}
+		if !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+	if len(tags) > model.MaxTodoTags {
+		return nil, ErrTooManyTags
+	}
+	return tags, nil
+}
+
+func isContextTag(name string) bool {
+	return strings.HasPrefix(name, model.ContextTagPrefix)
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrHabitNameRequired 习惯名称必填
+	ErrHabitNameRequired = errors.New("habit name is required")
+	// ErrInvalidHabitFrequency 频率无效
+	ErrInvalidHabitFrequency = errors.New("frequency must be daily or weekly")
+	// ErrInvalidTimesPerWeek 每周次数无效
+	ErrInvalidTimesPerWeek = errors.New("times_per_week must be between 1 and 7 for weekly habits")
+	// ErrInvalidHabitDate 日期格式无效
+	ErrInvalidHabitDate = errors.New("date must be YYYY-MM-DD or today")
+	// ErrHabitDateOutOfRange 打卡日期不在开始日期与今天之间
+	ErrHabitDateOutOfRange = errors.New("check-in date must be between the habit's start_date and today")
+)
+
+const (
+	// habitRateDays 每日习惯按最近 30 天计算完成率
+	habitRateDays = 30
+	// habitRateWeeks 每周习惯按最近 4 周计算完成率
+	habitRateWeeks = 4
+	// habitHistoryDays 历史默认返回最近一年
+	habitHistoryDays = 365
+)
+
+// HabitStats 习惯统计（日期均为用户时区下的日期）
+type HabitStats struct {
+	StreakUnit     string  `json:"streak_unit"` // day | week
+	CurrentStreak  int     `json:"current_streak"`
+	LongestStreak  int     `json:"longest_streak"`
+	CompletionRate float64 `json:"completion_rate"` // 0-100
+	CheckedToday   bool    `json:"checked_today"`
+	ThisWeek       int     `json:"this_week"` // 本周（周一起）打卡次数
+	TotalCheckIns  int     `json:"total_check_ins"`
+}
+
+// HabitView 习惯及其统计
+type HabitView struct {
+	model.Habit
+	Stats HabitStats `json:"stats"`
+}
+
+// HabitDay 历史中的一天
+type HabitDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// HabitHistory 习惯的逐日打卡记录，适合绘制热力图
+type HabitHistory struct {
+	HabitID uint       `json:"habit_id"`
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Days    []HabitDay `json:"days"`
+}
+
+// HabitInput 创建或更新习惯的参数，nil 字段保持不变
+type HabitInput struct {
+	Name         *string
+	Frequency    *string
+	TimesPerWeek *int
+	StartDate    *string
+}
+
+// HabitService 习惯打卡
+type HabitService struct {
+	habitRepo   *repository.HabitRepository
+	settingRepo *repository.UserSettingRepository
+}
+
+// NewHabitService 创建习惯服务实例
+func NewHabitService(habitRepo *repository.HabitRepository, settingRepo *repository.UserSettingRepository) *HabitService {
+	return &HabitService{habitRepo: habitRepo, settingRepo: settingRepo}
+}
+
+// Create 创建习惯（start_date 默认用户时区的今天）
+func (s *HabitService) Create(userID uint, in HabitInput) (*HabitView, error) {
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name == nil {
+		return nil, ErrHabitNameRequired
+	}
+	if in.Frequency == nil {
+		return nil, ErrInvalidHabitFrequency
+	}
+	habit := &model.Habit{UserID: userID, StartDate: today}
+	if err := applyHabitInput(habit, in); err != nil {
+		return nil, err
+	}
+	if err := s.habitRepo.Create(habit); err != nil {
+		return nil, err
+	}
+	return s.view(habit, today)
+}
+
+// Update 部分更新习惯
+func (s *HabitService) Update(userID, id uint, in HabitInput) (*HabitView, error) {
+	habit, err := s.habitRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyHabitInput(habit, in); err != nil {
+		return nil, err
+	}
+	if _, err := s.habitRepo.UpdateFields(userID, id, map[string]interface{}{
+		"name":           habit.Name,
+		"frequency":      habit.Frequency,
+		"times_per_week": habit.TimesPerWeek,
+		"start_date":     habit.StartDate,
+	}); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// Delete 删除习惯及其打卡记录
+func (s *HabitService) Delete(userID, id uint) (int64, error) {
+	return s.habitRepo.Delete(userID, id)
+}
+
+// Get 获取习惯及统计
+func (s *HabitService) Get(userID, id uint) (*HabitView, error) {
+	habit, err := s.habitRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(habit, today)
+}
+
+// List 列出全部习惯及统计
+func (s *HabitService) List(userID uint) ([]HabitView, error) {
+	habits, err := s.habitRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]HabitView, 0, len(habits))
+	for i := range habits {
+		v, err := s.view(&habits[i], today)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *v)
+	}
+	return views, nil
+}
+
+// CheckIn 打卡（date 为 YYYY-MM-DD 或 today，按用户时区；不能早于开始日期或晚于今天）
+func (s *HabitService) CheckIn(userID, id uint, date string) (*HabitView, error) {
+	habit, today, date, err := s.checkInDate(userID, id, date)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.habitRepo.CheckIn(&model.HabitCheckIn{UserID: userID, HabitID: habit.ID, Date: date}); err != nil {
+		return nil, err
+	}
+	return s.view(habit, today)
+}
+
+// UndoCheckIn 取消某天的打卡
+func (s *HabitService) UndoCheckIn(userID, id uint, date string) (*HabitView, error) {
+	habit, today, date, err := s.checkInDate(userID, id, date)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.habitRepo.UndoCheckIn(habit.ID, date); err != nil {
+		return nil, err
+	}
+	return s.view(habit, today)
+}
+
+// History 返回 [from, to] 的逐日打卡次数（默认截至今天的最近一年，最长 366 天）
+func (s *HabitService) History(userID, id uint, from, to string) (*HabitHistory, error) {
+	habit, err := s.habitRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+	if to == "" {
+		to = today
+	}
+	end, err := time.Parse(workday.DateLayout, to)
+	if err != nil {
+		return nil, ErrInvalidHabitDate
+	}
+	start := end.AddDate(0, 0, 1-habitHistoryDays)
+	if from != "" {
+		if start, err = time.Parse(workday.DateLayout, from); err != nil {
+			return nil, ErrInvalidHabitDate
+		}
+	}
+	if end.Before(start) || end.Sub(start) >= maxCalendarRangeDays*24*time.Hour {
+		return nil, ErrInvalidDateRange
+	}
+
+	dates, err := s.habitRepo.ListCheckInDates(habit.ID)
+	if err != nil {
+		return nil, err
+	}
+	checked := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		checked[d] = true
+	}
+	h := &HabitHistory{HabitID: habit.ID, From: start.Format(workday.DateLayout), To: to, Days: []HabitDay{}}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		day := HabitDay{Date: d.Format(workday.DateLayout)}
+		if checked[day.Date] {
+			day.Count = 1
+		}
+		h.Days = append(h.Days, day)
+	}
+	return h, nil
+}
+
+// today 用户时区下的今天
+func (s *HabitService) today(userID uint) (string, error) {
+	setting, err := s.settingRepo.Get(userID)
+	if err != nil {
+		return "", err
+	}
+	loc, err := LoadTimezone(setting.Timezone)
+	if err != nil {
+		return "", err
+	}
+	return time.Now().In(loc).Format(workday.DateLayout), nil
+}
+
+func (s *HabitService) checkInDate(userID, id uint, date string) (*model.Habit, string, string, error) {
+	habit, err := s.habitRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, "", "", err
+	}
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	date = strings.TrimSpace(date)
+	if date == "today" {
+		date = today
+	}
+	if _, err := time.Parse(workday.DateLayout, date); err != nil {
+		return nil, "", "", ErrInvalidHabitDate
+	}
+	if date < habit.StartDate || date > today {
+		return nil, "", "", ErrHabitDateOutOfRange
+	}
+	return habit, today, date, nil
+}
+
+func (s *HabitService) view(habit *model.Habit, today string) (*HabitView, error) {
+	dates, err := s.habitRepo.ListCheckInDates(habit.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &HabitView{Habit: *habit, Stats: habitStats(habit, dates, today)}, nil
+}
+
+// habitStats 计算连续次数与完成率。
+// 每日习惯：连续打卡天数，今天尚未打卡不算中断；完成率为最近 30 天已打卡天数占比（今天未打卡时不计入分母）。
+// 每周习惯：连续达标周数（周一起），本周未达标不算中断；完成率为最近 4 周的达成比例（每周最多计 N 次，本周未达标时不计入）。
+func habitStats(habit *model.Habit, dates []string, today string) HabitStats {
+	checked := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		checked[d] = true
+	}
+	now, _ := time.Parse(workday.DateLayout, today)
+	start, _ := time.Parse(workday.DateLayout, habit.StartDate)
+	if len(dates) > 0 {
+		// 开始日期被改晚时，早于它的打卡仍计入连续次数
+		if first, _ := time.Parse(workday.DateLayout, dates[0]); first.Before(start) {
+			start = first
+		}
+	}
+	st := HabitStats{CheckedToday: checked[today], TotalCheckIns: len(dates)}
+
+	weekCounts := make(map[string]int)
+	for _, d := range dates {
+		t, _ := time.Parse(workday.DateLayout, d)
+		weekCounts[habitWeekStart(t).Format(workday.DateLayout)]++
+	}
+	thisWeek := habitWeekStart(now)
+	st.ThisWeek = weekCounts[thisWeek.Format(workday.DateLayout)]
+
+	if habit.Frequency == model.HabitWeekly {
+		st.StreakUnit = "week"
+		n := habit.TimesPerWeek
+		met := func(w time.Time) bool { return weekCounts[w.Format(workday.DateLayout)] >= n }
+
+		w := thisWeek
+		if !met(w) {
+			w = w.AddDate(0, 0, -7)
+		}
+		for ; !w.Before(habitWeekStart(start)) && met(w); w = w.AddDate(0, 0, -7) {
+			st.CurrentStreak++
+		}
+		run := 0
+		for w := habitWeekStart(start); !w.After(thisWeek); w = w.AddDate(0, 0, 7) {
+			if met(w) {
+				run++
+				st.LongestStreak = max(st.LongestStreak, run)
+			} else {
+				run = 0
+			}
+		}
+
+		var done, target int
+		for i := 0; i < habitRateWeeks; i++ {
+			w := thisWeek.AddDate(0, 0, -7*i)
+			if w.Before(habitWeekStart(start)) || (i == 0 && !met(w)) {
+				continue
+			}
+			done += min(weekCounts[w.Format(workday.DateLayout)], n)
+			target += n
+		}
+		if target > 0 {
+			st.CompletionRate = roundPercent(float64(done) / float64(target))
+		}
+		return st
+	}
+
+	st.StreakUnit = "day"
+	d := now
+	if !checked[today] {
+		d = d.AddDate(0, 0, -1)
+	}
+	for ; checked[d.Format(workday.DateLayout)]; d = d.AddDate(0, 0, -1) {
+		st.CurrentStreak++
+	}
+	run := 0
+	var prev time.Time
+	for i, date := range dates {
+		t, _ := time.Parse(workday.DateLayout, date)
+		if i > 0 && t.Sub(prev) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		st.LongestStreak = max(st.LongestStreak, run)
+		prev = t
+	}
+
+	var done, days int
+	for i := 0; i < habitRateDays; i++ {
+		d := now.AddDate(0, 0, -i)
+		key := d.Format(workday.DateLayout)
+		if d.Before(start) || (i == 0 && !checked[key]) {
+			continue
+		}
+		days++
+		if checked[key] {
+			done++
+		}
+	}
+	if days > 0 {
+		st.CompletionRate = roundPercent(float64(done) / float64(days))
+	}
+	return st
+}
+
+// habitWeekStart 所在周的周一
+func habitWeekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+func applyHabitInput(habit *model.Habit, in HabitInput) error {
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return ErrHabitNameRequired
+		}
+		habit.Name = truncate(name, 100)
+	}
+	if in.Frequency != nil {
+		habit.Frequency = *in.Frequency
+	}
+	if in.TimesPerWeek != nil {
+		habit.TimesPerWeek = *in.TimesPerWeek
+	}
+	switch habit.Frequency {
+	case model.HabitDaily:
+		habit.TimesPerWeek = 0
+	case model.HabitWeekly:
+		if habit.TimesPerWeek < 1 || habit.TimesPerWeek > 7 {
+			return ErrInvalidTimesPerWeek
+		}
+	default:
+		return ErrInvalidHabitFrequency
+	}
+	if in.StartDate != nil {
+		if _, err := time.Parse(workday.DateLayout, *in.StartDate); err != nil {
+			return ErrInvalidHabitDate
+		}
+		habit.StartDate = *in.StartDate
+	}
+	return nil
+}
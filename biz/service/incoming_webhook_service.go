@@ -0,0 +1,282 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/jsonpath"
+)
+
+var (
+	// ErrTitleTemplateRequired 标题模板必填
+	ErrTitleTemplateRequired = errors.New("title_template is required")
+	// ErrInvalidWebhookPayload 请求体不是 JSON 或映射结果不合法
+	ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+	// ErrWebhookSignatureMismatch 签名缺失或不匹配
+	ErrWebhookSignatureMismatch = errors.New("webhook signature mismatch")
+)
+
+// WebhookSignatureHeader 入站 webhook 的签名请求头
+const WebhookSignatureHeader = "X-Memogo-Signature"
+
+// CreateIncomingWebhookInput 创建入站 webhook 的参数
+type CreateIncomingWebhookInput struct {
+	Name                   string
+	ProjectID              uint
+	TitleTemplate          string
+	ContentTemplate        string
+	DueTimeTemplate        string
+	IdempotencyKeyTemplate string
+	RequireSignature       bool
+}
+
+// WebhookTodoDraft 按模板从请求体映射出的待办字段
+type WebhookTodoDraft struct {
+	Title          string     `json:"title"`
+	Content        string     `json:"content"`
+	DueTime        *time.Time `json:"due_time"`
+	IdempotencyKey string     `json:"idempotency_key"`
+}
+
+// IncomingWebhookService 入站 webhook 业务逻辑
+type IncomingWebhookService struct {
+	hookRepo     *repository.IncomingWebhookRepository
+	ingestRepo   *repository.IngestRecordRepository
+	todoRepo     *repository.TodoRepository
+	projectRepo  *repository.ProjectRepository
+	entitlements *EntitlementService
+}
+
+// NewIncomingWebhookService 创建入站 webhook 服务实例
+func NewIncomingWebhookService(
+	hookRepo *repository.IncomingWebhookRepository,
+	ingestRepo *repository.IngestRecordRepository,
+	todoRepo *repository.TodoRepository,
+	projectRepo *repository.ProjectRepository,
+	planRepo *repository.PlanRepository,
+) *IncomingWebhookService {
+	return &IncomingWebhookService{
+		hookRepo:     hookRepo,
+		ingestRepo:   ingestRepo,
+		todoRepo:     todoRepo,
+		projectRepo:  projectRepo,
+		entitlements: NewEntitlementService(planRepo),
+	}
+}
+
+// CreateWebhook 新建入站 webhook；RequireSignature 时生成签名密钥，仅在返回值中出现一次
+func (s *IncomingWebhookService) CreateWebhook(userID uint, in CreateIncomingWebhookInput) (*model.IncomingWebhook, error) {
+	in.TitleTemplate = strings.TrimSpace(in.TitleTemplate)
+	if in.TitleTemplate == "" {
+		return nil, ErrTitleTemplateRequired
+	}
+	for _, tpl := range []string{in.TitleTemplate, in.ContentTemplate, in.DueTimeTemplate, in.IdempotencyKeyTemplate} {
+		if err := jsonpath.Validate(tpl); err != nil {
+			return nil, err
+		}
+	}
+
+	hook := &model.IncomingWebhook{
+		UserID:                 userID,
+		Name:                   truncate(strings.TrimSpace(in.Name), 100),
+		TitleTemplate:          in.TitleTemplate,
+		ContentTemplate:        in.ContentTemplate,
+		DueTimeTemplate:        strings.TrimSpace(in.DueTimeTemplate),
+		IdempotencyKeyTemplate: strings.TrimSpace(in.IdempotencyKeyTemplate),
+	}
+	if in.ProjectID != 0 {
+		if _, err := s.projectRepo.GetByID(userID, in.ProjectID); err != nil {
+			return nil, err
+		}
+		hook.ProjectID = &in.ProjectID
+	}
+
+	token, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	hook.Token = token
+	if in.RequireSignature {
+		if hook.Secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.hookRepo.Create(hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// ListWebhooks 列出入站 webhook
+func (s *IncomingWebhookService) ListWebhooks(userID uint) ([]model.IncomingWebhook, error) {
+	return s.hookRepo.ListByUser(userID)
+}
+
+// DeleteWebhook 删除入站 webhook
+func (s *IncomingWebhookService) DeleteWebhook(userID, id uint) (int64, error) {
+	return s.hookRepo.Delete(userID, id)
+}
+
+// Preview 用样例请求体试算映射结果，不创建待办
+func (s *IncomingWebhookService) Preview(userID, id uint, body []byte) (*WebhookTodoDraft, error) {
+	hook, err := s.hookRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return renderWebhookDraft(hook, body)
+}
+
+// Deliver 处理一次外部推送：校验签名、映射字段并在目标项目中创建待办
+// 配置了幂等键模板时，重复投递只会创建一条待办；所有者的套餐不含入站 webhook 或待办数已达上限时拒绝
+func (s *IncomingWebhookService) Deliver(token, signature string, body []byte) (*IngestResult, error) {
+	hook, err := s.hookRepo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if hook.Secret != "" && !verifyWebhookSignature(hook.Secret, signature, body) {
+		return nil, ErrWebhookSignatureMismatch
+	}
+	// 创建后降级的套餐同样生效
+	sub := SubjectForUser(hook.UserID)
+	if err := s.entitlements.CheckFeature(sub, model.FeatureIncomingWebhooks); err != nil {
+		return nil, err
+	}
+
+	draft, err := renderWebhookDraft(hook, body)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := &model.Todo{
+		UserID:    hook.UserID,
+		ProjectID: hook.ProjectID,
+		Title:     draft.Title,
+		Content:   draft.Content,
+		DueTime:   draft.DueTime,
+		Status:    0,
+	}
+	if todo.Content == "" {
+		todo.Content = todo.Title
+	}
+
+	result := &IngestResult{}
+	if draft.IdempotencyKey != "" {
+		record := &model.IngestRecord{
+			UserID:         hook.UserID,
+			IdempotencyKey: webhookIdempotencyKey(hook.ID, draft.IdempotencyKey),
+			Source:         "webhook",
+		}
+		result.TodoID, result.Duplicate, err = createTodoOnce(s.ingestRepo, s.entitlements, record, todo)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Duplicate {
+			s.todoRepo.InvalidateCache(hook.UserID)
+		}
+	} else {
+		if err := s.entitlements.CheckLimit(sub, model.LimitTodos, 1); err != nil {
+			return nil, err
+		}
+		if err := s.todoRepo.Create(todo); err != nil {
+			return nil, err
+		}
+		result.TodoID = todo.ID
+	}
+
+	// 投递统计失败不影响本次结果
+	_ = s.hookRepo.MarkDelivered(hook.ID, time.Now())
+	return result, nil
+}
+
+func renderWebhookDraft(hook *model.IncomingWebhook, body []byte) (*WebhookTodoDraft, error) {
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	render := func(tpl string) (string, error) {
+		if tpl == "" {
+			return "", nil
+		}
+		out, err := jsonpath.Render(tpl, doc)
+		return strings.TrimSpace(out), err
+	}
+
+	draft := &WebhookTodoDraft{}
+	if draft.Title, err = render(hook.TitleTemplate); err != nil {
+		return nil, err
+	}
+	if draft.Title == "" {
+		return nil, fmt.Errorf("%w: title_template rendered an empty title", ErrInvalidWebhookPayload)
+	}
+	draft.Title = truncate(draft.Title, 200)
+	if draft.Content, err = render(hook.ContentTemplate); err != nil {
+		return nil, err
+	}
+	if draft.IdempotencyKey, err = render(hook.IdempotencyKeyTemplate); err != nil {
+		return nil, err
+	}
+
+	due, err := render(hook.DueTimeTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if due != "" {
+		t, err := parseWebhookTime(due)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+		}
+		draft.DueTime = &t
+	}
+	return draft, nil
+}
+
+// parseWebhookTime 解析截止时间：unix 秒/毫秒、RFC3339、"2006-01-02 15:04:05" 或 "2006-01-02"（本地时区）
+func parseWebhookTime(v string) (time.Time, error) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized due_time %q", v)
+}
+
+// webhookIdempotencyKey 按 webhook 隔离幂等键；过长时取哈希以适配列宽
+func webhookIdempotencyKey(hookID uint, key string) string {
+	full := fmt.Sprintf("webhook:%d:%s", hookID, key)
+	if len(full) <= maxIdempotencyKeyLen {
+		return full
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("webhook:%d:sha256:%s", hookID, hex.EncodeToString(sum[:]))
+}
+
+// verifyWebhookSignature 校验 "sha256=<hex>" 格式的 HMAC-SHA256 签名
+func verifyWebhookSignature(secret, signature string, body []byte) bool {
+	sig := strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	want, err := hex.DecodeString(sig)
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), want)
+}
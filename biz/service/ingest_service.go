@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrInvalidIngestMessage 消息不符合导入 schema，重试也不会成功，应进入死信
+	ErrInvalidIngestMessage = errors.New("invalid ingest message")
+)
+
+// maxIdempotencyKeyLen 幂等键最大长度（与表结构一致）
+const maxIdempotencyKeyLen = 128
+
+// IngestMessage 消息队列创建待办的 JSON schema
+//
+//	{
+//	  "idempotency_key": "ci-build-1234",   // 必填，同一目标用户内唯一
+//	  "user_id": 1,                         // user_id 与 username 二选一
+//	  "username": "alice",
+//	  "project_id": 3,                      // 可选
+//	  "title": "构建失败",                   // 必填
+//	  "content": "...",                     // 可选，默认同 title
+//	  "start_time": 1730000000,             // 可选，unix 秒
+//	  "due_time": 1730086400                // 可选，unix 秒
+//	}
+type IngestMessage struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	UserID         uint   `json:"user_id"`
+	Username       string `json:"username"`
+	ProjectID      uint   `json:"project_id"`
+	Title          string `json:"title"`
+	Content        string `json:"content"`
+	StartTime      int64  `json:"start_time"`
+	DueTime        int64  `json:"due_time"`
+}
+
+// IngestResult 一条消息的处理结果
+type IngestResult struct {
+	TodoID    uint `json:"todo_id"`
+	Duplicate bool `json:"duplicate"` // 幂等键重复，未重复创建
+}
+
+// IngestService 从消息队列批量导入待办
+type IngestService struct {
+	ingestRepo   *repository.IngestRecordRepository
+	todoRepo     *repository.TodoRepository
+	userRepo     *repository.UserRepository
+	projectRepo  *repository.ProjectRepository
+	entitlements *EntitlementService
+}
+
+// NewIngestService 创建导入服务实例
+func NewIngestService(
+	ingestRepo *repository.IngestRecordRepository,
+	todoRepo *repository.TodoRepository,
+	userRepo *repository.UserRepository,
+	projectRepo *repository.ProjectRepository,
+	planRepo *repository.PlanRepository,
+) *IngestService {
+	return &IngestService{
+		ingestRepo:   ingestRepo,
+		todoRepo:     todoRepo,
+		userRepo:     userRepo,
+		projectRepo:  projectRepo,
+		entitlements: NewEntitlementService(planRepo),
+	}
+}
+
+// Ingest 解析并处理一条消息；返回的错误若包装了 ErrInvalidIngestMessage 则应进入死信，
+// 包装了 ErrPlanLimitReached 表示目标用户的待办数已达套餐上限，重试同样不会成功
+func (s *IngestService) Ingest(source string, data []byte) (*IngestResult, error) {
+	var msg IngestMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngestMessage, err)
+	}
+
+	msg.IdempotencyKey = strings.TrimSpace(msg.IdempotencyKey)
+	msg.Title = strings.TrimSpace(msg.Title)
+	switch {
+	case msg.IdempotencyKey == "":
+		return nil, fmt.Errorf("%w: idempotency_key is required", ErrInvalidIngestMessage)
+	case len(msg.IdempotencyKey) > maxIdempotencyKeyLen:
+		return nil, fmt.Errorf("%w: idempotency_key exceeds %d bytes", ErrInvalidIngestMessage, maxIdempotencyKeyLen)
+	case msg.Title == "":
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngestMessage, ErrTitleRequired)
+	case msg.UserID == 0 && msg.Username == "":
+		return nil, fmt.Errorf("%w: user_id or username is required", ErrInvalidIngestMessage)
+	}
+
+	user, err := s.resolveUser(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := &model.Todo{
+		UserID:  user.ID,
+		Title:   truncate(msg.Title, 200),
+		Content: msg.Content,
+		Status:  0,
+	}
+	if todo.Content == "" {
+		todo.Content = todo.Title
+	}
+	if msg.ProjectID != 0 {
+		if _, err := s.projectRepo.GetByID(user.ID, msg.ProjectID); err != nil {
+			if errors.Is(err, repository.ErrProjectNotFound) {
+				return nil, fmt.Errorf("%w: project %d not found for user %d", ErrInvalidIngestMessage, msg.ProjectID, user.ID)
+			}
+			return nil, err
+		}
+		todo.ProjectID = &msg.ProjectID
+	}
+	if msg.StartTime > 0 {
+		t := time.Unix(msg.StartTime, 0)
+		todo.StartTime = &t
+	}
+	if msg.DueTime > 0 {
+		t := time.Unix(msg.DueTime, 0)
+		todo.DueTime = &t
+	}
+
+	record := &model.IngestRecord{
+		UserID:         user.ID,
+		IdempotencyKey: msg.IdempotencyKey,
+		Source:         source,
+	}
+	todoID, duplicate, err := createTodoOnce(s.ingestRepo, s.entitlements, record, todo)
+	if err != nil {
+		return nil, err
+	}
+	if !duplicate {
+		s.todoRepo.InvalidateCache(user.ID)
+	}
+	return &IngestResult{TodoID: todoID, Duplicate: duplicate}, nil
+}
+
+// createTodoOnce 检查待办数上限后按幂等键创建待办；已达上限时重复投递仍返回之前创建的待办，只拒绝新建
+func createTodoOnce(ingestRepo *repository.IngestRecordRepository, entitlements *EntitlementService, record *model.IngestRecord, todo *model.Todo) (todoID uint, duplicate bool, err error) {
+	if err := entitlements.CheckLimit(SubjectForUser(record.UserID), model.LimitTodos, 1); err != nil {
+		if !errors.Is(err, ErrPlanLimitReached) {
+			return 0, false, err
+		}
+		todoID, found, findErr := ingestRepo.FindTodoID(record.UserID, record.IdempotencyKey)
+		if findErr != nil || !found {
+			return 0, false, errors.Join(err, findErr)
+		}
+		return todoID, true, nil
+	}
+	return ingestRepo.CreateTodoOnce(record, todo)
+}
+
+func (s *IngestService) resolveUser(msg IngestMessage) (*model.User, error) {
+	var (
+		user *model.User
+		err  error
+	)
+	if msg.UserID != 0 {
+		user, err = s.userRepo.GetByID(msg.UserID)
+	} else {
+		user, err = s.userRepo.GetByUsername(msg.Username)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: target user not found", ErrInvalidIngestMessage)
+		}
+		return nil, err
+	}
+	if msg.UserID != 0 && msg.Username != "" && user.Username != msg.Username {
+		return nil, fmt.Errorf("%w: user_id and username refer to different users", ErrInvalidIngestMessage)
+	}
+	return user, nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/issuetracker"
+)
+
+var (
+	// ErrRepoRequired 仓库必填
+	ErrRepoRequired = errors.New("repo is required")
+	// ErrInvalidConflictPolicy 冲突策略非法
+	ErrInvalidConflictPolicy = errors.New("conflict_policy must be one of newest/remote/local")
+)
+
+// syncOverlap 增量拉取时向前多取的时间窗口，避免两端时钟偏差漏掉更新
+const syncOverlap = 2 * time.Minute
+
+// CreateIssueConnectorInput 创建同步连接的参数
+type CreateIssueConnectorInput struct {
+	Provider       string
+	BaseURL        string
+	Repo           string
+	Token          string
+	Assignee       string
+	ProjectID      uint
+	WebhookSecret  string
+	ConflictPolicy string
+}
+
+// IssueSyncResult 一次同步的统计
+type IssueSyncResult struct {
+	Imported  int `json:"imported"`  // 新导入的 issue
+	Pulled    int `json:"pulled"`    // 远程状态同步到本地
+	Pushed    int `json:"pushed"`    // 本地状态推送到远程
+	Conflicts int `json:"conflicts"` // 两端都有变化、按策略裁决的次数
+	Skipped   int `json:"skipped"`   // 待办数已达套餐上限、未能导入的 issue
+}
+
+// IssueClientFactory 根据连接创建 API 客户端，可替换为指向 mock 的实现
+type IssueClientFactory func(conn *model.IssueConnector) (issuetracker.Client, error)
+
+// IssueSyncService GitHub/GitLab issue 双向同步服务
+type IssueSyncService struct {
+	connRepo     *repository.IssueConnectorRepository
+	linkRepo     *repository.IssueLinkRepository
+	todoRepo     *repository.TodoRepository
+	projectRepo  *repository.ProjectRepository
+	entitlements *EntitlementService
+	newClient    IssueClientFactory
+}
+
+// NewIssueSyncService 创建同步服务实例
+func NewIssueSyncService(
+	connRepo *repository.IssueConnectorRepository,
+	linkRepo *repository.IssueLinkRepository,
+	todoRepo *repository.TodoRepository,
+	projectRepo *repository.ProjectRepository,
+	planRepo *repository.PlanRepository,
+) *IssueSyncService {
+	return &IssueSyncService{
+		connRepo:     connRepo,
+		linkRepo:     linkRepo,
+		todoRepo:     todoRepo,
+		projectRepo:  projectRepo,
+		entitlements: NewEntitlementService(planRepo),
+		newClient:    defaultIssueClient,
+	}
+}
+
+// WithClientFactory 替换 API 客户端工厂
+func (s *IssueSyncService) WithClientFactory(f IssueClientFactory) *IssueSyncService {
+	s.newClient = f
+	return s
+}
+
+func defaultIssueClient(conn *model.IssueConnector) (issuetracker.Client, error) {
+	return issuetracker.NewClient(issuetracker.Config{
+		Provider: conn.Provider,
+		BaseURL:  conn.BaseURL,
+		Repo:     conn.Repo,
+		Token:    conn.Token,
+		Assignee: conn.Assignee,
+		// 每次读取，管理员收紧白名单后已有连接随即失效
+		AllowedHosts: issuetracker.AllowedHostsFromEnv(),
+	})
+}
+
+// CreateConnector 把项目连接到仓库；未提供 webhook 密钥时自动生成
+// base_url 只能为空（官方 API）或指向管理员列入 ISSUE_TRACKER_ALLOWED_HOSTS 的实例，避免借服务端访问内网
+func (s *IssueSyncService) CreateConnector(userID uint, in CreateIssueConnectorInput) (*model.IssueConnector, error) {
+	in.Provider = strings.ToLower(strings.TrimSpace(in.Provider))
+	if in.Provider != issuetracker.ProviderGitHub && in.Provider != issuetracker.ProviderGitLab {
+		return nil, issuetracker.ErrUnsupportedProvider
+	}
+	in.BaseURL = strings.TrimSpace(in.BaseURL)
+	if err := issuetracker.CheckBaseURL(in.Provider, in.BaseURL, issuetracker.AllowedHostsFromEnv()); err != nil {
+		return nil, err
+	}
+	in.Repo = strings.Trim(strings.TrimSpace(in.Repo), "/")
+	if in.Repo == "" {
+		return nil, ErrRepoRequired
+	}
+	if in.ConflictPolicy == "" {
+		in.ConflictPolicy = model.ConflictPolicyNewest
+	}
+	switch in.ConflictPolicy {
+	case model.ConflictPolicyNewest, model.ConflictPolicyRemote, model.ConflictPolicyLocal:
+	default:
+		return nil, ErrInvalidConflictPolicy
+	}
+
+	conn := &model.IssueConnector{
+		UserID:         userID,
+		Provider:       in.Provider,
+		BaseURL:        in.BaseURL,
+		Repo:           in.Repo,
+		Token:          in.Token,
+		Assignee:       strings.TrimSpace(in.Assignee),
+		WebhookSecret:  in.WebhookSecret,
+		ConflictPolicy: in.ConflictPolicy,
+	}
+	if in.ProjectID != 0 {
+		if _, err := s.projectRepo.GetByID(userID, in.ProjectID); err != nil {
+			return nil, err
+		}
+		conn.ProjectID = &in.ProjectID
+	}
+	if conn.WebhookSecret == "" {
+		secret, err := randomHex(20)
+		if err != nil {
+			return nil, err
+		}
+		conn.WebhookSecret = secret
+	}
+
+	if err := s.connRepo.Create(conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// ListConnectors 列出同步连接
+func (s *IssueSyncService) ListConnectors(userID uint) ([]model.IssueConnector, error) {
+	return s.connRepo.ListByUser(userID)
+}
+
+// DeleteConnector 删除同步连接
+func (s *IssueSyncService) DeleteConnector(userID, id uint) (int64, error) {
+	return s.connRepo.Delete(userID, id)
+}
+
+// SyncConnector 手动触发一次同步（限定用户）
+func (s *IssueSyncService) SyncConnector(ctx context.Context, userID, id uint) (*IssueSyncResult, error) {
+	conn, err := s.connRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, conn)
+}
+
+// SyncAll 同步全部连接（后台定时任务），单个连接失败不影响其它连接
+func (s *IssueSyncService) SyncAll(ctx context.Context) {
+	conns, err := s.connRepo.ListAll()
+	if err != nil {
+		log.Printf("Issue sync: list connectors failed: %v", err)
+		return
+	}
+	for i := range conns {
+		if _, err := s.sync(ctx, &conns[i]); err != nil {
+			log.Printf("Issue sync: connector %d failed: %v", conns[i].ID, err)
+		}
+	}
+}
+
+// HandleWebhook 处理平台推送的 issue 事件
+func (s *IssueSyncService) HandleWebhook(ctx context.Context, connectorID uint, header func(string) string, body []byte) error {
+	conn, err := s.connRepo.GetByIDForWebhook(connectorID)
+	if err != nil {
+		return err
+	}
+	issue, err := issuetracker.ParseWebhook(conn.Provider, conn.WebhookSecret, header, body)
+	if err != nil {
+		return err
+	}
+	if err := s.entitlements.CheckFeature(SubjectForUser(conn.UserID), model.FeatureIssueSync); err != nil {
+		return err
+	}
+
+	client, err := s.newClient(conn)
+	if err != nil {
+		return err
+	}
+	var result IssueSyncResult
+	return s.reconcileIssue(ctx, client, conn, issue, &result)
+}
+
+// sync 拉取远程变化并推送本地变化；所有者的套餐已不含 issue 同步时不再同步，并记录到 last_error
+func (s *IssueSyncService) sync(ctx context.Context, conn *model.IssueConnector) (*IssueSyncResult, error) {
+	startedAt := time.Now()
+	result := &IssueSyncResult{}
+
+	err := s.entitlements.CheckFeature(SubjectForUser(conn.UserID), model.FeatureIssueSync)
+	if err == nil {
+		err = s.doSync(ctx, conn, result)
+	}
+	if err != nil {
+		if markErr := s.connRepo.MarkSynced(conn.ID, nil, truncate(err.Error(), 500)); markErr != nil {
+			log.Printf("Issue sync: record error for connector %d failed: %v", conn.ID, markErr)
+		}
+		return nil, err
+	}
+	if result.Skipped > 0 {
+		// 不推进 last_synced_at，腾出额度后下次同步会重新拉取被跳过的 issue
+		msg := fmt.Sprintf("%d issue(s) not imported: %v", result.Skipped, ErrPlanLimitReached)
+		if err := s.connRepo.MarkSynced(conn.ID, nil, msg); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err := s.connRepo.MarkSynced(conn.ID, &startedAt, ""); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *IssueSyncService) doSync(ctx context.Context, conn *model.IssueConnector, result *IssueSyncResult) error {
+	client, err := s.newClient(conn)
+	if err != nil {
+		return err
+	}
+
+	// 1. 拉取远程：导入新 issue，并与已关联的待办对账
+	var since time.Time
+	if conn.LastSyncedAt != nil {
+		since = conn.LastSyncedAt.Add(-syncOverlap)
+	}
+	issues, err := client.ListAssignedIssues(ctx, since)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]bool, len(issues))
+	for i := range issues {
+		seen[issues[i].Number] = true
+		if err := s.reconcileIssue(ctx, client, conn, &issues[i], result); err != nil {
+			return err
+		}
+	}
+
+	// 2. 推送本地：远程没有变化、但本地待办在上次同步后被修改的关联
+	links, err := s.linkRepo.ListByConnector(conn.ID)
+	if err != nil {
+		return err
+	}
+	for i := range links {
+		link := &links[i]
+		if seen[link.IssueNumber] {
+			continue
+		}
+		todo, err := s.todoRepo.GetByID(conn.UserID, link.TodoID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTodoNotFound) {
+				continue // 本地待办已删除，不再同步
+			}
+			return err
+		}
+		if isDone(todo) == link.RemoteClosed || !todo.UpdatedAt.After(link.SyncedAt) {
+			continue
+		}
+		if err := s.pushStatus(ctx, client, link, todo); err != nil {
+			return err
+		}
+		result.Pushed++
+	}
+	return nil
+}
+
+// reconcileIssue 对单个远程 issue 做导入或双向对账
+func (s *IssueSyncService) reconcileIssue(ctx context.Context, client issuetracker.Client, conn *model.IssueConnector, issue *issuetracker.Issue, result *IssueSyncResult) error {
+	link, err := s.linkRepo.GetByIssue(conn.ID, issue.Number)
+	if err != nil && !errors.Is(err, repository.ErrIssueLinkNotFound) {
+		return err
+	}
+
+	// 尚未关联：只导入仍打开且指派给目标用户的 issue
+	if link == nil {
+		if issue.Closed || !issue.AssignedTo(conn.Assignee) {
+			return nil
+		}
+		if err := s.entitlements.CheckLimit(SubjectForUser(conn.UserID), model.LimitTodos, 1); err != nil {
+			if errors.Is(err, ErrPlanLimitReached) {
+				result.Skipped++
+				return nil
+			}
+			return err
+		}
+		imported, err := s.importIssue(conn, issue)
+		if err != nil {
+			return err
+		}
+		if imported {
+			result.Imported++
+		}
+		return nil
+	}
+
+	todo, err := s.todoRepo.GetByID(conn.UserID, link.TodoID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTodoNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	localDone := isDone(todo)
+	if localDone == issue.Closed {
+		// 两端一致，刷新同步基线
+		link.RemoteClosed = issue.Closed
+		link.RemoteUpdatedAt = issue.UpdatedAt
+		link.SyncedAt = time.Now()
+		return s.linkRepo.Save(link)
+	}
+
+	remoteChanged := issue.Closed != link.RemoteClosed || issue.UpdatedAt.After(link.SyncedAt)
+	localChanged := todo.UpdatedAt.After(link.SyncedAt)
+
+	useRemote := true
+	switch {
+	case remoteChanged && localChanged:
+		result.Conflicts++
+		switch conn.ConflictPolicy {
+		case model.ConflictPolicyLocal:
+			useRemote = false
+		case model.ConflictPolicyRemote:
+			useRemote = true
+		default:
+			useRemote = !todo.UpdatedAt.After(issue.UpdatedAt)
+		}
+	case localChanged:
+		useRemote = false
+	}
+
+	if useRemote {
+		status := int32(0)
+		if issue.Closed {
+			status = 1
+		}
+		// 经 TodoService 更新：关闭的是重复待办时同样生成下一条
+		if _, err := NewTodoService(s.todoRepo).UpdateTodoStatus(conn.UserID, todo.ID, status); err != nil {
+			return err
+		}
+		link.RemoteClosed = issue.Closed
+		link.RemoteUpdatedAt = issue.UpdatedAt
+		link.SyncedAt = time.Now()
+		result.Pulled++
+		return s.linkRepo.Save(link)
+	}
+
+	if err := s.pushStatus(ctx, client, link, todo); err != nil {
+		return err
+	}
+	result.Pushed++
+	return nil
+}
+
+// pushStatus 把本地完成状态写回远程 issue
+func (s *IssueSyncService) pushStatus(ctx context.Context, client issuetracker.Client, link *model.IssueLink, todo *model.Todo) error {
+	updated, err := client.SetState(ctx, link.IssueNumber, isDone(todo))
+	if err != nil {
+		return fmt.Errorf("update issue #%d: %w", link.IssueNumber, err)
+	}
+	link.RemoteClosed = updated.Closed
+	link.RemoteUpdatedAt = updated.UpdatedAt
+	link.SyncedAt = time.Now()
+	return s.linkRepo.Save(link)
+}
+
+// importIssue 为远程 issue 创建待办并建立关联（同一事务）；并发的同步已导入时返回 false
+func (s *IssueSyncService) importIssue(conn *model.IssueConnector, issue *issuetracker.Issue) (bool, error) {
+	content := strings.TrimSpace(issue.Body)
+	if content == "" {
+		content = issue.URL
+	}
+	// 待办的更新时间与同步基线相同，下次同步不会把刚导入的待办当作本地修改
+	now := time.Now()
+	todo := &model.Todo{
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    conn.UserID,
+		ProjectID: conn.ProjectID,
+		Title:     truncate(issue.Title, 200),
+		Content:   content,
+		Status:    0,
+	}
+	return s.linkRepo.CreateWithTodo(&model.IssueLink{
+		ConnectorID:     conn.ID,
+		IssueNumber:     issue.Number,
+		IssueURL:        issue.URL,
+		RemoteClosed:    issue.Closed,
+		RemoteUpdatedAt: issue.UpdatedAt,
+		SyncedAt:        now,
+	}, todo)
+}
+
+func isDone(todo *model.Todo) bool {
+	return todo.Status == 1
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
+
+// randomHex 生成 n 字节随机数的十六进制串
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
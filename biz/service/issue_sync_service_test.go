@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/issuetracker"
+	"memogo/pkg/issuetracker/issuetrackertest"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+const issueSyncUser = 1
+
+type issueSyncFixture struct {
+	svc      *IssueSyncService
+	srv      *issuetrackertest.Server
+	conn     *model.IssueConnector
+	todoRepo *repository.TodoRepository
+	linkRepo *repository.IssueLinkRepository
+	connRepo *repository.IssueConnectorRepository
+	planRepo *repository.PlanRepository
+}
+
+// newIssueSyncFixture 连接到 mock 仓库，只导入指派给 alice 的 issue
+func newIssueSyncFixture(t *testing.T, provider, policy string) *issueSyncFixture {
+	t.Helper()
+	db := dbtest.Open(t)
+	srv := issuetrackertest.NewServer(provider, "acme/app")
+	t.Cleanup(srv.Close)
+	t.Setenv("ISSUE_TRACKER_ALLOWED_HOSTS", srv.Host())
+
+	projectRepo := repository.NewProjectRepository(db)
+	project := &model.Project{UserID: issueSyncUser, Name: "App"}
+	if err := projectRepo.Create(project); err != nil {
+		t.Fatal(err)
+	}
+	f := &issueSyncFixture{
+		srv:      srv,
+		todoRepo: repository.NewTodoRepository(db),
+		linkRepo: repository.NewIssueLinkRepository(db),
+		connRepo: repository.NewIssueConnectorRepository(db),
+		planRepo: repository.NewPlanRepository(db),
+	}
+	f.svc = NewIssueSyncService(f.connRepo, f.linkRepo, f.todoRepo, projectRepo, f.planRepo)
+	conn, err := f.svc.CreateConnector(issueSyncUser, CreateIssueConnectorInput{
+		Provider:       provider,
+		BaseURL:        srv.URL,
+		Repo:           srv.Repo,
+		Token:          "test-token",
+		Assignee:       "alice",
+		ProjectID:      project.ID,
+		ConflictPolicy: policy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.conn = conn
+	return f
+}
+
+func (f *issueSyncFixture) sync(t *testing.T) IssueSyncResult {
+	t.Helper()
+	result, err := f.svc.SyncConnector(context.Background(), issueSyncUser, f.conn.ID)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	return *result
+}
+
+// todoFor 返回与 issue 关联的待办
+func (f *issueSyncFixture) todoFor(t *testing.T, number int64) *model.Todo {
+	t.Helper()
+	link, err := f.linkRepo.GetByIssue(f.conn.ID, number)
+	if err != nil {
+		t.Fatalf("link for #%d: %v", number, err)
+	}
+	todo, err := f.todoRepo.GetByID(issueSyncUser, link.TodoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
+
+func (f *issueSyncFixture) setLocalStatus(t *testing.T, todoID uint, status int32) {
+	t.Helper()
+	if _, err := f.todoRepo.UpdateStatusByID(issueSyncUser, todoID, status); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *issueSyncFixture) remoteClosed(t *testing.T, number int64) bool {
+	t.Helper()
+	issue, ok := f.srv.Issue(number)
+	if !ok {
+		t.Fatalf("remote issue #%d missing", number)
+	}
+	return issue.Closed
+}
+
+// tick 让后续修改的时间戳严格晚于之前的同步
+func tick() { time.Sleep(20 * time.Millisecond) }
+
+func TestIssueSyncImport(t *testing.T) {
+	for _, provider := range []string{issuetracker.ProviderGitHub, issuetracker.ProviderGitLab} {
+		t.Run(provider, func(t *testing.T) {
+			f := newIssueSyncFixture(t, provider, "")
+			f.srv.Put(issuetracker.Issue{Number: 1, Title: "Fix login", Body: "Steps to reproduce", Assignees: []string{"alice"}})
+			f.srv.Put(issuetracker.Issue{Number: 2, Title: "Someone else's", Assignees: []string{"bob"}})
+			f.srv.Put(issuetracker.Issue{Number: 3, Title: "Already closed", Assignees: []string{"alice"}, Closed: true})
+			f.srv.Put(issuetracker.Issue{Number: 4, Title: "No description", Assignees: []string{"carol", "Alice"}})
+
+			if got := f.sync(t); got != (IssueSyncResult{Imported: 2}) {
+				t.Fatalf("first sync = %+v, want 2 imported", got)
+			}
+			todo := f.todoFor(t, 1)
+			if todo.Title != "Fix login" || todo.Content != "Steps to reproduce" || todo.Status != 0 ||
+				todo.ProjectID == nil || *todo.ProjectID != *f.conn.ProjectID {
+				t.Fatalf("imported todo = %+v", todo)
+			}
+			if issue, _ := f.srv.Issue(4); f.todoFor(t, 4).Content != issue.URL {
+				t.Errorf("empty body should fall back to the issue URL")
+			}
+			for _, number := range []int64{2, 3} {
+				if _, err := f.linkRepo.GetByIssue(f.conn.ID, number); !errors.Is(err, repository.ErrIssueLinkNotFound) {
+					t.Errorf("issue #%d should not be imported (err = %v)", number, err)
+				}
+			}
+
+			if got := f.sync(t); got != (IssueSyncResult{}) {
+				t.Fatalf("second sync = %+v, want no changes", got)
+			}
+			if f.srv.Writes() != 0 {
+				t.Fatalf("import should not write to the issue tracker, got %d writes", f.srv.Writes())
+			}
+		})
+	}
+}
+
+func TestIssueSyncMirrorsStatus(t *testing.T) {
+	for _, provider := range []string{issuetracker.ProviderGitHub, issuetracker.ProviderGitLab} {
+		t.Run(provider, func(t *testing.T) {
+			f := newIssueSyncFixture(t, provider, "")
+			f.srv.Put(issuetracker.Issue{Number: 1, Title: "Fix login", Assignees: []string{"alice"}})
+			f.sync(t)
+			todo := f.todoFor(t, 1)
+
+			// 远程关闭 → 本地完成
+			tick()
+			f.srv.SetClosed(1, true)
+			if got := f.sync(t); got != (IssueSyncResult{Pulled: 1}) {
+				t.Fatalf("after remote close = %+v, want 1 pulled", got)
+			}
+			if f.todoFor(t, 1).Status != 1 {
+				t.Fatal("todo should be done after the issue was closed")
+			}
+
+			// 本地重新打开 → 远程重新打开
+			tick()
+			f.setLocalStatus(t, todo.ID, 0)
+			if got := f.sync(t); got != (IssueSyncResult{Pushed: 1}) {
+				t.Fatalf("after local reopen = %+v, want 1 pushed", got)
+			}
+			if f.remoteClosed(t, 1) {
+				t.Fatal("issue should be reopened after the todo was reopened")
+			}
+
+			// 本地完成 → 远程关闭
+			tick()
+			f.setLocalStatus(t, todo.ID, 1)
+			if got := f.sync(t); got != (IssueSyncResult{Pushed: 1}) {
+				t.Fatalf("after local done = %+v, want 1 pushed", got)
+			}
+			if !f.remoteClosed(t, 1) {
+				t.Fatal("issue should be closed after the todo was completed")
+			}
+
+			writes := f.srv.Writes()
+			if got := f.sync(t); got != (IssueSyncResult{}) {
+				t.Fatalf("idle sync = %+v, want no changes", got)
+			}
+			if f.srv.Writes() != writes {
+				t.Fatal("idle sync should not write to the issue tracker")
+			}
+		})
+	}
+}
+
+func TestIssueSyncImportOnce(t *testing.T) {
+	f := newIssueSyncFixture(t, issuetracker.ProviderGitHub, "")
+	issue := &issuetracker.Issue{Number: 1, Title: "Fix login", Assignees: []string{"alice"}}
+
+	// 两次同步先后读到“尚未关联”时，后到的一次不再创建待办
+	for i, want := range []bool{true, false} {
+		imported, err := f.svc.importIssue(f.conn, issue)
+		if err != nil || imported != want {
+			t.Fatalf("import #%d = %v, %v; want %v", i+1, imported, err, want)
+		}
+	}
+	if _, total, _ := f.todoRepo.ListTodos(issueSyncUser, "all", nil, repository.TodoSortCreated, 1, 10); total != 1 {
+		t.Fatalf("todos after importing twice = %d, want 1", total)
+	}
+	if todo := f.todoFor(t, 1); todo.Title != "Fix login" || todo.ProjectID == nil || *todo.ProjectID != *f.conn.ProjectID {
+		t.Errorf("linked todo = %+v", todo)
+	}
+}
+
+func TestIssueSyncClosesRecurringTodo(t *testing.T) {
+	f := newIssueSyncFixture(t, issuetracker.ProviderGitHub, "")
+	f.srv.Put(issuetracker.Issue{Number: 1, Title: "Weekly report", Assignees: []string{"alice"}})
+	f.sync(t)
+	todo := f.todoFor(t, 1)
+	due := time.Now().Add(time.Hour)
+	if _, err := f.todoRepo.UpdateFields(issueSyncUser, todo.ID, map[string]interface{}{"recurrence": model.RecurrenceWeekly, "due_time": due}); err != nil {
+		t.Fatal(err)
+	}
+	tick()
+	f.sync(t) // 两端一致，刷新同步基线
+
+	tick()
+	f.srv.SetClosed(1, true)
+	if got := f.sync(t); got != (IssueSyncResult{Pulled: 1}) {
+		t.Fatalf("after remote close = %+v, want 1 pulled", got)
+	}
+	todos, total, err := f.todoRepo.ListTodos(issueSyncUser, "all", nil, repository.TodoSortCreated, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("todos after closing a recurring todo = %d, want the next occurrence created", total)
+	}
+	for _, next := range todos {
+		if next.ID == todo.ID {
+			continue
+		}
+		if next.Status != 0 || next.Recurrence != model.RecurrenceWeekly || next.DueTime == nil || !next.DueTime.After(due) {
+			t.Errorf("next occurrence = %+v, want an open weekly todo due after %v", next, due)
+		}
+	}
+}
+
+func TestIssueSyncConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		localLatest bool // 本地修改晚于远程关闭
+		wantClosed  bool // 裁决后两端的状态
+	}{
+		{"remote wins", model.ConflictPolicyRemote, true, true},
+		{"local wins", model.ConflictPolicyLocal, false, false},
+		{"newest: remote is newer", model.ConflictPolicyNewest, false, true},
+		{"newest: local is newer", model.ConflictPolicyNewest, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newIssueSyncFixture(t, issuetracker.ProviderGitHub, tt.policy)
+			f.srv.Put(issuetracker.Issue{Number: 1, Title: "Fix login", Assignees: []string{"alice"}})
+			f.sync(t)
+			todo := f.todoFor(t, 1)
+
+			// 两端在上次同步后都有变化：远程关闭了 issue，本地编辑了（仍未完成的）待办
+			editLocal := func() {
+				if _, err := f.todoRepo.UpdateFields(issueSyncUser, todo.ID, map[string]interface{}{"title": "Fix login (edited)"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tick()
+			if tt.localLatest {
+				f.srv.SetClosed(1, true)
+				tick()
+				editLocal()
+			} else {
+				editLocal()
+				tick()
+				f.srv.SetClosed(1, true)
+			}
+
+			got := f.sync(t)
+			if got.Conflicts != 1 {
+				t.Fatalf("sync = %+v, want 1 conflict", got)
+			}
+			if done := f.todoFor(t, 1).Status == 1; done != tt.wantClosed {
+				t.Errorf("todo done = %v, want %v", done, tt.wantClosed)
+			}
+			if closed := f.remoteClosed(t, 1); closed != tt.wantClosed {
+				t.Errorf("issue closed = %v, want %v", closed, tt.wantClosed)
+			}
+			if got := f.sync(t); got.Conflicts != 0 || got.Pulled != 0 || got.Pushed != 0 {
+				t.Errorf("sync after resolution = %+v, want no changes", got)
+			}
+		})
+	}
+}
+
+func TestIssueSyncWebhook(t *testing.T) {
+	for _, provider := range []string{issuetracker.ProviderGitHub, issuetracker.ProviderGitLab} {
+		t.Run(provider, func(t *testing.T) {
+			f := newIssueSyncFixture(t, provider, "")
+			f.srv.Put(issuetracker.Issue{Number: 1, Title: "Fix login", Assignees: []string{"alice"}})
+			f.sync(t)
+			handle := func(number int64, secret string) error {
+				header, body := f.srv.Webhook(number, secret)
+				return f.svc.HandleWebhook(context.Background(), f.conn.ID, header, body)
+			}
+
+			tick()
+			f.srv.SetClosed(1, true)
+			if err := handle(1, "wrong-secret"); !errors.Is(err, issuetracker.ErrInvalidWebhookSignature) {
+				t.Fatalf("wrong secret: err = %v", err)
+			}
+			if err := handle(1, ""); !errors.Is(err, issuetracker.ErrInvalidWebhookSignature) {
+				t.Fatalf("unsigned: err = %v", err)
+			}
+			if f.todoFor(t, 1).Status != 0 {
+				t.Fatal("rejected webhooks must not change the todo")
+			}
+			if err := handle(1, f.conn.WebhookSecret); err != nil {
+				t.Fatalf("signed webhook: %v", err)
+			}
+			if f.todoFor(t, 1).Status != 1 {
+				t.Fatal("todo should be done after the close webhook")
+			}
+
+			// webhook 带来的新 issue 同样按指派人导入
+			f.srv.Put(issuetracker.Issue{Number: 2, Title: "New for alice", Assignees: []string{"alice"}})
+			f.srv.Put(issuetracker.Issue{Number: 3, Title: "New for bob", Assignees: []string{"bob"}})
+			for _, number := range []int64{2, 3} {
+				if err := handle(number, f.conn.WebhookSecret); err != nil {
+					t.Fatalf("webhook for #%d: %v", number, err)
+				}
+			}
+			if f.todoFor(t, 2).Title != "New for alice" {
+				t.Error("issue #2 should be imported from the webhook")
+			}
+			if _, err := f.linkRepo.GetByIssue(f.conn.ID, 3); !errors.Is(err, repository.ErrIssueLinkNotFound) {
+				t.Errorf("issue #3 is not assigned to alice and should not be imported (err = %v)", err)
+			}
+		})
+	}
+}
+
+func TestIssueSyncPlanLimits(t *testing.T) {
+	f := newIssueSyncFixture(t, issuetracker.ProviderGitHub, "")
+	for number := int64(1); number <= 3; number++ {
+		f.srv.Put(issuetracker.Issue{Number: number, Title: "Issue", Assignees: []string{"alice"}})
+	}
+	setDefaultPlan(t, f.planRepo, map[string]int{model.LimitTodos: 2}, model.FeatureIssueSync)
+
+	if got := f.sync(t); got != (IssueSyncResult{Imported: 2, Skipped: 1}) {
+		t.Fatalf("sync at limit = %+v, want 2 imported and 1 skipped", got)
+	}
+	conn, err := f.connRepo.GetByID(issueSyncUser, f.conn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.LastSyncedAt != nil || !strings.Contains(conn.LastError, "1 issue(s) not imported") {
+		t.Fatalf("connector after skipping = last_synced_at %v, last_error %q; want unchanged and the skip recorded",
+			conn.LastSyncedAt, conn.LastError)
+	}
+
+	// 腾出额度后，被跳过的 issue 在下次同步时导入
+	if _, err := f.todoRepo.DeleteOne(issueSyncUser, f.todoFor(t, 1).ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.sync(t); got != (IssueSyncResult{Imported: 1}) {
+		t.Fatalf("sync after freeing a slot = %+v, want 1 imported", got)
+	}
+
+	// 套餐降级后不再同步，连接与 webhook 都拒绝
+	setDefaultPlan(t, f.planRepo, nil)
+	if _, err := f.svc.SyncConnector(context.Background(), issueSyncUser, f.conn.ID); !errors.Is(err, ErrFeatureNotInPlan) {
+		t.Fatalf("sync without the feature = %v, want ErrFeatureNotInPlan", err)
+	}
+	if conn, _ := f.connRepo.GetByID(issueSyncUser, f.conn.ID); !strings.Contains(conn.LastError, model.FeatureIssueSync) {
+		t.Errorf("last_error = %q, want the missing feature", conn.LastError)
+	}
+	header, body := f.srv.Webhook(2, f.conn.WebhookSecret)
+	if err := f.svc.HandleWebhook(context.Background(), f.conn.ID, header, body); !errors.Is(err, ErrFeatureNotInPlan) {
+		t.Fatalf("webhook without the feature = %v, want ErrFeatureNotInPlan", err)
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/wikilink"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrNoteTitleRequired 笔记标题必填
+	ErrNoteTitleRequired = errors.New("note title is required")
+	// ErrNoteTitleTooLong 笔记标题过长
+	ErrNoteTitleTooLong = errors.New("note title must be at most 200 characters")
+	// ErrNoteContentTooLong 笔记内容过长
+	ErrNoteContentTooLong = errors.New("note content must be at most 65535 bytes")
+	// ErrInvalidJournalDate 日记日期无效
+	ErrInvalidJournalDate = errors.New("journal date must be YYYY-MM-DD or today")
+	// ErrJournalTitleFixed 日记标题固定为日期
+	ErrJournalTitleFixed = errors.New("journal note title cannot be changed")
+)
+
+const (
+	maxNoteTitleLen   = 200
+	maxNoteContentLen = 65535 // MySQL TEXT 上限
+)
+
+// NoteSummary 笔记摘要（用于列表与反向链接）
+type NoteSummary struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	JournalDate *string   `json:"journal_date"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NoteLinkView 笔记发出的一个链接；目标不存在时 Resolved 为 false
+type NoteLinkView struct {
+	Type     string `json:"type"` // todo | note | journal
+	Target   string `json:"target"`
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Resolved bool   `json:"resolved"`
+}
+
+// NoteDetail 笔记详情：正文、发出的链接与反向链接
+type NoteDetail struct {
+	Note      *model.Note    `json:"note"`
+	Links     []NoteLinkView `json:"links"`
+	Backlinks []NoteSummary  `json:"backlinks"`
+}
+
+// NoteUpdate 笔记的部分更新，nil 字段保持不变
+type NoteUpdate struct {
+	Title   *string
+	Content *string
+}
+
+// NoteService 笔记与日记
+type NoteService struct {
+	noteRepo *repository.NoteRepository
+	todoRepo *repository.TodoRepository
+}
+
+// NewNoteService 创建笔记服务实例
+func NewNoteService(noteRepo *repository.NoteRepository, todoRepo *repository.TodoRepository) *NoteService {
+	return &NoteService{noteRepo: noteRepo, todoRepo: todoRepo}
+}
+
+// Create 创建普通笔记，解析其中的 wiki 链接，并解析此前指向同名笔记的未解析链接
+func (s *NoteService) Create(userID uint, title, content string) (*NoteDetail, error) {
+	title = strings.TrimSpace(title)
+	if err := validateNote(title, content); err != nil {
+		return nil, err
+	}
+	note := &model.Note{UserID: userID, Title: title, Content: content}
+	if err := s.noteRepo.Create(note); err != nil {
+		return nil, err
+	}
+	if err := s.noteRepo.ResolvePending(userID, model.NoteLinkTargetNote, note.Title, note.ID); err != nil {
+		return nil, err
+	}
+	if err := s.syncLinks(note); err != nil {
+		return nil, err
+	}
+	return s.detail(note)
+}
+
+// Update 部分更新笔记（日记不能改标题）
+func (s *NoteService) Update(userID, id uint, in NoteUpdate) (*NoteDetail, error) {
+	note, err := s.noteRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if in.Title != nil {
+		title := strings.TrimSpace(*in.Title)
+		if note.JournalDate != nil && title != note.Title {
+			return nil, ErrJournalTitleFixed
+		}
+		if err := validateNote(title, note.Content); err != nil {
+			return nil, err
+		}
+		updates["title"] = title
+		note.Title = title
+	}
+	if in.Content != nil {
+		if err := validateNote(note.Title, *in.Content); err != nil {
+			return nil, err
+		}
+		updates["content"] = *in.Content
+		note.Content = *in.Content
+	}
+	if len(updates) == 0 {
+		return s.detail(note)
+	}
+	if _, err := s.noteRepo.UpdateFields(userID, id, updates); err != nil {
+		return nil, err
+	}
+	if in.Title != nil && note.JournalDate == nil {
+		if err := s.noteRepo.ResolvePending(userID, model.NoteLinkTargetNote, note.Title, note.ID); err != nil {
+			return nil, err
+		}
+	}
+	if in.Content != nil {
+		if err := s.syncLinks(note); err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(userID, id)
+}
+
+// Get 获取笔记详情
+func (s *NoteService) Get(userID, id uint) (*NoteDetail, error) {
+	note, err := s.noteRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(note)
+}
+
+// Delete 删除笔记
+func (s *NoteService) Delete(userID, id uint) (int64, error) {
+	return s.noteRepo.Delete(userID, id)
+}
+
+// List 搜索笔记（游标分页，limit 默认 20、最大 100），返回摘要
+func (s *NoteService) List(userID uint, f repository.NoteFilter) ([]NoteSummary, error) {
+	if f.Limit <= 0 {
+		f.Limit = 20
+	} else if f.Limit > 100 {
+		f.Limit = 100
+	}
+	notes, err := s.noteRepo.List(userID, f)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeNotes(notes), nil
+}
+
+// Journal 获取某天的日记（date 为 2006-01-02 或 today）
+func (s *NoteService) Journal(userID uint, date string) (*NoteDetail, error) {
+	date, err := ParseJournalDate(date)
+	if err != nil {
+		return nil, err
+	}
+	note, err := s.noteRepo.GetByJournalDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(note)
+}
+
+// SaveJournal 写入某天的日记，不存在时创建（标题固定为日期）
+func (s *NoteService) SaveJournal(userID uint, date, content string) (*NoteDetail, error) {
+	date, err := ParseJournalDate(date)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateNote(date, content); err != nil {
+		return nil, err
+	}
+	note, err := s.noteRepo.GetByJournalDate(userID, date)
+	switch {
+	case err == nil:
+		return s.Update(userID, note.ID, NoteUpdate{Content: &content})
+	case !errors.Is(err, repository.ErrNoteNotFound):
+		return nil, err
+	}
+
+	note = &model.Note{UserID: userID, JournalDate: &date, Title: date, Content: content}
+	if err := s.noteRepo.Create(note); err != nil {
+		return nil, err
+	}
+	if err := s.noteRepo.ResolvePending(userID, model.NoteLinkTargetJournal, date, note.ID); err != nil {
+		return nil, err
+	}
+	if err := s.syncLinks(note); err != nil {
+		return nil, err
+	}
+	return s.detail(note)
+}
+
+// TodoBacklinks 列出链接到某条待办的笔记
+func (s *NoteService) TodoBacklinks(userID, todoID uint) ([]NoteSummary, error) {
+	notes, err := s.noteRepo.ListBacklinks(userID, []string{model.NoteLinkTargetTodo}, todoID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeNotes(notes), nil
+}
+
+// ParseJournalDate 校验日记日期，today 取北京时间的当天
+func ParseJournalDate(date string) (string, error) {
+	date = strings.TrimSpace(date)
+	if date == "today" {
+		return time.Now().In(workday.Location).Format(wikilink.DateLayout), nil
+	}
+	if _, err := time.Parse(wikilink.DateLayout, date); err != nil {
+		return "", ErrInvalidJournalDate
+	}
+	return date, nil
+}
+
+// syncLinks 重新解析笔记正文中的 wiki 链接；目标暂不存在的链接也会保存，待目标创建后解析
+func (s *NoteService) syncLinks(note *model.Note) error {
+	parsed := wikilink.Parse(note.Content)
+	links := make([]model.NoteLink, 0, len(parsed))
+	for _, l := range parsed {
+		link := model.NoteLink{UserID: note.UserID, NoteID: note.ID, Target: l.Target}
+		var err error
+		switch l.Kind {
+		case wikilink.KindTodo:
+			link.TargetType = model.NoteLinkTargetTodo
+			var todo *model.Todo
+			if l.TodoKey != "" {
+				todo, err = s.todoRepo.GetByKey(note.UserID, l.TodoKey)
+			} else {
+				todo, err = s.todoRepo.GetByID(note.UserID, l.TodoID)
+			}
+			if err == nil {
+				link.TargetID = todo.ID
+			} else if errors.Is(err, repository.ErrTodoNotFound) {
+				err = nil
+			}
+		case wikilink.KindJournal:
+			link.TargetType = model.NoteLinkTargetJournal
+			link.TargetID, err = s.resolveNote(s.noteRepo.GetByJournalDate(note.UserID, l.Target))
+		default:
+			link.TargetType = model.NoteLinkTargetNote
+			link.TargetID, err = s.resolveNote(s.noteRepo.FindByTitle(note.UserID, l.Target))
+		}
+		if err != nil {
+			return err
+		}
+		links = append(links, link)
+	}
+	return s.noteRepo.ReplaceLinks(note.ID, links)
+}
+
+func (s *NoteService) resolveNote(note *model.Note, err error) (uint, error) {
+	if err != nil {
+		if errors.Is(err, repository.ErrNoteNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return note.ID, nil
+}
+
+// detail 组装笔记详情：链接目标的标题与反向链接
+func (s *NoteService) detail(note *model.Note) (*NoteDetail, error) {
+	links, err := s.noteRepo.ListLinks(note.ID)
+	if err != nil {
+		return nil, err
+	}
+	var noteIDs []uint
+	for _, l := range links {
+		if l.TargetType != model.NoteLinkTargetTodo && l.TargetID != 0 {
+			noteIDs = append(noteIDs, l.TargetID)
+		}
+	}
+	targets, err := s.noteRepo.ListByIDs(note.UserID, noteIDs)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[uint]string, len(targets))
+	for _, n := range targets {
+		titles[n.ID] = n.Title
+	}
+
+	d := &NoteDetail{Note: note, Links: make([]NoteLinkView, 0, len(links))}
+	for _, l := range links {
+		view := NoteLinkView{Type: l.TargetType, Target: l.Target, ID: l.TargetID}
+		if l.TargetID != 0 {
+			if l.TargetType == model.NoteLinkTargetTodo {
+				// 待办可能在链接建立后被删除
+				if todo, err := s.todoRepo.GetByID(note.UserID, l.TargetID); err == nil {
+					view.Title, view.Resolved = todo.Title, true
+				}
+			} else {
+				view.Title, view.Resolved = titles[l.TargetID]
+			}
+		}
+		d.Links = append(d.Links, view)
+	}
+
+	backlinks, err := s.noteRepo.ListBacklinks(note.UserID,
+		[]string{model.NoteLinkTargetNote, model.NoteLinkTargetJournal}, note.ID)
+	if err != nil {
+		return nil, err
+	}
+	d.Backlinks = summarizeNotes(backlinks)
+	return d, nil
+}
+
+func validateNote(title, content string) error {
+	if title == "" {
+		return ErrNoteTitleRequired
+	}
+	if len([]rune(title)) > maxNoteTitleLen {
+		return ErrNoteTitleTooLong
+	}
+	if len(content) > maxNoteContentLen {
+		return ErrNoteContentTooLong
+	}
+	return nil
+}
+
+func summarizeNotes(notes []model.Note) []NoteSummary {
+	out := make([]NoteSummary, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, NoteSummary{ID: n.ID, Title: n.Title, JournalDate: n.JournalDate, UpdatedAt: n.UpdatedAt})
+	}
+	return out
+}
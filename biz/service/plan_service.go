@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/billing"
+)
+
+var (
+	// ErrInvalidPlanCode 套餐代码格式无效
+	ErrInvalidPlanCode = errors.New("plan code must match [a-z0-9][a-z0-9._-]*, at most 50 characters")
+	// ErrPlanNameRequired 套餐名称不能为空
+	ErrPlanNameRequired = errors.New("plan name is required")
+	// ErrInvalidPlanLimit 限额项无效
+	ErrInvalidPlanLimit = errors.New("limit keys must match [a-z0-9][a-z0-9._-]* and values must be >= 0")
+	// ErrInvalidPlanFeature 功能名无效
+	ErrInvalidPlanFeature = errors.New("feature names must match [a-z0-9][a-z0-9._-]*")
+	// ErrPlanInUse 套餐仍有分配，不能删除
+	ErrPlanInUse = errors.New("plan is still assigned")
+	// ErrInvalidPlanSubject 分配主体无效
+	ErrInvalidPlanSubject = errors.New("subject_type must be user or workspace with a subject_id")
+	// ErrBillingNotConfigured 未配置计费提供方
+	ErrBillingNotConfigured = errors.New("billing provider is not configured")
+	// ErrNoSubscription 套餐分配没有关联的订阅
+	ErrNoSubscription = errors.New("plan assignment has no subscription")
+)
+
+// 审计日志动作
+const (
+	AuditActionPlanCreate   = "plan.create"
+	AuditActionPlanUpdate   = "plan.update"
+	AuditActionPlanDelete   = "plan.delete"
+	AuditActionPlanAssign   = "plan.assign"
+	AuditActionPlanUnassign = "plan.unassign"
+)
+
+// PlanInput 创建或更新套餐的参数，nil 字段保持不变（创建时取零值）
+type PlanInput struct {
+	Name        *string
+	Description *string
+	IsDefault   *bool
+	Limits      *map[string]int
+	Features    *[]string
+}
+
+// PlanAssignInput 分配套餐的参数
+type PlanAssignInput struct {
+	SubjectType string
+	SubjectID   uint
+	PlanCode    string
+	ExpiresAt   *time.Time // 手动分配的到期时间
+	Billing     bool       // 通过计费提供方订阅
+}
+
+// PlanService 套餐定义与分配（管理员），订阅经由计费提供方
+type PlanService struct {
+	planRepo  *repository.PlanRepository
+	userRepo  *repository.UserRepository
+	auditRepo *repository.AuditLogRepository
+	provider  billing.Provider // 为空时只能手动分配
+}
+
+// NewPlanService 创建套餐服务实例
+func NewPlanService(
+	planRepo *repository.PlanRepository,
+	userRepo *repository.UserRepository,
+	auditRepo *repository.AuditLogRepository,
+	provider billing.Provider,
+) *PlanService {
+	return &PlanService{planRepo: planRepo, userRepo: userRepo, auditRepo: auditRepo, provider: provider}
+}
+
+// CreatePlan 创建套餐
+func (s *PlanService) CreatePlan(adminID uint, code string, in PlanInput) (*model.Plan, error) {
+	if len(code) > 50 || !flagKeyPattern.MatchString(code) {
+		return nil, ErrInvalidPlanCode
+	}
+	plan := &model.Plan{Code: code, Limits: map[string]int{}, Features: []string{}}
+	if err := applyPlanInput(plan, in); err != nil {
+		return nil, err
+	}
+	if plan.Name == "" {
+		return nil, ErrPlanNameRequired
+	}
+	if err := s.planRepo.Create(plan); err != nil {
+		return nil, err
+	}
+	return plan, s.auditRepo.Record(adminID, AuditActionPlanCreate, "plan", plan.ID, plan)
+}
+
+// UpdatePlan 部分更新套餐
+func (s *PlanService) UpdatePlan(adminID uint, code string, in PlanInput) (*model.Plan, error) {
+	var before model.Plan
+	plan, err := s.planRepo.Update(code, func(plan *model.Plan) error {
+		before = *plan
+		if err := applyPlanInput(plan, in); err != nil {
+			return err
+		}
+		if plan.Name == "" {
+			return ErrPlanNameRequired
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, s.auditRepo.Record(adminID, AuditActionPlanUpdate, "plan", plan.ID,
+		map[string]interface{}{"before": before, "after": plan})
+}
+
+// DeletePlan 删除没有分配的套餐
+func (s *PlanService) DeletePlan(adminID uint, code string) error {
+	count, err := s.planRepo.CountAssignments(code)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPlanInUse
+	}
+	plan, err := s.planRepo.Delete(code)
+	if err != nil {
+		return err
+	}
+	return s.auditRepo.Record(adminID, AuditActionPlanDelete, "plan", plan.ID, plan)
+}
+
+// ListPlans 列出全部套餐
+func (s *PlanService) ListPlans() ([]model.Plan, error) {
+	return s.planRepo.List()
+}
+
+// ListAssignments 列出套餐分配，planCode 非空时只列该套餐的
+func (s *PlanService) ListAssignments(planCode string) ([]model.PlanAssignment, error) {
+	return s.planRepo.ListAssignments(planCode)
+}
+
+// Assign 为用户或工作区分配套餐，覆盖已有分配（原有订阅会被取消）；
+// Billing 为 true 时先在计费提供方创建订阅，状态以提供方为准
+func (s *PlanService) Assign(ctx context.Context, adminID uint, in PlanAssignInput) (*model.PlanAssignment, error) {
+	if err := s.checkSubject(in.SubjectType, in.SubjectID); err != nil {
+		return nil, err
+	}
+	if _, err := s.planRepo.GetByCode(in.PlanCode); err != nil {
+		return nil, err
+	}
+	if in.Billing && s.provider == nil {
+		return nil, ErrBillingNotConfigured
+	}
+	previous, err := s.planRepo.GetAssignment(in.SubjectType, in.SubjectID)
+	if err != nil && !errors.Is(err, repository.ErrPlanAssignmentNotFound) {
+		return nil, err
+	}
+
+	a := &model.PlanAssignment{
+		SubjectType: in.SubjectType,
+		SubjectID:   in.SubjectID,
+		PlanCode:    in.PlanCode,
+		Status:      model.PlanStatusActive,
+		ExpiresAt:   in.ExpiresAt,
+	}
+	if in.Billing {
+		sub, err := s.provider.Subscribe(ctx, customerRef(in.SubjectType, in.SubjectID), in.PlanCode)
+		if err != nil {
+			return nil, fmt.Errorf("subscribe: %w", err)
+		}
+		a.BillingProvider, a.SubscriptionID = s.provider.Name(), sub.ID
+		applySubscription(a, sub)
+	}
+	if err := s.planRepo.SaveAssignment(a); err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		if err := s.cancelSubscription(ctx, previous); err != nil {
+			return nil, err
+		}
+	}
+	return a, s.auditRepo.Record(adminID, AuditActionPlanAssign, in.SubjectType, in.SubjectID,
+		map[string]interface{}{"before": previous, "after": a})
+}
+
+// Unassign 取消分配（及其订阅），主体回到默认套餐
+func (s *PlanService) Unassign(ctx context.Context, adminID uint, subjectType string, subjectID uint) error {
+	a, err := s.planRepo.GetAssignment(subjectType, subjectID)
+	if err != nil {
+		return err
+	}
+	if err := s.cancelSubscription(ctx, a); err != nil {
+		return err
+	}
+	if _, err := s.planRepo.DeleteAssignment(subjectType, subjectID); err != nil {
+		return err
+	}
+	return s.auditRepo.Record(adminID, AuditActionPlanUnassign, subjectType, subjectID, a)
+}
+
+// SyncAssignment 从计费提供方拉取订阅的最新状态（扣款失败、取消、续期、换套餐）
+func (s *PlanService) SyncAssignment(ctx context.Context, subjectType string, subjectID uint) (*model.PlanAssignment, error) {
+	a, err := s.planRepo.GetAssignment(subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if a.SubscriptionID == "" {
+		return nil, ErrNoSubscription
+	}
+	if s.provider == nil || s.provider.Name() != a.BillingProvider {
+		return nil, ErrBillingNotConfigured
+	}
+	sub, err := s.provider.Subscription(ctx, a.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	applySubscription(a, sub)
+	if sub.PlanCode != a.PlanCode {
+		if _, err := s.planRepo.GetByCode(sub.PlanCode); err == nil {
+			a.PlanCode = sub.PlanCode
+		}
+	}
+	if err := s.planRepo.SaveAssignment(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *PlanService) checkSubject(subjectType string, subjectID uint) error {
+	if subjectID == 0 || (subjectType != model.PlanSubjectUser && subjectType != model.PlanSubjectWorkspace) {
+		return ErrInvalidPlanSubject
+	}
+	if subjectType == model.PlanSubjectUser {
+		if _, err := s.userRepo.GetByID(subjectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelSubscription 取消分配关联的订阅；提供方已找不到订阅时忽略
+func (s *PlanService) cancelSubscription(ctx context.Context, a *model.PlanAssignment) error {
+	if a.SubscriptionID == "" || s.provider == nil || s.provider.Name() != a.BillingProvider {
+		return nil
+	}
+	if err := s.provider.Cancel(ctx, a.SubscriptionID); err != nil && !errors.Is(err, billing.ErrSubscriptionNotFound) {
+		return fmt.Errorf("cancel subscription: %w", err)
+	}
+	return nil
+}
+
+func applySubscription(a *model.PlanAssignment, sub *billing.Subscription) {
+	a.Status = sub.Status
+	if !sub.CurrentPeriodEnd.IsZero() {
+		end := sub.CurrentPeriodEnd
+		a.CurrentPeriodEnd = &end
+	}
+}
+
+// customerRef 计费提供方中的客户标识
+func customerRef(subjectType string, subjectID uint) string {
+	return subjectType + ":" + strconv.FormatUint(uint64(subjectID), 10)
+}
+
+func applyPlanInput(plan *model.Plan, in PlanInput) error {
+	if in.Name != nil {
+		plan.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Description != nil {
+		plan.Description = *in.Description
+	}
+	if in.IsDefault != nil {
+		plan.IsDefault = *in.IsDefault
+	}
+	if in.Limits != nil {
+		for key, value := range *in.Limits {
+			if !flagKeyPattern.MatchString(key) || value < 0 {
+				return ErrInvalidPlanLimit
+			}
+		}
+		plan.Limits = *in.Limits
+		if plan.Limits == nil {
+			plan.Limits = map[string]int{}
+		}
+	}
+	if in.Features != nil {
+		features := []string{}
+		for _, f := range *in.Features {
+			if !flagKeyPattern.MatchString(f) {
+				return ErrInvalidPlanFeature
+			}
+			if !slices.Contains(features, f) {
+				features = append(features, f)
+			}
+		}
+		plan.Features = features
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/billing"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+func TestPlanSyncAssignment(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	planRepo := repository.NewPlanRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	fake := billing.NewFake()
+	plans := NewPlanService(planRepo, userRepo, repository.NewAuditLogRepository(db), fake)
+	entitlements := NewEntitlementService(planRepo)
+
+	user := &model.User{Username: "alice", PasswordHash: "x"}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatal(err)
+	}
+	setDefaultPlan(t, planRepo, nil)
+	pro := "Pro"
+	if _, err := plans.CreatePlan(1, "pro", PlanInput{Name: &pro}); err != nil {
+		t.Fatal(err)
+	}
+	a, err := plans.Assign(ctx, 1, PlanAssignInput{SubjectType: model.PlanSubjectUser, SubjectID: user.ID, PlanCode: "pro", Billing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.BillingProvider != "fake" || a.SubscriptionID == "" || a.Status != model.PlanStatusActive || a.CurrentPeriodEnd == nil {
+		t.Fatalf("billed assignment = %+v", a)
+	}
+
+	steps := []struct {
+		status     string
+		wantPlan   string
+		wantSource string
+	}{
+		{billing.StatusPastDue, "pro", PlanSourceUser}, // 宽限期内仍然有效
+		{billing.StatusActive, "pro", PlanSourceUser},
+		{billing.StatusCanceled, "free", PlanSourceDefault},
+	}
+	for _, step := range steps {
+		if err := fake.SetStatus(a.SubscriptionID, step.status); err != nil {
+			t.Fatal(err)
+		}
+		synced, err := plans.SyncAssignment(ctx, model.PlanSubjectUser, user.ID)
+		if err != nil {
+			t.Fatalf("sync after %s: %v", step.status, err)
+		}
+		if synced.Status != step.status {
+			t.Errorf("assignment status after %s = %s", step.status, synced.Status)
+		}
+		resolved, err := entitlements.Resolve(SubjectForUser(user.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolved.Plan == nil || resolved.Plan.Code != step.wantPlan || resolved.Source != step.wantSource {
+			t.Errorf("after %s: resolved %+v, want %s from %s", step.status, resolved, step.wantPlan, step.wantSource)
+		}
+	}
+
+	// 重新分配时取消原订阅
+	previous := a.SubscriptionID
+	if err := fake.SetStatus(previous, billing.StatusActive); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plans.Assign(ctx, 1, PlanAssignInput{SubjectType: model.PlanSubjectUser, SubjectID: user.ID, PlanCode: "pro", Billing: true}); err != nil {
+		t.Fatal(err)
+	}
+	if sub, err := fake.Subscription(ctx, previous); err != nil || sub.Status != billing.StatusCanceled {
+		t.Errorf("previous subscription = %+v, %v; want canceled", sub, err)
+	}
+
+	// 手动分配没有订阅，不能同步
+	if _, err := plans.Assign(ctx, 1, PlanAssignInput{SubjectType: model.PlanSubjectUser, SubjectID: user.ID, PlanCode: "pro"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plans.SyncAssignment(ctx, model.PlanSubjectUser, user.ID); !errors.Is(err, ErrNoSubscription) {
+		t.Errorf("sync of a manual assignment = %v, want ErrNoSubscription", err)
+	}
+}
+
+func TestAccountMergePlanAssignments(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name           string
+		source, target string // 分配的状态，空表示没有分配
+		wantStatus     string // 合并后目标账号的分配状态
+		wantDropped    string // 被舍弃并取消订阅的一方
+	}{
+		{"source only", model.PlanStatusActive, "", model.PlanStatusActive, ""},
+		{"target only", "", model.PlanStatusActive, model.PlanStatusActive, ""},
+		{"both effective keeps target", model.PlanStatusTrialing, model.PlanStatusActive, model.PlanStatusActive, "source"},
+		{"canceled target replaced", model.PlanStatusActive, model.PlanStatusCanceled, model.PlanStatusActive, "target"},
+		{"neither effective keeps target", model.PlanStatusCanceled, model.PlanStatusCanceled, model.PlanStatusCanceled, "source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			planRepo := repository.NewPlanRepository(db)
+			userRepo := repository.NewUserRepository(db)
+			auditRepo := repository.NewAuditLogRepository(db)
+			fake := billing.NewFake()
+			plans := NewPlanService(planRepo, userRepo, auditRepo, fake)
+			pro := "Pro"
+			if _, err := plans.CreatePlan(1, "pro", PlanInput{Name: &pro}); err != nil {
+				t.Fatal(err)
+			}
+
+			users := map[string]*model.User{"source": {Username: "source", PasswordHash: "x"}, "target": {Username: "target", PasswordHash: "x"}}
+			subscriptions := map[string]string{}
+			for side, status := range map[string]string{"source": tt.source, "target": tt.target} {
+				if err := userRepo.Create(users[side]); err != nil {
+					t.Fatal(err)
+				}
+				if status == "" {
+					continue
+				}
+				a, err := plans.Assign(ctx, 1, PlanAssignInput{SubjectType: model.PlanSubjectUser, SubjectID: users[side].ID, PlanCode: "pro", Billing: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := fake.SetStatus(a.SubscriptionID, status); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := plans.SyncAssignment(ctx, model.PlanSubjectUser, users[side].ID); err != nil {
+					t.Fatal(err)
+				}
+				subscriptions[side] = a.SubscriptionID
+			}
+
+			merge := NewAccountMergeService(userRepo, repository.NewAccountMergeRepository(db), auditRepo, fake)
+			result, err := merge.MergeByAdmin(1, users["source"].ID, users["target"].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := planRepo.GetAssignment(model.PlanSubjectUser, users["source"].ID); !errors.Is(err, repository.ErrPlanAssignmentNotFound) {
+				t.Errorf("source assignment after merge: %v, want none", err)
+			}
+			a, err := planRepo.GetAssignment(model.PlanSubjectUser, users["target"].ID)
+			if err != nil {
+				t.Fatalf("target assignment after merge: %v", err)
+			}
+			if a.Status != tt.wantStatus {
+				t.Errorf("target assignment status = %s, want %s", a.Status, tt.wantStatus)
+			}
+
+			dropped := result.Stats.DroppedPlan
+			if tt.wantDropped == "" {
+				if dropped != nil {
+					t.Fatalf("dropped plan = %+v, want none", dropped)
+				}
+				return
+			}
+			if dropped == nil || dropped.SubscriptionID != subscriptions[tt.wantDropped] {
+				t.Fatalf("dropped plan = %+v, want the %s assignment", dropped, tt.wantDropped)
+			}
+			if a.SubscriptionID == dropped.SubscriptionID {
+				t.Errorf("kept and dropped assignments share subscription %s", a.SubscriptionID)
+			}
+			if sub, err := fake.Subscription(ctx, dropped.SubscriptionID); err != nil || sub.Status != billing.StatusCanceled {
+				t.Errorf("dropped subscription = %+v, %v; want canceled", sub, err)
+			}
+		})
+	}
+}
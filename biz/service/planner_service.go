@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/planner"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrInvalidGroupBy 分组方式非法
+	ErrInvalidGroupBy = errors.New("group_by must be day or project")
+)
+
+// 计划本分组方式
+const (
+	PlannerGroupByDay     = "day"
+	PlannerGroupByProject = "project"
+)
+
+// maxPlannerDays 单次导出的最大天数
+const maxPlannerDays = 62
+
+var weekdayNames = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// PlannerOptions 导出计划本的参数（日期均为北京时间，包含首尾两天）
+type PlannerOptions struct {
+	From        time.Time
+	To          time.Time
+	GroupBy     string
+	IncludeDone bool
+}
+
+// PlannerService 可打印计划本导出
+type PlannerService struct {
+	todoRepo    *repository.TodoRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewPlannerService 创建计划本服务实例
+func NewPlannerService(todoRepo *repository.TodoRepository, projectRepo *repository.ProjectRepository) *PlannerService {
+	return &PlannerService{todoRepo: todoRepo, projectRepo: projectRepo}
+}
+
+// ExportPDF 把日期范围内（按截止时间）的待办渲染为 PDF 写入 w
+func (s *PlannerService) ExportPDF(w io.Writer, userID uint, opts PlannerOptions) error {
+	p, err := s.Build(userID, opts)
+	if err != nil {
+		return err
+	}
+	font, err := planner.LoadFont(os.Getenv("PDF_FONT_PATH"))
+	if err != nil {
+		return err
+	}
+	return planner.Render(w, *p, font, workday.Location)
+}
+
+// Build 查询待办并组织为计划本结构
+func (s *PlannerService) Build(userID uint, opts PlannerOptions) (*planner.Planner, error) {
+	if opts.GroupBy == "" {
+		opts.GroupBy = PlannerGroupByDay
+	}
+	if opts.GroupBy != PlannerGroupByDay && opts.GroupBy != PlannerGroupByProject {
+		return nil, ErrInvalidGroupBy
+	}
+	from := dayStart(opts.From)
+	end := dayStart(opts.To).AddDate(0, 0, 1)
+	days := int(end.Sub(from).Hours()/24 + 0.5)
+	if days < 1 || days > maxPlannerDays {
+		return nil, ErrInvalidDateRange
+	}
+
+	todos, err := s.todoRepo.ListByDueRange(userID, from, end, opts.IncludeDone)
+	if err != nil {
+		return nil, err
+	}
+
+	done := 0
+	for _, t := range todos {
+		if t.Status == 1 {
+			done++
+		}
+	}
+	p := &planner.Planner{
+		Title: fmt.Sprintf("计划本 %s ~ %s", from.Format(workday.DateLayout), end.AddDate(0, 0, -1).Format(workday.DateLayout)),
+		Subtitle: fmt.Sprintf("共 %d 项，已完成 %d 项 · 生成于 %s",
+			len(todos), done, time.Now().In(workday.Location).Format("2006-01-02 15:04")),
+	}
+
+	if opts.GroupBy == PlannerGroupByDay {
+		p.Sections = groupPlannerByDay(todos, from, days)
+		return p, nil
+	}
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	p.Sections = groupPlannerByProject(todos, projects)
+	return p, nil
+}
+
+// groupPlannerByDay 范围内每天一节（没有待办的日期留空白行），附注节假日、调休与农历
+func groupPlannerByDay(todos []model.Todo, from time.Time, days int) []planner.Section {
+	cal := workday.Default()
+	sections := make([]planner.Section, days)
+	index := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		d := from.AddDate(0, 0, i)
+		info := cal.Day(d)
+		note := info.Lunar
+		switch {
+		case info.Holiday:
+			note = info.Name + " · " + note
+		case info.Adjusted:
+			note = "调休上班 · " + note
+		}
+		sections[i] = planner.Section{
+			Heading: fmt.Sprintf("%s %s", d.Format("01月02日"), weekdayNames[d.Weekday()]),
+			Note:    note,
+		}
+		index[info.Date] = i
+	}
+	for _, t := range todos {
+		if i, ok := index[t.DueTime.In(workday.Location).Format(workday.DateLayout)]; ok {
+			sections[i].Items = append(sections[i].Items, plannerItem(t))
+		}
+	}
+	return sections
+}
+
+// groupPlannerByProject 按项目树节点分节（标题为完整路径），未归属项目的待办放在最后
+func groupPlannerByProject(todos []model.Todo, projects []model.Project) []planner.Section {
+	var sections []planner.Section
+	index := make(map[uint]int, len(projects))
+	paths := ProjectPaths(projects)
+	// 按路径排序，子节点紧跟在父节点之后
+	ordered := append([]model.Project(nil), projects...)
+	sort.SliceStable(ordered, func(i, j int) bool { return paths[ordered[i].ID] < paths[ordered[j].ID] })
+	for _, p := range ordered {
+		index[p.ID] = len(sections)
+		sections = append(sections, planner.Section{Heading: paths[p.ID]})
+	}
+	inbox := planner.Section{Heading: "未归类"}
+	for _, t := range todos {
+		if t.ProjectID != nil {
+			if i, ok := index[*t.ProjectID]; ok {
+				sections[i].Items = append(sections[i].Items, plannerItem(t))
+				continue
+			}
+		}
+		inbox.Items = append(inbox.Items, plannerItem(t))
+	}
+	sections = append(sections, inbox)
+
+	// 去掉没有待办的项目
+	out := sections[:0]
+	for _, s := range sections {
+		if len(s.Items) > 0 {
+			s.Note = fmt.Sprintf("%d 项", len(s.Items))
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func plannerItem(t model.Todo) planner.Item {
+	return planner.Item{Title: t.Title, Done: t.Status == 1, Due: t.DueTime}
+}
+
+// dayStart 北京时间当天零点
+func dayStart(t time.Time) time.Time {
+	t = t.In(workday.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, workday.Location)
+}
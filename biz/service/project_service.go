@@ -0,0 +1,346 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrProjectNameRequired 项目名称必填
+	ErrProjectNameRequired = errors.New("project name is required")
+	// ErrInvalidProjectKind 节点类型无效
+	ErrInvalidProjectKind = errors.New("kind must be area, project or section")
+	// ErrInvalidProjectParent 父节点类型不允许（领域只能挂在领域下，项目只能挂在领域下，分区必须挂在项目下）
+	ErrInvalidProjectParent = errors.New("invalid parent for this kind")
+	// ErrProjectCycle 不能把节点移到自身或其下级之下
+	ErrProjectCycle = errors.New("cannot move a node under itself or its descendants")
+	// ErrInvalidProjectKey 待办编号前缀格式无效
+	ErrInvalidProjectKey = errors.New("key must be 2-10 letters or digits starting with a letter")
+)
+
+// allowedParentKinds 各类节点允许的父节点类型，空字符串表示允许作为顶层节点
+var allowedParentKinds = map[string][]string{
+	model.ProjectKindArea:    {"", model.ProjectKindArea},
+	model.ProjectKindProject: {"", model.ProjectKindArea},
+	model.ProjectKindSection: {model.ProjectKindProject},
+}
+
+// TodoCounts 待办数量
+type TodoCounts struct {
+	Open int64 `json:"open"`
+	Done int64 `json:"done"`
+}
+
+// ProjectNode 项目树节点：Counts 为直接挂在该节点上的待办，Total 含全部下级
+type ProjectNode struct {
+	model.Project
+	Counts   TodoCounts     `json:"counts"`
+	Total    TodoCounts     `json:"total"`
+	Children []*ProjectNode `json:"children"`
+}
+
+// ProjectTree 项目树及未归属项目的待办数量
+type ProjectTree struct {
+	Nodes      []*ProjectNode `json:"nodes"`
+	Unassigned TodoCounts     `json:"unassigned"`
+}
+
+// ProjectService 项目服务
+type ProjectService struct {
+	projectRepo *repository.ProjectRepository
+	todoRepo    *repository.TodoRepository
+}
+
+// NewProjectService 创建项目服务实例
+func NewProjectService(projectRepo *repository.ProjectRepository, todoRepo *repository.TodoRepository) *ProjectService {
+	return &ProjectService{projectRepo: projectRepo, todoRepo: todoRepo}
+}
+
+// Create 创建节点（kind 为空时为项目，key 为空表示不设编号前缀，parentID 为 0 表示顶层）
+func (s *ProjectService) Create(userID uint, name, kind, key string, parentID uint) (*model.Project, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrProjectNameRequired
+	}
+	if key != "" {
+		var ok bool
+		if key, ok = model.NormalizeProjectKey(key); !ok {
+			return nil, ErrInvalidProjectKey
+		}
+	}
+	if kind == "" {
+		kind = model.ProjectKindProject
+	}
+	if _, ok := allowedParentKinds[kind]; !ok {
+		return nil, ErrInvalidProjectKind
+	}
+	project := &model.Project{UserID: userID, Name: name, Kind: kind, Key: key}
+	if parentID != 0 || kind == model.ProjectKindSection {
+		projects, err := s.projectRepo.List(userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProjectParent(indexProjects(projects), project, parentID); err != nil {
+			return nil, err
+		}
+		project.ParentID = &parentID
+	}
+	if err := s.projectRepo.Create(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// List 列出项目
+func (s *ProjectService) List(userID uint) ([]model.Project, error) {
+	return s.projectRepo.List(userID)
+}
+
+// Move 修改节点的父节点（parentID 为 0 表示移到顶层），拒绝形成环
+func (s *ProjectService) Move(userID, id, parentID uint) (*model.Project, error) {
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := indexProjects(projects)
+	project, ok := byID[id]
+	if !ok {
+		return nil, repository.ErrProjectNotFound
+	}
+	if err := checkProjectParent(byID, project, parentID); err != nil {
+		return nil, err
+	}
+	var parent *uint
+	if parentID != 0 {
+		parent = &parentID
+	}
+	if _, err := s.projectRepo.SetParent(userID, id, parent); err != nil {
+		return nil, err
+	}
+	project.ParentID = parent
+	return project, nil
+}
+
+// SetKey 设置或修改节点的待办编号前缀（前缀设置后不能清空）：已编号的待办改用新前缀，
+// 以该节点为最近带前缀上级的待办中尚未编号的按创建顺序补发编号
+func (s *ProjectService) SetKey(userID, id uint, key string) (*model.Project, error) {
+	key, ok := model.NormalizeProjectKey(key)
+	if !ok {
+		return nil, ErrInvalidProjectKey
+	}
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := indexProjects(projects)[id]; !ok {
+		return nil, repository.ErrProjectNotFound
+	}
+	// 下级节点自己设置了前缀时，它及其下级的待办归它编号
+	children := make(map[uint][]model.Project, len(projects))
+	for _, p := range projects {
+		children[parentOf(p)] = append(children[parentOf(p)], p)
+	}
+	scope := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(scope); i++ {
+		for _, child := range children[scope[i]] {
+			if child.Key == "" && !seen[child.ID] {
+				seen[child.ID] = true
+				scope = append(scope, child.ID)
+			}
+		}
+	}
+	project, err := s.projectRepo.SetKey(userID, id, key, scope)
+	if err != nil {
+		return nil, err
+	}
+	s.todoRepo.InvalidateCache(userID)
+	return project, nil
+}
+
+// Tree 返回用户的项目树，每个节点附带自身与含下级的待办数量
+func (s *ProjectService) Tree(userID uint) (*ProjectTree, error) {
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.todoRepo.CountByProject(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &ProjectTree{Nodes: []*ProjectNode{}}
+	nodes := make(map[uint]*ProjectNode, len(projects))
+	for _, p := range projects {
+		nodes[p.ID] = &ProjectNode{Project: p, Children: []*ProjectNode{}}
+	}
+	for _, c := range counts {
+		target := &tree.Unassigned
+		if c.ProjectID != nil {
+			node, ok := nodes[*c.ProjectID]
+			if !ok {
+				continue // 指向已删除项目的残留引用
+			}
+			target = &node.Counts
+		}
+		if c.Status == 1 {
+			target.Done += c.Count
+		} else {
+			target.Open += c.Count
+		}
+	}
+	for _, p := range projects {
+		node := nodes[p.ID]
+		if parent, ok := nodes[parentOf(p)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Nodes = append(tree.Nodes, node)
+		}
+	}
+	for _, node := range tree.Nodes {
+		sumProjectNode(node)
+	}
+	return tree, nil
+}
+
+// SubtreeIDs 返回节点自身及其全部下级的 ID，用于“某领域下的全部待办”这类递归筛选
+func (s *ProjectService) SubtreeIDs(userID, id uint) ([]uint, error) {
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := indexProjects(projects)[id]; !ok {
+		return nil, repository.ErrProjectNotFound
+	}
+	return expandProjectIDs(projects, []uint{id}), nil
+}
+
+// Delete 删除项目
+func (s *ProjectService) Delete(userID, id uint) (int64, error) {
+	return s.projectRepo.Delete(userID, id)
+}
+
+// MoveTodo 把待办移入项目（projectID 为 0 表示移出项目）
+func (s *ProjectService) MoveTodo(userID, todoID, projectID uint) (int64, error) {
+	if projectID == 0 {
+		return s.todoRepo.SetProject(userID, todoID, nil)
+	}
+	// 确认项目属于当前用户
+	if _, err := s.projectRepo.GetByID(userID, projectID); err != nil {
+		return 0, err
+	}
+	return s.todoRepo.SetProject(userID, todoID, &projectID)
+}
+
+// checkProjectParent 校验 project 挂到 parentID 下是否合法：父节点类型符合层级规则，且不是自身或其下级
+func checkProjectParent(byID map[uint]*model.Project, project *model.Project, parentID uint) error {
+	parentKind := ""
+	if parentID != 0 {
+		parent, ok := byID[parentID]
+		if !ok {
+			return repository.ErrProjectNotFound
+		}
+		parentKind = parent.Kind
+	}
+	if !slices.Contains(allowedParentKinds[project.Kind], parentKind) {
+		if parentKind == "" {
+			return fmt.Errorf("%w: %s cannot be top level", ErrInvalidProjectParent, project.Kind)
+		}
+		return fmt.Errorf("%w: %s cannot be placed under %s", ErrInvalidProjectParent, project.Kind, parentKind)
+	}
+	// 沿父节点链向上查找，遇到自身即成环；seen 防御库中已有的脏数据
+	seen := make(map[uint]bool)
+	for id := parentID; id != 0 && !seen[id]; {
+		if id == project.ID {
+			return ErrProjectCycle
+		}
+		seen[id] = true
+		p, ok := byID[id]
+		if !ok {
+			break
+		}
+		id = parentOf(*p)
+	}
+	return nil
+}
+
+// expandProjectIDs 返回 roots 及其全部下级的 ID（去重，忽略不存在的节点）
+func expandProjectIDs(projects []model.Project, roots []uint) []uint {
+	children := make(map[uint][]uint, len(projects))
+	exists := make(map[uint]bool, len(projects))
+	for _, p := range projects {
+		children[parentOf(p)] = append(children[parentOf(p)], p.ID)
+		exists[p.ID] = true
+	}
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, root := range roots {
+		if exists[root] && !seen[root] {
+			seen[root] = true
+			ids = append(ids, root)
+		}
+	}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// sumProjectNode 自底向上累加含下级的待办数量
+func sumProjectNode(node *ProjectNode) TodoCounts {
+	node.Total = node.Counts
+	for _, child := range node.Children {
+		sub := sumProjectNode(child)
+		node.Total.Open += sub.Open
+		node.Total.Done += sub.Done
+	}
+	return node.Total
+}
+
+// ProjectPaths 返回每个节点从顶层开始的完整路径，例如 “工作 / 官网改版 / 设计”
+func ProjectPaths(projects []model.Project) map[uint]string {
+	byID := indexProjects(projects)
+	paths := make(map[uint]string, len(projects))
+	var resolve func(id uint, depth int) string
+	resolve = func(id uint, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		p := byID[id]
+		path := p.Name
+		if parent, ok := byID[parentOf(*p)]; ok && depth < len(projects) {
+			path = resolve(parent.ID, depth+1) + " / " + p.Name
+		}
+		paths[id] = path
+		return path
+	}
+	for _, p := range projects {
+		resolve(p.ID, 0)
+	}
+	return paths
+}
+
+func indexProjects(projects []model.Project) map[uint]*model.Project {
+	byID := make(map[uint]*model.Project, len(projects))
+	for i := range projects {
+		byID[projects[i].ID] = &projects[i]
+	}
+	return byID
+}
+
+func parentOf(p model.Project) uint {
+	if p.ParentID == nil {
+		return 0
+	}
+	return *p.ParentID
+}
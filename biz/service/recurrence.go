@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/lunar"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrInvalidRecurrence 重复规则非法
+	ErrInvalidRecurrence = errors.New("recurrence must be one of daily/workday/weekly/monthly/yearly/lunar_yearly")
+	// ErrInvalidDueShift 截止日期调整方式非法
+	ErrInvalidDueShift = errors.New("due_shift must be one of next_workday/prev_workday")
+	// ErrRecurrenceNeedsDueTime 设置重复规则前需要先有截止时间
+	ErrRecurrenceNeedsDueTime = errors.New("recurrence requires a due_time")
+	// ErrLunarDateRequired 农历重复规则需要指定农历月日
+	ErrLunarDateRequired = errors.New("lunar date is required for lunar_yearly recurrence")
+)
+
+// maxRecurrenceSteps 推算下一次时最多前进的步数（防止异常数据死循环）
+const maxRecurrenceSteps = 10000
+
+// ValidRecurrence 是否为支持的重复规则（空串表示不重复）
+func ValidRecurrence(rule string) bool {
+	switch rule {
+	case model.RecurrenceNone, model.RecurrenceDaily, model.RecurrenceWorkday,
+		model.RecurrenceWeekly, model.RecurrenceMonthly, model.RecurrenceYearly,
+		model.RecurrenceLunarYearly:
+		return true
+	}
+	return false
+}
+
+// ValidDueShift 是否为支持的截止日期调整方式（空串表示不调整）
+func ValidDueShift(shift string) bool {
+	switch shift {
+	case model.DueShiftNone, model.DueShiftNextWorkday, model.DueShiftPrevWorkday:
+		return true
+	}
+	return false
+}
+
+// ShiftDue 按调整方式把截止时间移到工作日
+func ShiftDue(cal *workday.Calendar, due time.Time, shift string) time.Time {
+	switch shift {
+	case model.DueShiftNextWorkday:
+		return cal.Shift(due, true)
+	case model.DueShiftPrevWorkday:
+		return cal.Shift(due, false)
+	default:
+		return due
+	}
+}
+
+// NextOccurrence 推算重复待办的下一次计划日期（调整前）与截止时间（调整后）
+// 从上一次计划日期出发逐次前进，直到截止时间晚于 now，逾期完成不会生成已过期的下一条
+func NextOccurrence(cal *workday.Calendar, t *model.Todo, now time.Time) (anchor, due time.Time, ok bool) {
+	base := now
+	switch {
+	case t.RecurrenceAnchor != nil:
+		base = *t.RecurrenceAnchor
+	case t.DueTime != nil:
+		base = *t.DueTime
+	}
+
+	anchor = base
+	for i := 0; i < maxRecurrenceSteps; i++ {
+		switch t.Recurrence {
+		case model.RecurrenceDaily:
+			anchor = anchor.AddDate(0, 0, 1)
+		case model.RecurrenceWorkday:
+			anchor = cal.NextWorkday(anchor, 1)
+		case model.RecurrenceWeekly:
+			anchor = anchor.AddDate(0, 0, 7)
+		case model.RecurrenceMonthly:
+			anchor = addMonthsClamped(base, i+1)
+		case model.RecurrenceYearly:
+			anchor = addMonthsClamped(base, 12*(i+1))
+		case model.RecurrenceLunarYearly:
+			var err error
+			if anchor, err = lunar.Next(t.LunarMonth, t.LunarDay, t.LunarLeap, anchor.In(workday.Location), false); err != nil {
+				return time.Time{}, time.Time{}, false
+			}
+		default:
+			return time.Time{}, time.Time{}, false
+		}
+		due = ShiftDue(cal, anchor, t.DueShift)
+		if due.After(now) {
+			return anchor, due, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// addMonthsClamped 按月前进，目标月没有对应日期时取月末（1 月 31 日 → 2 月 28/29 日）
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := first.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+// RecurrenceInput 设置重复规则的参数
+type RecurrenceInput struct {
+	Rule     string
+	DueShift string
+	Lunar    string // Rule 为 lunar_yearly 时必填，如 "八月十五"、"闰四月初一"、"8-15"、"L4-1"
+}
+
+// SetRecurrence 设置待办的重复规则与截止日期调整方式，并立即按调整方式修正当前截止时间
+// 农历规则会把截止日期移到最近一次（含当天）对应的公历日期；没有截止时间时默认北京时间 9:00
+func (s *TodoService) SetRecurrence(userID, id uint, in RecurrenceInput) (*model.Todo, error) {
+	if !ValidRecurrence(in.Rule) {
+		return nil, ErrInvalidRecurrence
+	}
+	if !ValidDueShift(in.DueShift) {
+		return nil, ErrInvalidDueShift
+	}
+
+	todo, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"recurrence":  in.Rule,
+		"due_shift":   in.DueShift,
+		"lunar_month": 0,
+		"lunar_day":   0,
+		"lunar_leap":  false,
+	}
+	anchor := todo.RecurrenceAnchor
+	if anchor == nil {
+		anchor = todo.DueTime
+	}
+
+	if in.Rule == model.RecurrenceLunarYearly {
+		if strings.TrimSpace(in.Lunar) == "" {
+			return nil, ErrLunarDateRequired
+		}
+		date, err := lunar.Parse(in.Lunar)
+		if err != nil {
+			return nil, err
+		}
+		base := time.Now().In(workday.Location)
+		base = time.Date(base.Year(), base.Month(), base.Day(), 9, 0, 0, 0, workday.Location)
+		if anchor != nil {
+			base = anchor.In(workday.Location)
+		}
+		next, err := lunar.Next(date.Month, date.Day, date.Leap, base, true)
+		if err != nil {
+			return nil, err
+		}
+		anchor = &next
+		updates["lunar_month"] = date.Month
+		updates["lunar_day"] = date.Day
+		updates["lunar_leap"] = date.Leap
+	}
+
+	if anchor == nil {
+		if in.Rule != model.RecurrenceNone {
+			return nil, ErrRecurrenceNeedsDueTime
+		}
+	} else {
+		updates["recurrence_anchor"] = *anchor
+		updates["due_time"] = ShiftDue(workday.Default(), *anchor, in.DueShift)
+	}
+
+	affected, err := s.repo.UpdateFields(userID, id, updates)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, repository.ErrTodoNotFound
+	}
+	return s.repo.GetByID(userID, id)
+}
+
+// LunarRuleString 农历重复规则的中文描述（非农历规则返回空串）
+func LunarRuleString(t *model.Todo) string {
+	if t.Recurrence != model.RecurrenceLunarYearly {
+		return ""
+	}
+	return lunar.Date{Month: t.LunarMonth, Day: t.LunarDay, Leap: t.LunarLeap}.String()
+}
+
+// spawnNextOccurrence 重复待办完成后生成下一条
+func (s *TodoService) spawnNextOccurrence(t *model.Todo) error {
+	anchor, due, ok := NextOccurrence(workday.Default(), t, time.Now())
+	if !ok {
+		return nil
+	}
+
+	next := &model.Todo{
+		UserID:           t.UserID,
+		ProjectID:        t.ProjectID,
+		Title:            t.Title,
+		Content:          t.Content,
+		Status:           0,
+		DueTime:          &due,
+		Recurrence:       t.Recurrence,
+		DueShift:         t.DueShift,
+		RecurrenceAnchor: &anchor,
+		LunarMonth:       t.LunarMonth,
+		LunarDay:         t.LunarDay,
+		LunarLeap:        t.LunarLeap,
+		GtdList:          t.GtdList,
+	}
+	// 开始时间与截止时间保持原有间隔
+	if t.StartTime != nil && t.DueTime != nil {
+		start := due.Add(t.StartTime.Sub(*t.DueTime))
+		next.StartTime = &start
+	}
+	_, err := s.repo.CreateNextOccurrence(t, next)
+	return err
+}
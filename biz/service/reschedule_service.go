@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+// ErrInvalidRescheduleGroup 拖延统计的分组方式无效
+var ErrInvalidRescheduleGroup = errors.New("group_by must be project or tag")
+
+// 拖延统计的分组方式
+const (
+	RescheduleGroupProject = "project"
+	RescheduleGroupTag     = "tag"
+)
+
+const (
+	defaultRescheduleDays = 90
+	maxRescheduleDays     = 3650
+	mostRescheduledLimit  = 10
+)
+
+// DueChangeView 一次截止时间变更；ShiftDays 为改期的偏移（天，正数为推迟），首次设置与清空时为空
+type DueChangeView struct {
+	model.TodoDueChange
+	ShiftDays *float64 `json:"shift_days"`
+}
+
+// RescheduleHistory 待办的改期记录
+type RescheduleHistory struct {
+	TodoID          uint            `json:"todo_id"`
+	RescheduleCount int             `json:"reschedule_count"`
+	Changes         []DueChangeView `json:"changes"`
+}
+
+// PostponementStat 某个项目或标签下的拖延统计
+type PostponementStat struct {
+	Key               string  `json:"key"`               // 项目 ID 或标签名，未归属项目 / 没有标签的为空串
+	Name              string  `json:"name"`              // 项目名或标签名
+	Todos             int     `json:"todos"`             // 被改期过的待办数
+	Reschedules       int     `json:"reschedules"`       // 改期次数（含提前）
+	Postponements     int     `json:"postponements"`     // 其中推迟的次数
+	AvgPostponeDays   float64 `json:"avg_postpone_days"` // 每次推迟的平均天数
+	TotalPostponeDays float64 `json:"total_postpone_days"`
+}
+
+// RescheduleStats 拖延统计
+type RescheduleStats struct {
+	GroupBy         string             `json:"group_by"`
+	Since           time.Time          `json:"since"`
+	Groups          []PostponementStat `json:"groups"`
+	MostRescheduled []model.Todo       `json:"most_rescheduled"` // 改期次数最多的未完成待办
+}
+
+// RescheduleService 截止时间改期历史与拖延统计
+type RescheduleService struct {
+	todoRepo    *repository.TodoRepository
+	tagRepo     *repository.TodoTagRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewRescheduleService 创建改期统计服务实例
+func NewRescheduleService(
+	todoRepo *repository.TodoRepository,
+	tagRepo *repository.TodoTagRepository,
+	projectRepo *repository.ProjectRepository,
+) *RescheduleService {
+	return &RescheduleService{todoRepo: todoRepo, tagRepo: tagRepo, projectRepo: projectRepo}
+}
+
+// History 待办的截止时间变更历史
+func (s *RescheduleService) History(userID, todoID uint) (*RescheduleHistory, error) {
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.todoRepo.ListDueChanges(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]DueChangeView, 0, len(changes))
+	for _, c := range changes {
+		view := DueChangeView{TodoDueChange: c}
+		if c.IsReschedule() {
+			days := shiftDays(&c)
+			view.ShiftDays = &days
+		}
+		views = append(views, view)
+	}
+	return &RescheduleHistory{TodoID: todo.ID, RescheduleCount: todo.RescheduleCount, Changes: views}, nil
+}
+
+// Stats 最近 days 天（默认 90）的改期按项目或标签统计：一条待办有多个标签时计入每个标签
+func (s *RescheduleService) Stats(userID uint, groupBy string, days int, now time.Time) (*RescheduleStats, error) {
+	if groupBy == "" {
+		groupBy = RescheduleGroupProject
+	}
+	if groupBy != RescheduleGroupProject && groupBy != RescheduleGroupTag {
+		return nil, ErrInvalidRescheduleGroup
+	}
+	if days <= 0 {
+		days = defaultRescheduleDays
+	}
+	days = min(days, maxRescheduleDays)
+	since := dayStart(now).AddDate(0, 0, -days+1)
+
+	changes, err := s.todoRepo.ListDueChangesSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	changes = slices.DeleteFunc(changes, func(c model.TodoDueChange) bool { return !c.IsReschedule() })
+	var todoIDs []uint
+	for _, c := range changes {
+		if !slices.Contains(todoIDs, c.TodoID) {
+			todoIDs = append(todoIDs, c.TodoID)
+		}
+	}
+	keys, names, err := s.groupKeys(userID, groupBy, todoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*PostponementStat{}
+	seen := map[string]map[uint]bool{}
+	var order []string
+	for i := range changes {
+		c := &changes[i]
+		shift := shiftDays(c)
+		for _, key := range keys[c.TodoID] {
+			stat, ok := byKey[key]
+			if !ok {
+				stat = &PostponementStat{Key: key, Name: names[key]}
+				byKey[key] = stat
+				seen[key] = map[uint]bool{}
+				order = append(order, key)
+			}
+			if !seen[key][c.TodoID] {
+				seen[key][c.TodoID] = true
+				stat.Todos++
+			}
+			stat.Reschedules++
+			if shift > 0 {
+				stat.Postponements++
+				stat.TotalPostponeDays += shift
+			}
+		}
+	}
+	groups := make([]PostponementStat, 0, len(order))
+	for _, key := range order {
+		stat := byKey[key]
+		if stat.Postponements > 0 {
+			stat.AvgPostponeDays = roundDays(stat.TotalPostponeDays / float64(stat.Postponements))
+		}
+		stat.TotalPostponeDays = roundDays(stat.TotalPostponeDays)
+		groups = append(groups, *stat)
+	}
+	// 平均推迟天数多的在前
+	slices.SortStableFunc(groups, func(a, b PostponementStat) int {
+		if a.AvgPostponeDays != b.AvgPostponeDays {
+			if a.AvgPostponeDays > b.AvgPostponeDays {
+				return -1
+			}
+			return 1
+		}
+		return b.Reschedules - a.Reschedules
+	})
+
+	most, err := s.todoRepo.ListMostRescheduled(userID, mostRescheduledLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &RescheduleStats{GroupBy: groupBy, Since: since, Groups: groups, MostRescheduled: most}, nil
+}
+
+// groupKeys 每条待办所属的分组（项目 ID 或标签名）及分组的显示名
+func (s *RescheduleService) groupKeys(userID uint, groupBy string, todoIDs []uint) (map[uint][]string, map[string]string, error) {
+	keys := make(map[uint][]string, len(todoIDs))
+	names := map[string]string{}
+	if groupBy == RescheduleGroupTag {
+		tags, err := s.tagRepo.ListByTodos(userID, todoIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range tags {
+			keys[t.TodoID] = append(keys[t.TodoID], t.Name)
+			names[t.Name] = t.Name
+		}
+	} else {
+		todos, err := s.todoRepo.ListByIDs(userID, todoIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		projects, err := s.projectRepo.List(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range projects {
+			names[idKey(p.ID)] = p.Name
+		}
+		for _, t := range todos {
+			if t.ProjectID != nil {
+				keys[t.ID] = []string{idKey(*t.ProjectID)}
+			}
+		}
+	}
+	for _, id := range todoIDs {
+		if len(keys[id]) == 0 {
+			keys[id] = []string{""}
+		}
+	}
+	return keys, names, nil
+}
+
+func idKey(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// shiftDays 改期的偏移天数，正数为推迟
+func shiftDays(c *model.TodoDueChange) float64 {
+	return c.NewDue.Sub(*c.OldDue).Hours() / 24
+}
+
+func roundDays(d float64) float64 {
+	return math.Round(d*10) / 10
+}
@@ -0,0 +1,500 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/pkg/workday"
+)
+
+var (
+	// ErrSprintNameRequired 迭代名称必填
+	ErrSprintNameRequired = errors.New("sprint name is required")
+	// ErrInvalidSprintDate 日期格式无效
+	ErrInvalidSprintDate = errors.New("start_date and end_date must be YYYY-MM-DD")
+	// ErrSprintDateRange 开始日期晚于结束日期
+	ErrSprintDateRange = errors.New("start_date must not be after end_date")
+	// ErrSprintOverlap 与同项目的其他迭代时间重叠
+	ErrSprintOverlap = errors.New("sprint overlaps another sprint of the project")
+	// ErrSprintProjectKind 只有项目可以建迭代
+	ErrSprintProjectKind = errors.New("sprints can only be created on a project")
+	// ErrSprintClosed 迭代已关闭
+	ErrSprintClosed = errors.New("sprint is closed")
+	// ErrTodoNotInSprintProject 待办不属于迭代所在项目
+	ErrTodoNotInSprintProject = errors.New("todo does not belong to the sprint's project")
+	// ErrInvalidCarryOver 结转目标无效
+	ErrInvalidCarryOver = errors.New("carry-over target must be another open sprint of the same project")
+)
+
+// 迭代状态（由日期与关闭时间推算）
+const (
+	SprintPlanned = "planned"
+	SprintActive  = "active"
+	SprintClosed  = "closed"
+)
+
+// SprintView 迭代及其当前待办统计
+type SprintView struct {
+	model.Sprint
+	State string `json:"state"`
+	Total int    `json:"total"`
+	Done  int    `json:"done"`
+}
+
+// SprintTodo 迭代中的待办
+type SprintTodo struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Status    int32     `json:"status"`
+	Estimate  int       `json:"estimate"`
+	AddedAt   time.Time `json:"added_at"`
+	CarriedTo uint      `json:"carried_to,omitempty"`
+}
+
+// SprintDetail 迭代详情：当前待办与关闭时结转出去的待办
+type SprintDetail struct {
+	SprintView
+	Todos       []SprintTodo `json:"todos"`
+	CarriedOver []SprintTodo `json:"carried_over"`
+}
+
+// SprintCloseResult 关闭迭代的结果
+type SprintCloseResult struct {
+	Sprint      *SprintDetail `json:"sprint"`
+	Completed   int           `json:"completed"`
+	CarriedOver []uint        `json:"carried_over"`
+	CarriedTo   uint          `json:"carried_to"`
+}
+
+// BurndownDay 燃尽/燃起图的一天（按当天结束时的状态统计）
+type BurndownDay struct {
+	Date      string  `json:"date"`
+	Scope     int     `json:"scope"`     // 迭代内待办总数（燃起图的范围线）
+	Done      int     `json:"done"`      // 已完成数（燃起图）
+	Remaining int     `json:"remaining"` // 剩余数（燃尽图）
+	Ideal     float64 `json:"ideal"`     // 理想剩余：从初始范围线性降到结束日的 0
+
+	ScopePoints     int `json:"scope_points"` // 以下三项按估算工作量统计
+	DonePoints      int `json:"done_points"`
+	RemainingPoints int `json:"remaining_points"`
+}
+
+// Burndown 迭代的每日燃尽/燃起数据（截至今天或关闭当天）
+type Burndown struct {
+	SprintID  uint          `json:"sprint_id"`
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Days      []BurndownDay `json:"days"`
+}
+
+// SprintInput 创建或更新迭代的参数，nil 字段保持不变
+type SprintInput struct {
+	Name      *string
+	StartDate *string
+	EndDate   *string
+}
+
+// SprintService 迭代
+type SprintService struct {
+	sprintRepo  *repository.SprintRepository
+	todoRepo    *repository.TodoRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewSprintService 创建迭代服务实例
+func NewSprintService(sprintRepo *repository.SprintRepository, todoRepo *repository.TodoRepository, projectRepo *repository.ProjectRepository) *SprintService {
+	return &SprintService{sprintRepo: sprintRepo, todoRepo: todoRepo, projectRepo: projectRepo}
+}
+
+// Create 在项目下创建迭代（名称与起止日期必填，不能与同项目的其他迭代重叠）
+func (s *SprintService) Create(userID, projectID uint, in SprintInput) (*SprintDetail, error) {
+	project, err := s.projectRepo.GetByID(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Kind != model.ProjectKindProject {
+		return nil, ErrSprintProjectKind
+	}
+	if in.Name == nil {
+		return nil, ErrSprintNameRequired
+	}
+	if in.StartDate == nil || in.EndDate == nil {
+		return nil, ErrInvalidSprintDate
+	}
+	sprint := &model.Sprint{UserID: userID, ProjectID: projectID}
+	if err := applySprintInput(sprint, in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(sprint); err != nil {
+		return nil, err
+	}
+	if err := s.sprintRepo.Create(sprint); err != nil {
+		return nil, err
+	}
+	return s.detail(sprint, time.Now())
+}
+
+// Update 部分更新迭代（已关闭的迭代不能修改）
+func (s *SprintService) Update(userID, id uint, in SprintInput) (*SprintDetail, error) {
+	sprint, err := s.sprintRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.ClosedAt != nil {
+		return nil, ErrSprintClosed
+	}
+	if err := applySprintInput(sprint, in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(sprint); err != nil {
+		return nil, err
+	}
+	if _, err := s.sprintRepo.UpdateFields(userID, id, map[string]interface{}{
+		"name":       sprint.Name,
+		"start_date": sprint.StartDate,
+		"end_date":   sprint.EndDate,
+	}); err != nil {
+		return nil, err
+	}
+	return s.detail(sprint, time.Now())
+}
+
+// Delete 删除迭代（其中的待办不受影响）
+func (s *SprintService) Delete(userID, id uint) (int64, error) {
+	return s.sprintRepo.Delete(userID, id)
+}
+
+// Get 获取迭代详情
+func (s *SprintService) Get(userID, id uint) (*SprintDetail, error) {
+	sprint, err := s.sprintRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(sprint, time.Now())
+}
+
+// List 列出项目的全部迭代（按开始日期升序）
+func (s *SprintService) List(userID, projectID uint) ([]SprintView, error) {
+	if _, err := s.projectRepo.GetByID(userID, projectID); err != nil {
+		return nil, err
+	}
+	sprints, err := s.sprintRepo.ListByProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	views := make([]SprintView, 0, len(sprints))
+	for i := range sprints {
+		d, err := s.detail(&sprints[i], now)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, d.SprintView)
+	}
+	return views, nil
+}
+
+// AddTodo 把待办加入迭代（待办须属于迭代所在项目或其分区；已在其他迭代中时从那里移出）
+func (s *SprintService) AddTodo(userID, sprintID, todoID uint) (*SprintDetail, error) {
+	sprint, err := s.openSprint(userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.todoRepo.GetByID(userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.projectRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.ProjectID == nil || !slices.Contains(expandProjectIDs(projects, []uint{sprint.ProjectID}), *todo.ProjectID) {
+		return nil, ErrTodoNotInSprintProject
+	}
+	now := time.Now()
+	if err := s.sprintRepo.AddItem(userID, sprint.ID, todo.ID, now); err != nil {
+		return nil, err
+	}
+	return s.detail(sprint, now)
+}
+
+// RemoveTodo 把待办移出迭代
+func (s *SprintService) RemoveTodo(userID, sprintID, todoID uint) (*SprintDetail, error) {
+	sprint, err := s.openSprint(userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if _, err := s.sprintRepo.RemoveItem(sprint.ID, todoID, now); err != nil {
+		return nil, err
+	}
+	return s.detail(sprint, now)
+}
+
+// Close 关闭迭代：未完成的待办标记为结转，nextID 不为 0 时加入该迭代（须为同项目未关闭的其他迭代）
+func (s *SprintService) Close(userID, id, nextID uint) (*SprintCloseResult, error) {
+	sprint, err := s.openSprint(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if nextID != 0 {
+		next, err := s.sprintRepo.GetByID(userID, nextID)
+		if err != nil {
+			return nil, err
+		}
+		if next.ID == sprint.ID || next.ProjectID != sprint.ProjectID || next.ClosedAt != nil {
+			return nil, ErrInvalidCarryOver
+		}
+	}
+
+	items, err := s.sprintRepo.ListItems(sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.itemTodos(userID, items)
+	if err != nil {
+		return nil, err
+	}
+	res := &SprintCloseResult{CarriedOver: []uint{}, CarriedTo: nextID}
+	for _, item := range items {
+		todo, ok := todos[item.TodoID]
+		if item.RemovedAt != nil || !ok {
+			continue
+		}
+		if isDone(todo) {
+			res.Completed++
+		} else {
+			res.CarriedOver = append(res.CarriedOver, item.TodoID)
+		}
+	}
+
+	now := time.Now()
+	closed, err := s.sprintRepo.Close(userID, sprint.ID, res.CarriedOver, nextID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrSprintClosed
+	}
+	sprint.ClosedAt = &now
+	if res.Sprint, err = s.detail(sprint, now); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Burndown 按状态变更历史回放每天结束时的范围与完成情况
+func (s *SprintService) Burndown(userID, id uint) (*Burndown, error) {
+	sprint, err := s.sprintRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.sprintRepo.ListItems(sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.itemTodos(userID, items)
+	if err != nil {
+		return nil, err
+	}
+	end, _ := time.ParseInLocation(workday.DateLayout, sprint.EndDate, workday.Location)
+	changes, err := s.todoRepo.ListStatusChanges(userID, sprintTodoIDs(items), end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	history := make(map[uint][]model.TodoStatusChange)
+	for _, c := range changes {
+		history[c.TodoID] = append(history[c.TodoID], c)
+	}
+	return sprintBurndown(sprint, items, todos, history, time.Now()), nil
+}
+
+// sprintBurndown 计算每日数据；closedAt 或 now 之后的日期不输出
+func sprintBurndown(sprint *model.Sprint, items []model.SprintItem, todos map[uint]*model.Todo, history map[uint][]model.TodoStatusChange, now time.Time) *Burndown {
+	b := &Burndown{SprintID: sprint.ID, StartDate: sprint.StartDate, EndDate: sprint.EndDate, Days: []BurndownDay{}}
+	start, _ := time.ParseInLocation(workday.DateLayout, sprint.StartDate, workday.Location)
+	end, _ := time.ParseInLocation(workday.DateLayout, sprint.EndDate, workday.Location)
+	last := dayStart(now)
+	if sprint.ClosedAt != nil {
+		last = dayStart(*sprint.ClosedAt)
+	}
+	if last.After(end) {
+		last = end
+	}
+	totalDays := int(end.Sub(start).Hours()/24+0.5) + 1
+
+	var initial int
+	for day, i := start, 0; !day.After(last); day, i = day.AddDate(0, 0, 1), i+1 {
+		eod := day.AddDate(0, 0, 1)
+		d := BurndownDay{Date: day.Format(workday.DateLayout)}
+		seen := make(map[uint]bool)
+		for _, item := range items {
+			todo, ok := todos[item.TodoID]
+			if !ok || seen[item.TodoID] || !item.AddedAt.Before(eod) {
+				continue
+			}
+			// 结转的待办保留在本迭代的范围内，体现为未完成
+			if item.RemovedAt != nil && item.RemovedAt.Before(eod) && !item.CarriedOver {
+				continue
+			}
+			seen[item.TodoID] = true
+			d.Scope++
+			d.ScopePoints += todo.Estimate
+			if statusAt(todo, history[item.TodoID], eod) == 1 {
+				d.Done++
+				d.DonePoints += todo.Estimate
+			}
+		}
+		d.Remaining = d.Scope - d.Done
+		d.RemainingPoints = d.ScopePoints - d.DonePoints
+		if initial == 0 {
+			// 迭代开始后才排入待办时，以首个非空的范围为理想线起点
+			initial = d.Scope
+		}
+		if totalDays > 1 {
+			d.Ideal = math.Round(float64(initial)*(1-float64(i)/float64(totalDays-1))*100) / 100
+		}
+		b.Days = append(b.Days, d)
+	}
+	return b
+}
+
+// statusAt 推算待办在 at 时刻的状态：取 at 之前最后一次变更的结果；
+// 之前没有变更时取第一次变更前的状态；完全没有历史（早于历史记录上线）时，已完成的待办以更新时间作为完成时间
+func statusAt(todo *model.Todo, changes []model.TodoStatusChange, at time.Time) int32 {
+	if len(changes) == 0 {
+		if isDone(todo) && !todo.UpdatedAt.Before(at) {
+			return 0
+		}
+		return todo.Status
+	}
+	status := changes[0].FromStatus
+	for _, c := range changes {
+		if !c.CreatedAt.Before(at) {
+			break
+		}
+		status = c.ToStatus
+	}
+	return status
+}
+
+func (s *SprintService) openSprint(userID, id uint) (*model.Sprint, error) {
+	sprint, err := s.sprintRepo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.ClosedAt != nil {
+		return nil, ErrSprintClosed
+	}
+	return sprint, nil
+}
+
+// checkOverlap 检查与同项目其他迭代的日期是否重叠（日期格式固定，可按字符串比较）
+func (s *SprintService) checkOverlap(sprint *model.Sprint) error {
+	sprints, err := s.sprintRepo.ListByProject(sprint.UserID, sprint.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, other := range sprints {
+		if other.ID != sprint.ID && sprint.StartDate <= other.EndDate && other.StartDate <= sprint.EndDate {
+			return ErrSprintOverlap
+		}
+	}
+	return nil
+}
+
+// itemTodos 加载迭代记录对应的待办（已删除的待办不在结果中）
+func (s *SprintService) itemTodos(userID uint, items []model.SprintItem) (map[uint]*model.Todo, error) {
+	list, err := s.todoRepo.ListLinked(userID, sprintTodoIDs(items), nil)
+	if err != nil {
+		return nil, err
+	}
+	todos := make(map[uint]*model.Todo, len(list))
+	for i := range list {
+		todos[list[i].ID] = &list[i]
+	}
+	return todos, nil
+}
+
+// detail 组装迭代详情
+func (s *SprintService) detail(sprint *model.Sprint, now time.Time) (*SprintDetail, error) {
+	items, err := s.sprintRepo.ListItems(sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.itemTodos(sprint.UserID, items)
+	if err != nil {
+		return nil, err
+	}
+	d := &SprintDetail{SprintView: SprintView{Sprint: *sprint, State: sprintState(sprint, now)}, Todos: []SprintTodo{}, CarriedOver: []SprintTodo{}}
+	for _, item := range items {
+		todo, ok := todos[item.TodoID]
+		if !ok {
+			continue
+		}
+		st := SprintTodo{ID: todo.ID, Title: todo.Title, Status: todo.Status, Estimate: todo.Estimate, AddedAt: item.AddedAt, CarriedTo: item.CarriedTo}
+		switch {
+		case item.RemovedAt == nil:
+			d.Todos = append(d.Todos, st)
+			d.Total++
+			if isDone(todo) {
+				d.Done++
+			}
+		case item.CarriedOver:
+			d.CarriedOver = append(d.CarriedOver, st)
+		}
+	}
+	return d, nil
+}
+
+func sprintState(sprint *model.Sprint, now time.Time) string {
+	switch {
+	case sprint.ClosedAt != nil:
+		return SprintClosed
+	case now.In(workday.Location).Format(workday.DateLayout) < sprint.StartDate:
+		return SprintPlanned
+	default:
+		return SprintActive
+	}
+}
+
+func sprintTodoIDs(items []model.SprintItem) []uint {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if !slices.Contains(ids, item.TodoID) {
+			ids = append(ids, item.TodoID)
+		}
+	}
+	return ids
+}
+
+func applySprintInput(sprint *model.Sprint, in SprintInput) error {
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return ErrSprintNameRequired
+		}
+		sprint.Name = truncate(name, 100)
+	}
+	for _, d := range []struct {
+		in  *string
+		out *string
+	}{{in.StartDate, &sprint.StartDate}, {in.EndDate, &sprint.EndDate}} {
+		if d.in == nil {
+			continue
+		}
+		if _, err := time.Parse(workday.DateLayout, *d.in); err != nil {
+			return ErrInvalidSprintDate
+		}
+		*d.out = *d.in
+	}
+	if sprint.StartDate > sprint.EndDate {
+		return ErrSprintDateRange
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package service
 
 import (
     "errors"
+    "strconv"
     "time"
 
     "memogo/biz/dal/model"
     "memogo/biz/dal/repository"
+    "memogo/pkg/workday"
 )
 
 var (
@@ -13,6 +15,10 @@ var (
     ErrTitleRequired = errors.New("title is required")
     // ErrContentRequired 内容必填
     ErrContentRequired = errors.New("content is required")
+    // ErrInvalidEstimate 估算工作量不能为负
+    ErrInvalidEstimate = errors.New("estimate must not be negative")
+    // ErrInvalidSort 排序方式无效
+    ErrInvalidSort = errors.New("sort must be title or -title")
 )
 
 // TodoService 待办事项服务
@@ -48,14 +54,114 @@ func (s *TodoService) Create(userID uint, title, content string, startTime, dueT
     return todo, nil
 }
 
-// UpdateTodoStatus 更新单条状态
+// TodoUpdate 待办的部分更新，nil 字段保持不变
+type TodoUpdate struct {
+    Title        *string
+    Content      *string
+    DueTime      *time.Time
+    ClearDueTime bool // 为 true 时清空截止时间
+    Estimate     *int
+}
+
+// UpdateTodo 部分更新待办的标题、内容、截止时间与估算工作量
+func (s *TodoService) UpdateTodo(userID, id uint, in TodoUpdate) (*model.Todo, error) {
+    updates := map[string]interface{}{}
+    if in.Title != nil {
+        if *in.Title == "" {
+            return nil, ErrTitleRequired
+        }
+        updates["title"] = *in.Title
+    }
+    if in.Content != nil {
+        if *in.Content == "" {
+            return nil, ErrContentRequired
+        }
+        updates["content"] = *in.Content
+    }
+    if in.Estimate != nil {
+        if *in.Estimate < 0 {
+            return nil, ErrInvalidEstimate
+        }
+        updates["estimate"] = *in.Estimate
+    }
+    if in.ClearDueTime {
+        updates["due_time"] = nil
+        updates["recurrence_anchor"] = nil
+    } else if in.DueTime != nil {
+        // 设置了截止日期调整方式时，新的截止时间同样调整到工作日
+        todo, err := s.repo.GetByID(userID, id)
+        if err != nil {
+            return nil, err
+        }
+        updates["due_time"] = ShiftDue(workday.Default(), *in.DueTime, todo.DueShift)
+        updates["recurrence_anchor"] = *in.DueTime
+    }
+
+    if len(updates) > 0 {
+        affected, err := s.repo.UpdateFields(userID, id, updates)
+        if err != nil {
+            return nil, err
+        }
+        if affected == 0 {
+            return nil, repository.ErrTodoNotFound
+        }
+    }
+    return s.repo.GetByID(userID, id)
+}
+
+// GetTodo 获取单条待办
+func (s *TodoService) GetTodo(userID, id uint) (*model.Todo, error) {
+    return s.repo.GetByID(userID, id)
+}
+
+// GetTodoByRef 按引用获取单条待办：数字为待办 ID，形如 OPS-17 为项目内编号（不区分大小写）
+func (s *TodoService) GetTodoByRef(userID uint, ref string) (*model.Todo, error) {
+    if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+        return s.repo.GetByID(userID, uint(id))
+    }
+    if key, _, ok := model.ParseTodoKey(ref); ok {
+        return s.repo.GetByKey(userID, key)
+    }
+    return nil, repository.ErrTodoNotFound
+}
+
+// UpdateTodoStatus 更新单条状态；重复待办标记完成时生成下一条
 func (s *TodoService) UpdateTodoStatus(userID, id uint, status int32) (int64, error) {
-    return s.repo.UpdateStatusByID(userID, id, status)
+    affected, err := s.repo.UpdateStatusByID(userID, id, status)
+    if err != nil || affected == 0 || status != 1 {
+        return affected, err
+    }
+    todo, err := s.repo.GetByID(userID, id)
+    if err != nil {
+        return affected, err
+    }
+    if todo.Recurrence != model.RecurrenceNone {
+        if err := s.spawnNextOccurrence(todo); err != nil {
+            return affected, err
+        }
+    }
+    return affected, nil
 }
 
-// UpdateAllStatus 批量更新状态 from → to
+// UpdateAllStatus 批量更新状态 from → to；批量完成时同样为重复待办生成下一条
 func (s *TodoService) UpdateAllStatus(userID uint, fromStatus, toStatus int32) (int64, error) {
-    return s.repo.UpdateAllStatus(userID, fromStatus, toStatus)
+    var recurring []model.Todo
+    if toStatus == 1 && fromStatus != 1 {
+        var err error
+        if recurring, err = s.repo.ListRecurring(userID, fromStatus); err != nil {
+            return 0, err
+        }
+    }
+    affected, err := s.repo.UpdateAllStatus(userID, fromStatus, toStatus)
+    if err != nil {
+        return affected, err
+    }
+    for i := range recurring {
+        if err := s.spawnNextOccurrence(&recurring[i]); err != nil {
+            return affected, err
+        }
+    }
+    return affected, nil
 }
 
 // DeleteOne 删除单条
@@ -68,8 +174,11 @@ func (s *TodoService) DeleteByScope(userID uint, scope string) (int64, error) {
     return s.repo.DeleteByScope(userID, scope)
 }
 
-// ListTodos 分页查询（status: "todo"|"done"|"all"|""）
-func (s *TodoService) ListTodos(userID uint, status string, page, pageSize int) ([]model.Todo, int64, error) {
+// ListTodos 分页查询（status: "todo"|"done"|"all"|""；projectIDs 为 nil 表示不限项目）
+func (s *TodoService) ListTodos(userID uint, status string, projectIDs []uint, sort string, page, pageSize int) ([]model.Todo, int64, error) {
+    if err := checkTodoSort(sort); err != nil {
+        return nil, 0, err
+    }
     // 统一页大小限制
     if page < 1 {
         page = 1
@@ -79,11 +188,14 @@ func (s *TodoService) ListTodos(userID uint, status string, page, pageSize int)
     } else if pageSize > 50 {
         pageSize = 50
     }
-    return s.repo.ListTodos(userID, status, page, pageSize)
+    return s.repo.ListTodos(userID, status, projectIDs, sort, page, pageSize)
 }
 
 // SearchTodos 关键词分页查询
-func (s *TodoService) SearchTodos(userID uint, keyword string, page, pageSize int) ([]model.Todo, int64, error) {
+func (s *TodoService) SearchTodos(userID uint, keyword string, projectIDs []uint, sort string, page, pageSize int) ([]model.Todo, int64, error) {
+    if err := checkTodoSort(sort); err != nil {
+        return nil, 0, err
+    }
     if page < 1 {
         page = 1
     }
@@ -92,26 +204,41 @@ func (s *TodoService) SearchTodos(userID uint, keyword string, page, pageSize in
     } else if pageSize > 50 {
         pageSize = 50
     }
-    return s.repo.SearchTodos(userID, keyword, page, pageSize)
+    return s.repo.SearchTodos(userID, keyword, projectIDs, sort, page, pageSize)
 }
 
 // ListTodosCursor 游标分页查询（用于高效遍历全部数据，O(n) 复杂度）
-func (s *TodoService) ListTodosCursor(userID uint, status string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+func (s *TodoService) ListTodosCursor(userID uint, status string, projectIDs []uint, sort string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+    if err := checkTodoSort(sort); err != nil {
+        return nil, 0, false, err
+    }
     // 限制每次查询的最大数量
     if limit <= 0 {
         limit = 10
     } else if limit > 100 {
         limit = 100 // 游标分页可以允许更大的 limit
     }
-    return s.repo.ListTodosCursor(userID, status, cursor, limit)
+    return s.repo.ListTodosCursor(userID, status, projectIDs, sort, cursor, limit)
 }
 
 // SearchTodosCursor 关键词游标分页查询
-func (s *TodoService) SearchTodosCursor(userID uint, keyword string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+func (s *TodoService) SearchTodosCursor(userID uint, keyword string, projectIDs []uint, sort string, cursor uint, limit int) ([]model.Todo, uint, bool, error) {
+    if err := checkTodoSort(sort); err != nil {
+        return nil, 0, false, err
+    }
     if limit <= 0 {
         limit = 10
     } else if limit > 100 {
         limit = 100
     }
-    return s.repo.SearchTodosCursor(userID, keyword, cursor, limit)
+    return s.repo.SearchTodosCursor(userID, keyword, projectIDs, sort, cursor, limit)
+}
+
+// checkTodoSort 校验排序方式（空串为默认的按创建时间）
+func checkTodoSort(sort string) error {
+    switch sort {
+    case repository.TodoSortCreated, repository.TodoSortTitle, repository.TodoSortTitleDesc:
+        return nil
+    }
+    return ErrInvalidSort
 }
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+)
+
+var (
+	// ErrTransferToSelf 不能转移给自己
+	ErrTransferToSelf = errors.New("cannot transfer todos to the same user")
+	// ErrInvalidTransferScope 转移范围无效
+	ErrInvalidTransferScope = errors.New("scope must be todos, project or open")
+	// ErrTransferEmpty 没有可转移的待办
+	ErrTransferEmpty = errors.New("nothing to transfer")
+	// ErrTransferSection 分区不能单独转移
+	ErrTransferSection = errors.New("a section cannot be transferred on its own, transfer its project instead")
+	// ErrTransferForbidden 当前用户不能执行该操作
+	ErrTransferForbidden = errors.New("only the recipient can accept or decline, and only the owner or initiator can cancel")
+)
+
+// 审计日志动作
+const (
+	AuditActionTodoTransferRequest = "todo_transfer.request"
+	AuditActionTodoTransferAccept  = "todo_transfer.accept"
+	AuditActionTodoTransferDecline = "todo_transfer.decline"
+	AuditActionTodoTransferCancel  = "todo_transfer.cancel"
+)
+
+// maxTransferNoteLen 附言最大长度
+const maxTransferNoteLen = 500
+
+// TodoTransferInput 发起转移的参数
+type TodoTransferInput struct {
+	ToUserID  uint
+	Scope     string
+	TodoIDs   []uint // scope 为 todos 时
+	ProjectID uint   // scope 为 project 时
+	Note      string
+}
+
+// TodoTransferService 待办所有权转移：原所有者（或管理员）发起，接收方接受后执行
+type TodoTransferService struct {
+	transferRepo *repository.TodoTransferRepository
+	todoRepo     *repository.TodoRepository
+	projectRepo  *repository.ProjectRepository
+	userRepo     *repository.UserRepository
+	auditRepo    *repository.AuditLogRepository
+	entitlements *EntitlementService
+}
+
+// NewTodoTransferService 创建转移服务实例
+func NewTodoTransferService(
+	transferRepo *repository.TodoTransferRepository,
+	todoRepo *repository.TodoRepository,
+	projectRepo *repository.ProjectRepository,
+	userRepo *repository.UserRepository,
+	auditRepo *repository.AuditLogRepository,
+	planRepo *repository.PlanRepository,
+) *TodoTransferService {
+	return &TodoTransferService{
+		transferRepo: transferRepo,
+		todoRepo:     todoRepo,
+		projectRepo:  projectRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		entitlements: NewEntitlementService(planRepo),
+	}
+}
+
+// RecipientID 按用户名查找接收方
+func (s *TodoTransferService) RecipientID(username string) (uint, error) {
+	user, err := s.userRepo.GetByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// Request 发起转移：actorID 为发起人（原所有者本人，或代为操作的管理员），fromID 为原所有者
+func (s *TodoTransferService) Request(actorID, fromID uint, in TodoTransferInput) (*model.TodoTransfer, error) {
+	if fromID == in.ToUserID {
+		return nil, ErrTransferToSelf
+	}
+	for _, id := range []uint{fromID, in.ToUserID} {
+		if _, err := s.userRepo.GetByID(id); err != nil {
+			return nil, err
+		}
+	}
+	t := &model.TodoTransfer{
+		FromUserID:  fromID,
+		ToUserID:    in.ToUserID,
+		InitiatorID: actorID,
+		Scope:       in.Scope,
+		Note:        truncate(strings.TrimSpace(in.Note), maxTransferNoteLen),
+		Status:      model.TransferPending,
+	}
+	switch in.Scope {
+	case model.TransferScopeTodos:
+		for _, id := range in.TodoIDs {
+			if _, err := s.todoRepo.GetByID(fromID, id); err != nil {
+				return nil, err
+			}
+		}
+		t.TodoIDs = in.TodoIDs
+	case model.TransferScopeProject:
+		t.ProjectID = in.ProjectID
+	case model.TransferScopeOpen:
+	default:
+		return nil, ErrInvalidTransferScope
+	}
+	items, err := s.items(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(items.TodoIDs) == 0 && len(items.ProjectIDs) == 0 {
+		return nil, ErrTransferEmpty
+	}
+
+	if err := s.transferRepo.Create(t); err != nil {
+		return nil, err
+	}
+	return t, s.audit(actorID, AuditActionTodoTransferRequest, t, items)
+}
+
+// List 列出与用户相关的转移请求
+func (s *TodoTransferService) List(userID uint, f repository.TodoTransferFilter) ([]model.TodoTransfer, error) {
+	return s.transferRepo.List(userID, f)
+}
+
+// Get 获取转移请求（仅原所有者、接收方与发起人可见）
+func (s *TodoTransferService) Get(userID, id uint) (*model.TodoTransfer, error) {
+	t, err := s.transferRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != t.FromUserID && userID != t.ToUserID && userID != t.InitiatorID {
+		return nil, repository.ErrTodoTransferNotFound
+	}
+	return t, nil
+}
+
+// Accept 接收方接受：按当前数据重新计算范围（发起后新增的未完成待办、项目中的新待办一并转移）；
+// 转移后接收方的待办数或项目数超出其套餐上限时拒绝
+func (s *TodoTransferService) Accept(userID, id uint) (*model.TodoTransfer, error) {
+	t, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != t.ToUserID {
+		return nil, ErrTransferForbidden
+	}
+	if t.Status != model.TransferPending {
+		return nil, repository.ErrTodoTransferNotPending
+	}
+	items, err := s.items(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRecipientLimits(t.ToUserID, items); err != nil {
+		return nil, err
+	}
+	if err := s.transferRepo.Accept(t, items, time.Now()); err != nil {
+		return nil, err
+	}
+	// 转移已提交，审计写入失败只返回错误让调用方感知，不回滚
+	return t, s.audit(userID, AuditActionTodoTransferAccept, t, items)
+}
+
+// Decline 接收方拒绝
+func (s *TodoTransferService) Decline(userID, id uint) (*model.TodoTransfer, error) {
+	return s.respond(userID, id, model.TransferDeclined)
+}
+
+// Cancel 原所有者或发起人撤回
+func (s *TodoTransferService) Cancel(userID, id uint) (*model.TodoTransfer, error) {
+	return s.respond(userID, id, model.TransferCancelled)
+}
+
+func (s *TodoTransferService) respond(userID, id uint, status string) (*model.TodoTransfer, error) {
+	t, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	action := AuditActionTodoTransferDecline
+	allowed := userID == t.ToUserID
+	if status == model.TransferCancelled {
+		action = AuditActionTodoTransferCancel
+		allowed = userID == t.FromUserID || userID == t.InitiatorID
+	}
+	if !allowed {
+		return nil, ErrTransferForbidden
+	}
+	now := time.Now()
+	if err := s.transferRepo.Respond(t.ID, status, now); err != nil {
+		return nil, err
+	}
+	t.Status, t.RespondedAt = status, &now
+	return t, s.audit(userID, action, t, repository.TransferItems{})
+}
+
+// checkRecipientLimits 接收方的待办数、项目数加上转入的部分不能超出其套餐上限
+func (s *TodoTransferService) checkRecipientLimits(toUserID uint, items repository.TransferItems) error {
+	sub := SubjectForUser(toUserID)
+	if len(items.TodoIDs) > 0 {
+		if err := s.entitlements.CheckLimit(sub, model.LimitTodos, len(items.TodoIDs)); err != nil {
+			return err
+		}
+	}
+	if len(items.ProjectIDs) > 0 {
+		return s.entitlements.CheckLimit(sub, model.LimitProjects, len(items.ProjectIDs))
+	}
+	return nil
+}
+
+// items 计算要转移的待办与项目
+func (s *TodoTransferService) items(t *model.TodoTransfer) (repository.TransferItems, error) {
+	var items repository.TransferItems
+	switch t.Scope {
+	case model.TransferScopeTodos:
+		items.TodoIDs = t.TodoIDs
+	case model.TransferScopeOpen:
+		ids, err := s.todoRepo.ListIDsByStatus(t.FromUserID, 0)
+		if err != nil {
+			return items, err
+		}
+		items.TodoIDs = ids
+	case model.TransferScopeProject:
+		project, err := s.projectRepo.GetByID(t.FromUserID, t.ProjectID)
+		if err != nil {
+			return items, err
+		}
+		if project.Kind == model.ProjectKindSection {
+			return items, ErrTransferSection
+		}
+		projects, err := s.projectRepo.List(t.FromUserID)
+		if err != nil {
+			return items, err
+		}
+		items.RootID = project.ID
+		items.ProjectIDs = expandProjectIDs(projects, []uint{project.ID})
+		todos, err := s.todoRepo.ListLinked(t.FromUserID, nil, items.ProjectIDs)
+		if err != nil {
+			return items, err
+		}
+		for _, todo := range todos {
+			items.TodoIDs = append(items.TodoIDs, todo.ID)
+		}
+	}
+	return items, nil
+}
+
+func (s *TodoTransferService) audit(actorID uint, action string, t *model.TodoTransfer, items repository.TransferItems) error {
+	detail := map[string]interface{}{
+		"from_user_id": t.FromUserID,
+		"to_user_id":   t.ToUserID,
+		"initiator_id": t.InitiatorID,
+		"scope":        t.Scope,
+		"status":       t.Status,
+	}
+	if items.TodoIDs != nil || items.ProjectIDs != nil {
+		detail["todo_ids"] = items.TodoIDs
+		detail["project_ids"] = items.ProjectIDs
+	}
+	if t.Status == model.TransferAccepted {
+		detail["moved_todos"] = t.MovedTodos
+		detail["moved_projects"] = t.MovedProjects
+	}
+	return s.auditRepo.Record(actorID, action, "todo_transfer", t.ID, detail)
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+	"memogo/pkg/blobstore"
+)
+
+// StartAnalyticsExport 启动分析数据导出（ANALYTICS_EXPORT_INTERVAL，默认 1h，设为 0 关闭）。
+// 目的地为 ANALYTICS_EXPORT_DIR（本地目录）或 ANALYTICS_EXPORT_S3_*（S3 兼容存储），都未配置时不启动。
+// 多实例部署时只应在一个实例上开启
+func StartAnalyticsExport(ctx context.Context) {
+	interval := getEnvAsDuration("ANALYTICS_EXPORT_INTERVAL", time.Hour)
+	if interval <= 0 {
+		log.Println("Analytics export worker disabled")
+		return
+	}
+
+	var store blobstore.Store
+	var target string
+	if bucket := getEnv("ANALYTICS_EXPORT_S3_BUCKET", ""); bucket != "" {
+		useSSL, _ := strconv.ParseBool(getEnv("ANALYTICS_EXPORT_S3_USE_SSL", "true"))
+		s3, err := blobstore.NewS3(blobstore.S3Config{
+			Endpoint:  getEnv("ANALYTICS_EXPORT_S3_ENDPOINT", "s3.amazonaws.com"),
+			Bucket:    bucket,
+			AccessKey: getEnv("ANALYTICS_EXPORT_S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("ANALYTICS_EXPORT_S3_SECRET_KEY", ""),
+			Region:    getEnv("ANALYTICS_EXPORT_S3_REGION", ""),
+			UseSSL:    useSSL,
+		})
+		if err != nil {
+			log.Printf("Analytics export worker disabled: %v", err)
+			return
+		}
+		store, target = s3, "s3://"+bucket
+	} else if dir := getEnv("ANALYTICS_EXPORT_DIR", ""); dir != "" {
+		store, target = blobstore.NewLocal(dir), dir
+	} else {
+		log.Println("Analytics export worker disabled (ANALYTICS_EXPORT_DIR or ANALYTICS_EXPORT_S3_BUCKET not set)")
+		return
+	}
+
+	batchSize, _ := strconv.Atoi(getEnv("ANALYTICS_EXPORT_BATCH_SIZE", "5000"))
+	svc, err := service.NewAnalyticsExportService(repository.NewAnalyticsExportRepository(db.DB), store, service.ExportOptions{
+		Format:    getEnv("ANALYTICS_EXPORT_FORMAT", service.ExportFormatParquet),
+		Prefix:    getEnv("ANALYTICS_EXPORT_PREFIX", ""),
+		Lag:       getEnvAsDuration("ANALYTICS_EXPORT_LAG", time.Minute),
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		log.Printf("Analytics export worker disabled: %v", err)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				results, err := svc.Run(ctx, time.Now())
+				for _, r := range results {
+					if r.Rows > 0 {
+						log.Printf("Analytics export: %s %d rows in %d files", r.Dataset, r.Rows, len(r.Files))
+					}
+				}
+				if err != nil {
+					log.Printf("Analytics export failed: %v", err)
+				}
+			}
+		}
+	}()
+	log.Printf("Analytics export worker started (interval %s, target %s)", interval, target)
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+)
+
+// StartDelegationFollowUps 启动等待项逾期跟进（WAITING_FOLLOW_UP_INTERVAL，默认 1h，设为 0 关闭；
+// WAITING_FOLLOW_UP_REPEAT 为同一等待项两次跟进的最短间隔，默认 72h）
+func StartDelegationFollowUps(ctx context.Context) {
+	interval := getEnvAsDuration("WAITING_FOLLOW_UP_INTERVAL", time.Hour)
+	if interval <= 0 {
+		log.Println("Waiting follow-up worker disabled")
+		return
+	}
+	repeat := getEnvAsDuration("WAITING_FOLLOW_UP_REPEAT", 72*time.Hour)
+
+	svc := service.NewDelegationService(
+		repository.NewDelegationRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewUserSettingRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := svc.FollowUp(ctx, time.Now(), repeat)
+				if err != nil {
+					log.Printf("Waiting follow-up failed: %v", err)
+					continue
+				}
+				if result.Created > 0 {
+					log.Printf("Waiting follow-up: created %d follow-up todos", result.Created)
+				}
+			}
+		}
+	}()
+	log.Printf("Waiting follow-up worker started (interval %s, repeat %s)", interval, repeat)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"os"
+	"time"
+
+	// 确保环境变量在读取配置之前加载
+	_ "memogo/pkg/env"
+)
+
+// getEnv 获取环境变量，如果不存在则返回默认值
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration 获取时长类型的环境变量（如 "10m"），解析失败返回默认值
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
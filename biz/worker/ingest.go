@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+)
+
+// ingestRetryDelays 临时错误（如数据库抖动）的重试间隔，用尽后进入死信
+var ingestRetryDelays = []time.Duration{200 * time.Millisecond, time.Second, 3 * time.Second}
+
+// StartIngest 启动消息队列待办导入（NATS 与 Redis Streams 可分别启用）
+func StartIngest(ctx context.Context) {
+	svc := service.NewIngestService(
+		repository.NewIngestRecordRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewUserRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+	startNATSIngest(ctx, svc)
+	startRedisIngest(ctx, svc)
+}
+
+// ingestWithRetry 处理一条消息；schema 错误与套餐上限立即返回，其它错误按 ingestRetryDelays 重试
+func ingestWithRetry(ctx context.Context, svc *service.IngestService, source string, data []byte) (*service.IngestResult, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := svc.Ingest(source, data)
+		if err == nil || errors.Is(err, service.ErrInvalidIngestMessage) ||
+			errors.Is(err, service.ErrPlanLimitReached) || attempt >= len(ingestRetryDelays) {
+			return result, err
+		}
+		log.Printf("Ingest (%s): attempt %d failed: %v", source, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(ingestRetryDelays[attempt]):
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	"memogo/biz/service"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSIngestConfig NATS 导入配置
+type NATSIngestConfig struct {
+	Subject    string // 订阅的主题，支持通配符
+	Queue      string // 队列组，多实例部署时每条消息只被一个实例处理
+	DLQSubject string // 死信主题
+}
+
+// startNATSIngest 按环境变量连接 NATS（INGEST_NATS_URL 为空时不启用）
+func startNATSIngest(ctx context.Context, svc *service.IngestService) {
+	url := os.Getenv("INGEST_NATS_URL")
+	if url == "" {
+		return
+	}
+	cfg := NATSIngestConfig{
+		Subject:    getEnv("INGEST_NATS_SUBJECT", "memogo.todos.create"),
+		Queue:      getEnv("INGEST_NATS_QUEUE", "memogo-ingest"),
+		DLQSubject: os.Getenv("INGEST_NATS_DLQ_SUBJECT"),
+	}
+
+	nc, err := nats.Connect(url, nats.Name("memogo-ingest"), nats.MaxReconnects(-1))
+	if err != nil {
+		log.Printf("Warning: Failed to connect to NATS: %v (ingest disabled)", err)
+		return
+	}
+	if _, err := SubscribeNATSIngest(ctx, nc, cfg, svc); err != nil {
+		log.Printf("Warning: Failed to subscribe NATS ingest: %v", err)
+		nc.Close()
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		_ = nc.Drain()
+	}()
+	log.Printf("✅ NATS ingest subscribed to %s (queue %s)", cfg.Subject, cfg.Queue)
+}
+
+// SubscribeNATSIngest 在已有连接上订阅导入主题（可直接传入内嵌 NATS server 的连接）
+// 合法消息创建待办；不合法的消息连同原因转发到死信主题；带 reply 的请求会收到处理结果
+func SubscribeNATSIngest(ctx context.Context, nc *nats.Conn, cfg NATSIngestConfig, svc *service.IngestService) (*nats.Subscription, error) {
+	if cfg.DLQSubject == "" {
+		cfg.DLQSubject = cfg.Subject + ".dlq"
+	}
+	return nc.QueueSubscribe(cfg.Subject, cfg.Queue, func(msg *nats.Msg) {
+		result, err := ingestWithRetry(ctx, svc, "nats", msg.Data)
+		if err != nil {
+			deadLetterNATS(nc, cfg.DLQSubject, msg, err)
+		}
+		if msg.Reply != "" {
+			replyNATS(msg, result, err)
+		}
+	})
+}
+
+func deadLetterNATS(nc *nats.Conn, subject string, msg *nats.Msg, cause error) {
+	dlq := nats.NewMsg(subject)
+	dlq.Data = msg.Data
+	dlq.Header.Set("Memogo-Error", cause.Error())
+	dlq.Header.Set("Memogo-Subject", msg.Subject)
+	if err := nc.PublishMsg(dlq); err != nil {
+		log.Printf("Ingest (nats): dead-letter publish failed: %v (cause: %v)", err, cause)
+		return
+	}
+	log.Printf("Ingest (nats): message dead-lettered to %s: %v", subject, cause)
+}
+
+func replyNATS(msg *nats.Msg, result *service.IngestResult, err error) {
+	resp := map[string]interface{}{"status": 200, "msg": "ok", "data": result}
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, service.ErrInvalidIngestMessage):
+			status = 400
+		case errors.Is(err, service.ErrPlanLimitReached):
+			status = 403
+		}
+		resp = map[string]interface{}{"status": status, "msg": err.Error(), "data": nil}
+	}
+	data, _ := json.Marshal(resp)
+	if err := msg.Respond(data); err != nil {
+		log.Printf("Ingest (nats): reply failed: %v", err)
+	}
+}
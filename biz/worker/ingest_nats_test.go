@@ -0,0 +1,267 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"memogo/biz/dal/db/dbtest"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+const ingestSubject = "memogo.todos.create"
+
+type natsIngestFixture struct {
+	nc    *nats.Conn
+	db    *gorm.DB
+	alice *model.User
+	bob   *model.User
+	dlq   *nats.Subscription
+}
+
+// newNATSIngestFixture 启动内嵌 NATS server，订阅导入主题（instances 个实例共用队列组）与死信主题
+func newNATSIngestFixture(t *testing.T, instances int) *natsIngestFixture {
+	t.Helper()
+	srv := natstest.RunRandClientPortServer()
+	t.Cleanup(srv.Shutdown)
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+
+	db := dbtest.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	f := &natsIngestFixture{nc: nc, db: db}
+	f.alice = &model.User{Username: "alice", PasswordHash: "x"}
+	f.bob = &model.User{Username: "bob", PasswordHash: "x"}
+	for _, u := range []*model.User{f.alice, f.bob} {
+		if err := userRepo.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc := service.NewIngestService(
+		repository.NewIngestRecordRepository(db),
+		repository.NewTodoRepository(db),
+		userRepo,
+		repository.NewProjectRepository(db),
+		repository.NewPlanRepository(db),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	for i := 0; i < instances; i++ {
+		// 每个实例一条连接，模拟多实例部署
+		conn, err := nats.Connect(srv.ClientURL())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(conn.Close)
+		cfg := NATSIngestConfig{Subject: ingestSubject, Queue: "memogo-ingest"}
+		if _, err := SubscribeNATSIngest(ctx, conn, cfg, svc); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.dlq, err = nc.SubscribeSync(ingestSubject + ".dlq"); err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+type ingestReply struct {
+	Status int                   `json:"status"`
+	Msg    string                `json:"msg"`
+	Data   *service.IngestResult `json:"data"`
+}
+
+func (f *natsIngestFixture) request(t *testing.T, payload string) ingestReply {
+	t.Helper()
+	reply, err := f.tryRequest(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reply
+}
+
+func (f *natsIngestFixture) tryRequest(payload string) (ingestReply, error) {
+	var reply ingestReply
+	msg, err := f.nc.Request(ingestSubject, []byte(payload), 5*time.Second)
+	if err != nil {
+		return reply, fmt.Errorf("request %s: %w", payload, err)
+	}
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return reply, fmt.Errorf("decode reply %s: %w", msg.Data, err)
+	}
+	return reply, nil
+}
+
+func (f *natsIngestFixture) countTodos(t *testing.T, userID uint) int64 {
+	t.Helper()
+	var n int64
+	if err := f.db.Model(&model.Todo{}).Where("user_id = ?", userID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestNATSIngestSchemaValidation(t *testing.T) {
+	f := newNATSIngestFixture(t, 1)
+	tests := []struct {
+		name    string
+		payload string
+		reason  string
+	}{
+		{"malformed json", `{"idempotency_key":`, "unexpected EOF"},
+		{"unknown field", `{"idempotency_key":"k1","username":"alice","title":"t","priority":1}`, `unknown field "priority"`},
+		{"wrong type", `{"idempotency_key":"k1","username":"alice","title":"t","due_time":"tomorrow"}`, "due_time"},
+		{"missing idempotency key", `{"username":"alice","title":"t"}`, "idempotency_key is required"},
+		{"blank idempotency key", `{"idempotency_key":"  ","username":"alice","title":"t"}`, "idempotency_key is required"},
+		{"idempotency key too long", `{"idempotency_key":"` + strings.Repeat("k", 129) + `","username":"alice","title":"t"}`, "exceeds 128 bytes"},
+		{"missing title", `{"idempotency_key":"k1","username":"alice","title":" "}`, "title"},
+		{"missing user", `{"idempotency_key":"k1","title":"t"}`, "user_id or username is required"},
+		{"unknown user", `{"idempotency_key":"k1","username":"mallory","title":"t"}`, "target user not found"},
+		{"user mismatch", `{"idempotency_key":"k1","user_id":` + uintJSON(f.bob.ID) + `,"username":"alice","title":"t"}`, "different users"},
+		{"foreign project", `{"idempotency_key":"k1","username":"alice","title":"t","project_id":999}`, "project 999 not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := f.request(t, tt.payload)
+			if reply.Status != 400 || !strings.Contains(reply.Msg, tt.reason) {
+				t.Fatalf("reply = %+v, want 400 containing %q", reply, tt.reason)
+			}
+
+			// 不合法的消息原样进入死信主题，并带上原因与原主题
+			dead, err := f.dlq.NextMsg(5 * time.Second)
+			if err != nil {
+				t.Fatalf("no dead letter: %v", err)
+			}
+			if string(dead.Data) != tt.payload {
+				t.Errorf("dead letter body = %s, want the original message", dead.Data)
+			}
+			if cause := dead.Header.Get("Memogo-Error"); !strings.Contains(cause, tt.reason) {
+				t.Errorf("Memogo-Error = %q, want it to contain %q", cause, tt.reason)
+			}
+			if subject := dead.Header.Get("Memogo-Subject"); subject != ingestSubject {
+				t.Errorf("Memogo-Subject = %q, want %q", subject, ingestSubject)
+			}
+		})
+	}
+	if n := f.countTodos(t, f.alice.ID) + f.countTodos(t, f.bob.ID); n != 0 {
+		t.Fatalf("invalid messages created %d todos", n)
+	}
+}
+
+func TestNATSIngestCreatesTodo(t *testing.T) {
+	f := newNATSIngestFixture(t, 1)
+	reply := f.request(t, `{"idempotency_key":"ci-1","username":"alice","title":" Build failed ","due_time":1893456000}`)
+	if reply.Status != 200 || reply.Data == nil || reply.Data.TodoID == 0 || reply.Data.Duplicate {
+		t.Fatalf("reply = %+v, want a new todo", reply)
+	}
+	var todo model.Todo
+	if err := f.db.First(&todo, reply.Data.TodoID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if todo.UserID != f.alice.ID || todo.Title != "Build failed" || todo.Content != "Build failed" ||
+		todo.DueTime == nil || todo.DueTime.Unix() != 1893456000 {
+		t.Fatalf("todo = %+v", todo)
+	}
+
+	// 不带 reply 的发布同样会被处理
+	if err := f.nc.Publish(ingestSubject, []byte(`{"idempotency_key":"ci-2","user_id":`+uintJSON(f.alice.ID)+`,"title":"Deploy"}`)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return f.countTodos(t, f.alice.ID) == 2 })
+	if _, err := f.dlq.NextMsg(100 * time.Millisecond); !errors.Is(err, nats.ErrTimeout) {
+		t.Fatalf("valid messages must not be dead-lettered (err = %v)", err)
+	}
+}
+
+func TestNATSIngestIdempotencyKey(t *testing.T) {
+	f := newNATSIngestFixture(t, 1)
+	first := f.request(t, `{"idempotency_key":"ci-1","username":"alice","title":"Build failed"}`)
+	again := f.request(t, `{"idempotency_key":"ci-1","username":"alice","title":"Build failed (redelivered)"}`)
+	if first.Status != 200 || again.Status != 200 {
+		t.Fatalf("replies = %+v, %+v", first, again)
+	}
+	if !again.Data.Duplicate || again.Data.TodoID != first.Data.TodoID {
+		t.Fatalf("redelivery = %+v, want duplicate of todo %d", again.Data, first.Data.TodoID)
+	}
+	if n := f.countTodos(t, f.alice.ID); n != 1 {
+		t.Fatalf("alice has %d todos, want 1", n)
+	}
+
+	// 幂等键按目标用户区分
+	other := f.request(t, `{"idempotency_key":"ci-1","username":"bob","title":"Build failed"}`)
+	if other.Status != 200 || other.Data.Duplicate || other.Data.TodoID == first.Data.TodoID {
+		t.Fatalf("same key for another user = %+v, want a new todo", other)
+	}
+}
+
+func TestNATSIngestDedupAcrossInstances(t *testing.T) {
+	f := newNATSIngestFixture(t, 3)
+	const copies = 30
+	var wg sync.WaitGroup
+	replies := make([]ingestReply, copies)
+	errs := make([]error, copies)
+	for i := 0; i < copies; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			replies[i], errs[i] = f.tryRequest(`{"idempotency_key":"webhook-42","username":"alice","title":"Pager alert"}`)
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	created := 0
+	for _, r := range replies {
+		if r.Status != 200 || r.Data.TodoID != replies[0].Data.TodoID {
+			t.Fatalf("reply = %+v, want todo %d", r, replies[0].Data.TodoID)
+		}
+		if !r.Data.Duplicate {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d replies created a todo, want exactly 1", created)
+	}
+	if n := f.countTodos(t, f.alice.ID); n != 1 {
+		t.Fatalf("alice has %d todos, want 1", n)
+	}
+}
+
+func uintJSON(v uint) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	redisClient "memogo/biz/dal/redis"
+	"memogo/biz/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisIngestPayloadField 流消息中存放 JSON 的字段名
+const redisIngestPayloadField = "payload"
+
+// startRedisIngest 以消费者组读取 Redis Stream（INGEST_REDIS_STREAM 为空或 Redis 不可用时不启用）
+func startRedisIngest(ctx context.Context, svc *service.IngestService) {
+	stream := os.Getenv("INGEST_REDIS_STREAM")
+	if stream == "" {
+		return
+	}
+	if redisClient.RDB == nil {
+		log.Println("Warning: INGEST_REDIS_STREAM is set but Redis is unavailable (ingest disabled)")
+		return
+	}
+	group := getEnv("INGEST_REDIS_GROUP", "memogo-ingest")
+	dlq := getEnv("INGEST_REDIS_DLQ_STREAM", stream+":dlq")
+	consumer, _ := os.Hostname()
+	if consumer == "" {
+		consumer = "memogo"
+	}
+
+	rdb := redisClient.RDB
+	err := rdb.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !isBusyGroup(err) {
+		log.Printf("Warning: Failed to create Redis consumer group: %v (ingest disabled)", err)
+		return
+	}
+
+	go func() {
+		for ctx.Err() == nil {
+			// 先认领其它消费者崩溃后遗留超过 1 分钟的消息，再读取新消息
+			claimed, _, err := rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream: stream, Group: group, Consumer: consumer,
+				MinIdle: time.Minute, Start: "0", Count: 10,
+			}).Result()
+			if err == nil {
+				for _, m := range claimed {
+					handleRedisIngest(ctx, rdb, svc, stream, group, dlq, m)
+				}
+			}
+
+			streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group: group, Consumer: consumer,
+				Streams: []string{stream, ">"},
+				Count:   10, Block: 5 * time.Second,
+			}).Result()
+			if err != nil {
+				if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+					continue
+				}
+				log.Printf("Ingest (redis): read failed: %v", err)
+				time.Sleep(time.Second)
+				continue
+			}
+			for _, s := range streams {
+				for _, m := range s.Messages {
+					handleRedisIngest(ctx, rdb, svc, stream, group, dlq, m)
+				}
+			}
+		}
+	}()
+	log.Printf("✅ Redis Streams ingest reading %s (group %s)", stream, group)
+}
+
+func handleRedisIngest(ctx context.Context, rdb *redis.Client, svc *service.IngestService, stream, group, dlq string, m redis.XMessage) {
+	payload, _ := m.Values[redisIngestPayloadField].(string)
+	_, err := ingestWithRetry(ctx, svc, "redis", []byte(payload))
+	if err != nil {
+		if ctx.Err() != nil {
+			return // 退出中，不确认，留给下次认领
+		}
+		addErr := rdb.XAdd(ctx, &redis.XAddArgs{
+			Stream: dlq,
+			Values: map[string]interface{}{
+				redisIngestPayloadField: payload,
+				"error":                 err.Error(),
+				"source_id":             m.ID,
+			},
+		}).Err()
+		if addErr != nil {
+			log.Printf("Ingest (redis): dead-letter failed: %v (cause: %v)", addErr, err)
+			return // 不确认，稍后由 XAutoClaim 重新处理
+		}
+		log.Printf("Ingest (redis): message %s dead-lettered to %s: %v", m.ID, dlq, err)
+	}
+	if err := rdb.XAck(ctx, stream, group, m.ID).Err(); err != nil {
+		log.Printf("Ingest (redis): ack %s failed: %v", m.ID, err)
+	}
+}
+
+func isBusyGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP")
+}
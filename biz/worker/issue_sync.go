@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+)
+
+// StartIssueSync 启动 issue 定时同步（ISSUE_SYNC_INTERVAL，默认 10m，设为 0 关闭）
+func StartIssueSync(ctx context.Context) {
+	interval := getEnvAsDuration("ISSUE_SYNC_INTERVAL", 10*time.Minute)
+	if interval <= 0 {
+		log.Println("Issue sync worker disabled")
+		return
+	}
+
+	svc := service.NewIssueSyncService(
+		repository.NewIssueConnectorRepository(db.DB),
+		repository.NewIssueLinkRepository(db.DB),
+		repository.NewTodoRepository(db.DB),
+		repository.NewProjectRepository(db.DB),
+		repository.NewPlanRepository(db.DB),
+	)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				svc.SyncAll(ctx)
+			}
+		}
+	}()
+	log.Printf("Issue sync worker started (interval %s)", interval)
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memogo/pkg/workday"
+)
+
+// StartWorkdayCalendar 从 WORKDAY_DATA_DIR 加载节假日数据并定期热更新（WORKDAY_RELOAD_INTERVAL，默认 1h）
+// 未配置目录时只使用内置数据
+func StartWorkdayCalendar(ctx context.Context) {
+	dir := getEnv("WORKDAY_DATA_DIR", "")
+	if dir == "" {
+		log.Println("Workday calendar using bundled holiday data")
+		return
+	}
+	reloadWorkdayCalendar(dir)
+
+	interval := getEnvAsDuration("WORKDAY_RELOAD_INTERVAL", time.Hour)
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reloadWorkdayCalendar(dir)
+			}
+		}
+	}()
+	log.Printf("Workday calendar reloader started (dir %s, interval %s)", dir, interval)
+}
+
+// reloadWorkdayCalendar 加载失败时保留上一份日历
+func reloadWorkdayCalendar(dir string) {
+	cal, err := workday.Load(dir)
+	if err != nil {
+		log.Printf("Workday calendar reload failed: %v", err)
+		return
+	}
+	workday.SetDefault(cal)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"memogo/pkg/vault"
+)
+
+// apiClient 通过 memogo HTTP API 实现 vault.Remote
+type apiClient struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+func newAPIClient(baseURL, username, password string) *apiClient {
+	return &apiClient{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type apiResp struct {
+	Status int             `json:"status"`
+	Msg    string          `json:"msg"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type apiTodo struct {
+	ID      uint   `json:"id"`
+	Title   string `json:"title"`
+	Status  int    `json:"status"`
+	DueTime int64  `json:"due_time"`
+}
+
+func (c *apiClient) login(ctx context.Context) error {
+	body, _ := json.Marshal(map[string]string{"username": c.username, "password": c.password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/auth/login", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var data struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.send(req, &data); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	c.token = data.AccessToken
+	return nil
+}
+
+// do 发送带认证的请求，令牌过期时重新登录并重试一次
+func (c *apiClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if c.token == "" {
+			if err := c.login(ctx); err != nil {
+				return err
+			}
+		}
+
+		var reader io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		err = c.send(req, out)
+		if err == errUnauthorized && attempt == 0 {
+			c.token = ""
+			continue
+		}
+		return err
+	}
+}
+
+var errUnauthorized = fmt.Errorf("unauthorized")
+
+func (c *apiClient) send(req *http.Request, out interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errUnauthorized
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var r apiResp
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, r.Msg)
+	}
+	if out == nil || len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, out)
+}
+
+// List 用游标分页拉取全部待办
+func (c *apiClient) List(ctx context.Context) ([]vault.RemoteTodo, error) {
+	var (
+		todos  []vault.RemoteTodo
+		cursor int64
+	)
+	for {
+		var page struct {
+			Items      []apiTodo `json:"items"`
+			NextCursor int64     `json:"next_cursor"`
+			HasMore    bool      `json:"has_more"`
+		}
+		q := url.Values{"status": {"all"}, "limit": {"100"}, "cursor": {fmt.Sprint(cursor)}}
+		if err := c.do(ctx, http.MethodGet, "/v1/todos/cursor?"+q.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, t := range page.Items {
+			todos = append(todos, vault.RemoteTodo{ID: t.ID, State: vault.State{
+				Title: t.Title,
+				Done:  t.Status == 1,
+				Due:   formatDue(t.DueTime),
+			}})
+		}
+		if !page.HasMore {
+			return todos, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func (c *apiClient) Create(ctx context.Context, s vault.State) (uint, error) {
+	body := map[string]interface{}{"title": s.Title, "content": s.Title}
+	if due := parseDue(s.Due); due != 0 {
+		body["due_time"] = due
+	}
+	var todo apiTodo
+	if err := c.do(ctx, http.MethodPost, "/v1/todos", body, &todo); err != nil {
+		return 0, err
+	}
+	if s.Done {
+		if err := c.setStatus(ctx, todo.ID, true); err != nil {
+			return 0, err
+		}
+	}
+	return todo.ID, nil
+}
+
+func (c *apiClient) Update(ctx context.Context, id uint, s, prev vault.State) error {
+	fields := map[string]interface{}{}
+	if s.Title != prev.Title {
+		fields["title"] = s.Title
+	}
+	if s.Due != prev.Due {
+		fields["due_time"] = parseDue(s.Due) // 0 表示清空
+	}
+	if len(fields) > 0 {
+		if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/v1/todos/%d", id), fields, nil); err != nil {
+			return err
+		}
+	}
+	if s.Done != prev.Done {
+		return c.setStatus(ctx, id, s.Done)
+	}
+	return nil
+}
+
+func (c *apiClient) Delete(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/v1/todos/%d", id), nil, nil)
+}
+
+func (c *apiClient) setStatus(ctx context.Context, id uint, done bool) error {
+	status := 0
+	if done {
+		status = 1
+	}
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/v1/todos/%d/status", id), map[string]int{"status": status}, nil)
+}
+
+// parseDue 把 YYYY-MM-DD 转为本地时区当天零点的 unix 秒
+func parseDue(date string) int64 {
+	if date == "" {
+		return 0
+	}
+	t, err := time.ParseInLocation(vault.DateLayout, date, time.Local)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func formatDue(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).In(time.Local).Format(vault.DateLayout)
+}
@@ -0,0 +1,133 @@
+// vaultsync 把 Markdown 目录（Obsidian 风格 vault）中的任务行与 memogo 待办双向同步。
+//
+// 任务行格式：`- [ ] 描述 📅 2026-10-20 🆔 memogo-42`，同步后自动写入 🆔 行内 ID。
+// 监听目录变化即时推送，并按固定间隔拉取服务端变化写回文件。
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"memogo/pkg/vault"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func main() {
+	var (
+		dir      = flag.String("dir", ".", "vault directory")
+		server   = flag.String("server", envOr("MEMOGO_URL", "http://localhost:8888"), "memogo server URL")
+		inbox    = flag.String("inbox", "memogo.md", "file (relative to dir) that receives todos created on the server")
+		interval = flag.Duration("interval", time.Minute, "interval for pulling server changes")
+		once     = flag.Bool("once", false, "run a single sync and exit")
+	)
+	flag.Parse()
+
+	username, password := os.Getenv("MEMOGO_USERNAME"), os.Getenv("MEMOGO_PASSWORD")
+	if username == "" || password == "" {
+		log.Fatal("MEMOGO_USERNAME and MEMOGO_PASSWORD are required")
+	}
+	root, err := filepath.Abs(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	syncer := &vault.Syncer{
+		Vault:     &vault.Vault{Root: root},
+		Remote:    newAPIClient(*server, username, password),
+		InboxFile: *inbox,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runOnce(ctx, syncer)
+	if *once {
+		return
+	}
+	if err := watch(ctx, syncer, *interval); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runOnce(ctx context.Context, syncer *vault.Syncer) {
+	result, err := syncer.Run(ctx)
+	if err != nil {
+		log.Printf("sync failed: %v", err)
+		return
+	}
+	if *result != (vault.SyncResult{}) {
+		log.Printf("synced: %+v", *result)
+	}
+}
+
+// watch 监听 vault 目录，文件变化后防抖 1 秒再同步；同时定时拉取服务端变化
+func watch(ctx context.Context, syncer *vault.Syncer, interval time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	addDirs := func() {
+		dirs, err := syncer.Vault.Dirs()
+		if err != nil {
+			log.Printf("list directories failed: %v", err)
+			return
+		}
+		for _, d := range dirs {
+			if err := watcher.Add(d); err != nil {
+				log.Printf("watch %s failed: %v", d, err)
+			}
+		}
+	}
+	addDirs()
+	log.Printf("watching %s (pull interval %s)", syncer.Vault.Root, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addDirs()
+				}
+			}
+			if strings.EqualFold(filepath.Ext(event.Name), ".md") {
+				debounce.Reset(time.Second)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watcher error: %v", err)
+		case <-debounce.C:
+			runOnce(ctx, syncer)
+		case <-ticker.C:
+			runOnce(ctx, syncer)
+		}
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -3,9 +3,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"memogo/biz/dal/db"
 	"memogo/biz/dal/redis"
+	"memogo/biz/worker"
 	"memogo/pkg/middleware"
 
 	"github.com/cloudwego/hertz/pkg/app/server"
@@ -28,8 +30,20 @@ func main() {
 		log.Fatal("Failed to initialize JWT middleware: ", err)
 	}
 
+	// 启动后台任务
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.StartWorkdayCalendar(ctx)
+	worker.StartIssueSync(ctx)
+	worker.StartIngest(ctx)
+	worker.StartDelegationFollowUps(ctx)
+	worker.StartAnalyticsExport(ctx)
+
 	h := server.Default(server.WithHostPorts(":8888"))
 
+	// 自适应并发限制：过载时快速返回 503，健康检查不受限制
+	h.Use(middleware.ConcurrencyLimit())
+
 	// 注册业务路由
 	register(h)
 
@@ -0,0 +1,66 @@
+// Package billing 定义计费提供方接口：为客户订阅套餐、取消与查询订阅。
+// 具体的支付平台（Stripe、Paddle 等）实现 Provider 接口后在 Default 中注册；Fake 供开发与测试使用
+package billing
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	// 确保环境变量在读取配置之前加载
+	_ "memogo/pkg/env"
+)
+
+// 订阅状态
+const (
+	StatusActive   = "active"
+	StatusTrialing = "trialing"
+	StatusPastDue  = "past_due"
+	StatusCanceled = "canceled"
+)
+
+// ErrSubscriptionNotFound 订阅不存在
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// Subscription 提供方的订阅
+type Subscription struct {
+	ID               string
+	CustomerRef      string // 本系统的主体标识，如 user:12
+	PlanCode         string
+	Status           string
+	CurrentPeriodEnd time.Time
+}
+
+// Provider 计费提供方
+type Provider interface {
+	// Name 提供方名称，记录在套餐分配上
+	Name() string
+	// Subscribe 为客户订阅套餐，返回新的订阅
+	Subscribe(ctx context.Context, customerRef, planCode string) (*Subscription, error)
+	// Cancel 取消订阅
+	Cancel(ctx context.Context, subscriptionID string) error
+	// Subscription 查询订阅的当前状态
+	Subscription(ctx context.Context, subscriptionID string) (*Subscription, error)
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultProvider Provider
+)
+
+// Default 按 BILLING_PROVIDER 选择的提供方：为空时返回 nil（只能手动分配套餐），fake 为内存实现
+func Default() Provider {
+	defaultOnce.Do(func() {
+		switch name := os.Getenv("BILLING_PROVIDER"); name {
+		case "":
+		case "fake":
+			defaultProvider = NewFake()
+		default:
+			log.Printf("Unknown BILLING_PROVIDER %q, billing disabled", name)
+		}
+	})
+	return defaultProvider
+}
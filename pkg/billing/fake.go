@@ -0,0 +1,77 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fake 内存中的计费提供方：订阅立即生效，周期 30 天；SetStatus 可模拟扣款失败、取消等状态变化
+type Fake struct {
+	mu   sync.Mutex
+	seq  int
+	subs map[string]*Subscription
+}
+
+// NewFake 创建内存计费提供方
+func NewFake() *Fake {
+	return &Fake{subs: map[string]*Subscription{}}
+}
+
+// Name 实现 Provider
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Subscribe 实现 Provider
+func (f *Fake) Subscribe(ctx context.Context, customerRef, planCode string) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	sub := &Subscription{
+		ID:               fmt.Sprintf("fake_sub_%d", f.seq),
+		CustomerRef:      customerRef,
+		PlanCode:         planCode,
+		Status:           StatusActive,
+		CurrentPeriodEnd: time.Now().AddDate(0, 0, 30),
+	}
+	f.subs[sub.ID] = sub
+	copied := *sub
+	return &copied, nil
+}
+
+// Cancel 实现 Provider
+func (f *Fake) Cancel(ctx context.Context, subscriptionID string) error {
+	return f.SetStatus(subscriptionID, StatusCanceled)
+}
+
+// Subscription 实现 Provider
+func (f *Fake) Subscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sub, ok := f.subs[subscriptionID]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+// SetStatus 修改订阅状态，模拟提供方侧的变化
+func (f *Fake) SetStatus(subscriptionID, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sub, ok := f.subs[subscriptionID]
+	if !ok {
+		return ErrSubscriptionNotFound
+	}
+	sub.Status = status
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package blobstore 把生成的文件写到本地目录或 S3 兼容的对象存储（AWS S3、MinIO、OSS 等）
+package blobstore
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Store 对象存储：key 是以 / 分隔的相对路径
+type Store interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Local 写到本地目录，先写临时文件再重命名，读取方不会看到写了一半的文件
+type Local struct {
+	dir string
+}
+
+// NewLocal 创建本地目录存储
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+// Put 实现 Store
+func (l *Local) Put(ctx context.Context, key string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name := filepath.Join(l.dir, filepath.FromSlash(path.Clean("/"+key)))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// contentType 按扩展名推断对象的 Content-Type
+func contentType(key string) string {
+	switch {
+	case strings.HasSuffix(key, ".parquet"):
+		return "application/vnd.apache.parquet"
+	case strings.HasSuffix(key, ".gz"):
+		return "application/gzip"
+	case strings.HasSuffix(key, ".csv"):
+		return "text/csv"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,38 @@
+// Package collation 生成按语言习惯排序用的排序键：中文按拼音，其它文字按 Unicode 排序规则（CLDR）。
+// 排序键是排序规则字节串的十六进制形式，数据库按普通字符串比较即可得到正确顺序，可用于键集分页
+package collation
+
+import (
+	"encoding/hex"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+const (
+	// maxKeyRunes 只取前若干个字符计算排序键，超出部分不影响顺序（并列时由调用方按 ID 排序）
+	maxKeyRunes = 40
+	// MaxKeyLen 排序键的最大长度（十六进制字符数），与数据库列宽一致
+	MaxKeyLen = 512
+)
+
+// collate.Collator 不能并发使用，按需复用
+var collators = sync.Pool{
+	New: func() any { return collate.New(language.Chinese) },
+}
+
+// Key 计算 s 的排序键
+func Key(s string) string {
+	if r := []rune(s); len(r) > maxKeyRunes {
+		s = string(r[:maxKeyRunes])
+	}
+	c := collators.Get().(*collate.Collator)
+	defer collators.Put(c)
+	var buf collate.Buffer
+	key := hex.EncodeToString(c.KeyFromString(&buf, s))
+	if len(key) > MaxKeyLen {
+		key = key[:MaxKeyLen]
+	}
+	return key
+}
@@ -0,0 +1,15 @@
+// Package testenv 为测试补齐启动时必需的环境变量，测试文件以空导入引入：
+//
+//	import _ "memogo/pkg/env/testenv"
+//
+// 包按导入路径排序初始化，本包只依赖 os 且路径排在 memogo/pkg/jwt 之前，
+// 因此先于 jwt 的 init 执行，不设置 JWT_SECRET 也能直接 go test ./...
+package testenv
+
+import "os"
+
+func init() {
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "memogo-test-secret")
+	}
+}
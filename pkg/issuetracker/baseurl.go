@@ -0,0 +1,102 @@
+package issuetracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrBaseURLNotAllowed base_url 不是官方 API 地址，也不在管理员配置的自建实例白名单中
+var ErrBaseURLNotAllowed = errors.New("base_url must be the official API or a host listed in ISSUE_TRACKER_ALLOWED_HOSTS")
+
+// officialHosts 各平台官方 API 的主机名
+var officialHosts = map[string]string{
+	ProviderGitHub: "api.github.com",
+	ProviderGitLab: "gitlab.com",
+}
+
+// AllowedHostsFromEnv 读取 ISSUE_TRACKER_ALLOWED_HOSTS（逗号分隔的主机名）：管理员信任的 GitHub Enterprise / 自建 GitLab 实例
+func AllowedHostsFromEnv() []string {
+	var hosts []string
+	for _, h := range strings.Split(os.Getenv("ISSUE_TRACKER_ALLOWED_HOSTS"), ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// CheckBaseURL 校验用户提供的 base_url：为空使用官方地址；官方地址须为 https；
+// 其它主机必须在 allowedHosts 中（白名单内的实例可以使用 http 与内网地址）
+func CheckBaseURL(provider, baseURL string, allowedHosts []string) error {
+	if baseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrBaseURLNotAllowed
+	}
+	host := strings.ToLower(u.Hostname())
+	if hostAllowed(host, allowedHosts) {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return ErrBaseURLNotAllowed
+		}
+		return nil
+	}
+	if u.Scheme != "https" || host != officialHosts[provider] || (u.Port() != "" && u.Port() != "443") {
+		return ErrBaseURLNotAllowed
+	}
+	return nil
+}
+
+func hostAllowed(host string, allowedHosts []string) bool {
+	for _, h := range allowedHosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// publicOnly 判断地址是否可以访问：拒绝回环、私有、链路本地、未指定与组播地址
+func publicOnly(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// newHTTPClient 只连接公网地址的客户端（白名单主机除外）：在拨号时解析并检查 IP，
+// 重定向与 DNS 重新绑定同样无法指向内网
+func newHTTPClient(allowedHosts []string) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if hostAllowed(strings.ToLower(host), allowedHosts) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if !publicOnly(ip.IP) {
+				return nil, fmt.Errorf("%w: %s resolves to a non-public address", ErrBaseURLNotAllowed, host)
+			}
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no address for %s", host)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+	}
+	return &http.Client{Timeout: 15 * time.Second, Transport: transport}
+}
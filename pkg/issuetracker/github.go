@@ -0,0 +1,144 @@
+package issuetracker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type githubClient struct {
+	cfg Config
+}
+
+type githubIssue struct {
+	Number    int64     `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	State     string    `json:"state"` // "open" | "closed"
+	UpdatedAt time.Time `json:"updated_at"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	// PullRequest 非空表示这是 PR（GitHub 的 issues 接口会同时返回 PR）
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+func (g githubIssue) toIssue() Issue {
+	issue := Issue{
+		Number:    g.Number,
+		Title:     g.Title,
+		Body:      g.Body,
+		URL:       g.HTMLURL,
+		Closed:    g.State == "closed",
+		UpdatedAt: g.UpdatedAt,
+	}
+	for _, a := range g.Assignees {
+		issue.Assignees = append(issue.Assignees, a.Login)
+	}
+	return issue
+}
+
+func (c *githubClient) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, c.cfg.BaseURL+path, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (c *githubClient) ListAssignedIssues(ctx context.Context, since time.Time) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		q := url.Values{}
+		q.Set("state", "all")
+		q.Set("per_page", fmt.Sprint(perPage))
+		q.Set("page", fmt.Sprint(page))
+		if c.cfg.Assignee != "" {
+			q.Set("assignee", c.cfg.Assignee)
+		}
+		if !since.IsZero() {
+			q.Set("since", since.UTC().Format(time.RFC3339))
+		}
+		req, err := c.newRequest(http.MethodGet, "/repos/"+c.cfg.Repo+"/issues?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var batch []githubIssue
+		if err := doJSON(ctx, c.cfg.HTTPClient, req, &batch); err != nil {
+			return nil, err
+		}
+		for _, g := range batch {
+			if len(g.PullRequest) > 0 && string(g.PullRequest) != "null" {
+				continue
+			}
+			issues = append(issues, g.toIssue())
+		}
+		if len(batch) < perPage {
+			return issues, nil
+		}
+	}
+}
+
+func (c *githubClient) SetState(ctx context.Context, number int64, closed bool) (*Issue, error) {
+	state := "open"
+	if closed {
+		state = "closed"
+	}
+	req, err := c.newRequest(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", c.cfg.Repo, number),
+		map[string]string{"state": state})
+	if err != nil {
+		return nil, err
+	}
+	var g githubIssue
+	if err := doJSON(ctx, c.cfg.HTTPClient, req, &g); err != nil {
+		return nil, err
+	}
+	issue := g.toIssue()
+	return &issue, nil
+}
+
+// parseGitHubWebhook 校验 X-Hub-Signature-256 并解析 issues 事件
+func parseGitHubWebhook(secret string, header func(string) string, body []byte) (*Issue, error) {
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(expected), []byte(header("X-Hub-Signature-256"))) {
+			return nil, ErrInvalidWebhookSignature
+		}
+	}
+	if header("X-GitHub-Event") != "issues" {
+		return nil, ErrIgnoredEvent
+	}
+
+	var payload struct {
+		Action string      `json:"action"`
+		Issue  githubIssue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	issue := payload.Issue.toIssue()
+	return &issue, nil
+}
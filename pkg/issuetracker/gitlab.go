@@ -0,0 +1,153 @@
+package issuetracker
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type gitlabClient struct {
+	cfg Config
+}
+
+type gitlabIssue struct {
+	IID         int64      `json:"iid"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	WebURL      string     `json:"web_url"`
+	URL         string     `json:"url"`   // webhook 中的字段名
+	State       string     `json:"state"` // "opened" | "closed"
+	UpdatedAt   gitlabTime `json:"updated_at"`
+	Assignees   []struct {
+		Username string `json:"username"`
+	} `json:"assignees"`
+}
+
+func (g gitlabIssue) toIssue() Issue {
+	issue := Issue{
+		Number:    g.IID,
+		Title:     g.Title,
+		Body:      g.Description,
+		URL:       g.WebURL,
+		Closed:    g.State == "closed",
+		UpdatedAt: time.Time(g.UpdatedAt),
+	}
+	if issue.URL == "" {
+		issue.URL = g.URL
+	}
+	for _, a := range g.Assignees {
+		issue.Assignees = append(issue.Assignees, a.Username)
+	}
+	return issue
+}
+
+// gitlabTime 兼容 REST（RFC3339）与 webhook（"2006-01-02 15:04:05 UTC"）两种时间格式
+type gitlabTime time.Time
+
+func (t *gitlabTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = gitlabTime(parsed)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid gitlab time %q", s)
+}
+
+func (c *gitlabClient) projectPath() string {
+	return "/projects/" + url.PathEscape(c.cfg.Repo)
+}
+
+func (c *gitlabClient) newRequest(method, path string) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.cfg.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.cfg.Token)
+	}
+	return req, nil
+}
+
+func (c *gitlabClient) ListAssignedIssues(ctx context.Context, since time.Time) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		q := url.Values{}
+		q.Set("scope", "all")
+		q.Set("per_page", fmt.Sprint(perPage))
+		q.Set("page", fmt.Sprint(page))
+		if c.cfg.Assignee != "" {
+			q.Set("assignee_username", c.cfg.Assignee)
+		}
+		if !since.IsZero() {
+			q.Set("updated_after", since.UTC().Format(time.RFC3339))
+		}
+		req, err := c.newRequest(http.MethodGet, c.projectPath()+"/issues?"+q.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var batch []gitlabIssue
+		if err := doJSON(ctx, c.cfg.HTTPClient, req, &batch); err != nil {
+			return nil, err
+		}
+		for _, g := range batch {
+			issues = append(issues, g.toIssue())
+		}
+		if len(batch) < perPage {
+			return issues, nil
+		}
+	}
+}
+
+func (c *gitlabClient) SetState(ctx context.Context, number int64, closed bool) (*Issue, error) {
+	event := "reopen"
+	if closed {
+		event = "close"
+	}
+	req, err := c.newRequest(http.MethodPut, fmt.Sprintf("%s/issues/%d?state_event=%s", c.projectPath(), number, event))
+	if err != nil {
+		return nil, err
+	}
+	var g gitlabIssue
+	if err := doJSON(ctx, c.cfg.HTTPClient, req, &g); err != nil {
+		return nil, err
+	}
+	issue := g.toIssue()
+	return &issue, nil
+}
+
+// parseGitLabWebhook 校验 X-Gitlab-Token 并解析 Issue Hook 事件
+func parseGitLabWebhook(secret string, header func(string) string, body []byte) (*Issue, error) {
+	if secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(header("X-Gitlab-Token"))) != 1 {
+		return nil, ErrInvalidWebhookSignature
+	}
+	if header("X-Gitlab-Event") != "Issue Hook" {
+		return nil, ErrIgnoredEvent
+	}
+
+	var payload struct {
+		ObjectAttributes gitlabIssue `json:"object_attributes"`
+		Assignees        []struct {
+			Username string `json:"username"`
+		} `json:"assignees"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	issue := payload.ObjectAttributes.toIssue()
+	for _, a := range payload.Assignees {
+		issue.Assignees = append(issue.Assignees, a.Username)
+	}
+	return &issue, nil
+}
@@ -0,0 +1,131 @@
+package issuetracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// ProviderGitHub GitHub（含 GitHub Enterprise）
+	ProviderGitHub = "github"
+	// ProviderGitLab GitLab（含自建实例）
+	ProviderGitLab = "gitlab"
+
+	perPage = 100
+)
+
+var (
+	// ErrUnsupportedProvider 不支持的平台
+	ErrUnsupportedProvider = errors.New("unsupported issue tracker provider")
+	// ErrInvalidWebhookSignature webhook 签名或令牌不匹配
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrIgnoredEvent 与 issue 无关的 webhook 事件
+	ErrIgnoredEvent = errors.New("ignored webhook event")
+)
+
+// Issue 平台无关的 issue 表示
+type Issue struct {
+	Number    int64 // GitHub number / GitLab iid
+	Title     string
+	Body      string
+	URL       string
+	Closed    bool
+	Assignees []string
+	UpdatedAt time.Time
+}
+
+// AssignedTo 判断 issue 是否指派给了 username（username 为空视为匹配）
+func (i *Issue) AssignedTo(username string) bool {
+	if username == "" {
+		return true
+	}
+	for _, a := range i.Assignees {
+		if strings.EqualFold(a, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client issue 平台 API 客户端
+type Client interface {
+	// ListAssignedIssues 列出指派给配置用户、且在 since 之后更新过的 issue（含已关闭）
+	ListAssignedIssues(ctx context.Context, since time.Time) ([]Issue, error)
+	// SetState 打开或关闭 issue
+	SetState(ctx context.Context, number int64, closed bool) (*Issue, error)
+}
+
+// Config 客户端配置；BaseURL 为空时使用官方 API，指向自建实例或本地 mock 时主机须列入 AllowedHosts
+type Config struct {
+	Provider     string
+	BaseURL      string
+	Repo         string // GitHub: owner/name；GitLab: group/project 路径或数字 ID
+	Token        string
+	Assignee     string
+	AllowedHosts []string     // 可信的自建实例主机名，见 CheckBaseURL
+	HTTPClient   *http.Client // 为空时使用只连接公网地址（白名单主机除外）的客户端
+}
+
+// NewClient 按平台创建客户端；BaseURL 未通过 CheckBaseURL 时返回 ErrBaseURLNotAllowed
+func NewClient(cfg Config) (Client, error) {
+	if err := CheckBaseURL(cfg.Provider, cfg.BaseURL, cfg.AllowedHosts); err != nil {
+		return nil, err
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = newHTTPClient(cfg.AllowedHosts)
+	}
+	switch cfg.Provider {
+	case ProviderGitHub:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "https://api.github.com"
+		}
+		cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+		return &githubClient{cfg: cfg}, nil
+	case ProviderGitLab:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "https://gitlab.com/api/v4"
+		}
+		cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+		return &gitlabClient{cfg: cfg}, nil
+	default:
+		return nil, ErrUnsupportedProvider
+	}
+}
+
+// ParseWebhook 校验并解析 webhook，返回其中的 issue；非 issue 事件返回 ErrIgnoredEvent
+func ParseWebhook(provider, secret string, header func(key string) string, body []byte) (*Issue, error) {
+	switch provider {
+	case ProviderGitHub:
+		return parseGitHubWebhook(secret, header, body)
+	case ProviderGitLab:
+		return parseGitLabWebhook(secret, header, body)
+	default:
+		return nil, ErrUnsupportedProvider
+	}
+}
+
+// doJSON 发送请求并把 2xx 响应解码到 out；错误中不包含响应内容（会记入连接的 last_error 并返回给用户）
+func doJSON(ctx context.Context, client *http.Client, req *http.Request, out interface{}) error {
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: invalid JSON response", req.Method, req.URL.Path)
+	}
+	return nil
+}
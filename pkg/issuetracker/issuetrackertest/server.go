@@ -0,0 +1,332 @@
+// Package issuetrackertest 提供 GitHub / GitLab issue API 的本地 mock，用于测试同步逻辑：
+//
+//	srv := issuetrackertest.NewServer(issuetracker.ProviderGitHub, "acme/app")
+//	defer srv.Close()
+//	srv.Put(issuetracker.Issue{Number: 1, Title: "Bug", Assignees: []string{"alice"}})
+//	client, _ := issuetracker.NewClient(srv.Config("alice"))
+//
+// 只实现客户端用到的接口：列出 issue（分页、指派人与更新时间过滤）与打开 / 关闭 issue，
+// 另可生成带签名的 webhook 请求。
+package issuetrackertest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"memogo/pkg/issuetracker"
+)
+
+// Server 单个仓库的 issue API mock
+type Server struct {
+	*httptest.Server
+	Provider string
+	Repo     string
+
+	mu     sync.Mutex
+	issues map[int64]issuetracker.Issue
+	prs    map[int64]bool // GitHub 的 issues 接口也会返回 PR
+	writes int
+}
+
+// NewServer 启动 mock；provider 为 issuetracker.ProviderGitHub 或 ProviderGitLab
+func NewServer(provider, repo string) *Server {
+	s := &Server{
+		Provider: provider,
+		Repo:     repo,
+		issues:   make(map[int64]issuetracker.Issue),
+		prs:      make(map[int64]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Config 指向本 mock 的客户端配置（mock 监听回环地址，列入白名单）
+func (s *Server) Config(assignee string) issuetracker.Config {
+	return issuetracker.Config{
+		Provider:     s.Provider,
+		BaseURL:      s.URL,
+		Repo:         s.Repo,
+		Token:        "test-token",
+		Assignee:     assignee,
+		AllowedHosts: []string{s.Host()},
+	}
+}
+
+// Host mock 的主机名，用于 ISSUE_TRACKER_ALLOWED_HOSTS
+func (s *Server) Host() string {
+	u, _ := url.Parse(s.URL)
+	return u.Hostname()
+}
+
+// Put 新增或替换 issue；UpdatedAt 为零值时取当前时间，URL 为空时自动生成
+func (s *Server) Put(issue issuetracker.Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue.UpdatedAt.IsZero() {
+		issue.UpdatedAt = time.Now()
+	}
+	if issue.URL == "" {
+		issue.URL = fmt.Sprintf("https://example.test/%s/issues/%d", s.Repo, issue.Number)
+	}
+	s.issues[issue.Number] = issue
+}
+
+// PutPullRequest 新增一个 PR（GitHub 列表中带 pull_request 字段，客户端应跳过）
+func (s *Server) PutPullRequest(issue issuetracker.Issue) {
+	s.Put(issue)
+	s.mu.Lock()
+	s.prs[issue.Number] = true
+	s.mu.Unlock()
+}
+
+// SetClosed 在远程打开或关闭 issue，并刷新更新时间
+func (s *Server) SetClosed(number int64, closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.issues[number]
+	issue.Closed = closed
+	issue.UpdatedAt = time.Now()
+	s.issues[number] = issue
+}
+
+// Issue 返回 issue 的当前状态
+func (s *Server) Issue(number int64) (issuetracker.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[number]
+	return issue, ok
+}
+
+// Writes 客户端修改 issue 状态的次数
+func (s *Server) Writes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writes
+}
+
+// Webhook 生成该 issue 当前状态的 webhook 请求头与请求体；secret 为空时不签名
+func (s *Server) Webhook(number int64, secret string) (header func(string) string, body []byte) {
+	s.mu.Lock()
+	issue := s.issues[number]
+	s.mu.Unlock()
+
+	headers := map[string]string{}
+	if s.Provider == issuetracker.ProviderGitLab {
+		body, _ = json.Marshal(map[string]interface{}{
+			"object_kind":       "issue",
+			"object_attributes": gitlabIssue(issue, true),
+			"assignees":         usernames(issue.Assignees, "username"),
+		})
+		headers["X-Gitlab-Event"] = "Issue Hook"
+		if secret != "" {
+			headers["X-Gitlab-Token"] = secret
+		}
+	} else {
+		action := "reopened"
+		if issue.Closed {
+			action = "closed"
+		}
+		body, _ = json.Marshal(map[string]interface{}{"action": action, "issue": githubIssue(issue, false)})
+		headers["X-GitHub-Event"] = "issues"
+		if secret != "" {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(body)
+			headers["X-Hub-Signature-256"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		}
+	}
+	return func(key string) string { return headers[key] }, body
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	prefix := "/repos/" + s.Repo + "/issues"
+	if s.Provider == issuetracker.ProviderGitLab {
+		prefix = "/projects/" + url.PathEscape(s.Repo) + "/issues"
+	}
+	path := r.URL.EscapedPath()
+	switch {
+	case path == prefix && r.Method == http.MethodGet:
+		s.list(w, r)
+	case strings.HasPrefix(path, prefix+"/") && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+		number, err := strconv.ParseInt(strings.TrimPrefix(path, prefix+"/"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		s.setState(w, r, number)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Provider == issuetracker.ProviderGitLab {
+		return r.Header.Get("PRIVATE-TOKEN") != ""
+	}
+	return strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	assignee, sinceParam := q.Get("assignee"), q.Get("since")
+	if s.Provider == issuetracker.ProviderGitLab {
+		assignee, sinceParam = q.Get("assignee_username"), q.Get("updated_after")
+	}
+	var since time.Time
+	if sinceParam != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, sinceParam); err != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	page, perPage = max(page, 1), max(perPage, 1)
+
+	s.mu.Lock()
+	var matched []issuetracker.Issue
+	var isPR []bool
+	for _, issue := range s.issues {
+		if assignee != "" && !issue.AssignedTo(assignee) {
+			continue
+		}
+		if !since.IsZero() && issue.UpdatedAt.Before(since) {
+			continue
+		}
+		matched = append(matched, issue)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Number < matched[j].Number })
+	for _, issue := range matched {
+		isPR = append(isPR, s.prs[issue.Number])
+	}
+	s.mu.Unlock()
+
+	start := min((page-1)*perPage, len(matched))
+	end := min(start+perPage, len(matched))
+	out := make([]interface{}, 0, end-start)
+	for i := start; i < end; i++ {
+		if s.Provider == issuetracker.ProviderGitLab {
+			out = append(out, gitlabIssue(matched[i], false))
+		} else {
+			out = append(out, githubIssue(matched[i], isPR[i]))
+		}
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) setState(w http.ResponseWriter, r *http.Request, number int64) {
+	if !s.authorized(r) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+	var closed bool
+	if s.Provider == issuetracker.ProviderGitLab {
+		switch r.URL.Query().Get("state_event") {
+		case "close":
+			closed = true
+		case "reopen":
+		default:
+			http.Error(w, "invalid state_event", http.StatusBadRequest)
+			return
+		}
+	} else {
+		var body struct {
+			State string `json:"state"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body.State != "open" && body.State != "closed") {
+			http.Error(w, "invalid state", http.StatusUnprocessableEntity)
+			return
+		}
+		closed = body.State == "closed"
+	}
+
+	s.mu.Lock()
+	issue, ok := s.issues[number]
+	if ok {
+		issue.Closed = closed
+		issue.UpdatedAt = time.Now()
+		s.issues[number] = issue
+		s.writes++
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if s.Provider == issuetracker.ProviderGitLab {
+		writeJSON(w, gitlabIssue(issue, false))
+	} else {
+		writeJSON(w, githubIssue(issue, false))
+	}
+}
+
+func githubIssue(issue issuetracker.Issue, pr bool) map[string]interface{} {
+	state := "open"
+	if issue.Closed {
+		state = "closed"
+	}
+	out := map[string]interface{}{
+		"number":     issue.Number,
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"html_url":   issue.URL,
+		"state":      state,
+		"updated_at": issue.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		"assignees":  usernames(issue.Assignees, "login"),
+	}
+	if pr {
+		out["pull_request"] = map[string]string{"url": issue.URL}
+	}
+	return out
+}
+
+// gitlabIssue REST 与 webhook 的字段名、时间格式不同
+func gitlabIssue(issue issuetracker.Issue, webhook bool) map[string]interface{} {
+	state := "opened"
+	if issue.Closed {
+		state = "closed"
+	}
+	out := map[string]interface{}{
+		"iid":         issue.Number,
+		"title":       issue.Title,
+		"description": issue.Body,
+		"state":       state,
+	}
+	if webhook {
+		out["url"] = issue.URL
+		out["updated_at"] = issue.UpdatedAt.UTC().Format("2006-01-02 15:04:05 MST")
+	} else {
+		out["web_url"] = issue.URL
+		out["updated_at"] = issue.UpdatedAt.UTC().Format(time.RFC3339Nano)
+		out["assignees"] = usernames(issue.Assignees, "username")
+	}
+	return out
+}
+
+func usernames(names []string, key string) []map[string]string {
+	out := make([]map[string]string, len(names))
+	for i, name := range names {
+		out[i] = map[string]string{key: name}
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,181 @@
+// Package jsonpath 实现 JSONPath 的一个小子集，用于从任意 JSON 中取值：
+//
+//	$.alert.name
+//	$.alerts[0].labels.severity
+//	$['key with space'].value
+//
+// 以及模板插值："[{{$.alert.severity}}] {{$.alert.name}}"。
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath 路径语法错误
+	ErrInvalidPath = errors.New("invalid json path")
+
+	placeholderRe = regexp.MustCompile(`\{\{\s*(\$[^}]*?)\s*\}\}`)
+)
+
+// Decode 解析 JSON，数字保留为 json.Number 以免大整数丢失精度
+func Decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Lookup 按路径取值；路径合法但值不存在时返回 found=false
+func Lookup(doc interface{}, path string) (value interface{}, found bool, err error) {
+	steps, err := parse(path)
+	if err != nil {
+		return nil, false, err
+	}
+	cur := doc
+	for _, step := range steps {
+		switch s := step.(type) {
+		case string:
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if cur, ok = obj[s]; !ok {
+				return nil, false, nil
+			}
+		case int:
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if s < 0 {
+				s += len(arr)
+			}
+			if s < 0 || s >= len(arr) {
+				return nil, false, nil
+			}
+			cur = arr[s]
+		}
+	}
+	return cur, true, nil
+}
+
+// Validate 检查模板中的路径语法
+func Validate(tpl string) error {
+	if isPurePath(tpl) {
+		_, err := parse(tpl)
+		return err
+	}
+	for _, m := range placeholderRe.FindAllStringSubmatch(tpl, -1) {
+		if _, err := parse(m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render 渲染模板：整体是一个路径（如 "$.alert.name"）时直接取值，
+// 否则替换其中的 {{路径}} 占位符；不存在的值渲染为空字符串
+func Render(tpl string, doc interface{}) (string, error) {
+	if isPurePath(tpl) {
+		v, _, err := Lookup(doc, tpl)
+		if err != nil {
+			return "", err
+		}
+		return Stringify(v), nil
+	}
+
+	var renderErr error
+	out := placeholderRe.ReplaceAllStringFunc(tpl, func(m string) string {
+		path := placeholderRe.FindStringSubmatch(m)[1]
+		v, _, err := Lookup(doc, path)
+		if err != nil {
+			renderErr = err
+			return ""
+		}
+		return Stringify(v)
+	})
+	return out, renderErr
+}
+
+// Stringify 把 JSON 值转为字符串；对象与数组输出为紧凑 JSON
+func Stringify(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(data)
+	}
+}
+
+func isPurePath(tpl string) bool {
+	tpl = strings.TrimSpace(tpl)
+	return strings.HasPrefix(tpl, "$") && !strings.Contains(tpl, "{{")
+}
+
+// parse 把路径解析为步骤列表：string 为对象键，int 为数组下标
+func parse(path string) ([]interface{}, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%w: %q must start with $", ErrInvalidPath, path)
+	}
+
+	var steps []interface{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("%w: %q has an empty key", ErrInvalidPath, path)
+			}
+			steps = append(steps, key)
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("%w: %q has an unclosed [", ErrInvalidPath, path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, inner[1:len(inner)-1])
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q has a non-numeric index", ErrInvalidPath, path)
+			}
+			steps = append(steps, idx)
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidPath, rest[0], path)
+		}
+	}
+	return steps, nil
+}
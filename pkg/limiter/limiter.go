@@ -0,0 +1,184 @@
+// Package limiter 自适应并发限制：按观测到的请求延迟动态调整允许同时处理的请求数，
+// 超出上限的请求立即拒绝（load shedding），而不是排队等到超时。
+//
+// 上限的调整算法可选：
+//
+//	Gradient  比较短期与长期平均延迟，延迟上升时按比例收缩、平稳时缓慢增长（参考 Netflix gradient2）
+//	AIMD      请求失败或延迟超过阈值时乘性减小，否则加性增长（与 TCP 拥塞控制相同）
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Sample 一次请求的观测结果
+type Sample struct {
+	RTT      time.Duration // 处理耗时
+	Inflight int           // 请求开始时（含自身）的并发数
+	Dropped  bool          // 请求失败（如 5xx、panic）
+}
+
+// Algorithm 并发上限的调整算法；由 Limiter 在持锁时调用，实现无需自行加锁
+type Algorithm interface {
+	// Update 根据一次观测返回新的上限（Limiter 负责按最小、最大值截断）
+	Update(limit float64, s Sample) float64
+}
+
+// Options 上限的初始值与取值范围
+type Options struct {
+	Initial int
+	Min     int
+	Max     int
+}
+
+// Stats 当前状态
+type Stats struct {
+	Limit    int    `json:"limit"`
+	Inflight int    `json:"inflight"`
+	Accepted uint64 `json:"accepted"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// Limiter 一个并发预算
+type Limiter struct {
+	mu       sync.Mutex
+	algo     Algorithm
+	min, max float64
+	limit    float64
+	inflight int
+	accepted uint64
+	rejected uint64
+}
+
+// New 创建限制器；Min 至少为 1，Initial 截断到 [Min, Max]
+func New(algo Algorithm, opts Options) *Limiter {
+	l := &Limiter{algo: algo, min: float64(max(opts.Min, 1))}
+	l.max = max(float64(opts.Max), l.min)
+	l.limit = l.clamp(float64(opts.Initial))
+	return l
+}
+
+// Token 占用的一个并发名额
+type Token struct {
+	l        *Limiter
+	start    time.Time
+	inflight int
+	once     sync.Once
+}
+
+// Acquire 尝试占用一个名额；当前并发已达上限时返回 false
+func (l *Limiter) Acquire() (*Token, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight >= int(l.limit) {
+		l.rejected++
+		return nil, false
+	}
+	l.inflight++
+	l.accepted++
+	return &Token{l: l, start: time.Now(), inflight: l.inflight}, true
+}
+
+// Release 归还名额并上报结果；重复调用无效
+func (t *Token) Release(dropped bool) {
+	t.once.Do(func() {
+		s := Sample{RTT: time.Since(t.start), Inflight: t.inflight, Dropped: dropped}
+		l := t.l
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.inflight--
+		l.limit = l.clamp(l.algo.Update(l.limit, s))
+	})
+}
+
+// Stats 返回当前状态
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{Limit: int(l.limit), Inflight: l.inflight, Accepted: l.accepted, Rejected: l.rejected}
+}
+
+func (l *Limiter) clamp(v float64) float64 {
+	if math.IsNaN(v) {
+		return l.limit
+	}
+	return math.Min(math.Max(v, l.min), l.max)
+}
+
+// Gradient 基于延迟梯度的算法：每 WindowSize 个请求计算一次短期平均延迟，与长期平均延迟之比作为梯度，
+// 新上限 = 上限 × 梯度 + √上限（排队余量），再按 Smoothing 平滑
+type Gradient struct {
+	Tolerance  float64 // 短期延迟达到长期的多少倍才开始收缩，默认 1.5
+	Smoothing  float64 // 每次调整的平滑系数，默认 0.2
+	WindowSize int     // 每个短期窗口的请求数，默认 20
+	LongWindow int     // 长期平均延迟的窗口（以短期窗口计），默认 100
+
+	sum         time.Duration
+	count       int
+	maxInflight int
+	long        float64 // 长期平均延迟（纳秒）
+}
+
+// NewGradient 创建使用默认参数的梯度算法
+func NewGradient() *Gradient {
+	return &Gradient{Tolerance: 1.5, Smoothing: 0.2, WindowSize: 20, LongWindow: 100}
+}
+
+// Update 实现 Algorithm
+func (g *Gradient) Update(limit float64, s Sample) float64 {
+	g.sum += s.RTT
+	g.count++
+	g.maxInflight = max(g.maxInflight, s.Inflight)
+	if g.count < g.WindowSize {
+		return limit
+	}
+	short := float64(g.sum) / float64(g.count)
+	inflight := g.maxInflight
+	g.sum, g.count, g.maxInflight = 0, 0, 0
+	if short <= 0 {
+		return limit
+	}
+
+	if g.long == 0 {
+		g.long = short
+	} else {
+		g.long += (short - g.long) / float64(g.LongWindow)
+	}
+	// 负载回落后长期延迟明显高于短期时加快向短期靠拢，避免长时间高估可接受的延迟
+	if g.long > 2*short {
+		g.long = (g.long + short) / 2
+	}
+	// 并发远未用满时延迟不反映容量，不调整上限，避免空闲时上限无限增长
+	if float64(inflight) < limit/2 {
+		return limit
+	}
+
+	gradient := math.Max(0.5, math.Min(1, g.Tolerance*g.long/short))
+	next := limit*gradient + math.Sqrt(limit)
+	return limit*(1-g.Smoothing) + next*g.Smoothing
+}
+
+// AIMD 加性增、乘性减：请求失败或延迟超过 Latency 时上限乘以 Backoff，否则在并发用到一半以上时每个请求加 1/上限
+// （约每一轮满并发加 1）
+type AIMD struct {
+	Latency time.Duration // 视为过载的延迟阈值
+	Backoff float64       // 过载时的收缩比例，默认 0.9
+}
+
+// NewAIMD 创建以 latency 为过载阈值的 AIMD 算法
+func NewAIMD(latency time.Duration) *AIMD {
+	return &AIMD{Latency: latency, Backoff: 0.9}
+}
+
+// Update 实现 Algorithm
+func (a *AIMD) Update(limit float64, s Sample) float64 {
+	if s.Dropped || s.RTT > a.Latency {
+		return limit * a.Backoff
+	}
+	if float64(s.Inflight) >= limit/2 {
+		return limit + 1/limit
+	}
+	return limit
+}
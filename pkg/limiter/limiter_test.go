@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+// feed 按窗口喂入相同的观测，返回最终上限
+func feed(g *Gradient, limit float64, windows int, rtt time.Duration, inflight func(limit float64) int) float64 {
+	for w := 0; w < windows; w++ {
+		for i := 0; i < g.WindowSize; i++ {
+			limit = g.Update(limit, Sample{RTT: rtt, Inflight: inflight(limit)})
+		}
+	}
+	return limit
+}
+
+func saturated(limit float64) int { return int(limit) }
+
+func TestGradientUpdate(t *testing.T) {
+	limit := 20.0
+
+	// 窗口未满时不调整
+	g := NewGradient()
+	for i := 0; i < g.WindowSize-1; i++ {
+		if got := g.Update(limit, Sample{RTT: 10 * time.Millisecond, Inflight: 20}); got != limit {
+			t.Fatalf("update #%d inside a window = %v, want %v", i+1, got, limit)
+		}
+	}
+
+	// 延迟平稳且并发用满：上限增长
+	g = NewGradient()
+	grown := feed(g, limit, 10, 10*time.Millisecond, saturated)
+	if grown <= limit {
+		t.Fatalf("limit under steady latency = %v, want growth from %v", grown, limit)
+	}
+
+	// 延迟上升：上限收缩，且每个窗口最多收缩到一半附近
+	shrunk := feed(g, grown, 1, 100*time.Millisecond, saturated)
+	if shrunk >= grown {
+		t.Fatalf("limit after latency rose = %v, want less than %v", shrunk, grown)
+	}
+	if shrunk < grown*0.5 {
+		t.Errorf("limit after one slow window = %v, shrank more than the 0.5 gradient floor allows from %v", shrunk, grown)
+	}
+	if more := feed(g, shrunk, 5, 100*time.Millisecond, saturated); more >= shrunk {
+		t.Errorf("limit while latency stays high = %v, want it to keep shrinking from %v", more, shrunk)
+	}
+
+	// 并发远未用满时不调整，避免空闲时上限无限增长
+	idle := func(limit float64) int { return 1 }
+	if got := feed(NewGradient(), 50, 20, time.Millisecond, idle); got != 50 {
+		t.Errorf("limit while idle = %v, want unchanged 50", got)
+	}
+}
+
+func TestAIMDUpdate(t *testing.T) {
+	a := NewAIMD(100 * time.Millisecond)
+	tests := []struct {
+		name   string
+		sample Sample
+		want   float64
+	}{
+		{"dropped", Sample{RTT: time.Millisecond, Inflight: 10, Dropped: true}, 9},
+		{"too slow", Sample{RTT: 200 * time.Millisecond, Inflight: 10}, 9},
+		{"saturated", Sample{RTT: time.Millisecond, Inflight: 5}, 10.1},
+		{"idle", Sample{RTT: time.Millisecond, Inflight: 4}, 10},
+	}
+	for _, tt := range tests {
+		if got := a.Update(10, tt.sample); got != tt.want {
+			t.Errorf("%s: Update(10) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fixed 总是返回同一个上限的算法
+type fixed float64
+
+func (f fixed) Update(float64, Sample) float64 { return float64(f) }
+
+func TestLimiterAcquireRelease(t *testing.T) {
+	l := New(fixed(2), Options{Initial: 2, Min: 1, Max: 10})
+	a, ok := l.Acquire()
+	if !ok {
+		t.Fatal("first acquire rejected")
+	}
+	b, ok := l.Acquire()
+	if !ok {
+		t.Fatal("second acquire rejected")
+	}
+	if _, ok := l.Acquire(); ok {
+		t.Fatal("acquire over the limit accepted")
+	}
+
+	// 重复归还只生效一次
+	a.Release(false)
+	a.Release(false)
+	if got := l.Stats(); got != (Stats{Limit: 2, Inflight: 1, Accepted: 2, Rejected: 1}) {
+		t.Fatalf("stats after releasing one token twice = %+v", got)
+	}
+	b.Release(false)
+	if got := l.Stats().Inflight; got != 0 {
+		t.Errorf("inflight after releasing all tokens = %d", got)
+	}
+}
+
+func TestLimiterClamp(t *testing.T) {
+	tests := []struct {
+		name string
+		algo fixed
+		opts Options
+		init int
+		want int
+	}{
+		{"initial above max", 5, Options{Initial: 50, Min: 1, Max: 10}, 10, 5},
+		{"update below min", 0, Options{Initial: 4, Min: 2, Max: 10}, 4, 2},
+		{"update above max", 100, Options{Initial: 4, Min: 1, Max: 10}, 4, 10},
+		{"min at least one", 0, Options{Initial: 0, Min: 0, Max: 0}, 1, 1},
+	}
+	for _, tt := range tests {
+		l := New(tt.algo, tt.opts)
+		if got := l.Stats().Limit; got != tt.init {
+			t.Errorf("%s: initial limit = %d, want %d", tt.name, got, tt.init)
+		}
+		token, ok := l.Acquire()
+		if !ok {
+			t.Fatalf("%s: acquire rejected", tt.name)
+		}
+		token.Release(false)
+		if got := l.Stats().Limit; got != tt.want {
+			t.Errorf("%s: limit after update = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package lunar 封装农历与公历的换算，用于"每年八月十五"这类农历重复规则。
+//
+// 闰月规则：指定闰月的日期在当年没有该闰月时，落在同名的普通月；
+// 日期超出当月天数（如小月的三十）时取当月最后一天，与民间"除夕"等习惯一致。
+package lunar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/6tail/lunar-go/LunarUtil"
+	"github.com/6tail/lunar-go/calendar"
+)
+
+var (
+	// ErrInvalidDate 农历日期格式错误或超出范围
+	ErrInvalidDate = errors.New("invalid lunar date")
+)
+
+// 支持换算的年份范围（覆盖 lunar-go 的可靠区间）
+const (
+	MinYear = 1901
+	MaxYear = 2099
+)
+
+// Date 农历日期（Year 为 0 时表示每年重复的月日）
+type Date struct {
+	Year  int  `json:"year,omitempty"`
+	Month int  `json:"month"`
+	Day   int  `json:"day"`
+	Leap  bool `json:"leap"`
+}
+
+// String 中文月日，如 "八月十五"、"闰四月初一"
+func (d Date) String() string {
+	if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 30 {
+		return ""
+	}
+	s := LunarUtil.MONTH[d.Month] + "月" + LunarUtil.DAY[d.Day]
+	if d.Leap {
+		s = "闰" + s
+	}
+	return s
+}
+
+// FromSolar 公历日期（取 t 所在时区的年月日）换算为农历
+func FromSolar(t time.Time) Date {
+	l := calendar.NewLunarFromSolar(calendar.NewSolarFromYmd(t.Year(), int(t.Month()), t.Day()))
+	d := Date{Year: l.GetYear(), Month: l.GetMonth(), Day: l.GetDay()}
+	if d.Month < 0 {
+		d.Month, d.Leap = -d.Month, true
+	}
+	return d
+}
+
+// Resolve 把"每年某月某日"落到指定农历年，返回对应的公历日期（位于 loc 的零点）
+// 当年没有指定闰月时使用普通月，日期超出当月天数时取月末
+func Resolve(year, month, day int, leap bool, loc *time.Location) (time.Time, error) {
+	if year < MinYear || year > MaxYear || month < 1 || month > 12 || day < 1 || day > 30 {
+		return time.Time{}, ErrInvalidDate
+	}
+	ly := calendar.NewLunarYear(year)
+	m := ly.GetMonth(month)
+	if leap && ly.GetLeapMonth() == month {
+		m = ly.GetMonth(-month)
+	}
+	if m == nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	if n := m.GetDayCount(); day > n {
+		day = n
+	}
+	solar := calendar.NewSolarFromJulianDay(m.GetFirstJulianDay() + float64(day-1))
+	return time.Date(solar.GetYear(), time.Month(solar.GetMonth()), solar.GetDay(), 0, 0, 0, 0, loc), nil
+}
+
+// Next 返回晚于 after 的下一次农历月日对应的公历时间，时刻（时分秒）取自 after；
+// inclusive 为 true 时 after 当天本身也可作为结果
+func Next(month, day int, leap bool, after time.Time, inclusive bool) (time.Time, error) {
+	loc := after.Location()
+	start := FromSolar(after).Year - 1
+	for y := start; y <= start+3; y++ {
+		date, err := Resolve(y, month, day, leap, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := time.Date(date.Year(), date.Month(), date.Day(),
+			after.Hour(), after.Minute(), after.Second(), 0, loc)
+		if t.After(after) || (inclusive && t.Equal(after)) {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidDate
+}
+
+// Parse 解析农历月日：中文 "八月十五"、"闰四月初一"、"腊月廿三"，或数字 "8-15"、"L4-1"（L 表示闰月）
+func Parse(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, ErrInvalidDate
+	}
+	var d Date
+	if strings.HasPrefix(s, "L") || strings.HasPrefix(s, "l") || (s[0] >= '0' && s[0] <= '9') {
+		if s[0] == 'L' || s[0] == 'l' {
+			d.Leap, s = true, s[1:]
+		}
+		parts := strings.Split(s, "-")
+		if len(parts) != 2 {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+		}
+		var err1, err2 error
+		d.Month, err1 = strconv.Atoi(parts[0])
+		d.Day, err2 = strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+		}
+	} else {
+		if strings.HasPrefix(s, "闰") {
+			d.Leap, s = true, strings.TrimPrefix(s, "闰")
+		}
+		idx := strings.Index(s, "月")
+		if idx <= 0 {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+		}
+		d.Month = parseChineseMonth(s[:idx])
+		d.Day = parseChineseDay(s[idx+len("月"):])
+	}
+	if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 30 {
+		return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+	return d, nil
+}
+
+func parseChineseMonth(s string) int {
+	switch s {
+	case "正", "一":
+		return 1
+	case "冬", "十一":
+		return 11
+	case "腊", "十二":
+		return 12
+	}
+	for i, name := range LunarUtil.MONTH {
+		if i > 0 && name == s {
+			return i
+		}
+	}
+	return 0
+}
+
+func parseChineseDay(s string) int {
+	s = strings.NewReplacer("卅", "三十", "念", "廿").Replace(s)
+	for i, name := range LunarUtil.DAY {
+		if i > 0 && name == s {
+			return i
+		}
+	}
+	switch s {
+	case "十":
+		return 10
+	case "廿十":
+		return 20
+	}
+	return 0
+}
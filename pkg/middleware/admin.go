@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// AdminMiddleware 要求当前用户为管理员（需放在 JWT 中间件之后）
+func AdminMiddleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+			return
+		}
+		user, err := repository.NewUserRepository(db.DB).GetByID(userID)
+		if err != nil || user.Role != model.RoleAdmin {
+			c.AbortWithStatusJSON(consts.StatusForbidden, utils.H{"status": 403, "msg": "Forbidden: admin only", "data": nil})
+			return
+		}
+		c.Next(ctx)
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
+	"memogo/biz/dal/repository"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// RecordAuthEvent 记录一次认证事件（注册、登录、刷新令牌及其失败）；写入失败只记日志，不影响请求
+func RecordAuthEvent(c *app.RequestContext, userID uint, username, event string) {
+	if db.DB == nil {
+		return
+	}
+	record := &model.AuthEvent{
+		UserID:    userID,
+		Username:  clip(username, 50),
+		Event:     event,
+		IP:        clip(c.ClientIP(), 64),
+		UserAgent: clip(string(c.UserAgent()), 255),
+	}
+	if err := repository.NewAuthEventRepository(db.DB).Record(record); err != nil {
+		log.Printf("Record auth event %s for user %d failed: %v", event, userID, err)
+	}
+}
+
+func clip(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"memogo/pkg/limiter"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// 并发预算：读、写、认证分开限制，写入或登录（bcrypt 较耗 CPU）拥塞时不拖垮读取
+const (
+	BudgetRead  = "read"
+	BudgetWrite = "write"
+	BudgetAuth  = "auth"
+)
+
+// budgetDefaults 各预算的初始上限与最大上限（最大上限可用 CONCURRENCY_<READ|WRITE|AUTH>_MAX 覆盖）
+var budgetDefaults = map[string]limiter.Options{
+	BudgetRead:  {Initial: 50, Min: 2, Max: 400},
+	BudgetWrite: {Initial: 20, Min: 2, Max: 200},
+	BudgetAuth:  {Initial: 10, Min: 2, Max: 50},
+}
+
+// exemptPaths 不受并发限制的路径前缀：健康检查与限制状态在过载时也要能及时响应
+var exemptPaths = []string{"/ping", "/docs/", "/v1/admin/concurrency"}
+
+// concurrencyBudgets 当前生效的预算，未启用时为 nil
+var concurrencyBudgets map[string]*limiter.Limiter
+
+// ConcurrencyLimit 自适应并发限制中间件（全局注册）：按读、写、认证分别限制同时处理的请求数，
+// 上限随观测到的延迟自动调整，超出时立即返回 503 与 Retry-After，不让请求排队等到超时。
+// 算法由 CONCURRENCY_LIMITER 选择：gradient（默认）| aimd | off
+func ConcurrencyLimit() app.HandlerFunc {
+	algorithm := os.Getenv("CONCURRENCY_LIMITER")
+	if algorithm == "" {
+		algorithm = "gradient"
+	}
+	if algorithm == "off" {
+		return func(ctx context.Context, c *app.RequestContext) { c.Next(ctx) }
+	}
+	newAlgorithm := func() limiter.Algorithm { return limiter.NewGradient() }
+	switch algorithm {
+	case "gradient":
+	case "aimd":
+		latency := getEnvAsDuration("CONCURRENCY_AIMD_LATENCY", 500*time.Millisecond)
+		newAlgorithm = func() limiter.Algorithm { return limiter.NewAIMD(latency) }
+	default:
+		log.Printf("concurrency limiter: unknown CONCURRENCY_LIMITER %q, using gradient", algorithm)
+	}
+	budgets := make(map[string]*limiter.Limiter, len(budgetDefaults))
+	for name, opts := range budgetDefaults {
+		opts.Max = getEnvAsInt("CONCURRENCY_"+strings.ToUpper(name)+"_MAX", opts.Max)
+		opts.Initial = min(opts.Initial, opts.Max)
+		budgets[name] = limiter.New(newAlgorithm(), opts)
+	}
+	concurrencyBudgets = budgets
+
+	retryAfter := strconv.Itoa(int(math.Ceil(getEnvAsDuration("CONCURRENCY_RETRY_AFTER", time.Second).Seconds())))
+	return func(ctx context.Context, c *app.RequestContext) {
+		path := string(c.Path())
+		for _, prefix := range exemptPaths {
+			if strings.HasPrefix(path, prefix) {
+				c.Next(ctx)
+				return
+			}
+		}
+		budget := requestBudget(string(c.Method()), path)
+		token, ok := budgets[budget].Acquire()
+		if !ok {
+			c.Header("Retry-After", retryAfter)
+			c.AbortWithStatusJSON(consts.StatusServiceUnavailable, utils.H{"status": 503, "msg": "Server is busy (" + budget + "), retry later", "data": nil})
+			return
+		}
+		// 处理器 panic 时也要归还名额，并记为失败
+		completed := false
+		defer func() {
+			token.Release(!completed || c.Response.StatusCode() >= consts.StatusInternalServerError)
+		}()
+		c.Next(ctx)
+		completed = true
+	}
+}
+
+// ConcurrencyStats 返回各预算的当前上限、并发数与累计接受 / 拒绝数；未启用时返回 nil
+func ConcurrencyStats() map[string]limiter.Stats {
+	if concurrencyBudgets == nil {
+		return nil
+	}
+	stats := make(map[string]limiter.Stats, len(concurrencyBudgets))
+	for name, l := range concurrencyBudgets {
+		stats[name] = l.Stats()
+	}
+	return stats
+}
+
+// requestBudget 请求所属的预算：登录、注册、刷新令牌为认证，GET / HEAD / OPTIONS 为读，其余为写
+func requestBudget(method, path string) string {
+	if strings.HasPrefix(path, "/v1/auth/") {
+		return BudgetAuth
+	}
+	switch method {
+	case consts.MethodGet, consts.MethodHead, consts.MethodOptions:
+		return BudgetRead
+	}
+	return BudgetWrite
+}
+
+// getEnvAsInt 获取整数类型的环境变量，解析失败或不为正数时返回默认值
+func getEnvAsInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration 获取时长类型的环境变量（如 "500ms"），解析失败返回默认值
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultValue
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	t.Setenv("CONCURRENCY_LIMITER", "gradient")
+	t.Setenv("CONCURRENCY_READ_MAX", "2")
+	t.Setenv("CONCURRENCY_WRITE_MAX", "2")
+	t.Setenv("CONCURRENCY_RETRY_AFTER", "3s")
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	slow := func(ctx context.Context, c *app.RequestContext) {
+		entered <- struct{}{}
+		<-release
+		c.String(consts.StatusOK, "slow")
+	}
+	fast := func(ctx context.Context, c *app.RequestContext) { c.String(consts.StatusOK, "fast") }
+
+	r := route.NewEngine(config.NewOptions(nil))
+	r.Use(ConcurrencyLimit())
+	r.GET("/slow", slow)
+	r.POST("/slow", slow)
+	r.GET("/fast", fast)
+	r.POST("/fast", fast)
+	r.GET("/ping", fast)
+
+	// occupy 用慢请求占满某个预算，返回等待它们结束的函数
+	occupy := func(method string) (wait func()) {
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ut.PerformRequest(r, method, "/slow", nil)
+			}()
+			<-entered
+		}
+		return func() {
+			for i := 0; i < 2; i++ {
+				release <- struct{}{}
+			}
+			wg.Wait()
+		}
+	}
+	expect := func(method, path string, want int) *ut.ResponseRecorder {
+		t.Helper()
+		w := ut.PerformRequest(r, method, path, nil)
+		if got := w.Result().StatusCode(); got != want {
+			t.Fatalf("%s %s = %d, want %d (%s)", method, path, got, want, w.Result().Body())
+		}
+		return w
+	}
+
+	wait := occupy(consts.MethodGet)
+	w := expect(consts.MethodGet, "/fast", consts.StatusServiceUnavailable)
+	if got := string(w.Result().Header.Peek("Retry-After")); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+	if body := string(w.Result().Body()); !strings.Contains(body, `"status":503`) || !strings.Contains(body, "(read)") {
+		t.Errorf("busy response = %s", body)
+	}
+	// 健康检查不受限制；写入有自己的预算
+	expect(consts.MethodGet, "/ping", consts.StatusOK)
+	expect(consts.MethodPost, "/fast", consts.StatusOK)
+	wait()
+	expect(consts.MethodGet, "/fast", consts.StatusOK)
+
+	wait = occupy(consts.MethodPost)
+	w = expect(consts.MethodPost, "/fast", consts.StatusServiceUnavailable)
+	if body := string(w.Result().Body()); !strings.Contains(body, "(write)") {
+		t.Errorf("busy write response = %s", body)
+	}
+	expect(consts.MethodGet, "/fast", consts.StatusOK)
+	wait()
+
+	stats := ConcurrencyStats()
+	for _, budget := range []string{BudgetRead, BudgetWrite} {
+		if s := stats[budget]; s.Inflight != 0 || s.Rejected != 1 {
+			t.Errorf("%s stats = %+v, want nothing in flight and 1 rejected", budget, s)
+		}
+	}
+}
+
+func TestRequestBudget(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{consts.MethodPost, "/v1/auth/login", BudgetAuth},
+		{consts.MethodGet, "/v1/todos", BudgetRead},
+		{consts.MethodHead, "/v1/todos", BudgetRead},
+		{consts.MethodPost, "/v1/todos", BudgetWrite},
+		{consts.MethodDelete, "/v1/todos/1", BudgetWrite},
+	}
+	for _, tt := range tests {
+		if got := requestBudget(tt.method, tt.path); got != tt.want {
+			t.Errorf("requestBudget(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// RequireEntitlement 当前用户的套餐不包含功能时返回 403（需在 JWT 中间件之后）
+func RequireEntitlement(feature string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+			return
+		}
+		err = service.NewEntitlementService(repository.NewPlanRepository(db.DB)).
+			CheckFeature(service.SubjectForUser(userID), feature)
+		if err != nil {
+			status := consts.StatusInternalServerError
+			if errors.Is(err, service.ErrFeatureNotInPlan) {
+				status = consts.StatusForbidden
+			}
+			c.AbortWithStatusJSON(status, utils.H{"status": status, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.Next(ctx)
+	}
+}
+
+// RequireLimit 创建资源前检查套餐上限（再新增一个是否超出），超出时返回 403（需在 JWT 中间件之后）
+func RequireLimit(key string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, utils.H{"status": 401, "msg": "Unauthorized: " + err.Error(), "data": nil})
+			return
+		}
+		err = service.NewEntitlementService(repository.NewPlanRepository(db.DB)).
+			CheckLimit(service.SubjectForUser(userID), key, 1)
+		if err != nil {
+			status := consts.StatusInternalServerError
+			if errors.Is(err, service.ErrPlanLimitReached) {
+				status = consts.StatusForbidden
+			}
+			c.AbortWithStatusJSON(status, utils.H{"status": status, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.Next(ctx)
+	}
+}
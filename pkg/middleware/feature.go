@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+
+	"memogo/biz/dal/db"
+	"memogo/biz/dal/repository"
+	"memogo/biz/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// FeatureEnabled 判断功能开关对当前登录用户是否开启（需在 JWT 中间件之后调用）
+func FeatureEnabled(c *app.RequestContext, key string) bool {
+	userID, err := GetUserID(c)
+	if err != nil {
+		return false
+	}
+	user, err := repository.NewUserRepository(db.DB).GetByID(userID)
+	if err != nil {
+		return false
+	}
+	svc := service.NewFeatureFlagService(repository.NewFeatureFlagRepository(db.DB), repository.NewAuditLogRepository(db.DB))
+	return svc.IsEnabled(key, service.FlagContextForUser(user))
+}
+
+// RequireFeature 功能开关未对当前用户开启时返回 404，灰度中的接口对其他用户不可见
+func RequireFeature(key string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if !FeatureEnabled(c, key) {
+			c.AbortWithStatusJSON(consts.StatusNotFound, utils.H{"status": 404, "msg": "Not found", "data": nil})
+			return
+		}
+		c.Next(ctx)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"memogo/biz/dal/db"
+	"memogo/biz/dal/model"
 	"memogo/biz/dal/repository"
 	"memogo/pkg/hash"
 	"memogo/pkg/jwt"
@@ -60,6 +61,25 @@ func InitJWTMiddleware() (*hertzJWT.HertzJWTMiddleware, error) {
 			}
 		},
 
+		// Authorizator 拒绝已删除（如被合并）账号的令牌，以及签发早于吊销时间的令牌
+		Authorizator: func(data interface{}, ctx context.Context, c *app.RequestContext) bool {
+			v, ok := data.(*JWTClaims)
+			if !ok {
+				return false
+			}
+			user, err := repository.NewUserRepository(db.DB).GetByID(v.UserID)
+			if err != nil {
+				return false
+			}
+			if user.TokensRevokedAt != nil {
+				origIat, _ := hertzJWT.ExtractClaims(ctx, c)["orig_iat"].(float64)
+				if int64(origIat) <= user.TokensRevokedAt.Unix() {
+					return false
+				}
+			}
+			return true
+		},
+
 		// Authenticator 验证用户登录
 		Authenticator: func(ctx context.Context, c *app.RequestContext) (interface{}, error) {
 			var loginReq struct {
@@ -74,13 +94,16 @@ func InitJWTMiddleware() (*hertzJWT.HertzJWTMiddleware, error) {
 			userRepo := repository.NewUserRepository(db.DB)
 			user, err := userRepo.GetByUsername(loginReq.Username)
 			if err != nil {
+				RecordAuthEvent(c, 0, loginReq.Username, model.AuthEventLoginFailed)
 				return nil, hertzJWT.ErrFailedAuthentication
 			}
 
 			// 验证密码
 			if err := hash.VerifyPassword(user.PasswordHash, loginReq.Password); err != nil {
+				RecordAuthEvent(c, user.ID, user.Username, model.AuthEventLoginFailed)
 				return nil, hertzJWT.ErrFailedAuthentication
 			}
+			RecordAuthEvent(c, user.ID, user.Username, model.AuthEventLogin)
 
 			return &JWTClaims{
 				UserID:   user.ID,
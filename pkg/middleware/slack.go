@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"memogo/pkg/slack"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// SlackSignatureMiddleware 校验 Slack 请求签名（SLACK_SIGNING_SECRET）
+// 未配置密钥时拒绝所有请求，避免未签名的请求被当作合法命令执行
+func SlackSignatureMiddleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		secret := os.Getenv("SLACK_SIGNING_SECRET")
+		if secret == "" {
+			c.AbortWithStatusJSON(consts.StatusServiceUnavailable, utils.H{
+				"status": consts.StatusServiceUnavailable,
+				"msg":    "Slack integration is not configured",
+				"data":   nil,
+			})
+			return
+		}
+
+		err := slack.VerifySignature(
+			secret,
+			string(c.Request.Header.Peek("X-Slack-Request-Timestamp")),
+			string(c.Request.Header.Peek("X-Slack-Signature")),
+			c.Request.Body(),
+			time.Now(),
+		)
+		if err != nil {
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, utils.H{
+				"status": consts.StatusUnauthorized,
+				"msg":    "Unauthorized: " + err.Error(),
+				"data":   nil,
+			})
+			return
+		}
+		c.Next(ctx)
+	}
+}
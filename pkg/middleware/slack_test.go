@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"memogo/pkg/slack"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+
+	_ "memogo/pkg/env/testenv"
+)
+
+func TestSlackSignatureMiddleware(t *testing.T) {
+	const secret = "slack-test-secret"
+	body := "team_id=T1&user_id=U1&command=%2Ftodo&text=list"
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	r := route.NewEngine(config.NewOptions(nil))
+	r.POST("/slack", SlackSignatureMiddleware(), func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		want      int
+	}{
+		{"not configured", "", now, slack.Sign(secret, now, []byte(body)), consts.StatusServiceUnavailable},
+		{"signed", secret, now, slack.Sign(secret, now, []byte(body)), consts.StatusOK},
+		{"unsigned", secret, "", "", consts.StatusUnauthorized},
+		{"stale timestamp", secret, stale, slack.Sign(secret, stale, []byte(body)), consts.StatusUnauthorized},
+		{"wrong secret", secret, now, slack.Sign("other", now, []byte(body)), consts.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_SIGNING_SECRET", tt.secret)
+			w := ut.PerformRequest(r, consts.MethodPost, "/slack", &ut.Body{Body: strings.NewReader(body), Len: len(body)},
+				ut.Header{Key: "Content-Type", Value: "application/x-www-form-urlencoded"},
+				ut.Header{Key: "X-Slack-Request-Timestamp", Value: tt.timestamp},
+				ut.Header{Key: "X-Slack-Signature", Value: tt.signature},
+			)
+			if got := w.Result().StatusCode(); got != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", got, tt.want, w.Result().Body())
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package planner 把待办渲染为可打印的 PDF 计划本（纯 Go，基于 gopdf）。
+//
+// PDF 需要嵌入支持中文的 TrueType 字体（.ttf，不支持 .ttc/.otf），
+// 通过 PDF_FONT_PATH 指定，未指定时依次尝试常见系统字体路径。
+// gopdf 只嵌入实际用到的字形子集，生成的文件体积与字体大小无关。
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/signintech/gopdf"
+)
+
+var (
+	// ErrFontUnavailable 找不到可用的中文字体
+	ErrFontUnavailable = errors.New("no CJK TrueType font available, set PDF_FONT_PATH")
+
+	// fontCandidates 常见系统中自带中文字形的 TrueType 字体
+	fontCandidates = []string{
+		"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",
+		"/usr/share/fonts/truetype/noto/NotoSansSC-Regular.ttf",
+		"/usr/share/fonts/noto-cjk/NotoSansSC-Regular.ttf",
+		"/usr/share/fonts/google-droid/DroidSansFallbackFull.ttf",
+		"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+		"C:\\Windows\\Fonts\\simhei.ttf",
+	}
+
+	fontMu    sync.Mutex
+	fontCache = map[string][]byte{}
+)
+
+// 版式（单位 pt，A4 纵向）
+const (
+	pageW      = 595.0
+	pageH      = 842.0
+	margin     = 40.0
+	dueColW    = 120.0
+	boxSize    = 9.0
+	lineHeight = 16.0
+	fontFamily = "cjk"
+)
+
+// Planner 一份计划本
+type Planner struct {
+	Title    string
+	Subtitle string
+	Sections []Section
+}
+
+// Section 一个分组（某一天或某个项目）
+type Section struct {
+	Heading string
+	Note    string // 分组标题右侧的附注，如节假日、农历
+	Items   []Item
+}
+
+// Item 一条待办
+type Item struct {
+	Title string
+	Done  bool
+	Due   *time.Time
+}
+
+// LoadFont 读取字体文件；path 为空时依次尝试常见系统字体。读取结果按路径缓存
+func LoadFont(path string) ([]byte, error) {
+	candidates := fontCandidates
+	if path != "" {
+		candidates = []string{path}
+	}
+
+	fontMu.Lock()
+	defer fontMu.Unlock()
+	for _, p := range candidates {
+		if data, ok := fontCache[p]; ok {
+			return data, nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		fontCache[p] = data
+		return data, nil
+	}
+	return nil, ErrFontUnavailable
+}
+
+// Render 把计划本写为 PDF；loc 为截止时间的显示时区
+func Render(w io.Writer, p Planner, font []byte, loc *time.Location) error {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	pdf.SetInfo(gopdf.PdfInfo{Title: p.Title, Creator: "memogo", CreationDate: time.Now()})
+	if err := pdf.AddTTFFontData(fontFamily, font); err != nil {
+		return fmt.Errorf("load font: %w", err)
+	}
+
+	r := &renderer{pdf: pdf, loc: loc}
+	r.newPage()
+	if err := r.header(p); err != nil {
+		return err
+	}
+	for _, s := range p.Sections {
+		if err := r.section(s); err != nil {
+			return err
+		}
+	}
+	return pdf.Write(w)
+}
+
+type renderer struct {
+	pdf *gopdf.GoPdf
+	loc *time.Location
+	y   float64
+}
+
+func (r *renderer) newPage() {
+	r.pdf.AddPage()
+	r.y = margin
+}
+
+// ensure 剩余空间不足 h 时换页
+func (r *renderer) ensure(h float64) {
+	if r.y+h > pageH-margin {
+		r.newPage()
+	}
+}
+
+func (r *renderer) text(x, size float64, s string) error {
+	if err := r.pdf.SetFont(fontFamily, "", size); err != nil {
+		return err
+	}
+	r.pdf.SetXY(x, r.y)
+	return r.pdf.Cell(nil, s)
+}
+
+func (r *renderer) header(p Planner) error {
+	r.pdf.SetTextColor(0, 0, 0)
+	if err := r.text(margin, 18, p.Title); err != nil {
+		return err
+	}
+	r.y += 26
+	if p.Subtitle != "" {
+		r.pdf.SetTextColor(110, 110, 110)
+		if err := r.text(margin, 10, p.Subtitle); err != nil {
+			return err
+		}
+		r.y += 18
+	}
+	r.y += 6
+	return nil
+}
+
+func (r *renderer) section(s Section) error {
+	// 标题与至少一行内容放在同一页
+	r.ensure(24 + lineHeight)
+	r.pdf.SetTextColor(0, 0, 0)
+	if err := r.text(margin, 13, s.Heading); err != nil {
+		return err
+	}
+	if s.Note != "" {
+		r.pdf.SetTextColor(110, 110, 110)
+		if err := r.pdf.SetFont(fontFamily, "", 10); err != nil {
+			return err
+		}
+		noteW, err := r.pdf.MeasureTextWidth(s.Note)
+		if err != nil {
+			return err
+		}
+		r.pdf.SetXY(pageW-margin-noteW, r.y+3)
+		if err := r.pdf.Cell(nil, s.Note); err != nil {
+			return err
+		}
+	}
+	r.y += 18
+	r.pdf.SetStrokeColor(160, 160, 160)
+	r.pdf.SetLineWidth(0.6)
+	r.pdf.Line(margin, r.y, pageW-margin, r.y)
+	r.y += 6
+
+	if len(s.Items) == 0 {
+		// 空白日期留出书写空间
+		for i := 0; i < 3; i++ {
+			r.ensure(lineHeight)
+			r.checkbox(false)
+			r.pdf.SetStrokeColor(220, 220, 220)
+			r.pdf.Line(margin+boxSize+8, r.y+lineHeight-3, pageW-margin, r.y+lineHeight-3)
+			r.y += lineHeight + 2
+		}
+	}
+	for _, it := range s.Items {
+		if err := r.item(it); err != nil {
+			return err
+		}
+	}
+	r.y += 10
+	return nil
+}
+
+func (r *renderer) checkbox(done bool) {
+	x, y := margin, r.y+3
+	r.pdf.SetStrokeColor(60, 60, 60)
+	r.pdf.SetLineWidth(0.8)
+	r.pdf.RectFromUpperLeftWithStyle(x, y, boxSize, boxSize, "D")
+	if done {
+		r.pdf.Line(x+2, y+4.5, x+4, y+7)
+		r.pdf.Line(x+4, y+7, x+7.5, y+2)
+	}
+}
+
+func (r *renderer) item(it Item) error {
+	if err := r.pdf.SetFont(fontFamily, "", 11); err != nil {
+		return err
+	}
+	titleX := margin + boxSize + 8
+	lines, err := r.pdf.SplitText(it.Title, pageW-margin-dueColW-titleX)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	r.ensure(float64(len(lines)) * lineHeight)
+	r.checkbox(it.Done)
+
+	if it.Due != nil {
+		due := "截止 " + it.Due.In(r.loc).Format("01-02 15:04")
+		switch {
+		case it.Done:
+			r.pdf.SetTextColor(150, 150, 150)
+		case it.Due.Before(time.Now()):
+			r.pdf.SetTextColor(200, 40, 40)
+		default:
+			r.pdf.SetTextColor(90, 90, 90)
+		}
+		if err := r.text(pageW-margin-dueColW+10, 9, due); err != nil {
+			return err
+		}
+		if err := r.pdf.SetFont(fontFamily, "", 11); err != nil {
+			return err
+		}
+	}
+
+	if it.Done {
+		r.pdf.SetTextColor(150, 150, 150)
+	} else {
+		r.pdf.SetTextColor(0, 0, 0)
+	}
+	for i, line := range lines {
+		if i > 0 {
+			r.ensure(lineHeight)
+		}
+		r.pdf.SetXY(titleX, r.y)
+		if err := r.pdf.Cell(nil, line); err != nil {
+			return err
+		}
+		if it.Done {
+			// 已完成加删除线
+			w, err := r.pdf.MeasureTextWidth(line)
+			if err != nil {
+				return err
+			}
+			r.pdf.SetStrokeColor(150, 150, 150)
+			r.pdf.SetLineWidth(0.6)
+			r.pdf.Line(titleX, r.y+7, titleX+w, r.y+7)
+		}
+		r.y += lineHeight
+	}
+	r.y += 2
+	return nil
+}
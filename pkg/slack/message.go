@@ -0,0 +1,90 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// ResponseEphemeral 仅命令发起人可见
+	ResponseEphemeral = "ephemeral"
+	// ResponseInChannel 频道内所有人可见
+	ResponseInChannel = "in_channel"
+
+	// ActionCompleteTodo 完成待办按钮的 action_id
+	ActionCompleteTodo = "complete_todo"
+)
+
+// Message 斜杠命令 / 交互回调的响应消息
+type Message struct {
+	ResponseType    string  `json:"response_type,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
+}
+
+// Block Block Kit 布局块（仅实现用到的 section / actions）
+type Block struct {
+	Type      string    `json:"type"`
+	BlockID   string    `json:"block_id,omitempty"`
+	Text      *Text     `json:"text,omitempty"`
+	Accessory *Element  `json:"accessory,omitempty"`
+	Elements  []Element `json:"elements,omitempty"`
+}
+
+// Text 文本对象
+type Text struct {
+	Type string `json:"type"` // "mrkdwn" | "plain_text"
+	Text string `json:"text"`
+}
+
+// Element 交互元素（按钮）
+type Element struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// SectionBlock 生成一个 mrkdwn 文本块
+func SectionBlock(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}
+}
+
+// CompleteButton 生成“完成”按钮，value 为待办 ID
+func CompleteButton(todoID uint) *Element {
+	return &Element{
+		Type:     "button",
+		Text:     &Text{Type: "plain_text", Text: "Done"},
+		ActionID: ActionCompleteTodo,
+		Value:    fmt.Sprintf("%d", todoID),
+		Style:    "primary",
+	}
+}
+
+// InteractionPayload 交互回调（form 字段 payload）中用到的部分
+type InteractionPayload struct {
+	Type string `json:"type"` // "block_actions"
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// ParseInteractionPayload 解析交互回调的 payload 字段
+func ParseInteractionPayload(raw string) (*InteractionPayload, error) {
+	var p InteractionPayload
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,60 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureVersion Slack 签名版本前缀
+	SignatureVersion = "v0"
+	// MaxClockSkew 允许的请求时间戳偏差，超出视为重放
+	MaxClockSkew = 5 * time.Minute
+)
+
+var (
+	// ErrMissingSignature 缺少签名头
+	ErrMissingSignature = errors.New("missing slack signature")
+	// ErrInvalidTimestamp 时间戳非法或超出允许偏差
+	ErrInvalidTimestamp = errors.New("invalid slack request timestamp")
+	// ErrInvalidSignature 签名不匹配
+	ErrInvalidSignature = errors.New("invalid slack signature")
+)
+
+// Sign 计算请求签名：v0=hex(HMAC-SHA256(secret, "v0:{timestamp}:{body}"))
+// 本地调试时可用它为模拟请求生成 X-Slack-Signature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(SignatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return SignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 校验 X-Slack-Request-Timestamp 与 X-Slack-Signature
+func VerifySignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > MaxClockSkew {
+		return ErrInvalidTimestamp
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package slack
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "8f742231b10e8888abcd99yyyzzz85a5"
+	body := []byte("token=x&team_id=T1&user_id=U1&command=%2Ftodo&text=add+buy+milk")
+	now := time.Unix(1_700_000_000, 0)
+	ts := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{"valid", ts(0), Sign(secret, ts(0), body), body, nil},
+		{"valid within skew", ts(-4 * time.Minute), Sign(secret, ts(-4*time.Minute), body), body, nil},
+		{"valid future within skew", ts(4 * time.Minute), Sign(secret, ts(4*time.Minute), body), body, nil},
+		{"missing timestamp", "", Sign(secret, ts(0), body), body, ErrMissingSignature},
+		{"missing signature", ts(0), "", body, ErrMissingSignature},
+		{"non-numeric timestamp", "yesterday", Sign(secret, "yesterday", body), body, ErrInvalidTimestamp},
+		{"replayed old request", ts(-6 * time.Minute), Sign(secret, ts(-6*time.Minute), body), body, ErrInvalidTimestamp},
+		{"timestamp too far ahead", ts(6 * time.Minute), Sign(secret, ts(6*time.Minute), body), body, ErrInvalidTimestamp},
+		{"wrong secret", ts(0), Sign("other-secret", ts(0), body), body, ErrInvalidSignature},
+		{"tampered body", ts(0), Sign(secret, ts(0), body), []byte("token=x&text=done+1"), ErrInvalidSignature},
+		{"signature for other timestamp", ts(0), Sign(secret, ts(-time.Second), body), body, ErrInvalidSignature},
+		{"wrong version prefix", ts(0), "v1=" + Sign(secret, ts(0), body)[3:], body, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(secret, tt.timestamp, tt.signature, tt.body, now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifySignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignKnownVector(t *testing.T) {
+	// Slack 文档中的示例请求
+	secret := "8f742231b10e8888abcd99yyyzzz85a5"
+	timestamp := "1531420618"
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	want := "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	if got := Sign(secret, timestamp, body); got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+}
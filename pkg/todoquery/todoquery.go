@@ -0,0 +1,204 @@
+// Package todoquery 解析待办筛选表达式，空白分隔的各项之间为“且”：
+//
+//	status:todo tag:work due<2026-11-01     未完成、带 work 标签、11 月前到期
+//	project:OPS -tag:@home "周报"            OPS 项目（含下级）中不带 @home、标题或正文含“周报”
+//	@office due:overdue                     带 @office 情境标签且已逾期
+//
+// 形如 field:value 或 field<value（支持 < <= > >=）的项为字段条件，其余为关键词（双引号包住含空格的短语），
+// 前缀 - 表示取反。本包只做词法与字段校验，字段值的含义由调用方解释。
+package todoquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidQuery 表达式语法错误
+var ErrInvalidQuery = errors.New("invalid query")
+
+// 字段
+const (
+	FieldKeyword = "" // 关键词：标题或正文包含（形如 OPS-17 时也按编号匹配）
+	FieldStatus  = "status"
+	FieldTag     = "tag"
+	FieldProject = "project"
+	FieldDue     = "due"
+	FieldCreated = "created"
+	FieldGtd     = "gtd"
+	FieldIs      = "is"
+)
+
+// 比较运算符
+const (
+	OpEq  = ":"
+	OpLt  = "<"
+	OpLte = "<="
+	OpGt  = ">"
+	OpGte = ">="
+)
+
+// 长度限制
+const (
+	MaxTerms    = 20
+	maxValueLen = 200
+)
+
+// fields 已知字段，值为 true 表示支持大小比较（时间字段），其余字段只能用 field:value
+var fields = map[string]bool{
+	FieldStatus:  false,
+	FieldTag:     false,
+	FieldProject: false,
+	FieldDue:     true,
+	FieldCreated: true,
+	FieldGtd:     false,
+	FieldIs:      false,
+}
+
+// Term 一个筛选项
+type Term struct {
+	Field  string `json:"field"`
+	Op     string `json:"op"`
+	Value  string `json:"value"`
+	Negate bool   `json:"negate"`
+}
+
+// String 还原为表达式写法；会被当作字段条件或情境标签的关键词同样加引号，保证再次解析结果不变
+func (t Term) String() string {
+	s := t.Value
+	if strings.ContainsAny(s, " \t") || (t.Field == FieldKeyword && keywordNeedsQuote(s)) {
+		s = `"` + s + `"`
+	}
+	if t.Field != FieldKeyword {
+		s = t.Field + t.Op + s
+	}
+	if t.Negate {
+		s = "-" + s
+	}
+	return s
+}
+
+func keywordNeedsQuote(s string) bool {
+	_, _, _, isField := cutField(s)
+	return isField || strings.HasPrefix(s, "@")
+}
+
+// Parse 解析表达式；空表达式返回错误，避免误选全部待办
+func Parse(expr string) ([]Term, error) {
+	words, err := split(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%w: query is empty", ErrInvalidQuery)
+	}
+	if len(words) > MaxTerms {
+		return nil, fmt.Errorf("%w: at most %d terms", ErrInvalidQuery, MaxTerms)
+	}
+	terms := make([]Term, 0, len(words))
+	for _, w := range words {
+		term, err := parseTerm(w)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// Format 把各项还原为规范化的表达式
+func Format(terms []Term) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// word 一个分词；quoted 表示以引号开头（可带取反前缀），整体按关键词处理
+type word struct {
+	text   string
+	quoted bool
+}
+
+// split 按空白分词，双引号内的空白保留；-"短语" 为取反的短语
+func split(expr string) ([]word, error) {
+	var words []word
+	var b strings.Builder
+	inQuote, quoted, started := false, false, false
+	flush := func() {
+		if started {
+			words = append(words, word{text: b.String(), quoted: quoted})
+		}
+		b.Reset()
+		inQuote, quoted, started = false, false, false
+	}
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			if !started || b.String() == "-" {
+				quoted = true
+			}
+			inQuote, started = !inQuote, true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			flush()
+		default:
+			b.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	flush()
+	return words, nil
+}
+
+func parseTerm(w word) (Term, error) {
+	var term Term
+	text := w.text
+	if strings.HasPrefix(text, "-") && len(text) > 1 {
+		term.Negate, text = true, text[1:]
+	}
+	if !w.quoted {
+		if field, op, value, ok := cutField(text); ok {
+			if op != OpEq && !fields[field] {
+				return Term{}, fmt.Errorf("%w: %s only supports %s", ErrInvalidQuery, field, OpEq)
+			}
+			term.Field, term.Op, term.Value = field, op, value
+		} else if strings.HasPrefix(text, "@") {
+			// 情境标签简写：@home 即 tag:@home
+			term.Field, term.Op, term.Value = FieldTag, OpEq, text
+		}
+	}
+	if term.Field == FieldKeyword {
+		term.Value = strings.TrimSpace(text)
+	}
+	if term.Value == "" {
+		return Term{}, fmt.Errorf("%w: empty value in %q", ErrInvalidQuery, w.text)
+	}
+	if utf8.RuneCountInString(term.Value) > maxValueLen {
+		return Term{}, fmt.Errorf("%w: value too long in %q", ErrInvalidQuery, w.text)
+	}
+	return term, nil
+}
+
+// cutField 拆出 field、运算符与值；字段名不认识时按关键词处理（如 “http://...”）
+func cutField(text string) (field, op, value string, ok bool) {
+	i := strings.IndexAny(text, ":<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	field = strings.ToLower(text[:i])
+	if _, known := fields[field]; !known {
+		return "", "", "", false
+	}
+	rest := text[i:]
+	for _, candidate := range []string{OpLte, OpGte, OpEq, OpLt, OpGt} {
+		if strings.HasPrefix(rest, candidate) {
+			return field, candidate, rest[len(candidate):], true
+		}
+	}
+	return "", "", "", false
+}
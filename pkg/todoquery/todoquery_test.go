@@ -0,0 +1,96 @@
+package todoquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []Term
+	}{
+		{"status:todo tag:work due<2026-11-01", []Term{
+			{Field: FieldStatus, Op: OpEq, Value: "todo"},
+			{Field: FieldTag, Op: OpEq, Value: "work"},
+			{Field: FieldDue, Op: OpLt, Value: "2026-11-01"},
+		}},
+		{`project:OPS -tag:@home "周报"`, []Term{
+			{Field: FieldProject, Op: OpEq, Value: "OPS"},
+			{Field: FieldTag, Op: OpEq, Value: "@home", Negate: true},
+			{Field: FieldKeyword, Value: "周报"},
+		}},
+		{"@office due:overdue", []Term{
+			{Field: FieldTag, Op: OpEq, Value: "@office"},
+			{Field: FieldDue, Op: OpEq, Value: "overdue"},
+		}},
+		{"created>=2026-10-01 due<=today due>tomorrow", []Term{
+			{Field: FieldCreated, Op: OpGte, Value: "2026-10-01"},
+			{Field: FieldDue, Op: OpLte, Value: "today"},
+			{Field: FieldDue, Op: OpGt, Value: "tomorrow"},
+		}},
+		{`-"weekly report"  STATUS:done`, []Term{
+			{Field: FieldKeyword, Value: "weekly report", Negate: true},
+			{Field: FieldStatus, Op: OpEq, Value: "done"},
+		}},
+		// 引号内的 field:value、@ 开头的词与未知字段都是关键词
+		{`"status:done" "@home" http://example.com OPS-17`, []Term{
+			{Field: FieldKeyword, Value: "status:done"},
+			{Field: FieldKeyword, Value: "@home"},
+			{Field: FieldKeyword, Value: "http://example.com"},
+			{Field: FieldKeyword, Value: "OPS-17"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+		// 规范化的表达式再次解析得到相同的筛选项
+		again, err := Parse(Format(got))
+		if err != nil || !reflect.DeepEqual(again, got) {
+			t.Errorf("Parse(Format(%q)) = %+v, %v; want %+v", tt.expr, again, err, got)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"only spaces", "   \t "},
+		{"comparison on non-date field", "status<todo"},
+		{"unterminated quote", `"weekly report`},
+		{"empty value", "tag:"},
+		{"empty quoted phrase", `""`},
+		{"too many terms", strings.Repeat("a ", MaxTerms+1)},
+		{"value too long", "tag:" + strings.Repeat("x", maxValueLen+1)},
+	}
+	for _, tt := range tests {
+		if terms, err := Parse(tt.expr); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: Parse(%q) = %+v, %v; want ErrInvalidQuery", tt.name, tt.expr, terms, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	terms := []Term{
+		{Field: FieldProject, Op: OpEq, Value: "OPS"},
+		{Field: FieldTag, Op: OpEq, Value: "@home", Negate: true},
+		{Field: FieldKeyword, Value: "weekly report"},
+		{Field: FieldDue, Op: OpLte, Value: "2026-10-20"},
+		{Field: FieldKeyword, Value: "status:done"},
+		{Field: FieldKeyword, Value: "@home"},
+	}
+	if got, want := Format(terms), `project:OPS -tag:@home "weekly report" due<=2026-10-20 "status:done" "@home"`; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,231 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RemoteTodo 服务端待办的可同步状态
+type RemoteTodo struct {
+	ID uint
+	State
+}
+
+// Remote 服务端操作，由 memogo HTTP API 实现
+type Remote interface {
+	List(ctx context.Context) ([]RemoteTodo, error)
+	Create(ctx context.Context, s State) (uint, error)
+	// Update 把 s 写回服务端，prev 为服务端当前状态，只需推送有变化的字段
+	Update(ctx context.Context, id uint, s, prev State) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// SyncResult 一轮同步的统计
+type SyncResult struct {
+	Created       int // 本地新任务创建到服务端
+	Pushed        int // 本地修改推送到服务端
+	Pulled        int // 服务端修改写回文件
+	Appended      int // 服务端新待办追加到收件箱文件
+	DeletedLocal  int // 服务端已删除，删除本地任务行
+	DeletedRemote int // 本地删除了任务行，删除服务端待办
+}
+
+// Syncer Markdown vault 与 memogo 的双向同步
+//
+// 以上一轮同步结束时的状态快照（StateFile）为基线做三方合并：
+// 只有本地变化则推送，只有服务端变化则写回文件，两端都变化时以服务端为准。
+type Syncer struct {
+	Vault     *Vault
+	Remote    Remote
+	InboxFile string // 服务端新建的待办追加到该文件（相对 vault 根目录）
+	StateFile string // 快照文件路径，默认 <root>/.memogo-sync.json
+	Now       func() time.Time
+}
+
+// Run 执行一轮同步
+func (s *Syncer) Run(ctx context.Context) (*SyncResult, error) {
+	snapshot, err := s.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.Vault.Scan()
+	if err != nil {
+		return nil, err
+	}
+	remoteTodos, err := s.Remote.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remote := make(map[uint]State, len(remoteTodos))
+	for _, t := range remoteTodos {
+		remote[t.ID] = t.State
+	}
+
+	result := &SyncResult{}
+	next := make(map[uint]State)
+	var edits []Edit
+	var appendLines []string
+	today := s.now().Format(DateLayout)
+
+	// fail 中途出错时也写回已完成的部分：已创建到服务端的任务若不写入 ID 与快照，
+	// 下一轮会再次创建，服务端那份还会被当作新待办追加到收件箱。
+	// 尚未处理的任务保留上一轮的快照基线
+	fail := func(err error) (*SyncResult, error) {
+		merged := make(map[uint]State, len(snapshot)+len(next))
+		for id, st := range snapshot {
+			merged[id] = st
+		}
+		for id, st := range next {
+			merged[id] = st
+		}
+		if commitErr := s.commit(edits, appendLines, merged); commitErr != nil {
+			return result, errors.Join(err, commitErr)
+		}
+		return result, err
+	}
+
+	for _, task := range tasks {
+		old := task.Render()
+		if task.ID != 0 {
+			if _, dup := next[task.ID]; dup {
+				log.Printf("vault sync: duplicate id %d in %s:%d, ignored", task.ID, task.File, task.Line+1)
+				continue
+			}
+		}
+
+		serverState, onServer := remote[task.ID]
+		base, tracked := snapshot[task.ID]
+
+		switch {
+		case task.ID == 0 || (!onServer && !tracked):
+			// 新任务（或带着未知 ID，例如从别的 vault 复制而来）：创建到服务端并写入 ID
+			id, err := s.Remote.Create(ctx, task.State())
+			if err != nil {
+				return fail(err)
+			}
+			task.ID = id
+			next[id] = task.State()
+			result.Created++
+			edits = append(edits, replaceEdit(task, old))
+
+		case !onServer:
+			// 上一轮还在、现在服务端没有了：服务端已删除
+			edits = append(edits, Edit{File: task.File, Line: task.Line, Old: old})
+			result.DeletedLocal++
+
+		default:
+			local := task.State()
+			localChanged := local != base
+			serverChanged := serverState != base
+			switch {
+			case serverChanged || (!tracked && local != serverState):
+				if local != serverState {
+					task.Apply(serverState, today)
+					edits = append(edits, replaceEdit(task, old))
+					result.Pulled++
+				}
+				next[task.ID] = serverState
+			case localChanged:
+				if err := s.Remote.Update(ctx, task.ID, local, serverState); err != nil {
+					return fail(err)
+				}
+				next[task.ID] = local
+				result.Pushed++
+			default:
+				next[task.ID] = local
+			}
+		}
+	}
+
+	// 服务端有、本地没有的待办
+	for _, t := range remoteTodos {
+		if _, seen := next[t.ID]; seen {
+			continue
+		}
+		if _, tracked := snapshot[t.ID]; tracked {
+			// 本地删除了任务行
+			if err := s.Remote.Delete(ctx, t.ID); err != nil {
+				return fail(err)
+			}
+			result.DeletedRemote++
+			continue
+		}
+		if t.Done {
+			continue // 首次同步不导入历史已完成待办
+		}
+		task := &Task{ID: t.ID}
+		task.Apply(t.State, today)
+		appendLines = append(appendLines, task.Render())
+		next[t.ID] = t.State
+		result.Appended++
+	}
+
+	return result, s.commit(edits, appendLines, next)
+}
+
+// commit 把改动写回文件、追加服务端新待办，并保存快照
+func (s *Syncer) commit(edits []Edit, appendLines []string, snapshot map[uint]State) error {
+	if _, err := s.Vault.ApplyEdits(edits); err != nil {
+		return err
+	}
+	if len(appendLines) > 0 {
+		if err := s.Vault.AppendLines(s.inboxFile(), appendLines); err != nil {
+			return err
+		}
+	}
+	return s.saveSnapshot(snapshot)
+}
+
+func replaceEdit(task *Task, old string) Edit {
+	line := task.Render()
+	return Edit{File: task.File, Line: task.Line, Old: old, New: &line}
+}
+
+func (s *Syncer) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *Syncer) inboxFile() string {
+	if s.InboxFile != "" {
+		return s.InboxFile
+	}
+	return "memogo.md"
+}
+
+func (s *Syncer) stateFile() string {
+	if s.StateFile != "" {
+		return s.StateFile
+	}
+	return filepath.Join(s.Vault.Root, ".memogo-sync.json")
+}
+
+func (s *Syncer) loadSnapshot() (map[uint]State, error) {
+	data, err := os.ReadFile(s.stateFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return map[uint]State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := map[uint]State{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *Syncer) saveSnapshot(snapshot map[uint]State) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.stateFile(), data, 0o644)
+}
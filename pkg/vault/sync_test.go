@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRemote 内存中的服务端；failCreates > 0 时第 failCreates 次创建起返回错误
+type fakeRemote struct {
+	todos       map[uint]State
+	nextID      uint
+	creates     int
+	failCreates int
+}
+
+var errRemoteDown = errors.New("remote unavailable")
+
+func (r *fakeRemote) List(ctx context.Context) ([]RemoteTodo, error) {
+	var out []RemoteTodo
+	for id, s := range r.todos {
+		out = append(out, RemoteTodo{ID: id, State: s})
+	}
+	return out, nil
+}
+
+func (r *fakeRemote) Create(ctx context.Context, s State) (uint, error) {
+	r.creates++
+	if r.failCreates > 0 && r.creates >= r.failCreates {
+		return 0, errRemoteDown
+	}
+	r.nextID++
+	r.todos[r.nextID] = s
+	return r.nextID, nil
+}
+
+func (r *fakeRemote) Update(ctx context.Context, id uint, s, prev State) error {
+	r.todos[id] = s
+	return nil
+}
+
+func (r *fakeRemote) Delete(ctx context.Context, id uint) error {
+	delete(r.todos, id)
+	return nil
+}
+
+func TestSyncKeepsCreatedIDsWhenRunFails(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "tasks.md")
+	if err := os.WriteFile(file, []byte("- [ ] first\n- [ ] second\n- [ ] third\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	remote := &fakeRemote{todos: map[uint]State{}, failCreates: 3}
+	syncer := &Syncer{Vault: &Vault{Root: root}, Remote: remote}
+
+	result, err := syncer.Run(context.Background())
+	if !errors.Is(err, errRemoteDown) {
+		t.Fatalf("Run() err = %v, want %v", err, errRemoteDown)
+	}
+	if result.Created != 2 {
+		t.Fatalf("Created = %d, want 2", result.Created)
+	}
+	data, _ := os.ReadFile(file)
+	if got := strings.Count(string(data), IDPrefix); got != 2 {
+		t.Fatalf("IDs written before the failure = %d, want 2:\n%s", got, data)
+	}
+
+	// 恢复后再同步：只创建剩下的一条，服务端的两条不会被重复创建或追加到收件箱
+	remote.failCreates = 0
+	result, err = syncer.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (SyncResult{Created: 1}) {
+		t.Fatalf("second Run() = %+v, want only 1 created", *result)
+	}
+	if len(remote.todos) != 3 {
+		t.Fatalf("remote has %d todos, want 3", len(remote.todos))
+	}
+	if _, err := os.Stat(filepath.Join(root, syncer.inboxFile())); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be appended to the inbox file (stat err = %v)", err)
+	}
+	data, _ = os.ReadFile(file)
+	if got := strings.Count(string(data), IDPrefix); got != 3 {
+		t.Fatalf("IDs after recovery = %d, want 3:\n%s", got, data)
+	}
+
+	if result, err = syncer.Run(context.Background()); err != nil || *result != (SyncResult{}) {
+		t.Fatalf("idle Run() = %+v, %v; want no changes", result, err)
+	}
+}
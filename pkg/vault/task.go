@@ -0,0 +1,127 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DateLayout 任务行中日期的格式
+	DateLayout = "2006-01-02"
+	// IDPrefix 行内 ID 前缀，例如 "🆔 memogo-42"
+	IDPrefix = "memogo-"
+
+	dueMarker  = "📅"
+	doneMarker = "✅"
+	idMarker   = "🆔"
+)
+
+var (
+	// "- [ ] 描述"，兼容 * / + 列表符与缩进
+	taskLineRe = regexp.MustCompile(`^(\s*[-*+] \[)([ xX])\] (.*)$`)
+	dueRe      = regexp.MustCompile(`\s*` + dueMarker + `\s*(\d{4}-\d{2}-\d{2})`)
+	doneRe     = regexp.MustCompile(`\s*` + doneMarker + `\s*(\d{4}-\d{2}-\d{2})`)
+	idRe       = regexp.MustCompile(`\s*` + idMarker + `\s*` + IDPrefix + `(\d+)`)
+)
+
+// Task Markdown 文件中的一行任务
+type Task struct {
+	File   string // 相对 vault 根目录的路径
+	Line   int    // 行号（从 0 开始）
+	Prefix string // 缩进与列表符，例如 "  - ["
+
+	ID       uint // memogo 待办 ID，0 表示尚未同步
+	Title    string
+	Done     bool
+	Due      string // YYYY-MM-DD，空表示无截止日期
+	DoneDate string // ✅ 完成日期，原样保留
+}
+
+// ParseTaskLine 解析任务行，不是任务行时返回 false
+func ParseTaskLine(line string) (*Task, bool) {
+	m := taskLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	task := &Task{
+		Prefix: m[1],
+		Done:   m[2] != " ",
+	}
+	body := m[3]
+
+	if sm := idRe.FindStringSubmatch(body); sm != nil {
+		if id, err := strconv.ParseUint(sm[1], 10, 64); err == nil {
+			task.ID = uint(id)
+		}
+		body = idRe.ReplaceAllString(body, "")
+	}
+	if sm := dueRe.FindStringSubmatch(body); sm != nil {
+		if _, err := time.Parse(DateLayout, sm[1]); err == nil {
+			task.Due = sm[1]
+		}
+		body = dueRe.ReplaceAllString(body, "")
+	}
+	if sm := doneRe.FindStringSubmatch(body); sm != nil {
+		task.DoneDate = sm[1]
+		body = doneRe.ReplaceAllString(body, "")
+	}
+	task.Title = strings.TrimSpace(body)
+	return task, true
+}
+
+// Render 生成任务行：描述、📅 截止日期、✅ 完成日期、🆔 ID 依次排列
+func (t *Task) Render() string {
+	prefix := t.Prefix
+	if prefix == "" {
+		prefix = "- ["
+	}
+	check := " "
+	if t.Done {
+		check = "x"
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteString(check)
+	b.WriteString("] ")
+	b.WriteString(t.Title)
+	if t.Due != "" {
+		b.WriteString(" " + dueMarker + " " + t.Due)
+	}
+	if t.Done && t.DoneDate != "" {
+		b.WriteString(" " + doneMarker + " " + t.DoneDate)
+	}
+	if t.ID != 0 {
+		b.WriteString(fmt.Sprintf(" %s %s%d", idMarker, IDPrefix, t.ID))
+	}
+	return b.String()
+}
+
+// State 任务的可同步状态（标题、完成、截止日期）
+type State struct {
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+	Due   string `json:"due"`
+}
+
+// State 返回任务当前的可同步状态
+func (t *Task) State() State {
+	return State{Title: t.Title, Done: t.Done, Due: t.Due}
+}
+
+// Apply 用远程状态覆盖任务，完成时补上 ✅ 日期
+func (t *Task) Apply(s State, today string) {
+	if s.Done && !t.Done && t.DoneDate == "" {
+		t.DoneDate = today
+	}
+	if !s.Done {
+		t.DoneDate = ""
+	}
+	// 任务必须保持单行
+	t.Title = strings.Join(strings.Fields(s.Title), " ")
+	t.Done = s.Done
+	t.Due = s.Due
+}
@@ -0,0 +1,167 @@
+package vault
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Vault Markdown 文件目录（Obsidian 风格 vault）
+type Vault struct {
+	Root string
+}
+
+// Edit 对某个文件某一行的修改；New 为 nil 表示删除该行
+type Edit struct {
+	File string
+	Line int
+	Old  string // 修改前的原始内容，用于发现并跳过并发编辑
+	New  *string
+}
+
+// Scan 扫描 vault 下全部 .md 文件中的任务行（跳过以 . 开头的目录，如 .obsidian）
+func (v *Vault) Scan() ([]*Task, error) {
+	var tasks []*Task
+	err := filepath.WalkDir(v.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != v.Root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(v.Root, path)
+		if err != nil {
+			return err
+		}
+		lines, err := readLines(path)
+		if err != nil {
+			return err
+		}
+		for i, line := range lines {
+			if task, ok := ParseTaskLine(line); ok {
+				task.File = rel
+				task.Line = i
+				tasks = append(tasks, task)
+			}
+		}
+		return nil
+	})
+	return tasks, err
+}
+
+// Dirs 返回 vault 内需要监听的全部目录
+func (v *Vault) Dirs() ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(v.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != v.Root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, err
+}
+
+// ApplyEdits 按文件批量应用行修改；行内容已被用户改动的修改会被跳过，下一轮同步再处理
+func (v *Vault) ApplyEdits(edits []Edit) (applied int, err error) {
+	byFile := make(map[string][]Edit)
+	for _, e := range edits {
+		byFile[e.File] = append(byFile[e.File], e)
+	}
+
+	for file, fileEdits := range byFile {
+		path := filepath.Join(v.Root, file)
+		lines, err := readLines(path)
+		if err != nil {
+			return applied, err
+		}
+
+		// 从后往前应用，删除行不会影响前面的行号
+		sort.Slice(fileEdits, func(i, j int) bool { return fileEdits[i].Line > fileEdits[j].Line })
+		changed := false
+		for _, e := range fileEdits {
+			if e.Line >= len(lines) || lines[e.Line] != e.Old {
+				continue
+			}
+			if e.New == nil {
+				lines = append(lines[:e.Line], lines[e.Line+1:]...)
+			} else {
+				lines[e.Line] = *e.New
+			}
+			changed = true
+			applied++
+		}
+		if changed {
+			if err := writeLines(path, lines); err != nil {
+				return applied, err
+			}
+		}
+	}
+	return applied, nil
+}
+
+// AppendLines 在文件末尾追加行，文件不存在时创建
+func (v *Vault) AppendLines(file string, newLines []string) error {
+	path := filepath.Join(v.Root, file)
+	lines, err := readLines(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// 去掉末尾空行再追加，避免每次同步都多出空行
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeLines(path, append(lines, newLines...))
+}
+
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// writeLines 先写临时文件再重命名，避免编辑器读到写了一半的文件
+func writeLines(path string, lines []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".memogo-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode())
+	}
+	return os.Rename(tmp.Name(), path)
+}
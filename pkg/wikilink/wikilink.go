@@ -0,0 +1,87 @@
+// Package wikilink 解析 Markdown 中的 [[wiki 链接]]：
+//
+//	[[#42]] / [[todo:42]]   指向待办 42
+//	[[OPS-17]]              按项目内编号指向待办（不区分大小写）
+//	[[2026-10-16]]          指向当天的日记
+//	[[周会纪要]]             按标题指向笔记
+//	[[周会纪要|上周的会]]     竖线后为显示文本，解析时忽略
+package wikilink
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 链接类型
+const (
+	KindTodo    = "todo"
+	KindJournal = "journal"
+	KindNote    = "note"
+)
+
+// DateLayout 日记链接的日期格式
+const DateLayout = "2006-01-02"
+
+// maxTargetLen 链接目标的最大长度（与笔记标题长度一致）
+const maxTargetLen = 200
+
+var (
+	linkRe    = regexp.MustCompile(`\[\[([^\[\]\n]+?)\]\]`)
+	todoRefRe = regexp.MustCompile(`(?i)^(?:#|todo:)\s*(\d+)$`)
+	todoKeyRe = regexp.MustCompile(`(?i)^[A-Z][A-Z0-9]{1,9}-[1-9][0-9]{0,8}$`)
+)
+
+// Link 一个 wiki 链接
+type Link struct {
+	Kind    string
+	Target  string // 规范化后的目标：待办为 ID 字符串或大写编号，日记为日期，笔记为标题
+	TodoID  uint   // Kind 为 todo 且按 ID 引用时有效
+	TodoKey string // Kind 为 todo 且按项目内编号引用时有效，如 OPS-17
+}
+
+// Parse 按出现顺序返回内容中的全部链接（同一目标只保留一次）
+func Parse(content string) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+	for _, m := range linkRe.FindAllStringSubmatch(content, -1) {
+		link, ok := classify(m[1])
+		if !ok {
+			continue
+		}
+		key := link.Kind + ":" + link.Target
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+func classify(raw string) (Link, bool) {
+	if i := strings.IndexByte(raw, '|'); i >= 0 {
+		raw = raw[:i]
+	}
+	target := strings.Join(strings.Fields(raw), " ")
+	if target == "" || utf8.RuneCountInString(target) > maxTargetLen {
+		return Link{}, false
+	}
+	if m := todoRefRe.FindStringSubmatch(target); m != nil {
+		id, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil || id == 0 {
+			return Link{}, false
+		}
+		return Link{Kind: KindTodo, Target: m[1], TodoID: uint(id)}, true
+	}
+	if todoKeyRe.MatchString(target) {
+		key := strings.ToUpper(target)
+		return Link{Kind: KindTodo, Target: key, TodoKey: key}, true
+	}
+	if _, err := time.Parse(DateLayout, target); err == nil {
+		return Link{Kind: KindJournal, Target: target}, true
+	}
+	return Link{Kind: KindNote, Target: target}, true
+}
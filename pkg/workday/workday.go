@@ -0,0 +1,269 @@
+// Package workday 提供中国法定节假日与调休工作日日历。
+//
+// 内置年份的数据随二进制发布（data/*.json），每年国务院公布次年安排后，
+// 可以把新年份的 JSON 放到 WORKDAY_DATA_DIR 目录中热加载，同名年份覆盖内置数据。
+// 未收录的年份按"周一至周五上班"处理。
+package workday
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"memogo/pkg/lunar"
+)
+
+// DateLayout 日期格式
+const DateLayout = "2006-01-02"
+
+// Location 节假日按北京时间计算（中国不实行夏令时，固定 UTC+8 即可）
+var Location = time.FixedZone("CST", 8*3600)
+
+var (
+	// ErrInvalidData 节假日数据格式错误
+	ErrInvalidData = errors.New("invalid holiday data")
+
+	//go:embed data/*.json
+	bundled embed.FS
+
+	defaultCalendar atomic.Pointer[Calendar]
+)
+
+// YearData 一年的节假日安排（与 data/*.json 格式一致）
+type YearData struct {
+	Year     int       `json:"year"`
+	Source   string    `json:"source,omitempty"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+// Holiday 一个假期：[Start, End] 放假，Workdays 为调休上班的周末
+type Holiday struct {
+	Name     string   `json:"name"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Workdays []string `json:"workdays,omitempty"`
+}
+
+// Day 某一天的日历信息
+type Day struct {
+	Date     string `json:"date"`
+	Weekday  int    `json:"weekday"` // 0=周日
+	Workday  bool   `json:"workday"`
+	Holiday  bool   `json:"holiday"`        // 法定假期放假
+	Adjusted bool   `json:"adjusted"`       // 调休上班的周末
+	Name     string `json:"name,omitempty"` // 所属假期名称
+	Lunar    string `json:"lunar"`          // 农历月日，如 "八月十五"
+}
+
+type special struct {
+	name string
+	off  bool
+}
+
+// Calendar 节假日日历，只读，可在多个 goroutine 间共享
+type Calendar struct {
+	days  map[string]special
+	years map[int]string // 年份 → 数据来源
+}
+
+// New 由若干年份的数据构建日历，后出现的年份覆盖先出现的同名年份
+func New(years ...YearData) (*Calendar, error) {
+	byYear := make(map[int]YearData)
+	for _, y := range years {
+		byYear[y.Year] = y
+	}
+
+	cal := &Calendar{days: make(map[string]special), years: make(map[int]string)}
+	for _, y := range byYear {
+		if y.Year <= 0 {
+			return nil, fmt.Errorf("%w: missing year", ErrInvalidData)
+		}
+		for _, h := range y.Holidays {
+			start, err := time.ParseInLocation(DateLayout, h.Start, Location)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s start: %v", ErrInvalidData, h.Name, err)
+			}
+			end, err := time.ParseInLocation(DateLayout, h.End, Location)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s end: %v", ErrInvalidData, h.Name, err)
+			}
+			if end.Before(start) {
+				return nil, fmt.Errorf("%w: %s ends before it starts", ErrInvalidData, h.Name)
+			}
+			for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+				cal.days[d.Format(DateLayout)] = special{name: h.Name, off: true}
+			}
+			for _, w := range h.Workdays {
+				if _, err := time.ParseInLocation(DateLayout, w, Location); err != nil {
+					return nil, fmt.Errorf("%w: %s workday: %v", ErrInvalidData, h.Name, err)
+				}
+				cal.days[w] = special{name: h.Name, off: false}
+			}
+		}
+		cal.years[y.Year] = y.Source
+	}
+	return cal, nil
+}
+
+// ParseYear 解析一年的 JSON 数据
+func ParseYear(data []byte) (YearData, error) {
+	var y YearData
+	if err := json.Unmarshal(data, &y); err != nil {
+		return y, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return y, nil
+}
+
+// Load 加载内置数据，并用 dir 下的 *.json 覆盖同名年份（dir 为空时只用内置数据）
+func Load(dir string) (*Calendar, error) {
+	var years []YearData
+
+	entries, err := bundled.ReadDir("data")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		data, err := bundled.ReadFile("data/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		y, err := ParseYear(data)
+		if err != nil {
+			return nil, fmt.Errorf("bundled %s: %w", e.Name(), err)
+		}
+		years = append(years, y)
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			y, err := ParseYear(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			years = append(years, y)
+		}
+	}
+	return New(years...)
+}
+
+// Default 返回全局日历；未设置时使用内置数据
+func Default() *Calendar {
+	if cal := defaultCalendar.Load(); cal != nil {
+		return cal
+	}
+	cal, err := Load("")
+	if err != nil {
+		// 内置数据随代码发布，解析失败属于编程错误
+		panic(err)
+	}
+	defaultCalendar.CompareAndSwap(nil, cal)
+	return defaultCalendar.Load()
+}
+
+// SetDefault 替换全局日历（热更新节假日数据时使用）
+func SetDefault(cal *Calendar) {
+	defaultCalendar.Store(cal)
+}
+
+// Years 已收录的年份及数据来源
+func (c *Calendar) Years() map[int]string {
+	out := make(map[int]string, len(c.years))
+	for y, src := range c.years {
+		out[y] = src
+	}
+	return out
+}
+
+// Covers 该年份是否有官方安排数据
+func (c *Calendar) Covers(year int) bool {
+	_, ok := c.years[year]
+	return ok
+}
+
+// Day 返回 t 所在日期（北京时间）的日历信息
+func (c *Calendar) Day(t time.Time) Day {
+	t = t.In(Location)
+	key := t.Format(DateLayout)
+	weekend := t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	d := Day{Date: key, Weekday: int(t.Weekday()), Workday: !weekend, Lunar: lunar.FromSolar(t).String()}
+	if sp, ok := c.days[key]; ok {
+		d.Name = sp.name
+		d.Holiday = sp.off
+		d.Adjusted = !sp.off && weekend
+		d.Workday = !sp.off
+	}
+	return d
+}
+
+// IsWorkday t 所在日期是否为工作日（含调休上班）
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	t = t.In(Location)
+	if sp, ok := c.days[t.Format(DateLayout)]; ok {
+		return !sp.off
+	}
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// Range 列出 [from, to] 每一天的日历信息
+func (c *Calendar) Range(from, to time.Time) []Day {
+	var out []Day
+	from, to = dateOf(from), dateOf(to)
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		out = append(out, c.Day(d))
+	}
+	return out
+}
+
+// NextWorkday 返回 t 之后（不含当天）的第 n 个工作日，保留时刻
+func (c *Calendar) NextWorkday(t time.Time, n int) time.Time {
+	if n < 1 {
+		n = 1
+	}
+	d := t.In(Location)
+	for n > 0 {
+		d = d.AddDate(0, 0, 1)
+		if c.IsWorkday(d) {
+			n--
+		}
+	}
+	return d.In(t.Location())
+}
+
+// Shift 把 t 调整到工作日：t 本身是工作日时原样返回，否则按 forward 顺延或提前，保留时刻
+func (c *Calendar) Shift(t time.Time, forward bool) time.Time {
+	step := 1
+	if !forward {
+		step = -1
+	}
+	d := t.In(Location)
+	for !c.IsWorkday(d) {
+		d = d.AddDate(0, 0, step)
+	}
+	return d.In(t.Location())
+}
+
+// ParseDate 解析北京时间的日期字符串
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, strings.TrimSpace(s), Location)
+}
+
+func dateOf(t time.Time) time.Time {
+	t = t.In(Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Location)
+}
@@ -4,7 +4,9 @@ package main
 
 import (
     "github.com/cloudwego/hertz/pkg/app/server"
+    "memogo/biz/dal/model"
     handler "memogo/biz/handler"
+    "memogo/pkg/middleware"
 )
 
 // customizeRegister registers customize routers.
@@ -13,4 +15,171 @@ func customizedRegister(r *server.Hertz) {
 
     // 自定义路由可以在这里添加
     // 说明：IDL 已使用 `:id` 语法，生成的路由可直接工作，无需额外别名
+
+    // 聊天集成：Slack 斜杠命令与交互回调（请求签名校验，无需 JWT）
+    slackGroup := r.Group("/v1/integrations/slack", middleware.SlackSignatureMiddleware())
+    slackGroup.POST("/commands", handler.SlackCommand)
+    slackGroup.POST("/interactions", handler.SlackInteraction)
+
+    // 聊天账号绑定（需要 JWT 认证）
+    chatGroup := r.Group("/v1/integrations/chat", middleware.JWTMiddleware.MiddlewareFunc())
+    chatGroup.POST("/link", handler.LinkChatAccount)
+    chatGroup.GET("/accounts", handler.ListChatAccounts)
+    chatGroup.DELETE("/accounts/:id", handler.UnlinkChatAccount)
+
+    // 待办补充接口（需要 JWT 认证）
+    r.GET("/v1/todos/:id", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetTodo)
+    r.PATCH("/v1/todos/:id", middleware.JWTMiddleware.MiddlewareFunc(), handler.UpdateTodo)
+    r.GET("/v1/todos/:id/recurrence", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetTodoRecurrence)
+    r.PUT("/v1/todos/:id/recurrence", middleware.JWTMiddleware.MiddlewareFunc(), handler.SetTodoRecurrence)
+
+    // 工作日日历（法定节假日与调休，需要 JWT 认证）
+    calendarGroup := r.Group("/v1/calendar", middleware.JWTMiddleware.MiddlewareFunc())
+    calendarGroup.GET("/workdays", handler.ListWorkdays)
+    calendarGroup.GET("/next-workday", handler.NextWorkday)
+    calendarGroup.GET("/settings", handler.GetCalendarSettings)
+    calendarGroup.PATCH("/settings", handler.UpdateCalendarSettings)
+
+    // 可打印 PDF 计划本（需要 JWT 认证）
+    r.GET("/v1/planner/pdf", middleware.JWTMiddleware.MiddlewareFunc(), middleware.RequireEntitlement(model.FeaturePlannerPDF), handler.ExportPlannerPDF)
+
+    // 项目树：领域 → 项目 → 分区（需要 JWT 认证）
+    projectGroup := r.Group("/v1/projects", middleware.JWTMiddleware.MiddlewareFunc())
+    projectGroup.POST("", middleware.RequireLimit(model.LimitProjects), handler.CreateProject)
+    projectGroup.GET("", handler.ListProjects)
+    projectGroup.GET("/tree", handler.GetProjectTree)
+    projectGroup.PATCH("/:id/parent", handler.MoveProject)
+    projectGroup.PATCH("/:id/key", handler.SetProjectKey)
+    projectGroup.DELETE("/:id", handler.DeleteProject)
+    projectGroup.POST("/:id/sprints", handler.CreateSprint)
+    projectGroup.GET("/:id/sprints", handler.ListSprints)
+    r.PATCH("/v1/todos/:id/project", middleware.JWTMiddleware.MiddlewareFunc(), handler.MoveTodoToProject)
+
+    // GitHub/GitLab issue 双向同步
+    issueGroup := r.Group("/v1/integrations/issues")
+    issueGroup.POST("/webhook/:id", handler.IssueWebhook) // 平台签名认证，无需 JWT
+    connGroup := issueGroup.Group("/connectors", middleware.JWTMiddleware.MiddlewareFunc())
+    connGroup.POST("", middleware.RequireEntitlement(model.FeatureIssueSync), handler.CreateIssueConnector)
+    connGroup.GET("", handler.ListIssueConnectors)
+    connGroup.DELETE("/:id", handler.DeleteIssueConnector)
+    connGroup.POST("/:id/sync", handler.SyncIssueConnector)
+
+    // 入站 webhook：外部系统推送 JSON 创建待办
+    r.POST("/v1/hooks/:token", handler.ReceiveIncomingWebhook) // URL 令牌 + 可选签名认证，无需 JWT
+    hookGroup := r.Group("/v1/webhooks/incoming", middleware.JWTMiddleware.MiddlewareFunc())
+    hookGroup.POST("", middleware.RequireEntitlement(model.FeatureIncomingWebhooks), middleware.RequireLimit(model.LimitIncomingWebhooks), handler.CreateIncomingWebhook)
+    hookGroup.GET("", handler.ListIncomingWebhooks)
+    hookGroup.DELETE("/:id", handler.DeleteIncomingWebhook)
+    hookGroup.POST("/:id/preview", handler.PreviewIncomingWebhook)
+
+    // 账号合并：当前账号 + 源账号密码的双重认证（需要 JWT 认证）
+    r.POST("/v1/account/merge", middleware.JWTMiddleware.MiddlewareFunc(), handler.MergeAccount)
+
+    // 管理员接口（需要 JWT 认证且角色为 admin）
+    adminGroup := r.Group("/v1/admin", middleware.JWTMiddleware.MiddlewareFunc(), middleware.AdminMiddleware())
+    adminGroup.POST("/users/merge", handler.AdminMergeAccounts)
+    adminGroup.GET("/audit-logs", handler.ListAuditLogs)
+    adminGroup.POST("/transfers", handler.AdminRequestTodoTransfer)
+    adminGroup.POST("/feature-flags", handler.CreateFeatureFlag)
+    adminGroup.GET("/feature-flags", handler.ListFeatureFlags)
+    adminGroup.PATCH("/feature-flags/:key", handler.UpdateFeatureFlag)
+    adminGroup.DELETE("/feature-flags/:key", handler.DeleteFeatureFlag)
+    adminGroup.GET("/feature-flags/:key/evaluate", handler.EvaluateFeatureFlag)
+
+    // 功能开关：当前用户的评估结果（需要 JWT 认证）
+    r.GET("/v1/feature-flags", middleware.JWTMiddleware.MiddlewareFunc(), handler.ListMyFeatureFlags)
+
+    // 笔记与日记：Markdown 正文中的 [[wiki 链接]] 建立与待办、笔记之间的反向链接（需要 JWT 认证）
+    noteGroup := r.Group("/v1/notes", middleware.JWTMiddleware.MiddlewareFunc())
+    noteGroup.POST("", handler.CreateNote)
+    noteGroup.GET("", handler.ListNotes)
+    noteGroup.GET("/:id", handler.GetNote)
+    noteGroup.PATCH("/:id", handler.UpdateNote)
+    noteGroup.DELETE("/:id", handler.DeleteNote)
+    noteGroup.GET("/journal/:date", handler.GetJournal)
+    noteGroup.PUT("/journal/:date", handler.SaveJournal)
+
+    // 目标：关联待办与项目，按完成情况计算进度并识别风险（需要 JWT 认证）
+    goalGroup := r.Group("/v1/goals", middleware.JWTMiddleware.MiddlewareFunc())
+    goalGroup.POST("", handler.CreateGoal)
+    goalGroup.GET("", handler.ListGoals)
+    goalGroup.GET("/:id", handler.GetGoal)
+    goalGroup.PATCH("/:id", handler.UpdateGoal)
+    goalGroup.DELETE("/:id", handler.DeleteGoal)
+    goalGroup.PUT("/:id/todos/:target_id", handler.LinkGoalTodo)
+    goalGroup.DELETE("/:id/todos/:target_id", handler.UnlinkGoalTodo)
+    goalGroup.PUT("/:id/projects/:target_id", handler.LinkGoalProject)
+    goalGroup.DELETE("/:id/projects/:target_id", handler.UnlinkGoalProject)
+
+    // 迭代：项目下的冲刺周期，关闭时结转未完成的待办，燃尽图按状态变更历史计算（需要 JWT 认证）
+    sprintGroup := r.Group("/v1/sprints", middleware.JWTMiddleware.MiddlewareFunc())
+    sprintGroup.GET("/:id", handler.GetSprint)
+    sprintGroup.PATCH("/:id", handler.UpdateSprint)
+    sprintGroup.DELETE("/:id", handler.DeleteSprint)
+    sprintGroup.PUT("/:id/todos/:todo_id", handler.AddSprintTodo)
+    sprintGroup.DELETE("/:id/todos/:todo_id", handler.RemoveSprintTodo)
+    sprintGroup.POST("/:id/close", handler.CloseSprint)
+    sprintGroup.GET("/:id/burndown", handler.GetSprintBurndown)
+
+    // 习惯：按计划打卡，统计连续次数与完成率（需要 JWT 认证）
+    habitGroup := r.Group("/v1/habits", middleware.JWTMiddleware.MiddlewareFunc())
+    habitGroup.POST("", handler.CreateHabit)
+    habitGroup.GET("", handler.ListHabits)
+    habitGroup.GET("/:id", handler.GetHabit)
+    habitGroup.PATCH("/:id", handler.UpdateHabit)
+    habitGroup.DELETE("/:id", handler.DeleteHabit)
+    habitGroup.PUT("/:id/check-ins/:date", handler.CheckInHabit)
+    habitGroup.DELETE("/:id/check-ins/:date", handler.UndoHabitCheckIn)
+    habitGroup.GET("/:id/history", handler.GetHabitHistory)
+
+    // 待办所有权转移：原所有者发起、接收方接受后生效（需要 JWT 认证）
+    transferGroup := r.Group("/v1/transfers", middleware.JWTMiddleware.MiddlewareFunc())
+    transferGroup.POST("", handler.RequestTodoTransfer)
+    transferGroup.GET("", handler.ListTodoTransfers)
+    transferGroup.GET("/:id", handler.GetTodoTransfer)
+    transferGroup.POST("/:id/accept", handler.AcceptTodoTransfer)
+    transferGroup.POST("/:id/decline", handler.DeclineTodoTransfer)
+    transferGroup.POST("/:id/cancel", handler.CancelTodoTransfer)
+
+    // 等待他人：记录被委托人与预计日期，逾期后自动生成跟进待办（需要 JWT 认证）
+    r.GET("/v1/todos/:id/waiting", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetTodoWaiting)
+    r.PUT("/v1/todos/:id/waiting", middleware.JWTMiddleware.MiddlewareFunc(), handler.SetTodoWaiting)
+    r.DELETE("/v1/todos/:id/waiting", middleware.JWTMiddleware.MiddlewareFunc(), handler.ClearTodoWaiting)
+    r.GET("/v1/waiting", middleware.JWTMiddleware.MiddlewareFunc(), handler.ListWaiting)
+
+    // GTD：收集箱、处理、按情境的下一步行动、将来 / 也许（需要 JWT 认证）
+    gtdGroup := r.Group("/v1/gtd", middleware.JWTMiddleware.MiddlewareFunc())
+    gtdGroup.POST("/inbox", middleware.RequireLimit(model.LimitTodos), handler.CaptureInbox)
+    gtdGroup.GET("/inbox", handler.ListInbox)
+    gtdGroup.POST("/inbox/:id/process", handler.ProcessInboxItem)
+    gtdGroup.GET("/next-actions", handler.ListNextActions)
+    gtdGroup.GET("/someday", handler.ListSomeday)
+
+    // 标签（@ 开头的为情境标签）
+    r.PUT("/v1/todos/:id/tags", middleware.JWTMiddleware.MiddlewareFunc(), handler.SetTodoTags)
+    r.GET("/v1/tags", middleware.JWTMiddleware.MiddlewareFunc(), handler.ListTags)
+
+    // 改期历史与拖延统计
+    r.GET("/v1/todos/:id/reschedules", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetTodoReschedules)
+    r.GET("/v1/stats/reschedules", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetRescheduleStats)
+
+    // 套餐与权益：用户查看自己的权益，管理员维护套餐与分配
+    r.GET("/v1/entitlements", middleware.JWTMiddleware.MiddlewareFunc(), handler.GetMyEntitlements)
+    adminGroup.POST("/plans", handler.CreatePlan)
+    adminGroup.GET("/plans", handler.ListPlans)
+    adminGroup.PATCH("/plans/:code", handler.UpdatePlan)
+    adminGroup.DELETE("/plans/:code", handler.DeletePlan)
+    adminGroup.PUT("/plan-assignments", handler.AssignPlan)
+    adminGroup.GET("/plan-assignments", handler.ListPlanAssignments)
+    adminGroup.DELETE("/plan-assignments/:subject_type/:subject_id", handler.UnassignPlan)
+    adminGroup.POST("/plan-assignments/:subject_type/:subject_id/sync", handler.SyncPlanAssignment)
+
+    // 按筛选表达式批量操作待办：先预览拿确认令牌，再凭令牌执行（需要 JWT 认证）
+    bulkGroup := r.Group("/v1/todos/bulk", middleware.JWTMiddleware.MiddlewareFunc())
+    bulkGroup.GET("", handler.ListBulkUpdates)
+    bulkGroup.POST("/preview", handler.PreviewBulkUpdate)
+    bulkGroup.POST("/execute", handler.ExecuteBulkUpdate)
+
+    // 自适应并发限制的当前状态（管理员；该路径不受并发限制）
+    adminGroup.GET("/concurrency", handler.GetConcurrencyStats)
 }